@@ -1,12 +1,16 @@
 package v1
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/riser-platform/riser-server/pkg/deployment"
 	"github.com/riser-platform/riser-server/pkg/environment"
+	"github.com/riser-platform/riser-server/pkg/rollout"
+	"github.com/riser-platform/riser-server/pkg/scheduledjob"
 
 	"github.com/riser-platform/riser-server/pkg/core"
 
@@ -20,7 +24,7 @@ import (
 )
 
 // TODO: Refactor and add unit test coverage
-func PostDeployment(c echo.Context, stateRepo git.Repo, appService app.Service, deploymentService deployment.Service, environmentService environment.Service) error {
+func PostDeployment(c echo.Context, stateRepo git.Repo, appService app.Service, deploymentService deployment.Service, environmentService environment.Service, scheduledJobService scheduledjob.Service) error {
 	deploymentRequest := &model.DeploymentRequest{}
 	err := c.Bind(deploymentRequest)
 	if err != nil {
@@ -29,14 +33,23 @@ func PostDeployment(c echo.Context, stateRepo git.Repo, appService app.Service,
 
 	isDryRun := c.QueryParam("dryRun") == "true"
 
-	err = environmentService.ValidateDeployable(deploymentRequest.Environment)
-	if err != nil {
-		return err
-	}
+	newDeployments := []*core.DeploymentConfig{}
+	for _, envName := range deploymentRequest.EnvironmentNames() {
+		err = environmentService.ValidateDeployable(envName)
+		if err != nil {
+			return err
+		}
 
-	newDeployment, err := mapDeploymentRequestToDomain(deploymentRequest)
-	if err != nil {
-		return err
+		environmentConfig, err := environmentService.GetConfig(envName)
+		if err != nil {
+			return err
+		}
+
+		newDeployment, err := deployment.NewDeploymentConfig(deploymentRequest, envName, environmentConfig)
+		if err != nil {
+			return err
+		}
+		newDeployments = append(newDeployments, newDeployment)
 	}
 
 	err = appService.CheckID(deploymentRequest.App.AppConfig.Id, core.NewNamespacedName(string(deploymentRequest.App.Name), string(deploymentRequest.App.Namespace)))
@@ -44,18 +57,36 @@ func PostDeployment(c echo.Context, stateRepo git.Repo, appService app.Service,
 		return err
 	}
 
+	// A scheduled dry run is applied immediately so that the changes can be reviewed ahead of time
+	if deploymentRequest.Schedule != nil && !isDryRun {
+		job, err := scheduledJobService.ScheduleDeployment(*deploymentRequest.Schedule, deploymentRequest, newDeployments)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusAccepted, model.DeploymentResponse{
+			Message:          fmt.Sprintf("Deployment scheduled for %s", job.RunAt.Format(time.RFC3339)),
+			ScheduledJobId:   &job.Id,
+			DeprecatedFields: deploymentRequest.App.DeprecatedFields(),
+		})
+	}
+
 	var committer state.Committer
 
 	if isDryRun {
-		committer = state.NewDryRunCommitter()
+		committer = state.NewDryRunCommitterWithDiff(stateRepo)
 	} else {
 		committer = state.NewGitCommitter(stateRepo)
 	}
 
-	riserRevision, err := deploymentService.Update(newDeployment, committer, isDryRun)
+	response := model.DeploymentResponse{Message: "Deployment requested", DeprecatedFields: deploymentRequest.App.DeprecatedFields()}
+	if len(newDeployments) == 1 {
+		response.RiserRevision, err = deploymentService.Update(newDeployments[0], committer, isDryRun)
+	} else {
+		response.RiserRevisions, err = deploymentService.UpdateMany(newDeployments, committer, isDryRun)
+	}
 	if err != nil {
 		if err == git.ErrNoChanges {
-			return c.JSON(http.StatusOK, model.DeploymentResponse{Message: "No changes to deploy"})
+			return c.JSON(http.StatusOK, model.DeploymentResponse{Message: "No changes to deploy", DeprecatedFields: response.DeprecatedFields})
 		}
 		return err
 	}
@@ -64,12 +95,13 @@ func PostDeployment(c echo.Context, stateRepo git.Repo, appService app.Service,
 		dryRunCommitter := committer.(*state.DryRunComitter)
 		return c.JSON(http.StatusAccepted, model.DeploymentResponse{
 
-			Message:       "Dry run: changes not applied",
-			DryRunCommits: mapDryRunCommitsFromDomain(dryRunCommitter.Commits),
+			Message:          "Dry run: changes not applied",
+			DryRunCommits:    mapDryRunCommitsFromDomain(dryRunCommitter.Commits),
+			DeprecatedFields: response.DeprecatedFields,
 		})
 	}
 
-	return c.JSON(http.StatusAccepted, model.DeploymentResponse{RiserRevision: riserRevision, Message: "Deployment requested"})
+	return c.JSON(http.StatusAccepted, response)
 }
 
 func DeleteDeployment(c echo.Context, stateRepo git.Repo, deploymentService deployment.Service) error {
@@ -84,7 +116,7 @@ func DeleteDeployment(c echo.Context, stateRepo git.Repo, deploymentService depl
 	return c.JSON(http.StatusAccepted, model.APIResponse{Message: "Deployment deletion requested"})
 }
 
-func PutDeploymentStatus(c echo.Context, deployments core.DeploymentRepository) error {
+func PutDeploymentStatus(c echo.Context, deployments core.DeploymentRepository, rolloutService rollout.Service, stateRepo git.Repo) error {
 	deploymentStatus := &model.DeploymentStatusMutable{}
 	err := c.Bind(deploymentStatus)
 	if err != nil {
@@ -95,12 +127,31 @@ func PutDeploymentStatus(c echo.Context, deployments core.DeploymentRepository)
 	namespace := c.Param("namespace")
 	envName := c.Param("envName")
 
-	err = deployments.UpdateStatus(core.NewNamespacedName(deploymentName, namespace), envName, mapDeploymentStatusFromModel(deploymentStatus))
+	name := core.NewNamespacedName(deploymentName, namespace)
+	err = deployments.UpdateStatus(name, envName, mapDeploymentStatusFromModel(deploymentStatus))
 	if err == core.ErrConflictNewerVersion {
 		return echo.NewHTTPError(http.StatusConflict, "A newer revision of the deployment has been observed or the deployment does not exist in this environment")
 	}
+	if err != nil {
+		return err
+	}
+
+	// The status is saved regardless of whether the held traffic is applied. Both are idempotent so that a failure is retried when the
+	// status is next reported.
+	preDeployJob := deploymentStatus.PreDeployJob
+	if preDeployJob != nil {
+		switch preDeployJob.Status {
+		case model.PreDeployJobStatusSucceeded:
+			err = rolloutService.ApplyPreDeployTraffic(name, envName, preDeployJob.RiserRevision, state.NewGitCommitter(stateRepo))
+		case model.PreDeployJobStatusFailed:
+			err = rolloutService.DiscardPreDeployTraffic(name, envName, preDeployJob.RiserRevision)
+		}
+		if err != nil {
+			c.Logger().Error(fmt.Sprintf("Error updating the pre-deploy traffic of %q in environment %q: %+v", name, envName, err))
+		}
+	}
 
-	return err
+	return nil
 }
 
 func mapDryRunCommitsFromDomain(commits []state.DryRunCommit) []model.DryRunCommit {
@@ -112,25 +163,11 @@ func mapDryRunCommitsFromDomain(commits []state.DryRunCommit) []model.DryRunComm
 		for _, file := range commit.Files {
 			modelCommit.Files = append(modelCommit.Files, model.DryRunFile{Name: file.Name, Contents: string(file.Contents)})
 		}
+		for _, diff := range commit.Diffs {
+			modelCommit.Diffs = append(modelCommit.Diffs, model.DryRunFileDiff{Name: diff.Name, Change: diff.Change, Diff: diff.Diff})
+		}
 		out = append(out, modelCommit)
 	}
 
 	return out
 }
-
-func mapDeploymentRequestToDomain(deploymentRequest *model.DeploymentRequest) (*core.DeploymentConfig, error) {
-	app, err := deploymentRequest.App.ApplyOverrides(deploymentRequest.Environment)
-	if err != nil {
-		return nil, err
-	}
-	return &core.DeploymentConfig{
-		Name:            deploymentRequest.Name,
-		Namespace:       string(app.Namespace),
-		EnvironmentName: deploymentRequest.Environment,
-		Docker: core.DeploymentDocker{
-			Tag: deploymentRequest.Docker.Tag,
-		},
-		App:           app,
-		ManualRollout: deploymentRequest.ManualRollout,
-	}, nil
-}
@@ -6,12 +6,16 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
 	"github.com/riser-platform/riser-server/pkg/git"
-	"github.com/riser-platform/riser-server/pkg/util"
 
+	"github.com/riser-platform/riser-server/pkg/app"
 	"github.com/riser-platform/riser-server/pkg/deployment"
+	"github.com/riser-platform/riser-server/pkg/deploymentstatus"
+	"github.com/riser-platform/riser-server/pkg/environment"
+	"github.com/riser-platform/riser-server/pkg/rollout"
 
 	"github.com/riser-platform/riser-server/api/v1/model"
 
@@ -79,7 +83,7 @@ func Test_PutDeploymentStatus_UpdatesStatus(t *testing.T) {
 		},
 	}
 
-	err := PutDeploymentStatus(ctx, &deploymentRepository)
+	err := PutDeploymentStatus(ctx, &deploymentRepository, nil, nil)
 
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
@@ -101,7 +105,7 @@ func Test_PutDeploymentStatus_Returns401IfConflict(t *testing.T) {
 		},
 	}
 
-	err := PutDeploymentStatus(ctx, &deploymentRepository)
+	err := PutDeploymentStatus(ctx, &deploymentRepository, nil, nil)
 
 	require.IsType(t, &echo.HTTPError{}, err)
 	httpErr := err.(*echo.HTTPError)
@@ -124,11 +128,221 @@ func Test_PutDeploymentStatus_ReturnsErr(t *testing.T) {
 		},
 	}
 
-	err := PutDeploymentStatus(ctx, &deploymentRepository)
+	err := PutDeploymentStatus(ctx, &deploymentRepository, nil, nil)
 
 	assert.Error(t, err)
 }
 
+func Test_PutDeploymentStatus_AppliesPreDeployTraffic(t *testing.T) {
+	deploymentStatus := &model.DeploymentStatusMutable{
+		ObservedRiserRevision: 2,
+		PreDeployJob: &model.DeploymentPreDeployJobStatus{
+			RiserRevision: 2,
+			Status:        model.PreDeployJobStatusSucceeded,
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/deployments/dev/myns/mydep/status", safeMarshal(deploymentStatus))
+	req.Header.Add("CONTENT-TYPE", "application/json")
+	ctx, _ := newContextWithRecorder(req)
+	ctx.SetParamNames("envName", "namespace", "deploymentName")
+	ctx.SetParamValues("dev", "myns", "mydep")
+
+	deploymentRepository := core.FakeDeploymentRepository{
+		UpdateStatusFn: func(name *core.NamespacedName, envName string, status *core.DeploymentStatus) error {
+			return nil
+		},
+	}
+
+	rolloutService := &rollout.FakeService{
+		ApplyPreDeployTrafficFn: func(name *core.NamespacedName, envName string, riserRevision int64, committer state.Committer) error {
+			assert.Equal(t, core.NewNamespacedName("mydep", "myns"), name)
+			assert.Equal(t, "dev", envName)
+			assert.EqualValues(t, 2, riserRevision)
+			return nil
+		},
+	}
+
+	err := PutDeploymentStatus(ctx, &deploymentRepository, rolloutService, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rolloutService.ApplyPreDeployTrafficCallCount)
+}
+
+func Test_PutDeploymentStatus_DoesNotApplyPreDeployTrafficUntilSucceeded(t *testing.T) {
+	deploymentStatus := &model.DeploymentStatusMutable{
+		ObservedRiserRevision: 2,
+		PreDeployJob: &model.DeploymentPreDeployJobStatus{
+			RiserRevision: 2,
+			Status:        model.PreDeployJobStatusRunning,
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/deployments/dev/myns/mydep/status", safeMarshal(deploymentStatus))
+	req.Header.Add("CONTENT-TYPE", "application/json")
+	ctx, _ := newContextWithRecorder(req)
+
+	deploymentRepository := core.FakeDeploymentRepository{
+		UpdateStatusFn: func(name *core.NamespacedName, envName string, status *core.DeploymentStatus) error {
+			return nil
+		},
+	}
+
+	rolloutService := &rollout.FakeService{}
+
+	err := PutDeploymentStatus(ctx, &deploymentRepository, rolloutService, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, rolloutService.ApplyPreDeployTrafficCallCount)
+}
+
+func Test_PutDeploymentStatus_DiscardsPreDeployTrafficWhenFailed(t *testing.T) {
+	deploymentStatus := &model.DeploymentStatusMutable{
+		ObservedRiserRevision: 2,
+		PreDeployJob: &model.DeploymentPreDeployJobStatus{
+			RiserRevision: 2,
+			Status:        model.PreDeployJobStatusFailed,
+			StatusReason:  "BackoffLimitExceeded",
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/deployments/dev/myns/mydep/status", safeMarshal(deploymentStatus))
+	req.Header.Add("CONTENT-TYPE", "application/json")
+	ctx, _ := newContextWithRecorder(req)
+	ctx.SetParamNames("envName", "namespace", "deploymentName")
+	ctx.SetParamValues("dev", "myns", "mydep")
+
+	deploymentRepository := core.FakeDeploymentRepository{
+		UpdateStatusFn: func(name *core.NamespacedName, envName string, status *core.DeploymentStatus) error {
+			return nil
+		},
+	}
+
+	rolloutService := &rollout.FakeService{
+		DiscardPreDeployTrafficFn: func(name *core.NamespacedName, envName string, riserRevision int64) error {
+			assert.Equal(t, core.NewNamespacedName("mydep", "myns"), name)
+			assert.Equal(t, "dev", envName)
+			assert.EqualValues(t, 2, riserRevision)
+			return nil
+		},
+	}
+
+	err := PutDeploymentStatus(ctx, &deploymentRepository, rolloutService, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rolloutService.DiscardPreDeployTrafficCallCount)
+	assert.Equal(t, 0, rolloutService.ApplyPreDeployTrafficCallCount)
+}
+
+func Test_PutDeploymentStatus_WhenApplyPreDeployTrafficFails(t *testing.T) {
+	deploymentStatus := &model.DeploymentStatusMutable{
+		ObservedRiserRevision: 2,
+		PreDeployJob: &model.DeploymentPreDeployJobStatus{
+			RiserRevision: 2,
+			Status:        model.PreDeployJobStatusSucceeded,
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/deployments/dev/myns/mydep/status", safeMarshal(deploymentStatus))
+	req.Header.Add("CONTENT-TYPE", "application/json")
+	ctx, rec := newContextWithRecorder(req)
+	ctx.SetParamNames("envName", "namespace", "deploymentName")
+	ctx.SetParamValues("dev", "myns", "mydep")
+
+	deploymentRepository := core.FakeDeploymentRepository{
+		UpdateStatusFn: func(name *core.NamespacedName, envName string, status *core.DeploymentStatus) error {
+			return nil
+		},
+	}
+
+	rolloutService := &rollout.FakeService{
+		ApplyPreDeployTrafficFn: func(name *core.NamespacedName, envName string, riserRevision int64, committer state.Committer) error {
+			return errors.New("commit failed")
+		},
+	}
+
+	err := PutDeploymentStatus(ctx, &deploymentRepository, rolloutService, nil)
+
+	// The status was saved so the error is logged and the traffic is applied when the status is next reported
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+	assert.Equal(t, 1, deploymentRepository.UpdateStatusCallCount)
+	assert.Equal(t, 1, rolloutService.ApplyPreDeployTrafficCallCount)
+}
+
+func Test_PutDeploymentStatus_WorkerRoundTrip(t *testing.T) {
+	// A worker is reported as described in model.DeploymentStatusMutable: no traffic and a revision per ReplicaSet
+	workerStatus := model.DeploymentStatusMutable{
+		ObservedRiserRevision: 2,
+		Revisions: []model.DeploymentRevisionStatus{
+			{Name: "myworker-2", DockerImage: "myimage:v2", RiserRevision: 2, RevisionStatus: "Waiting", RevisionStatusReason: "ContainerCreating"},
+			{Name: "myworker-1", DockerImage: "myimage:v1", RiserRevision: 1, RevisionStatus: "Ready"},
+		},
+		LatestCreatedRevisionName: "myworker-2",
+		LatestReadyRevisionName:   "myworker-1",
+	}
+	appId := uuid.New()
+	deploymentId := uuid.New()
+	var stored *core.DeploymentStatus
+	deploymentRepository := &core.FakeDeploymentRepository{
+		UpdateStatusFn: func(name *core.NamespacedName, envName string, status *core.DeploymentStatus) error {
+			stored = status
+			return nil
+		},
+		FindByAppFn: func(id uuid.UUID) ([]core.Deployment, error) {
+			return []core.Deployment{
+				{
+					DeploymentReservation: core.DeploymentReservation{AppId: appId, Name: "myworker", Namespace: "myns"},
+					DeploymentRecord: core.DeploymentRecord{
+						Id:              deploymentId,
+						EnvironmentName: "dev",
+						RiserRevision:   2,
+						Doc:             core.DeploymentDoc{Status: stored},
+					},
+				},
+			}, nil
+		},
+		FindRevisionsByAppFn: func(id uuid.UUID, limit int) ([]core.DeploymentRevision, error) {
+			return []core.DeploymentRevision{}, nil
+		},
+	}
+
+	putReq := httptest.NewRequest(http.MethodPut, "/deployments/dev/myns/myworker/status", safeMarshal(workerStatus))
+	putReq.Header.Add("CONTENT-TYPE", "application/json")
+	putCtx, putRec := newContextWithRecorder(putReq)
+	putCtx.SetParamNames("envName", "namespace", "deploymentName")
+	putCtx.SetParamValues("dev", "myns", "myworker")
+
+	err := PutDeploymentStatus(putCtx, deploymentRepository, nil, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, putRec.Result().StatusCode)
+
+	appService := app.NewService(&core.FakeAppRepository{
+		GetByNameFn: func(name *core.NamespacedName) (*core.App, error) {
+			return &core.App{Id: appId, Name: "myworker", Namespace: "myns"}, nil
+		},
+	}, nil)
+	envService := &environment.FakeService{
+		GetStatusFn: func(envName string) (*core.EnvironmentStatus, error) {
+			return &core.EnvironmentStatus{EnvironmentName: envName, Healthy: true}, nil
+		},
+	}
+	getReq := httptest.NewRequest(http.MethodGet, "/apps/myns/myworker/status", nil)
+	getCtx, getRec := newContextWithRecorder(getReq)
+	getCtx.SetParamNames("namespace", "appName")
+	getCtx.SetParamValues("myns", "myworker")
+
+	err = GetAppStatus(getCtx, appService, deploymentstatus.NewService(deploymentRepository, envService))
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, getRec.Result().StatusCode)
+	appStatus := model.AppStatus{}
+	require.NoError(t, json.Unmarshal(getRec.Body.Bytes(), &appStatus))
+	require.Len(t, appStatus.Deployments, 1)
+	assert.Equal(t, workerStatus, appStatus.Deployments[0].DeploymentStatusMutable)
+}
+
 func Test_mapDryRunCommitsFromDomain(t *testing.T) {
 	commits := []state.DryRunCommit{
 		{
@@ -146,6 +360,13 @@ func Test_mapDryRunCommitsFromDomain(t *testing.T) {
 		},
 		{
 			Message: "commit2",
+			Diffs: []state.FileDiff{
+				{
+					Name:   "file3",
+					Change: state.FileChangeAdded,
+					Diff:   "diff3",
+				},
+			},
 		},
 	}
 
@@ -158,63 +379,9 @@ func Test_mapDryRunCommitsFromDomain(t *testing.T) {
 	assert.Equal(t, "contents1", result[0].Files[0].Contents)
 	assert.Equal(t, "file2", result[0].Files[1].Name)
 	assert.Equal(t, "contents2", result[0].Files[1].Contents)
+	assert.Empty(t, result[0].Diffs)
 	assert.Equal(t, result[1].Message, "commit2")
 	assert.Empty(t, result[1].Files)
-}
-
-func Test_mapDeploymentRequestToDomain(t *testing.T) {
-	request := &model.DeploymentRequest{
-		DeploymentMeta: model.DeploymentMeta{
-			Name:        "mydeployment",
-			Environment: "myenv",
-			Docker: model.DeploymentDocker{
-				Tag: "mytag",
-			},
-			ManualRollout: true,
-		},
-		App: &model.AppConfigWithOverrides{
-			AppConfig: model.AppConfig{
-				Name:      "myapp",
-				Namespace: "myns",
-			},
-		},
-	}
-
-	result, err := mapDeploymentRequestToDomain(request)
-
-	assert.NoError(t, err)
-	assert.Equal(t, "mydeployment", result.Name)
-	assert.Equal(t, "myns", result.Namespace)
-	assert.Equal(t, "myenv", result.EnvironmentName)
-	assert.Equal(t, "mytag", result.Docker.Tag)
-	assert.Equal(t, request.App.AppConfig, *result.App)
-	assert.True(t, result.ManualRollout)
-}
-
-func Test_mapDeploymentRequestToDomain_Overrides(t *testing.T) {
-	request := &model.DeploymentRequest{
-		DeploymentMeta: model.DeploymentMeta{
-			Name:        "mydeployment",
-			Environment: "myenv",
-			Docker: model.DeploymentDocker{
-				Tag: "mytag",
-			},
-		},
-		App: &model.AppConfigWithOverrides{
-			AppConfig: model.AppConfig{},
-			Overrides: map[string]model.OverrideableAppConfig{
-				"myenv": {
-					Autoscale: &model.AppConfigAutoscale{
-						Min: util.PtrInt(1),
-					},
-				},
-			},
-		},
-	}
-
-	result, err := mapDeploymentRequestToDomain(request)
-
-	assert.NoError(t, err)
-	assert.Equal(t, 1, *result.App.Autoscale.Min)
-
+	require.Len(t, result[1].Diffs, 1)
+	assert.Equal(t, model.DryRunFileDiff{Name: "file3", Change: model.DryRunFileChange_Added, Diff: "diff3"}, result[1].Diffs[0])
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/riser-platform/riser-server/api/v1/model"
 
 	"github.com/labstack/echo/v4"
+	"github.com/riser-platform/riser-server/pkg/admission"
 	"github.com/riser-platform/riser-server/pkg/core"
 	"github.com/riser-platform/riser-server/pkg/environment"
 )
@@ -36,6 +37,11 @@ func PutEnvironmentConfig(c echo.Context, environmentService environment.Service
 		return err
 	}
 
+	err = admission.ValidatePolicies(environmentConfig.AdmissionPolicies)
+	if err != nil {
+		return core.NewValidationError("invalid admission policy", err)
+	}
+
 	err = environmentService.SetConfig(envName, mapEnvironmentConfigToDomain(environmentConfig))
 	if err != nil {
 		return err
@@ -78,8 +84,22 @@ func mapEnvironmentMetaArrayFromDomain(domainArray []core.Environment) []model.E
 }
 
 func mapEnvironmentConfigToDomain(in *model.EnvironmentConfig) *core.EnvironmentConfig {
-	return &core.EnvironmentConfig{
+	out := &core.EnvironmentConfig{
 		SealedSecretCert:  in.SealedSecretCert,
 		PublicGatewayHost: in.PublicGatewayHost,
+		CertificateIssuer: in.CertificateIssuer,
+		DefaultDenyAccess: in.DefaultDenyAccess,
+		KNativeScheduling: in.KNativeScheduling,
+		AppDefaults:       in.AppDefaults,
+		AppConstraints:    in.AppConstraints,
+		AdmissionPolicies: in.AdmissionPolicies,
+		SecurityBaseline:  in.SecurityBaseline,
+	}
+	if in.DefaultResources != nil {
+		out.DefaultResources = &core.EnvironmentDefaultResources{
+			Requests: in.DefaultResources.Requests,
+			Limits:   in.DefaultResources.Limits,
+		}
 	}
+	return out
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/riser-platform/riser-server/api/v1/model"
 
 	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/riser-platform/riser-server/pkg/util"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -36,12 +37,38 @@ func Test_mapEnvironmentConfigToDomain(t *testing.T) {
 	config := &model.EnvironmentConfig{
 		SealedSecretCert:  []byte{0x1},
 		PublicGatewayHost: "myhost",
+		CertificateIssuer: "myissuer",
+		DefaultDenyAccess: true,
+		AppDefaults:       &model.OverrideableAppConfig{TimeoutSeconds: util.PtrInt64(30)},
+		AppConstraints: &model.EnvironmentAppConstraints{
+			Autoscale: &model.EnvironmentAutoscaleConstraints{MinAtLeast: util.PtrInt(2)},
+		},
+		AdmissionPolicies: []model.EnvironmentAdmissionPolicy{{Name: "mypolicy", Expression: "name != 'test'"}},
+		SecurityBaseline:  &model.EnvironmentSecurityBaseline{RunAsNonRoot: true},
+		DefaultResources: &model.EnvironmentDefaultResources{
+			Requests: &model.AppConfigResourceList{CpuCores: util.PtrFloat32(0.1)},
+			Limits:   &model.AppConfigResourceList{MemoryMB: util.PtrInt32(512)},
+		},
 	}
 
 	result := mapEnvironmentConfigToDomain(config)
 
 	assert.Equal(t, []byte{0x1}, result.SealedSecretCert)
 	assert.Equal(t, "myhost", result.PublicGatewayHost)
+	assert.Equal(t, "myissuer", result.CertificateIssuer)
+	assert.True(t, result.DefaultDenyAccess)
+	assert.Equal(t, config.AppDefaults, result.AppDefaults)
+	assert.Equal(t, config.AppConstraints, result.AppConstraints)
+	assert.Equal(t, config.AdmissionPolicies, result.AdmissionPolicies)
+	assert.Equal(t, config.SecurityBaseline, result.SecurityBaseline)
+	assert.Equal(t, config.DefaultResources.Requests, result.DefaultResources.Requests)
+	assert.Equal(t, config.DefaultResources.Limits, result.DefaultResources.Limits)
+}
+
+func Test_mapEnvironmentConfigToDomain_NoDefaultResources(t *testing.T) {
+	result := mapEnvironmentConfigToDomain(&model.EnvironmentConfig{})
+
+	assert.Nil(t, result.DefaultResources)
 }
 
 func Test_validateEnvironmentName_Error(t *testing.T) {
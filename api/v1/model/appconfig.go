@@ -1,8 +1,15 @@
 package model
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"path"
+	"reflect"
 	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/docker/distribution/reference"
 	validation "github.com/go-ozzo/ozzo-validation/v3"
@@ -10,11 +17,57 @@ import (
 	"github.com/imdario/mergo"
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
 	AppExposeScope_External = "external"
 	AppExposeScope_Cluster  = "cluster"
+
+	AppExposeProtocol_HTTP  = "http"
+	AppExposeProtocol_HTTP2 = "http2"
+	AppExposeProtocol_GRPC  = "grpc"
+	AppExposeProtocol_TCP   = "tcp"
+
+	AppKind_Service      = "service"
+	AppKind_Worker       = "worker"
+	AppKind_ScheduledJob = "scheduledJob"
+
+	AppJobConcurrencyPolicy_Allow   = "allow"
+	AppJobConcurrencyPolicy_Forbid  = "forbid"
+	AppJobConcurrencyPolicy_Replace = "replace"
+
+	AppHealthCheckMode_HTTPGet = "httpGet"
+	AppHealthCheckMode_TCP     = "tcp"
+	AppHealthCheckMode_GRPC    = "grpc"
+	AppHealthCheckMode_Exec    = "exec"
+
+	AppAutoscaleClass_KPA = "kpa"
+	AppAutoscaleClass_HPA = "hpa"
+
+	AppAutoscaleMetric_Concurrency = "concurrency"
+	AppAutoscaleMetric_RPS         = "rps"
+	AppAutoscaleMetric_CPU         = "cpu"
+
+	AppTolerationOperator_Equal  = "equal"
+	AppTolerationOperator_Exists = "exists"
+
+	AppTolerationEffect_NoSchedule       = "noSchedule"
+	AppTolerationEffect_PreferNoSchedule = "preferNoSchedule"
+	AppTolerationEffect_NoExecute        = "noExecute"
+
+	AppZoneSpread_Preferred = "preferred"
+	AppZoneSpread_Required  = "required"
+
+	// AppSchedulingZoneLabel is the well-known node label that contains the node's zone
+	AppSchedulingZoneLabel = "topology.kubernetes.io/zone"
+
+	// These match the KNative defaults for "container-concurrency-max-limit" and "max-revision-timeout-seconds"
+	appConcurrencyMax    = 1000
+	appTimeoutSecondsMax = 600
+
+	// Matches the max mode of a Kubernetes volume file
+	appSecretFileModeMax = 0777
 )
 
 var (
@@ -22,88 +75,499 @@ var (
 	appConfigDefaults = &AppConfig{
 		Namespace: "apps",
 		Expose: &AppConfigExpose{
-			Protocol: "http",
+			Protocol: AppExposeProtocol_HTTP,
 			Scope:    AppExposeScope_External,
 		},
 	}
 
+	unexposedAppConfigDefaults = &AppConfig{
+		Namespace: appConfigDefaults.Namespace,
+	}
+
+	// Matches the max size of a ConfigMap
+	appFileMaxBytes = 1024 * 1024
+
+	// Matches a five field cron schedule or one of the predefined schedules supported by a Kubernetes CronJob
+	jobSchedulePattern = regexp.MustCompile(`^(@(yearly|annually|monthly|weekly|daily|midnight|hourly)|@every \S+|(\S+\s+){4}\S+)$`)
+
+	// Matches an RFC 1123 domain name with at least two labels. Wildcards are not allowed.
+	domainPattern = regexp.MustCompile(`^([a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?\.)+[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+
+	accessMethods = map[string]bool{
+		http.MethodGet:     true,
+		http.MethodHead:    true,
+		http.MethodPost:    true,
+		http.MethodPut:     true,
+		http.MethodPatch:   true,
+		http.MethodDelete:  true,
+		http.MethodOptions: true,
+	}
+
+	// Matches a Linux capability name without the CAP_ prefix (e.g. NET_BIND_SERVICE) or ALL
+	capabilityPattern = regexp.MustCompile("^[A-Z][A-Z_]*$")
+
 	envVarKeyPattern      = regexp.MustCompile("^[A-Z][A-Z0-9_]*$")
 	envVarKeyRiserPattern = regexp.MustCompile("^RISER_")
 )
 
-// TODO: Move outside the API and into a separate module. The AppConfig should version independently of the API via a Version field on the root AppConfig object
+// TODO: Move outside the API and into a separate module.
 // Also, pkg/* should not have a dependency here. However, moving this into pkg/core (for example) would cause a circular module dependency so we
 // may need to create a separate module e.g. pkg/core/appconfig
 
 // AppConfigWithOverrides contains an app with environment level overrides
 type AppConfigWithOverrides struct {
 	AppConfig `json:",inline"`
-	Overrides map[string]OverrideableAppConfig `json:"environmentOverrides,omitempty"`
+	Overrides map[string]AppConfigOverride `json:"environmentOverrides,omitempty"`
+	// deprecatedFields are the deprecated fields that were used when the app config was decoded
+	deprecatedFields []DeprecatedField
+}
+
+// AppConfigOverride contains the values that are overridden for an environment. Values that are not specified are not overridden.
+// Nested values (e.g. healthcheck.liveness.periodSeconds) are merged with the app config, except for lists which are replaced.
+type AppConfigOverride struct {
+	OverrideableAppConfig `json:",inline"`
+	Expose                *AppConfigExposeOverride `json:"expose,omitempty"`
+	// Clear removes values specified by the app config for the environment using the value's path (e.g. "healthcheck.liveness",
+	// "env.LOG_LEVEL"). Values are cleared before the override is applied.
+	Clear []string `json:"clear,omitempty"`
+}
+
+type AppConfigExposeOverride struct {
+	Scope string `json:"scope,omitempty"`
 }
 
+// Validate validates the app config along with the parts of each environment override that are not validated once the override is
+// applied (see ApplyOverrides)
+func (cfg AppConfigWithOverrides) Validate() error {
+	validationErrors := cfg.AppConfig.Validate()
+	envNames := []string{}
+	for envName := range cfg.Overrides {
+		envNames = append(envNames, envName)
+	}
+	sort.Strings(envNames)
+	for _, envName := range envNames {
+		validationErrors = mergeValidationErrors(validationErrors, cfg.Overrides[envName].validate(), "environmentOverrides."+envName)
+	}
+	return validationErrors
+}
+
+func (override AppConfigOverride) validate() error {
+	clearErrors := validation.Errors{}
+	for idx, clearPath := range override.Clear {
+		// Validated against an empty config since the path must be valid even when the app config does not specify a value
+		err := clearField(reflect.ValueOf(&OverrideableAppConfig{}).Elem(), strings.Split(clearPath, "."))
+		if err != nil {
+			clearErrors[fmt.Sprintf("clear[%d]", idx)] = err
+		}
+	}
+	return clearErrors.Filter()
+}
+
+// ApplyOverrides returns the app config for the environment with the environment's overrides applied
 func (cfg *AppConfigWithOverrides) ApplyOverrides(envName string) (*AppConfig, error) {
-	app := cfg.AppConfig
-	if overrideApp, ok := cfg.Overrides[envName]; ok {
-		err := mergo.Merge(&app.OverrideableAppConfig, overrideApp, mergo.WithOverride, mergo.WithOverwriteWithEmptyValue)
+	return cfg.ApplyEnvironment(envName, nil)
+}
+
+// ApplyEnvironment returns the app config for the environment. The environment's app defaults only fill in values that are not specified by
+// the app config or its overrides. The app config is copied so that applying one environment never affects another.
+func (cfg *AppConfigWithOverrides) ApplyEnvironment(envName string, appDefaults *OverrideableAppConfig) (*AppConfig, error) {
+	app := AppConfig{}
+	err := copyViaJSON(cfg.AppConfig, &app)
+	if err != nil {
+		return nil, errors.Wrap(err, "error copying app config")
+	}
+
+	if override, ok := cfg.Overrides[envName]; ok {
+		overrideApp := AppConfigOverride{}
+		err = copyViaJSON(override, &overrideApp)
+		if err != nil {
+			return nil, errors.Wrap(err, "error copying environment override")
+		}
+
+		// Values are cleared before the override is merged so that a value may be cleared and replaced by the same override
+		for _, clearPath := range overrideApp.Clear {
+			err = clearField(reflect.ValueOf(&app.OverrideableAppConfig).Elem(), strings.Split(clearPath, "."))
+			if err != nil {
+				return nil, fmt.Errorf("unable to clear %q for environment %q: %v", clearPath, envName, err)
+			}
+		}
+
+		mergeOverrides(reflect.ValueOf(&app.OverrideableAppConfig).Elem(), reflect.ValueOf(overrideApp.OverrideableAppConfig))
+
+		if overrideApp.Expose != nil && overrideApp.Expose.Scope != "" {
+			if app.Expose == nil {
+				app.Expose = &AppConfigExpose{}
+			}
+			app.Expose.Scope = overrideApp.Expose.Scope
+		}
+	}
+
+	if appDefaults != nil {
+		defaults := OverrideableAppConfig{}
+		err = copyViaJSON(appDefaults, &defaults)
 		if err != nil {
-			return nil, err
+			return nil, errors.Wrap(err, "error copying app defaults")
 		}
+		// A default healthcheck or access is only used when the app config does not specify one at all
+		if app.HealthCheck != nil {
+			defaults.HealthCheck = nil
+		}
+		if app.Access != nil {
+			defaults.Access = nil
+		}
+		if app.KindOrDefault() != AppKind_Service {
+			removeServiceDefaults(&defaults, app.KindOrDefault())
+		}
+		if app.KindOrDefault() != AppKind_ScheduledJob {
+			defaults.Job = nil
+		}
+		mergeDefaults(reflect.ValueOf(&app.OverrideableAppConfig).Elem(), reflect.ValueOf(defaults))
 	}
 
 	return &app, nil
 }
 
+// removeServiceDefaults removes the app defaults that only apply to apps that serve requests so that they are not applied to other kinds
+func removeServiceDefaults(defaults *OverrideableAppConfig, kind string) {
+	defaults.Access = nil
+	defaults.Concurrency = nil
+	defaults.TimeoutSeconds = nil
+	if defaults.HealthCheck != nil && defaults.HealthCheck.Mode != AppHealthCheckMode_Exec {
+		defaults.HealthCheck = nil
+	}
+	if defaults.Autoscale != nil {
+		defaults.Autoscale = &AppConfigAutoscale{Min: defaults.Autoscale.Min, Max: defaults.Autoscale.Max}
+	}
+	// A scheduled job runs to completion so it is neither probed nor scaled
+	if kind == AppKind_ScheduledJob {
+		defaults.HealthCheck = nil
+		defaults.Autoscale = nil
+	}
+}
+
 // AppConfig is the root of the application config object graph without environment overrides
 type AppConfig struct {
-	Id                    uuid.UUID             `json:"id"`
-	Name                  AppName               `json:"name"`
-	Namespace             NamespaceName         `json:"namespace"`
-	Expose                *AppConfigExpose      `json:"expose,omitempty"`
-	HealthCheck           *AppConfigHealthCheck `json:"healthcheck,omitempty"`
-	Image                 string                `json:"image"`
+	// ApiVersion versions the app config independently of the API. Older versions are converted to the latest version when decoded.
+	ApiVersion string        `json:"apiVersion,omitempty"`
+	Id         uuid.UUID     `json:"id"`
+	Name       AppName       `json:"name"`
+	Namespace  NamespaceName `json:"namespace"`
+	// Kind is one of: service (default), worker, scheduledJob. A worker is not exposed and runs continuously (e.g. a queue consumer). A
+	// scheduledJob is not exposed and runs to completion on a schedule (see job).
+	Kind                  string           `json:"kind,omitempty"`
+	Expose                *AppConfigExpose `json:"expose,omitempty"`
+	Image                 string           `json:"image"`
 	OverrideableAppConfig `json:",inline"`
 }
 
 // OverrideableAppConfig contains properties that are overrideable
 type OverrideableAppConfig struct {
-	Autoscale   *AppConfigAutoscale           `json:"autoscale,omitempty"`
+	// Access restricts which apps and namespaces may call the app. Any caller is allowed when not specified unless the environment
+	// requires default deny. Calls proxied by the KNative activator (e.g. when scaling from zero or when the burst capacity is exceeded)
+	// are allowed regardless of the caller, path, or method, since the activator calls the app from the knative-serving namespace. To
+	// enforce access for every call, keep the activator out of the request path with autoscale.min of at least 1 and a
+	// target-burst-capacity of 0.
+	Access *AppConfigAccess `json:"access,omitempty"`
+	// Args overrides the image's CMD. Env vars may be referenced using the $(VAR_NAME) syntax.
+	Args      []string            `json:"args,omitempty"`
+	Autoscale *AppConfigAutoscale `json:"autoscale,omitempty"`
+	// Command overrides the image's ENTRYPOINT. Env vars may be referenced using the $(VAR_NAME) syntax.
+	Command []string `json:"command,omitempty"`
+	// Concurrency is the maximum number of concurrent requests sent to each instance of the app. 0 or unspecified allows unlimited requests.
+	Concurrency *int64 `json:"concurrency,omitempty"`
+	// Domains are custom domains (e.g. myapp.example.com) for an externally exposed app. A TLS certificate is automatically issued for each domain.
+	Domains     []string                      `json:"domains,omitempty"`
 	Environment map[string]intstr.IntOrString `json:"env,omitempty"`
-	Resources   *AppConfigResources           `json:"resources,omitempty"`
+	// Files maps an absolute mount path to the file's contents. Each file is mounted read-only in the container.
+	Files       map[string]string     `json:"files,omitempty"`
+	HealthCheck *AppConfigHealthCheck `json:"healthcheck,omitempty"`
+	// Job configures the schedule of the scheduledJob kind
+	Job *AppConfigJob `json:"job,omitempty"`
+	// PreDeploy is a job that must succeed before the new revision receives traffic (e.g. database migrations)
+	PreDeploy *AppConfigPreDeploy `json:"preDeploy,omitempty"`
+	Resources *AppConfigResources `json:"resources,omitempty"`
+	// Scheduling constrains the nodes that the app runs on (e.g. a high-memory node pool)
+	Scheduling *AppConfigScheduling `json:"scheduling,omitempty"`
+	// SecretFiles mounts secrets as files instead of env vars. The key is the name of the secret. Secrets that are not specified here are
+	// exposed as env vars.
+	SecretFiles map[string]AppConfigSecretFile `json:"secretFiles,omitempty"`
+	// Security configures the pod and container security contexts. Values that are not specified use the image's and Kubernetes' defaults
+	// unless the environment has a security baseline.
+	Security *AppConfigSecurity `json:"security,omitempty"`
+	// TimeoutSeconds is the maximum duration that the app has to respond to a request
+	TimeoutSeconds *int64 `json:"timeoutSeconds,omitempty"`
+}
+
+type AppConfigJob struct {
+	// Schedule is a cron schedule in UTC (e.g. "0 3 * * *") or a predefined schedule (e.g. "@daily")
+	Schedule string `json:"schedule,omitempty"`
+	// ConcurrencyPolicy is one of: allow (default), forbid, replace. It determines what happens when a run is due while the previous
+	// run is still active.
+	ConcurrencyPolicy string `json:"concurrencyPolicy,omitempty"`
+	// BackoffLimit is the number of retries before a run is considered failed (default 6)
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+	// SuccessfulJobsHistoryLimit is the number of successful runs to keep (default 3)
+	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty"`
+	// FailedJobsHistoryLimit is the number of failed runs to keep (default 1)
+	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
+}
+
+// AppConfigPreDeploy runs the app's image with the same env and secrets as the app. The job is run once for each riser revision.
+type AppConfigPreDeploy struct {
+	// Command overrides the image's ENTRYPOINT. Env vars may be referenced using the $(VAR_NAME) syntax.
+	Command []string `json:"command,omitempty"`
+	// Args overrides the image's CMD. Env vars may be referenced using the $(VAR_NAME) syntax.
+	Args []string `json:"args,omitempty"`
+}
+
+// AppConfigScheduling is rendered into the pod spec. KNative requires the kubernetes.podspec-nodeselector, kubernetes.podspec-tolerations,
+// kubernetes.podspec-affinity, and kubernetes.podspec-topologyspreadconstraints features to be enabled, so an app served by KNative may
+// only specify scheduling in an environment that declares them (see EnvironmentConfig.KNativeScheduling).
+type AppConfigScheduling struct {
+	// NodeSelector requires the app to run on nodes with each of the labels (e.g. node-pool: highmem)
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations allow the app to run on nodes with matching taints
+	Tolerations []AppConfigToleration `json:"tolerations,omitempty"`
+	Affinity    *AppConfigAffinity    `json:"affinity,omitempty"`
+}
+
+type AppConfigToleration struct {
+	Key string `json:"key"`
+	// Operator is one of: equal (default), exists. The exists operator tolerates any value and must not specify a value.
+	Operator string `json:"operator,omitempty"`
+	Value    string `json:"value,omitempty"`
+	// Effect is one of: noSchedule, preferNoSchedule, noExecute. All effects are tolerated when not specified.
+	Effect string `json:"effect,omitempty"`
+}
+
+// AppConfigAffinity is a simplified form of the Kubernetes node affinity and pod topology spread constraints
+type AppConfigAffinity struct {
+	// Zones requires the app to run on nodes in one of the zones
+	Zones []string `json:"zones,omitempty"`
+	// ZoneSpread is one of: preferred, required. It spreads the app's pods evenly across zones. Pods are not spread when not specified.
+	ZoneSpread string `json:"zoneSpread,omitempty"`
+}
+
+// AppConfigSecurity is rendered into the pod and container security contexts. KNative requires the kubernetes.podspec-securitycontext
+// feature to be enabled for fsGroup.
+type AppConfigSecurity struct {
+	// RunAsNonRoot prevents the container from starting when it would run as root
+	RunAsNonRoot *bool `json:"runAsNonRoot,omitempty"`
+	// RunAsUser is the user id that the container runs as. Overrides the image's USER.
+	RunAsUser *int64 `json:"runAsUser,omitempty"`
+	// RunAsGroup is the group id that the container runs as
+	RunAsGroup *int64 `json:"runAsGroup,omitempty"`
+	// FSGroup is the group id that owns mounted volumes
+	FSGroup *int64 `json:"fsGroup,omitempty"`
+	// ReadOnlyRootFilesystem mounts the container's root filesystem as read-only. Files and secret files are still mounted.
+	ReadOnlyRootFilesystem *bool `json:"readOnlyRootFilesystem,omitempty"`
+	// AllowPrivilegeEscalation determines whether a process may gain more privileges than its parent process (e.g. setuid binaries)
+	AllowPrivilegeEscalation *bool                  `json:"allowPrivilegeEscalation,omitempty"`
+	Capabilities             *AppConfigCapabilities `json:"capabilities,omitempty"`
+}
+
+type AppConfigCapabilities struct {
+	// Add lists the Linux capabilities to add (e.g. NET_BIND_SERVICE)
+	Add []string `json:"add,omitempty"`
+	// Drop lists the Linux capabilities to drop. ALL drops every capability.
+	Drop []string `json:"drop,omitempty"`
+}
+
+type AppConfigAccess struct {
+	// Allow lists the callers that may call the app. All other callers are denied.
+	Allow []AppConfigAccessRule `json:"allow,omitempty"`
+}
+
+type AppConfigAccessRule struct {
+	// Namespace is the namespace of the caller
+	Namespace string `json:"namespace"`
+	// App restricts the rule to a single app in the namespace. All apps in the namespace are allowed when not specified.
+	App string `json:"app,omitempty"`
+	// Paths restricts the rule to the specified paths. A path may start or end with a wildcard (e.g. "/api/*").
+	Paths []string `json:"paths,omitempty"`
+	// Methods restricts the rule to the specified HTTP methods (e.g. GET)
+	Methods []string `json:"methods,omitempty"`
 }
 
 type AppConfigAutoscale struct {
 	Min *int `json:"min,omitempty"`
 	Max *int `json:"max,omitempty"`
+	// Class is one of: kpa (default), hpa
+	Class string `json:"class,omitempty"`
+	// Metric is one of: concurrency (default), rps, cpu. The cpu metric requires the hpa class.
+	Metric string `json:"metric,omitempty"`
+	// Target is the target value of the metric per instance of the app (e.g. 100 requests per second for the rps metric)
+	Target *int `json:"target,omitempty"`
+	// TargetUtilizationPercentage is the percentage of the target at which the autoscaler scales up. kpa class only.
+	TargetUtilizationPercentage *int `json:"targetUtilizationPercentage,omitempty"`
+	// ScaleDownDelay is how long to wait at a lower load before scaling down (e.g. "15m"). kpa class only.
+	ScaleDownDelay string `json:"scaleDownDelay,omitempty"`
+	// StableWindow is the time window over which metrics are averaged (e.g. "60s"). kpa class only.
+	StableWindow string `json:"stableWindow,omitempty"`
+}
+
+// KindOrDefault returns the app kind or service if not specified
+func (appConfig AppConfig) KindOrDefault() string {
+	if appConfig.Kind == "" {
+		return AppKind_Service
+	}
+	return appConfig.Kind
+}
+
+// ClassOrDefault returns the autoscaling class or kpa if not specified
+func (autoscale AppConfigAutoscale) ClassOrDefault() string {
+	if autoscale.Class == "" {
+		return AppAutoscaleClass_KPA
+	}
+	return autoscale.Class
 }
 
 type AppConfigExpose struct {
-	ContainerPort int32  `json:"containerPort"`
-	Protocol      string `json:"protocol,omitempty"`
-	Scope         string `json:"scope,omitempty"`
+	ContainerPort int32 `json:"containerPort"`
+	// Protocol is one of: http (default), http2, grpc, tcp. The tcp protocol is only available within the cluster and does not support
+	// HTTP features such as autoscaling on requests, concurrency, timeouts, or path and method access rules.
+	Protocol string `json:"protocol,omitempty"`
+	Scope    string `json:"scope,omitempty"`
 }
 
-// Mode is not yet implemented (httpGet = default)
+// AppConfigHealthCheck configures the readiness probe along with optional liveness and startup probes
 type AppConfigHealthCheck struct {
+	AppConfigProbe `json:",inline"`
+	// Liveness restarts the container when it fails. There is no liveness probe when not specified.
+	Liveness *AppConfigProbe `json:"liveness,omitempty"`
+	// Startup disables the other probes until it succeeds. There is no startup probe when not specified.
+	Startup *AppConfigProbe `json:"startup,omitempty"`
+}
+
+type AppConfigProbe struct {
+	// Mode is one of: httpGet, tcp, grpc, exec. Defaults to grpc for the grpc protocol, tcp for the tcp protocol, and httpGet otherwise.
+	Mode string `json:"mode,omitempty"`
+	// Path is the http path to probe when using the httpGet mode
 	Path string `json:"path,omitempty"`
+	// Service is the optional service name sent in the gRPC health check request when using the grpc mode.
+	// The grpc mode requires the grpc_health_probe binary (https://github.com/grpc-ecosystem/grpc-health-probe) in the image.
+	Service string `json:"service,omitempty"`
+	// Command is the command to execute in the container when using the exec mode
+	Command             []string `json:"command,omitempty"`
+	InitialDelaySeconds *int32   `json:"initialDelaySeconds,omitempty"`
+	PeriodSeconds       *int32   `json:"periodSeconds,omitempty"`
+	TimeoutSeconds      *int32   `json:"timeoutSeconds,omitempty"`
+	FailureThreshold    *int32   `json:"failureThreshold,omitempty"`
+}
+
+// ModeOrDefault returns the probe's mode or the default mode for the exposed protocol if not specified
+func (probe AppConfigProbe) ModeOrDefault(expose *AppConfigExpose) string {
+	if probe.Mode != "" {
+		return probe.Mode
+	}
+	if expose != nil {
+		switch expose.Protocol {
+		case AppExposeProtocol_GRPC:
+			return AppHealthCheckMode_GRPC
+		case AppExposeProtocol_TCP:
+			return AppHealthCheckMode_TCP
+		}
+	}
+	return AppHealthCheckMode_HTTPGet
+}
+
+type AppConfigSecretFile struct {
+	// MountPath is the absolute path of the file in the container
+	MountPath string `json:"mountPath"`
+	// Mode is the file's permission bits (e.g. 0400 in YAML). Defaults to 0644 when not specified.
+	Mode *int32 `json:"mode,omitempty"`
 }
 
 type AppConfigResources struct {
+	// CpuCores is shorthand for limits.cpuCores
+	CpuCores *float32 `json:"cpuCores,omitempty"`
+	// MemoryMB is shorthand for limits.memoryMB
+	MemoryMB *int32                 `json:"memoryMB,omitempty"`
+	Requests *AppConfigResourceList `json:"requests,omitempty"`
+	Limits   *AppConfigResourceList `json:"limits,omitempty"`
+}
+
+type AppConfigResourceList struct {
 	CpuCores *float32 `json:"cpuCores,omitempty"`
 	MemoryMB *int32   `json:"memoryMB,omitempty"`
 }
 
+// LimitsOrShorthand returns the limits with any unspecified limit taken from the cpuCores and memoryMB shorthand
+func (resources AppConfigResources) LimitsOrShorthand() AppConfigResourceList {
+	limits := AppConfigResourceList{
+		CpuCores: resources.CpuCores,
+		MemoryMB: resources.MemoryMB,
+	}
+	if resources.Limits != nil {
+		if resources.Limits.CpuCores != nil {
+			limits.CpuCores = resources.Limits.CpuCores
+		}
+		if resources.Limits.MemoryMB != nil {
+			limits.MemoryMB = resources.Limits.MemoryMB
+		}
+	}
+	return limits
+}
+
 // ApplyDefaults sets any unset values with their defaults
 func (appConfig *AppConfig) ApplyDefaults() error {
+	// Only a service is exposed
+	if appConfig.KindOrDefault() != AppKind_Service {
+		return mergo.Merge(appConfig, unexposedAppConfigDefaults)
+	}
+	// Raw TCP cannot be routed through the ingress gateway so it defaults to the cluster scope
+	if appConfig.Expose != nil && appConfig.Expose.Protocol == AppExposeProtocol_TCP && appConfig.Expose.Scope == "" {
+		appConfig.Expose.Scope = AppExposeScope_Cluster
+	}
 	return mergo.Merge(appConfig, appConfigDefaults)
 }
 
 func (appConfig AppConfig) Validate() error {
+	notForTCP := notForProtocol(appConfig.Expose, AppExposeProtocol_TCP)
+	kind := appConfig.KindOrDefault()
+	worker := kind == AppKind_Worker
+	scheduledJob := kind == AppKind_ScheduledJob
+	// Only a service is exposed and called by other apps
+	notForUnexposed := notForKind(appConfig, AppKind_Worker, AppKind_ScheduledJob)
+	notForScheduledJob := notForKind(appConfig, AppKind_ScheduledJob)
+	var exposeRule validation.Rule = validation.Required
+	if kind != AppKind_Service {
+		exposeRule = notForUnexposed
+	}
+	var jobRule validation.Rule = validation.Required
+	if !scheduledJob {
+		jobRule = validation.By(func(value interface{}) error {
+			if !validation.IsEmpty(value) {
+				return fmt.Errorf("must only be specified for the %s kind", AppKind_ScheduledJob)
+			}
+			return nil
+		})
+	}
 	validationErrors := validation.ValidateStruct(&appConfig,
+		validation.Field(&appConfig.ApiVersion, validation.In(AppConfigApiVersion_Latest).Error(fmt.Sprintf("must be %s", AppConfigApiVersion_Latest))),
 		validation.Field(&appConfig.Name),
 		validation.Field(&appConfig.Namespace),
 		validation.Field(&appConfig.Id, validation.By(validId)),
+		validation.Field(&appConfig.Kind, validation.In(AppKind_Service, AppKind_Worker, AppKind_ScheduledJob).Error(
+			fmt.Sprintf("must be one of: %s, %s, %s", AppKind_Service, AppKind_Worker, AppKind_ScheduledJob))),
 		validation.Field(&appConfig.Image, validation.Required, validation.By(validDockerImageWithoutTagOrDigest)),
-		validation.Field(&appConfig.Expose, validation.Required),
+		validation.Field(&appConfig.Expose, exposeRule),
+		validation.Field(&appConfig.Job, jobRule),
+		validation.Field(&appConfig.Domains, notForUnexposed, validation.By(validDomains), validation.By(func(value interface{}) error {
+			if kind == AppKind_Service && !validation.IsEmpty(value) && appConfig.Expose != nil && appConfig.Expose.Scope != AppExposeScope_External {
+				return fmt.Errorf("must only be specified when expose.scope is %s", AppExposeScope_External)
+			}
+			return nil
+		})),
+		validation.Field(&appConfig.Concurrency, notForTCP, notForUnexposed, validation.Min(0), validation.Max(appConcurrencyMax)),
+		// We have to customize the NilOrEmpty error to match "Min" since "Min" does not get applied to nillable 0 value
+		validation.Field(&appConfig.TimeoutSeconds, notForTCP, notForUnexposed,
+			validation.NilOrNotEmpty.Error("must be no less than 1"), validation.Min(1), validation.Max(appTimeoutSecondsMax)),
+		// A scheduled job runs to completion so it is neither probed nor scaled
+		validation.Field(&appConfig.HealthCheck, notForScheduledJob),
+		validation.Field(&appConfig.Autoscale, notForScheduledJob),
+		// The new revision's traffic is held until the pre-deploy job succeeds, which requires KNative
+		validation.Field(&appConfig.PreDeploy, notForTCP, notForUnexposed),
 	)
 
 	// Break out each struct so that we can have better error messages than the default
@@ -113,34 +577,538 @@ func (appConfig AppConfig) Validate() error {
 	envErr := validation.Validate(appConfig.Environment, validation.By(validEnvMap))
 	validationErrors = mergeValidationErrors(validationErrors, envErr, "env")
 
+	// Files are treated like env so that each file is mapped as a field with its own error (e.g. files./etc/myapp.conf)
+	filesErr := validation.Validate(appConfig.Files, validation.By(validFilesMap))
+	validationErrors = mergeValidationErrors(validationErrors, filesErr, "files")
+
+	// Secret files are treated like files so that each secret is mapped as a field with its own error (e.g. secretFiles.mysecret.mountPath)
+	for _, secretName := range sortedSecretFileNames(appConfig.SecretFiles) {
+		secretFile := appConfig.SecretFiles[secretName]
+		secretFileErr := validation.ValidateStruct(&secretFile,
+			validation.Field(&secretFile.MountPath, validation.Required, validation.By(validFileMountPath), validation.By(func(value interface{}) error {
+				if _, ok := appConfig.Files[secretFile.MountPath]; ok {
+					return errors.New("must not be the same as the path of a file")
+				}
+				return nil
+			})),
+			validation.Field(&secretFile.Mode, validation.Min(int32(0)), validation.Max(int32(appSecretFileModeMax)).Error("must be no greater than 0777")),
+		)
+		validationErrors = mergeValidationErrors(validationErrors, secretFileErr, fmt.Sprintf("secretFiles.%s", secretName))
+	}
+
 	if appConfig.Expose != nil {
 		exposeErr := validation.ValidateStruct(appConfig.Expose,
 			validation.Field(&appConfig.Expose.ContainerPort, validation.Required, validation.Min(1), validation.Max(65535)),
-			validation.Field(&appConfig.Expose.Protocol, validation.In("http", "http2").Error("must be one of: http, http2")),
+			validation.Field(&appConfig.Expose.Protocol,
+				validation.In(AppExposeProtocol_HTTP, AppExposeProtocol_HTTP2, AppExposeProtocol_GRPC, AppExposeProtocol_TCP).Error(
+					fmt.Sprintf("must be one of: %s, %s, %s, %s", AppExposeProtocol_HTTP, AppExposeProtocol_HTTP2, AppExposeProtocol_GRPC, AppExposeProtocol_TCP))),
 			validation.Field(&appConfig.Expose.Scope,
 				validation.In(AppExposeScope_External, AppExposeScope_Cluster).Error(
-					fmt.Sprintf("must be one of: %s, %s", AppExposeScope_External, AppExposeScope_Cluster))),
+					fmt.Sprintf("must be one of: %s, %s", AppExposeScope_External, AppExposeScope_Cluster)),
+				validation.By(func(value interface{}) error {
+					if appConfig.Expose.Protocol == AppExposeProtocol_TCP && appConfig.Expose.Scope != AppExposeScope_Cluster {
+						return fmt.Errorf("must be %s for the %s protocol", AppExposeScope_Cluster, AppExposeProtocol_TCP)
+					}
+					return nil
+				})),
 		)
 		validationErrors = mergeValidationErrors(validationErrors, exposeErr, "expose")
 	}
 
-	if appConfig.Autoscale != nil {
-		maxMinRule := validation.Min(1)
-		if appConfig.Autoscale.Min != nil {
-			maxMinRule = validation.Min(*appConfig.Autoscale.Min).Error("must be greater than or equal to autoscale.min")
+	if appConfig.Job != nil {
+		validationErrors = mergeValidationErrors(validationErrors, validJob(appConfig.Job), "job")
+	}
+
+	if appConfig.PreDeploy != nil {
+		preDeployErr := validation.ValidateStruct(appConfig.PreDeploy,
+			validation.Field(&appConfig.PreDeploy.Command, validation.Required))
+		validationErrors = mergeValidationErrors(validationErrors, preDeployErr, "preDeploy")
+	}
+
+	if appConfig.HealthCheck != nil && !scheduledJob {
+		validationErrors = mergeValidationErrors(validationErrors, validProbe(&appConfig.HealthCheck.AppConfigProbe, appConfig), "healthcheck")
+		if appConfig.HealthCheck.Liveness != nil {
+			validationErrors = mergeValidationErrors(validationErrors, validProbe(appConfig.HealthCheck.Liveness, appConfig), "healthcheck.liveness")
+		}
+		if appConfig.HealthCheck.Startup != nil {
+			validationErrors = mergeValidationErrors(validationErrors, validProbe(appConfig.HealthCheck.Startup, appConfig), "healthcheck.startup")
+		}
+	}
+
+	if appConfig.Resources != nil {
+		validationErrors = mergeValidationErrors(validationErrors, validAppResources(appConfig.Resources), "resources")
+	}
+
+	if appConfig.Scheduling != nil {
+		validationErrors = mergeValidationErrors(validationErrors, validScheduling(appConfig.Scheduling), "scheduling")
+	}
+
+	if appConfig.Security != nil {
+		validationErrors = mergeValidationErrors(validationErrors, validSecurity(appConfig.Security), "security")
+	}
+
+	if appConfig.Autoscale != nil && !scheduledJob {
+		validationErrors = mergeValidationErrors(validationErrors, validAutoscale(appConfig.Autoscale, notForTCP, worker), "autoscale")
+	}
+
+	if appConfig.Access != nil {
+		validationErrors = mergeValidationErrors(validationErrors, validation.Errors{"access": validation.Validate(appConfig.Access, notForUnexposed)}.Filter(), "")
+		for idx := range appConfig.Access.Allow {
+			validationErrors = mergeValidationErrors(validationErrors, validAccessRule(&appConfig.Access.Allow[idx], notForTCP), fmt.Sprintf("access.allow.%d", idx))
+		}
+	}
+
+	return validationErrors
+}
+
+func validJob(job *AppConfigJob) error {
+	return validation.ValidateStruct(job,
+		validation.Field(&job.Schedule, validation.Required, validation.Match(jobSchedulePattern).Error(
+			`must be a cron schedule with five fields (e.g. "0 3 * * *") or a predefined schedule (e.g. "@daily")`)),
+		validation.Field(&job.ConcurrencyPolicy,
+			validation.In(AppJobConcurrencyPolicy_Allow, AppJobConcurrencyPolicy_Forbid, AppJobConcurrencyPolicy_Replace).Error(
+				fmt.Sprintf("must be one of: %s, %s, %s", AppJobConcurrencyPolicy_Allow, AppJobConcurrencyPolicy_Forbid, AppJobConcurrencyPolicy_Replace))),
+		validation.Field(&job.BackoffLimit, validation.Min(int32(0))),
+		validation.Field(&job.SuccessfulJobsHistoryLimit, validation.Min(int32(0))),
+		validation.Field(&job.FailedJobsHistoryLimit, validation.Min(int32(0))),
+	)
+}
+
+func validScheduling(scheduling *AppConfigScheduling) error {
+	var validationErrors error
+	// The node selector is treated like env so that each label is mapped as a field with its own error (e.g. nodeSelector.node-pool)
+	nodeSelectorErrors := validation.Errors{}
+	for key, value := range scheduling.NodeSelector {
+		if err := validLabelKey(key); err != nil {
+			nodeSelectorErrors[key] = fmt.Errorf("invalid label: %v", err)
+		} else if err := validLabelValue(value); err != nil {
+			nodeSelectorErrors[key] = fmt.Errorf("invalid label value: %v", err)
 		}
-		autoscaleErr := validation.ValidateStruct(appConfig.Autoscale,
-			validation.Field(&appConfig.Autoscale.Min, validation.Min(0)),
-			// We have to customize the NilOrEmpty error to match "Min since "Min" does not get applied to nillable 0 value
-			validation.Field(&appConfig.Autoscale.Max, validation.NilOrNotEmpty.Error("must be no less than 1"), maxMinRule),
+	}
+	validationErrors = mergeValidationErrors(validationErrors, nodeSelectorErrors.Filter(), "nodeSelector")
+
+	for idx := range scheduling.Tolerations {
+		toleration := &scheduling.Tolerations[idx]
+		tolerationErr := validation.ValidateStruct(toleration,
+			validation.Field(&toleration.Key, validation.Required, validation.By(validLabelKey)),
+			validation.Field(&toleration.Operator,
+				validation.In(AppTolerationOperator_Equal, AppTolerationOperator_Exists).Error(
+					fmt.Sprintf("must be one of: %s, %s", AppTolerationOperator_Equal, AppTolerationOperator_Exists))),
+			validation.Field(&toleration.Value, validation.By(func(value interface{}) error {
+				if toleration.Operator == AppTolerationOperator_Exists && toleration.Value != "" {
+					return fmt.Errorf("must not be specified for the %s operator", AppTolerationOperator_Exists)
+				}
+				return nil
+			})),
+			validation.Field(&toleration.Effect,
+				validation.In(AppTolerationEffect_NoSchedule, AppTolerationEffect_PreferNoSchedule, AppTolerationEffect_NoExecute).Error(
+					fmt.Sprintf("must be one of: %s, %s, %s", AppTolerationEffect_NoSchedule, AppTolerationEffect_PreferNoSchedule, AppTolerationEffect_NoExecute))),
 		)
+		validationErrors = mergeValidationErrors(validationErrors, tolerationErr, fmt.Sprintf("tolerations.%d", idx))
+	}
 
-		validationErrors = mergeValidationErrors(validationErrors, autoscaleErr, "autoscale")
+	if scheduling.Affinity != nil {
+		affinityErr := validation.ValidateStruct(scheduling.Affinity,
+			validation.Field(&scheduling.Affinity.Zones, validation.Each(validation.Required, validation.By(validLabelValue))),
+			validation.Field(&scheduling.Affinity.ZoneSpread,
+				validation.In(AppZoneSpread_Preferred, AppZoneSpread_Required).Error(
+					fmt.Sprintf("must be one of: %s, %s", AppZoneSpread_Preferred, AppZoneSpread_Required))),
+		)
+		validationErrors = mergeValidationErrors(validationErrors, affinityErr, "affinity")
 	}
 
 	return validationErrors
 }
 
+func validSecurity(security *AppConfigSecurity) error {
+	validationErrors := validation.ValidateStruct(security,
+		validation.Field(&security.RunAsUser, validation.Min(int64(0)), validation.By(func(value interface{}) error {
+			if security.RunAsNonRoot != nil && *security.RunAsNonRoot && security.RunAsUser != nil && *security.RunAsUser == 0 {
+				return errors.New("must not be 0 (root) when runAsNonRoot is true")
+			}
+			return nil
+		})),
+		validation.Field(&security.RunAsGroup, validation.Min(int64(0))),
+		validation.Field(&security.FSGroup, validation.Min(int64(0))),
+	)
+
+	if security.Capabilities != nil {
+		capabilityRule := validation.Match(capabilityPattern).Error("must be an uppercase capability name (e.g. NET_BIND_SERVICE)")
+		capabilitiesErr := validation.ValidateStruct(security.Capabilities,
+			validation.Field(&security.Capabilities.Add, validation.Each(capabilityRule)),
+			validation.Field(&security.Capabilities.Drop, validation.Each(capabilityRule)),
+		)
+		validationErrors = mergeValidationErrors(validationErrors, capabilitiesErr, "capabilities")
+	}
+
+	return validationErrors
+}
+
+func validLabelKey(value interface{}) error {
+	if errs := k8svalidation.IsQualifiedName(value.(string)); len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func validLabelValue(value interface{}) error {
+	if errs := k8svalidation.IsValidLabelValue(value.(string)); len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// validAutoscale validates the autoscale config. Only min is supported by the tcp protocol as it is not autoscaled. A worker is always
+// autoscaled by the hpa class.
+func validAutoscale(autoscale *AppConfigAutoscale, notForTCP validation.Rule, worker bool) error {
+	maxMinRule := validation.Min(1)
+	if autoscale.Min != nil {
+		maxMinRule = validation.Min(*autoscale.Min).Error("must be greater than or equal to autoscale.min")
+	}
+
+	class := autoscale.ClassOrDefault()
+	classRule := validation.In(AppAutoscaleClass_KPA, AppAutoscaleClass_HPA).Error(
+		fmt.Sprintf("must be one of: %s, %s", AppAutoscaleClass_KPA, AppAutoscaleClass_HPA))
+	minRules := []validation.Rule{validation.Min(0)}
+	if worker {
+		class = AppAutoscaleClass_HPA
+		classRule = validation.In(AppAutoscaleClass_HPA).Error(fmt.Sprintf("must be %s for the %s kind", AppAutoscaleClass_HPA, AppKind_Worker))
+		// A HorizontalPodAutoscaler cannot scale to zero
+		if autoscale.Max != nil {
+			minRules = []validation.Rule{validation.NilOrNotEmpty.Error("must be no less than 1 when autoscale.max is specified"), validation.Min(1)}
+		}
+	}
+	classOnly := func(requiredClass string) validation.Rule {
+		return validation.By(func(value interface{}) error {
+			if class != requiredClass && !validation.IsEmpty(value) {
+				return fmt.Errorf("must only be specified for the %s class", requiredClass)
+			}
+			return nil
+		})
+	}
+	metricRule := validation.In(AppAutoscaleMetric_Concurrency, AppAutoscaleMetric_RPS).Error(
+		fmt.Sprintf("must be one of: %s, %s for the %s class", AppAutoscaleMetric_Concurrency, AppAutoscaleMetric_RPS, AppAutoscaleClass_KPA))
+	if class == AppAutoscaleClass_HPA {
+		metricRule = validation.In(AppAutoscaleMetric_CPU).Error(
+			fmt.Sprintf("must be %s for the %s class", AppAutoscaleMetric_CPU, AppAutoscaleClass_HPA))
+	}
+
+	return validation.ValidateStruct(autoscale,
+		validation.Field(&autoscale.Min, minRules...),
+		// We have to customize the NilOrEmpty error to match "Min since "Min" does not get applied to nillable 0 value
+		validation.Field(&autoscale.Max, notForTCP, validation.NilOrNotEmpty.Error("must be no less than 1"), maxMinRule),
+		validation.Field(&autoscale.Class, notForTCP, classRule),
+		validation.Field(&autoscale.Metric, notForTCP, metricRule),
+		validation.Field(&autoscale.Target, notForTCP, validation.NilOrNotEmpty.Error("must be no less than 1"), validation.Min(1)),
+		validation.Field(&autoscale.TargetUtilizationPercentage, notForTCP, classOnly(AppAutoscaleClass_KPA),
+			validation.NilOrNotEmpty.Error("must be no less than 1"), validation.Min(1), validation.Max(100)),
+		// These bounds match the bounds enforced by KNative
+		validation.Field(&autoscale.ScaleDownDelay, notForTCP, classOnly(AppAutoscaleClass_KPA), validation.By(validDurationBetween(0, time.Hour))),
+		validation.Field(&autoscale.StableWindow, notForTCP, classOnly(AppAutoscaleClass_KPA), validation.By(validDurationBetween(6*time.Second, time.Hour))),
+	)
+}
+
+// validAccessRule validates an access rule. Paths and methods are not supported by the tcp protocol as they require HTTP.
+func validAccessRule(rule *AppConfigAccessRule, notForTCP validation.Rule) error {
+	return validation.ValidateStruct(rule,
+		validation.Field(&rule.Namespace, append(RulesNamingIdentifier(), validation.Required)...),
+		validation.Field(&rule.App, RulesNamingIdentifier()...),
+		validation.Field(&rule.Paths, notForTCP, validation.By(func(value interface{}) error {
+			for _, accessPath := range rule.Paths {
+				if !strings.HasPrefix(accessPath, "/") && !strings.HasPrefix(accessPath, "*") {
+					return fmt.Errorf(`path %q must start with "/" or "*"`, accessPath)
+				}
+			}
+			return nil
+		})),
+		validation.Field(&rule.Methods, notForTCP, validation.By(func(value interface{}) error {
+			for _, method := range rule.Methods {
+				if !accessMethods[method] {
+					return fmt.Errorf("method %q must be one of: %s", method, strings.Join(sortedAccessMethods(), ", "))
+				}
+			}
+			return nil
+		})),
+	)
+}
+
+// mergeDefaults sets each unspecified field of dst to its default. Unlike mergo, a non-nil pointer is always treated as specified so that
+// an explicit zero value (e.g. autoscale.min: 0) is not replaced by a default. Structs are merged field by field and maps key by key.
+func mergeDefaults(dst reflect.Value, defaults reflect.Value) {
+	for i := 0; i < dst.NumField(); i++ {
+		dstField, defaultField := dst.Field(i), defaults.Field(i)
+		if !dstField.CanSet() {
+			continue
+		}
+		switch dstField.Kind() {
+		case reflect.Ptr:
+			if dstField.IsNil() {
+				dstField.Set(defaultField)
+			} else if !defaultField.IsNil() && dstField.Elem().Kind() == reflect.Struct {
+				mergeDefaults(dstField.Elem(), defaultField.Elem())
+			}
+		case reflect.Struct:
+			mergeDefaults(dstField, defaultField)
+		case reflect.Map:
+			if defaultField.IsNil() {
+				continue
+			}
+			if dstField.IsNil() {
+				dstField.Set(reflect.MakeMap(dstField.Type()))
+			}
+			for _, key := range defaultField.MapKeys() {
+				if !dstField.MapIndex(key).IsValid() {
+					dstField.SetMapIndex(key, defaultField.MapIndex(key))
+				}
+			}
+		default:
+			if dstField.IsZero() {
+				dstField.Set(defaultField)
+			}
+		}
+	}
+}
+
+// mergeOverrides sets each field of dst that is specified by the override. Like mergeDefaults, a non-nil pointer is always treated as
+// specified so that an explicit zero value (e.g. autoscale.min: 0) is applied. Structs are merged field by field, maps key by key, and
+// slices are replaced.
+func mergeOverrides(dst reflect.Value, override reflect.Value) {
+	for i := 0; i < dst.NumField(); i++ {
+		dstField, overrideField := dst.Field(i), override.Field(i)
+		if !dstField.CanSet() {
+			continue
+		}
+		switch dstField.Kind() {
+		case reflect.Ptr:
+			if overrideField.IsNil() {
+				continue
+			}
+			if !dstField.IsNil() && dstField.Elem().Kind() == reflect.Struct {
+				mergeOverrides(dstField.Elem(), overrideField.Elem())
+			} else {
+				dstField.Set(overrideField)
+			}
+		case reflect.Struct:
+			mergeOverrides(dstField, overrideField)
+		case reflect.Map:
+			if overrideField.IsNil() {
+				continue
+			}
+			if dstField.IsNil() {
+				dstField.Set(reflect.MakeMap(dstField.Type()))
+			}
+			for _, key := range overrideField.MapKeys() {
+				dstField.SetMapIndex(key, overrideField.MapIndex(key))
+			}
+		case reflect.Slice:
+			if !overrideField.IsNil() {
+				dstField.Set(overrideField)
+			}
+		default:
+			if !overrideField.IsZero() {
+				dstField.Set(overrideField)
+			}
+		}
+	}
+}
+
+// clearField sets the value at the path of JSON field names to its zero value. The last segment of the path may be a map key.
+func clearField(value reflect.Value, path []string) error {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			// There is nothing to clear but the rest of the path must still be valid
+			return clearField(reflect.New(value.Type().Elem()).Elem(), path)
+		}
+		return clearField(value.Elem(), path)
+	case reflect.Struct:
+		field, ok := fieldByJSONName(value, path[0])
+		if !ok {
+			return fmt.Errorf("%q is not an overrideable field", path[0])
+		}
+		if len(path) == 1 {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		return clearField(field, path[1:])
+	case reflect.Map:
+		if len(path) > 1 {
+			return fmt.Errorf("the fields of %q cannot be cleared individually", path[0])
+		}
+		if !value.IsNil() {
+			value.SetMapIndex(reflect.ValueOf(path[0]).Convert(value.Type().Key()), reflect.Value{})
+		}
+		return nil
+	default:
+		return fmt.Errorf("%q is not an overrideable field", path[0])
+	}
+}
+
+// fieldByJSONName returns the field of the struct with the JSON name. Fields of embedded structs are included.
+func fieldByJSONName(value reflect.Value, name string) (reflect.Value, bool) {
+	for i := 0; i < value.NumField(); i++ {
+		fieldType := value.Type().Field(i)
+		jsonName := strings.Split(fieldType.Tag.Get("json"), ",")[0]
+		if jsonName == "" && fieldType.Anonymous {
+			if field, ok := fieldByJSONName(value.Field(i), name); ok {
+				return field, true
+			}
+		} else if jsonName == name && fieldType.PkgPath == "" {
+			return value.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// copyViaJSON deep copies src into dst
+func copyViaJSON(src interface{}, dst interface{}) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+// notForKind returns a rule that fails when a value is specified and the app is of one of the kinds
+func notForKind(appConfig AppConfig, kinds ...string) validation.Rule {
+	return validation.By(func(value interface{}) error {
+		if validation.IsEmpty(value) {
+			return nil
+		}
+		for _, kind := range kinds {
+			if appConfig.KindOrDefault() == kind {
+				return fmt.Errorf("must not be specified for the %s kind", kind)
+			}
+		}
+		return nil
+	})
+}
+
+// notForProtocol returns a rule that fails when a value is specified and the app is exposed with the protocol
+func notForProtocol(expose *AppConfigExpose, protocol string) validation.Rule {
+	return validation.By(func(value interface{}) error {
+		if expose != nil && expose.Protocol == protocol && !validation.IsEmpty(value) {
+			return fmt.Errorf("must not be specified for the %s protocol", protocol)
+		}
+		return nil
+	})
+}
+
+func sortedAccessMethods() []string {
+	methods := []string{}
+	for method := range accessMethods {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+func validDurationBetween(min time.Duration, max time.Duration) validation.RuleFunc {
+	return func(value interface{}) error {
+		durationStr, _ := value.(string)
+		if durationStr == "" {
+			return nil
+		}
+		duration, err := time.ParseDuration(durationStr)
+		if err != nil {
+			return errors.New(`must be a valid duration (e.g. "30s", "15m")`)
+		}
+		if duration < min || duration > max {
+			return fmt.Errorf("must be between %s and %s", min, max)
+		}
+		return nil
+	}
+}
+
+func validProbe(probe *AppConfigProbe, appConfig AppConfig) error {
+	mode := probe.ModeOrDefault(appConfig.Expose)
+	var modeRule validation.Rule = validation.In(AppHealthCheckMode_HTTPGet, AppHealthCheckMode_TCP, AppHealthCheckMode_GRPC, AppHealthCheckMode_Exec).Error(
+		fmt.Sprintf("must be one of: %s, %s, %s, %s", AppHealthCheckMode_HTTPGet, AppHealthCheckMode_TCP, AppHealthCheckMode_GRPC, AppHealthCheckMode_Exec))
+	// A worker does not have a port to probe
+	if appConfig.KindOrDefault() == AppKind_Worker {
+		mode = AppHealthCheckMode_Exec
+		modeRule = validation.By(func(value interface{}) error {
+			if value != AppHealthCheckMode_Exec {
+				return fmt.Errorf("must be %s for the %s kind", AppHealthCheckMode_Exec, AppKind_Worker)
+			}
+			return nil
+		})
+	}
+	modeOnly := func(requiredMode string) validation.Rule {
+		return validation.By(func(value interface{}) error {
+			if mode != requiredMode && !validation.IsEmpty(value) {
+				return fmt.Errorf("must only be specified for the %s mode", requiredMode)
+			}
+			return nil
+		})
+	}
+	requiredForMode := func(requiredMode string) validation.Rule {
+		return validation.By(func(value interface{}) error {
+			if mode == requiredMode && validation.IsEmpty(value) {
+				return fmt.Errorf("is required for the %s mode", requiredMode)
+			}
+			return nil
+		})
+	}
+
+	return validation.ValidateStruct(probe,
+		validation.Field(&probe.Mode, modeRule),
+		validation.Field(&probe.Path, requiredForMode(AppHealthCheckMode_HTTPGet), modeOnly(AppHealthCheckMode_HTTPGet),
+			validation.Match(regexp.MustCompile("^/")).Error(`must start with "/"`)),
+		validation.Field(&probe.Service, modeOnly(AppHealthCheckMode_GRPC)),
+		validation.Field(&probe.Command, requiredForMode(AppHealthCheckMode_Exec), modeOnly(AppHealthCheckMode_Exec)),
+		validation.Field(&probe.InitialDelaySeconds, validation.Min(0)),
+		// We have to customize the NilOrEmpty error to match "Min" since "Min" does not get applied to nillable 0 value
+		validation.Field(&probe.PeriodSeconds, validation.NilOrNotEmpty.Error("must be no less than 1"), validation.Min(1)),
+		validation.Field(&probe.TimeoutSeconds, validation.NilOrNotEmpty.Error("must be no less than 1"), validation.Min(1)),
+		validation.Field(&probe.FailureThreshold, validation.NilOrNotEmpty.Error("must be no less than 1"), validation.Min(1)),
+	)
+}
+
+func validAppResources(resources *AppConfigResources) error {
+	validationErrors := validation.Errors{}
+	if resources.Limits != nil {
+		if resources.CpuCores != nil && resources.Limits.CpuCores != nil {
+			validationErrors["limits.cpuCores"] = errors.New("must not be specified with cpuCores")
+		}
+		if resources.MemoryMB != nil && resources.Limits.MemoryMB != nil {
+			validationErrors["limits.memoryMB"] = errors.New("must not be specified with memoryMB")
+		}
+	}
+
+	limits := resources.LimitsOrShorthand()
+	return mergeValidationErrors(validationErrors.Filter(), validResourceRequirements(resources.Requests, &limits), "")
+}
+
+// validResourceRequirements validates that resource values are positive and that no request exceeds its limit
+func validResourceRequirements(requests *AppConfigResourceList, limits *AppConfigResourceList) error {
+	if requests == nil {
+		requests = &AppConfigResourceList{}
+	}
+	if limits == nil {
+		limits = &AppConfigResourceList{}
+	}
+
+	validationErrors := validation.Errors{}
+	for name, list := range map[string]*AppConfigResourceList{"requests": requests, "limits": limits} {
+		if list.CpuCores != nil && *list.CpuCores <= 0 {
+			validationErrors[name+".cpuCores"] = errors.New("must be greater than 0")
+		}
+		if list.MemoryMB != nil && *list.MemoryMB <= 0 {
+			validationErrors[name+".memoryMB"] = errors.New("must be greater than 0")
+		}
+	}
+
+	if requests.CpuCores != nil && limits.CpuCores != nil && *requests.CpuCores > *limits.CpuCores {
+		validationErrors["requests.cpuCores"] = errors.New("must be less than or equal to the cpuCores limit")
+	}
+	if requests.MemoryMB != nil && limits.MemoryMB != nil && *requests.MemoryMB > *limits.MemoryMB {
+		validationErrors["requests.memoryMB"] = errors.New("must be less than or equal to the memoryMB limit")
+	}
+
+	return validationErrors.Filter()
+}
+
 func validDockerImageWithoutTagOrDigest(value interface{}) error {
 	dockerImageURL, _ := value.(string)
 	named, err := reference.ParseNormalizedNamed(dockerImageURL)
@@ -158,11 +1126,14 @@ func validDockerImageWithoutTagOrDigest(value interface{}) error {
 func validEnvMap(value interface{}) error {
 	validationErrors := validation.Errors{}
 	envMap, _ := value.(map[string]intstr.IntOrString)
-	for k := range envMap {
+	for k, v := range envMap {
 		err := validation.Validate(k,
 			validation.Match(envVarKeyPattern).Error(fmt.Sprintf(`The env var %q is not valid: Must start with A-Z and only contain A-Z, 0-9, and underscores (_)`, k)),
 			validation.By(validateEnvKeyNoRiserPrefix),
 		)
+		if err == nil && v.Type == intstr.String && !validAppReferences(v.StrVal) {
+			err = fmt.Errorf(`The env var %q is not valid: App references must be in the form ${app:<deployment>.<namespace>.url}`, k)
+		}
 		if err != nil {
 			validationErrors[k] = err
 		}
@@ -173,6 +1144,57 @@ func validEnvMap(value interface{}) error {
 	return nil
 }
 
+func validDomains(value interface{}) error {
+	domains, _ := value.([]string)
+	seen := map[string]bool{}
+	for _, domain := range domains {
+		if len(domain) > 253 || !domainPattern.MatchString(domain) {
+			return fmt.Errorf("domain %q must be a valid lowercase domain name (e.g. myapp.example.com)", domain)
+		}
+		if seen[domain] {
+			return fmt.Errorf("domain %q specified twice", domain)
+		}
+		seen[domain] = true
+	}
+	return nil
+}
+
+func validFilesMap(value interface{}) error {
+	validationErrors := validation.Errors{}
+	filesMap, _ := value.(map[string]string)
+	for mountPath, contents := range filesMap {
+		if err := validFileMountPath(mountPath); err != nil {
+			validationErrors[mountPath] = err
+		} else if len(contents) > appFileMaxBytes {
+			validationErrors[mountPath] = errors.New("must be no larger than 1MiB")
+		}
+	}
+	if len(validationErrors) > 0 {
+		return validationErrors
+	}
+	return nil
+}
+
+func validFileMountPath(value interface{}) error {
+	mountPath, _ := value.(string)
+	if mountPath == "" {
+		return nil
+	}
+	if !path.IsAbs(mountPath) || path.Clean(mountPath) != mountPath || mountPath == "/" {
+		return errors.New(`must be an absolute path to a file (e.g. "/etc/myapp/config.yaml")`)
+	}
+	return nil
+}
+
+func sortedSecretFileNames(secretFiles map[string]AppConfigSecretFile) []string {
+	names := []string{}
+	for name := range secretFiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // We have to do this until ozzo supports validation.NotMatch
 func validateEnvKeyNoRiserPrefix(v interface{}) error {
 	strVal, _ := v.(string)
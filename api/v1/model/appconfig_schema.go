@@ -0,0 +1,405 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const appConfigSchemaId = "https://riser.dev/schemas/appconfig.json"
+
+// JSONSchema is the subset of JSON Schema (draft-07) used to describe the app config
+type JSONSchema struct {
+	Schema      string                 `json:"$schema,omitempty"`
+	Id          string                 `json:"$id,omitempty"`
+	Title       string                 `json:"title,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Type        string                 `json:"type,omitempty"`
+	Properties  map[string]*JSONSchema `json:"properties,omitempty"`
+	Required    []string               `json:"required,omitempty"`
+	// AdditionalProperties is either false or a *JSONSchema
+	AdditionalProperties interface{}   `json:"additionalProperties,omitempty"`
+	PropertyNames        *JSONSchema   `json:"propertyNames,omitempty"`
+	Items                *JSONSchema   `json:"items,omitempty"`
+	UniqueItems          bool          `json:"uniqueItems,omitempty"`
+	Enum                 []string      `json:"enum,omitempty"`
+	Pattern              string        `json:"pattern,omitempty"`
+	MinLength            *int          `json:"minLength,omitempty"`
+	MaxLength            *int          `json:"maxLength,omitempty"`
+	Minimum              *float64      `json:"minimum,omitempty"`
+	Maximum              *float64      `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64      `json:"exclusiveMinimum,omitempty"`
+	Not                  *JSONSchema   `json:"not,omitempty"`
+	OneOf                []*JSONSchema `json:"oneOf,omitempty"`
+}
+
+// AppConfigSchema returns a JSON Schema for AppConfigWithOverrides. Rules that cannot be expressed in JSON Schema (e.g. autoscale.max
+// being greater than or equal to autoscale.min) are only enforced by Validate.
+func AppConfigSchema() *JSONSchema {
+	schema := overrideableAppConfigSchema()
+	schema.Schema = "http://json-schema.org/draft-07/schema#"
+	schema.Id = appConfigSchemaId
+	schema.Title = "Riser app config"
+	schema.Required = []string{"id", "name", "image"}
+	schema.Properties["apiVersion"] = &JSONSchema{Type: "string", Enum: []string{AppConfigApiVersion_Latest}}
+	schema.Properties["kind"] = &JSONSchema{Type: "string", Enum: []string{AppKind_Service, AppKind_Worker, AppKind_ScheduledJob}}
+	// Only a service is exposed. A scheduled job requires its job config.
+	absent := &JSONSchema{Not: &JSONSchema{}}
+	schema.OneOf = []*JSONSchema{
+		{
+			Required:   []string{"expose"},
+			Properties: map[string]*JSONSchema{"kind": {Enum: []string{AppKind_Service}}, "job": absent},
+		},
+		{
+			Required:   []string{"kind"},
+			Properties: map[string]*JSONSchema{"kind": {Enum: []string{AppKind_Worker}}, "expose": absent, "job": absent},
+		},
+		{
+			Required:   []string{"kind", "job"},
+			Properties: map[string]*JSONSchema{"kind": {Enum: []string{AppKind_ScheduledJob}}, "expose": absent},
+		},
+	}
+	schema.Properties["job"].Required = []string{"schedule"}
+	schema.Properties["preDeploy"].Required = []string{"command"}
+	schema.Properties["id"] = &JSONSchema{
+		Type:    "string",
+		Pattern: "^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$",
+		Not:     &JSONSchema{Enum: []string{uuid.Nil.String()}},
+	}
+	schema.Properties["name"] = namingIdentifierSchema(47)
+	schema.Properties["namespace"] = namingIdentifierSchema(63)
+	schema.Properties["namespace"].Not = &JSONSchema{Pattern: fmt.Sprintf("^(%s)", strings.Join(bannedNamespacePrefixes, "|"))}
+	schema.Properties["image"] = &JSONSchema{
+		Type:        "string",
+		Description: "The docker image without a tag or digest (e.g. riser-platform/myapp)",
+		MinLength:   intPtr(1),
+	}
+	schema.Properties["expose"] = &JSONSchema{
+		Type:     "object",
+		Required: []string{"containerPort"},
+		Properties: map[string]*JSONSchema{
+			"containerPort": integerSchema(1, 65535),
+			"protocol": {
+				Type: "string",
+				Enum: []string{AppExposeProtocol_HTTP, AppExposeProtocol_HTTP2, AppExposeProtocol_GRPC, AppExposeProtocol_TCP},
+			},
+			"scope": {Type: "string", Enum: []string{AppExposeScope_External, AppExposeScope_Cluster}},
+		},
+		// The tcp protocol is only available within the cluster. The scope defaults to cluster for the tcp protocol.
+		OneOf: []*JSONSchema{
+			{Properties: map[string]*JSONSchema{"protocol": {Not: &JSONSchema{Enum: []string{AppExposeProtocol_TCP}}}}},
+			{
+				Required: []string{"protocol"},
+				Properties: map[string]*JSONSchema{
+					"protocol": {Enum: []string{AppExposeProtocol_TCP}},
+					"scope":    {Enum: []string{AppExposeScope_Cluster}},
+				},
+			},
+		},
+		AdditionalProperties: false,
+	}
+	schema.Properties["environmentOverrides"] = &JSONSchema{
+		Type:                 "object",
+		Description:          "Overrides for each environment",
+		AdditionalProperties: appConfigOverrideSchema(),
+	}
+	return schema
+}
+
+func appConfigOverrideSchema() *JSONSchema {
+	schema := overrideableAppConfigSchema()
+	fieldNames := []string{}
+	for fieldName := range schema.Properties {
+		fieldNames = append(fieldNames, regexp.QuoteMeta(fieldName))
+	}
+	sort.Strings(fieldNames)
+	schema.Properties["expose"] = &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"scope": {Type: "string", Enum: []string{AppExposeScope_External, AppExposeScope_Cluster}},
+		},
+		AdditionalProperties: false,
+	}
+	schema.Properties["clear"] = &JSONSchema{
+		Type:        "array",
+		Description: "Paths of values to clear for the environment (e.g. healthcheck.liveness)",
+		UniqueItems: true,
+		Items:       &JSONSchema{Type: "string", Pattern: fmt.Sprintf(`^(%s)(\.[^.]+)*$`, strings.Join(fieldNames, "|"))},
+	}
+	return schema
+}
+
+func overrideableAppConfigSchema() *JSONSchema {
+	return &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"access":      accessSchema(),
+			"args":        stringArraySchema(),
+			"autoscale":   autoscaleSchema(),
+			"command":     stringArraySchema(),
+			"concurrency": integerSchema(0, appConcurrencyMax),
+			"domains": {
+				Type:        "array",
+				UniqueItems: true,
+				Items:       &JSONSchema{Type: "string", Pattern: domainPattern.String(), MaxLength: intPtr(253)},
+			},
+			"env": {
+				Type: "object",
+				PropertyNames: &JSONSchema{
+					Pattern: envVarKeyPattern.String(),
+					Not:     &JSONSchema{Pattern: envVarKeyRiserPattern.String()},
+				},
+				AdditionalProperties: &JSONSchema{OneOf: []*JSONSchema{{Type: "string"}, {Type: "integer"}}},
+			},
+			"files": {
+				Type:                 "object",
+				Description:          "Maps an absolute mount path to the file's contents",
+				PropertyNames:        &JSONSchema{Pattern: "^/.*[^/]$"},
+				AdditionalProperties: &JSONSchema{Type: "string", MaxLength: intPtr(appFileMaxBytes)},
+			},
+			"healthcheck": healthCheckSchema(),
+			"job":         jobSchema(),
+			"preDeploy": {
+				Type:        "object",
+				Description: "A job that must succeed before the new revision receives traffic (e.g. database migrations)",
+				Properties: map[string]*JSONSchema{
+					"command": stringArraySchema(),
+					"args":    stringArraySchema(),
+				},
+				AdditionalProperties: false,
+			},
+			"resources":  resourcesSchema(),
+			"scheduling": schedulingSchema(),
+			"secretFiles": {
+				Type:        "object",
+				Description: "Mounts secrets as files instead of env vars. The key is the name of the secret.",
+				AdditionalProperties: &JSONSchema{
+					Type:     "object",
+					Required: []string{"mountPath"},
+					Properties: map[string]*JSONSchema{
+						"mountPath": {Type: "string", Pattern: "^/.*[^/]$"},
+						"mode":      integerSchema(0, appSecretFileModeMax),
+					},
+					AdditionalProperties: false,
+				},
+			},
+			"security":       securitySchema(),
+			"timeoutSeconds": integerSchema(1, appTimeoutSecondsMax),
+		},
+		AdditionalProperties: false,
+	}
+}
+
+func accessSchema() *JSONSchema {
+	return &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"allow": {
+				Type: "array",
+				Items: &JSONSchema{
+					Type:     "object",
+					Required: []string{"namespace"},
+					Properties: map[string]*JSONSchema{
+						"namespace": namingIdentifierSchema(63),
+						"app":       namingIdentifierSchema(63),
+						"paths":     {Type: "array", Items: &JSONSchema{Type: "string", Pattern: "^[/*]"}},
+						"methods":   {Type: "array", Items: &JSONSchema{Type: "string", Enum: sortedAccessMethods()}},
+					},
+					AdditionalProperties: false,
+				},
+			},
+		},
+		AdditionalProperties: false,
+	}
+}
+
+func autoscaleSchema() *JSONSchema {
+	return &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"min":                         integerSchema(0, -1),
+			"max":                         integerSchema(1, -1),
+			"class":                       {Type: "string", Enum: []string{AppAutoscaleClass_KPA, AppAutoscaleClass_HPA}},
+			"metric":                      {Type: "string", Enum: []string{AppAutoscaleMetric_Concurrency, AppAutoscaleMetric_RPS, AppAutoscaleMetric_CPU}},
+			"target":                      integerSchema(1, -1),
+			"targetUtilizationPercentage": integerSchema(1, 100),
+			"scaleDownDelay":              durationSchema(),
+			"stableWindow":                durationSchema(),
+		},
+		AdditionalProperties: false,
+	}
+}
+
+func jobSchema() *JSONSchema {
+	return &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"schedule": {
+				Type:        "string",
+				Description: `A cron schedule in UTC (e.g. "0 3 * * *") or a predefined schedule (e.g. "@daily")`,
+				Pattern:     jobSchedulePattern.String(),
+			},
+			"concurrencyPolicy": {
+				Type: "string",
+				Enum: []string{AppJobConcurrencyPolicy_Allow, AppJobConcurrencyPolicy_Forbid, AppJobConcurrencyPolicy_Replace},
+			},
+			"backoffLimit":               integerSchema(0, -1),
+			"successfulJobsHistoryLimit": integerSchema(0, -1),
+			"failedJobsHistoryLimit":     integerSchema(0, -1),
+		},
+		AdditionalProperties: false,
+	}
+}
+
+func schedulingSchema() *JSONSchema {
+	return &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"nodeSelector": {
+				Type:                 "object",
+				Description:          "Requires the app to run on nodes with each of the labels",
+				AdditionalProperties: &JSONSchema{Type: "string"},
+			},
+			"tolerations": {
+				Type: "array",
+				Items: &JSONSchema{
+					Type:     "object",
+					Required: []string{"key"},
+					Properties: map[string]*JSONSchema{
+						"key":      {Type: "string", MinLength: intPtr(1)},
+						"operator": {Type: "string", Enum: []string{AppTolerationOperator_Equal, AppTolerationOperator_Exists}},
+						"value":    {Type: "string"},
+						"effect": {
+							Type: "string",
+							Enum: []string{AppTolerationEffect_NoSchedule, AppTolerationEffect_PreferNoSchedule, AppTolerationEffect_NoExecute},
+						},
+					},
+					AdditionalProperties: false,
+				},
+			},
+			"affinity": {
+				Type: "object",
+				Properties: map[string]*JSONSchema{
+					"zones":      {Type: "array", Items: &JSONSchema{Type: "string", MinLength: intPtr(1)}},
+					"zoneSpread": {Type: "string", Enum: []string{AppZoneSpread_Preferred, AppZoneSpread_Required}},
+				},
+				AdditionalProperties: false,
+			},
+		},
+		AdditionalProperties: false,
+	}
+}
+
+func securitySchema() *JSONSchema {
+	capabilitiesSchema := &JSONSchema{Type: "array", Items: &JSONSchema{Type: "string", Pattern: capabilityPattern.String()}}
+	return &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"runAsNonRoot":             {Type: "boolean"},
+			"runAsUser":                integerSchema(0, -1),
+			"runAsGroup":               integerSchema(0, -1),
+			"fsGroup":                  integerSchema(0, -1),
+			"readOnlyRootFilesystem":   {Type: "boolean"},
+			"allowPrivilegeEscalation": {Type: "boolean"},
+			"capabilities": {
+				Type: "object",
+				Properties: map[string]*JSONSchema{
+					"add":  capabilitiesSchema,
+					"drop": capabilitiesSchema,
+				},
+				AdditionalProperties: false,
+			},
+		},
+		AdditionalProperties: false,
+	}
+}
+
+func healthCheckSchema() *JSONSchema {
+	schema := probeSchema()
+	schema.Properties["liveness"] = probeSchema()
+	schema.Properties["startup"] = probeSchema()
+	return schema
+}
+
+func probeSchema() *JSONSchema {
+	return &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"mode": {
+				Type: "string",
+				Enum: []string{AppHealthCheckMode_HTTPGet, AppHealthCheckMode_TCP, AppHealthCheckMode_GRPC, AppHealthCheckMode_Exec},
+			},
+			"path":                {Type: "string", Pattern: "^/"},
+			"service":             {Type: "string"},
+			"command":             stringArraySchema(),
+			"initialDelaySeconds": integerSchema(0, -1),
+			"periodSeconds":       integerSchema(1, -1),
+			"timeoutSeconds":      integerSchema(1, -1),
+			"failureThreshold":    integerSchema(1, -1),
+		},
+		AdditionalProperties: false,
+	}
+}
+
+func resourcesSchema() *JSONSchema {
+	resourceListSchema := func() *JSONSchema {
+		return &JSONSchema{
+			Type: "object",
+			Properties: map[string]*JSONSchema{
+				"cpuCores": {Type: "number", ExclusiveMinimum: float64Ptr(0)},
+				"memoryMB": integerSchema(1, -1),
+			},
+			AdditionalProperties: false,
+		}
+	}
+	schema := resourceListSchema()
+	schema.Properties["cpuCores"].Description = "Deprecated: use limits.cpuCores"
+	schema.Properties["memoryMB"].Description = "Deprecated: use limits.memoryMB"
+	schema.Properties["requests"] = resourceListSchema()
+	schema.Properties["limits"] = resourceListSchema()
+	return schema
+}
+
+func namingIdentifierSchema(maxLength int) *JSONSchema {
+	return &JSONSchema{
+		Type:      "string",
+		Pattern:   namingIdentifierPattern.String(),
+		MinLength: intPtr(3),
+		MaxLength: intPtr(maxLength),
+	}
+}
+
+// integerSchema returns a schema for an integer between min and max. A negative max is unbounded.
+func integerSchema(min int, max int) *JSONSchema {
+	schema := &JSONSchema{Type: "integer", Minimum: float64Ptr(float64(min))}
+	if max >= 0 {
+		schema.Maximum = float64Ptr(float64(max))
+	}
+	return schema
+}
+
+func durationSchema() *JSONSchema {
+	return &JSONSchema{
+		Type:        "string",
+		Description: `A duration (e.g. "30s", "15m")`,
+		Pattern:     durationPattern.String(),
+	}
+}
+
+func stringArraySchema() *JSONSchema {
+	return &JSONSchema{Type: "array", Items: &JSONSchema{Type: "string"}}
+}
+
+// Matches a positive duration accepted by time.ParseDuration
+var durationPattern = regexp.MustCompile(`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`)
+
+func intPtr(val int) *int {
+	return &val
+}
+
+func float64Ptr(val float64) *float64 {
+	return &val
+}
@@ -0,0 +1,229 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_AppConfigSchema_AgreesWithValidate checks that the schema and Validate agree on each fixture in testdata/appconfig.
+// Fixtures prefixed with "valid_" must pass both and fixtures prefixed with "invalid_" must fail both.
+func Test_AppConfigSchema_AgreesWithValidate(t *testing.T) {
+	// Round trip the schema so that we validate against what is served by the API
+	schemaBytes, err := json.Marshal(AppConfigSchema())
+	require.NoError(t, err)
+	schema := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(schemaBytes, &schema))
+
+	fixtures, err := filepath.Glob("testdata/appconfig/*.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, fixtures)
+
+	for _, fixture := range fixtures {
+		name := strings.TrimSuffix(filepath.Base(fixture), ".json")
+		t.Run(name, func(t *testing.T) {
+			data, err := ioutil.ReadFile(fixture)
+			require.NoError(t, err)
+			expectValid := strings.HasPrefix(name, "valid_")
+
+			var doc interface{}
+			require.NoError(t, json.Unmarshal(data, &doc))
+			schemaErrors := validateJSONSchema(schema, doc, "")
+
+			validateErr := validateAppConfigFixture(data)
+
+			if expectValid {
+				assert.Empty(t, schemaErrors)
+				assert.NoError(t, validateErr)
+			} else {
+				assert.NotEmpty(t, schemaErrors, "schema should reject the fixture")
+				assert.Error(t, validateErr, "Validate should reject the fixture")
+			}
+		})
+	}
+}
+
+func Test_AppConfigSchema_EnvKeyPattern(t *testing.T) {
+	schema := AppConfigSchema()
+
+	propertyNames := schema.Properties["env"].PropertyNames
+	assert.Equal(t, envVarKeyPattern.String(), propertyNames.Pattern)
+	assert.Equal(t, envVarKeyRiserPattern.String(), propertyNames.Not.Pattern)
+	assert.Equal(t, propertyNames, schema.Properties["environmentOverrides"].AdditionalProperties.(*JSONSchema).Properties["env"].PropertyNames)
+}
+
+// validateAppConfigFixture validates the fixture the same way that a deployment is validated: the app config is validated and
+// then each environment's app config with overrides applied is validated.
+func validateAppConfigFixture(data []byte) error {
+	cfg := &AppConfigWithOverrides{}
+	err := json.Unmarshal(data, cfg)
+	if err != nil {
+		return err
+	}
+	err = cfg.ApplyDefaults()
+	if err != nil {
+		return err
+	}
+	err = cfg.Validate()
+	if err != nil {
+		return err
+	}
+	for envName := range cfg.Overrides {
+		app, err := cfg.ApplyOverrides(envName)
+		if err != nil {
+			return err
+		}
+		err = app.Validate()
+		if err != nil {
+			return fmt.Errorf("%s: %s", envName, err)
+		}
+	}
+	return nil
+}
+
+// validateJSONSchema validates a decoded JSON document against the subset of JSON Schema used by AppConfigSchema
+func validateJSONSchema(schema map[string]interface{}, value interface{}, path string) []string {
+	errs := []string{}
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Sprintf("%s: %s", path, fmt.Sprintf(format, args...)))
+	}
+
+	if schemaType, ok := schema["type"].(string); ok && !jsonSchemaTypeMatches(schemaType, value) {
+		fail("expected type %s", schemaType)
+		return errs
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, enumVal := range enum {
+			if reflect.DeepEqual(enumVal, value) {
+				found = true
+			}
+		}
+		if !found {
+			fail("must be one of %v", enum)
+		}
+	}
+
+	if notSchema, ok := schema["not"].(map[string]interface{}); ok && len(validateJSONSchema(notSchema, value, path)) == 0 {
+		fail("must not match %v", notSchema)
+	}
+
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		matches := 0
+		for _, oneOfSchema := range oneOf {
+			if len(validateJSONSchema(oneOfSchema.(map[string]interface{}), value, path)) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			fail("must match exactly one schema")
+		}
+	}
+
+	switch typedValue := value.(type) {
+	case string:
+		if pattern, ok := schema["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(typedValue) {
+			fail("must match %s", pattern)
+		}
+		if minLength, ok := schema["minLength"].(float64); ok && float64(len(typedValue)) < minLength {
+			fail("must be at least %v characters", minLength)
+		}
+		if maxLength, ok := schema["maxLength"].(float64); ok && float64(len(typedValue)) > maxLength {
+			fail("must be at most %v characters", maxLength)
+		}
+	case float64:
+		if minimum, ok := schema["minimum"].(float64); ok && typedValue < minimum {
+			fail("must be >= %v", minimum)
+		}
+		if maximum, ok := schema["maximum"].(float64); ok && typedValue > maximum {
+			fail("must be <= %v", maximum)
+		}
+		if exclusiveMinimum, ok := schema["exclusiveMinimum"].(float64); ok && typedValue <= exclusiveMinimum {
+			fail("must be > %v", exclusiveMinimum)
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for idx, item := range typedValue {
+				errs = append(errs, validateJSONSchema(items, item, fmt.Sprintf("%s[%d]", path, idx))...)
+			}
+		}
+		if uniqueItems, _ := schema["uniqueItems"].(bool); uniqueItems {
+			for i := range typedValue {
+				for j := i + 1; j < len(typedValue); j++ {
+					if reflect.DeepEqual(typedValue[i], typedValue[j]) {
+						fail("items must be unique")
+					}
+				}
+			}
+		}
+	case map[string]interface{}:
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, requiredName := range required {
+				if _, ok := typedValue[requiredName.(string)]; !ok {
+					fail("%s is required", requiredName)
+				}
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		keys := []string{}
+		for key := range typedValue {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			propertyPath := strings.TrimPrefix(fmt.Sprintf("%s.%s", path, key), ".")
+			if propertyNames, ok := schema["propertyNames"].(map[string]interface{}); ok {
+				errs = append(errs, validateJSONSchema(propertyNames, key, propertyPath)...)
+			}
+			if propertySchema, ok := properties[key].(map[string]interface{}); ok {
+				errs = append(errs, validateJSONSchema(propertySchema, typedValue[key], propertyPath)...)
+				continue
+			}
+			switch additionalProperties := schema["additionalProperties"].(type) {
+			case bool:
+				if !additionalProperties {
+					fail("%s is not allowed", key)
+				}
+			case map[string]interface{}:
+				errs = append(errs, validateJSONSchema(additionalProperties, typedValue[key], propertyPath)...)
+			}
+		}
+	}
+
+	return errs
+}
+
+func jsonSchemaTypeMatches(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	}
+	return false
+}
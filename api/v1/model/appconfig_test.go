@@ -2,6 +2,7 @@ package model
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -116,6 +117,134 @@ func Test_AppConfig_ValidateAutoscaleRange(t *testing.T) {
 	assert.Equal(t, "must be no less than 1", validationErrors["autoscale.max"].Error())
 }
 
+func Test_AppConfig_ValidateAutoscaleScaling(t *testing.T) {
+	var tests = []struct {
+		name      string
+		autoscale *AppConfigAutoscale
+		errors    map[string]string
+	}{
+		{"kpa", &AppConfigAutoscale{
+			Class:                       "kpa",
+			Metric:                      "rps",
+			Target:                      ptrInt(100),
+			TargetUtilizationPercentage: ptrInt(70),
+			ScaleDownDelay:              "15m",
+			StableWindow:                "60s",
+		}, nil},
+		{"default class", &AppConfigAutoscale{Metric: "concurrency", Target: ptrInt(10)}, nil},
+		{"hpa", &AppConfigAutoscale{Class: "hpa", Metric: "cpu", Target: ptrInt(80)}, nil},
+		{"invalid class", &AppConfigAutoscale{Class: "nope"},
+			map[string]string{"autoscale.class": "must be one of: kpa, hpa"}},
+		{"cpu requires hpa", &AppConfigAutoscale{Metric: "cpu"},
+			map[string]string{"autoscale.metric": "must be one of: concurrency, rps for the kpa class"}},
+		{"hpa requires cpu", &AppConfigAutoscale{Class: "hpa", Metric: "rps"},
+			map[string]string{"autoscale.metric": "must be cpu for the hpa class"}},
+		{"kpa only settings", &AppConfigAutoscale{
+			Class:                       "hpa",
+			TargetUtilizationPercentage: ptrInt(70),
+			ScaleDownDelay:              "15m",
+			StableWindow:                "60s",
+		}, map[string]string{
+			"autoscale.targetUtilizationPercentage": "must only be specified for the kpa class",
+			"autoscale.scaleDownDelay":              "must only be specified for the kpa class",
+			"autoscale.stableWindow":                "must only be specified for the kpa class",
+		}},
+		{"out of range", &AppConfigAutoscale{
+			Target:                      ptrInt(0),
+			TargetUtilizationPercentage: ptrInt(101),
+			ScaleDownDelay:              "2h",
+			StableWindow:                "5s",
+		}, map[string]string{
+			"autoscale.target":                      "must be no less than 1",
+			"autoscale.targetUtilizationPercentage": "must be no greater than 100",
+			"autoscale.scaleDownDelay":              "must be between 0s and 1h0m0s",
+			"autoscale.stableWindow":                "must be between 6s and 1h0m0s",
+		}},
+		{"invalid duration", &AppConfigAutoscale{StableWindow: "sixty"},
+			map[string]string{"autoscale.stableWindow": `must be a valid duration (e.g. "30s", "15m")`}},
+	}
+
+	for _, tt := range tests {
+		appConfig := createMinAppConfig()
+		appConfig.Autoscale = tt.autoscale
+		err := appConfig.Validate()
+
+		if tt.errors == nil {
+			assert.NoError(t, err, tt.name)
+		} else {
+			require.IsType(t, validation.Errors{}, err, tt.name)
+			validationErrors := err.(validation.Errors)
+			assert.Len(t, validationErrors, len(tt.errors), tt.name)
+			for field, expected := range tt.errors {
+				require.Contains(t, validationErrors, field, tt.name)
+				assert.Equal(t, expected, validationErrors[field].Error(), tt.name)
+			}
+		}
+	}
+}
+
+func Test_ApplyOverrides_AutoscaleScaling(t *testing.T) {
+	appConfig := &AppConfigWithOverrides{
+		AppConfig: AppConfig{
+			Name: "myapp",
+			OverrideableAppConfig: OverrideableAppConfig{
+				Autoscale: &AppConfigAutoscale{Metric: "concurrency", Target: ptrInt(10)},
+			},
+		},
+		Overrides: map[string]AppConfigOverride{
+			"prod": {OverrideableAppConfig: OverrideableAppConfig{
+				Autoscale: &AppConfigAutoscale{Metric: "rps", Target: ptrInt(100), StableWindow: "120s"},
+			}},
+		},
+	}
+
+	result, err := appConfig.ApplyOverrides("prod")
+
+	require.NoError(t, err)
+	assert.Equal(t, "rps", result.Autoscale.Metric)
+	assert.Equal(t, 100, *result.Autoscale.Target)
+	assert.Equal(t, "120s", result.Autoscale.StableWindow)
+}
+
+func Test_AppConfig_ValidateConcurrencyAndTimeout(t *testing.T) {
+	var tests = []struct {
+		name           string
+		concurrency    *int64
+		timeoutSeconds *int64
+		errors         map[string]string
+	}{
+		{"unspecified", nil, nil, nil},
+		{"valid", ptrInt64(0), ptrInt64(600), nil},
+		{"too low", ptrInt64(-1), ptrInt64(0), map[string]string{
+			"concurrency":    "must be no less than 0",
+			"timeoutSeconds": "must be no less than 1",
+		}},
+		{"too high", ptrInt64(1001), ptrInt64(601), map[string]string{
+			"concurrency":    "must be no greater than 1000",
+			"timeoutSeconds": "must be no greater than 600",
+		}},
+	}
+
+	for _, tt := range tests {
+		appConfig := createMinAppConfig()
+		appConfig.Concurrency = tt.concurrency
+		appConfig.TimeoutSeconds = tt.timeoutSeconds
+		err := appConfig.Validate()
+
+		if tt.errors == nil {
+			assert.NoError(t, err, tt.name)
+		} else {
+			require.IsType(t, validation.Errors{}, err, tt.name)
+			validationErrors := err.(validation.Errors)
+			assert.Len(t, validationErrors, len(tt.errors), tt.name)
+			for field, expected := range tt.errors {
+				require.Contains(t, validationErrors, field, tt.name)
+				assert.Equal(t, expected, validationErrors[field].Error(), tt.name)
+			}
+		}
+	}
+}
+
 func Test_AppConfig_ValidateAutoscaleMaxGtMin(t *testing.T) {
 	min := 2
 	max := 1
@@ -187,6 +316,7 @@ var protocolTests = []struct {
 }{
 	{"http", true},
 	{"http2", true},
+	{"grpc", true},
 	{"", true},
 	{"redis", false},
 }
@@ -204,11 +334,392 @@ func Test_AppConfig_ValidateExposeProtocol(t *testing.T) {
 			validationErrors := err.(validation.Errors)
 			assert.Len(t, validationErrors, 1, tt.protocol)
 			require.Contains(t, validationErrors, "expose.protocol", tt.protocol)
-			assert.Equal(t, "must be one of: http, http2", validationErrors["expose.protocol"].Error(), tt.protocol)
+			assert.Equal(t, "must be one of: http, http2, grpc, tcp", validationErrors["expose.protocol"].Error(), tt.protocol)
+		}
+	}
+}
+
+func Test_AppConfig_ApplyDefaults_TCPDefaultsToClusterScope(t *testing.T) {
+	appConfig := &AppConfig{
+		Name:   "myapp",
+		Expose: &AppConfigExpose{ContainerPort: 5432, Protocol: AppExposeProtocol_TCP},
+	}
+
+	err := appConfig.ApplyDefaults()
+
+	assert.NoError(t, err)
+	assert.Equal(t, AppExposeScope_Cluster, appConfig.Expose.Scope)
+}
+
+func Test_AppConfig_ValidateTCP(t *testing.T) {
+	appConfig := createMinAppConfig()
+	appConfig.Expose.Protocol = AppExposeProtocol_TCP
+	appConfig.Expose.Scope = AppExposeScope_Cluster
+	appConfig.Autoscale = &AppConfigAutoscale{Min: ptrInt(2)}
+
+	assert.NoError(t, appConfig.Validate())
+}
+
+func Test_AppConfig_ValidateTCP_HTTPFeatures(t *testing.T) {
+	appConfig := createMinAppConfig()
+	appConfig.Expose.Protocol = AppExposeProtocol_TCP
+	appConfig.Expose.Scope = AppExposeScope_External
+	appConfig.Concurrency = ptrInt64(10)
+	appConfig.TimeoutSeconds = ptrInt64(30)
+	appConfig.Autoscale = &AppConfigAutoscale{Min: ptrInt(1), Max: ptrInt(2), Metric: AppAutoscaleMetric_RPS}
+	appConfig.Access = &AppConfigAccess{
+		Allow: []AppConfigAccessRule{
+			{Namespace: "billing", Paths: []string{"/api"}, Methods: []string{"GET"}},
+		},
+	}
+
+	err := appConfig.Validate()
+
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 7)
+	assert.Equal(t, "must be cluster for the tcp protocol", validationErrors["expose.scope"].Error())
+	assert.Equal(t, "must not be specified for the tcp protocol", validationErrors["concurrency"].Error())
+	assert.Equal(t, "must not be specified for the tcp protocol", validationErrors["timeoutSeconds"].Error())
+	assert.Equal(t, "must not be specified for the tcp protocol", validationErrors["autoscale.max"].Error())
+	assert.Equal(t, "must not be specified for the tcp protocol", validationErrors["autoscale.metric"].Error())
+	assert.Equal(t, "must not be specified for the tcp protocol", validationErrors["access.allow.0.paths"].Error())
+	assert.Equal(t, "must not be specified for the tcp protocol", validationErrors["access.allow.0.methods"].Error())
+}
+
+func Test_AppConfig_ApplyDefaults_Worker(t *testing.T) {
+	appConfig := &AppConfig{
+		Name: "myworker",
+		Kind: AppKind_Worker,
+	}
+
+	err := appConfig.ApplyDefaults()
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, "apps", appConfig.Namespace)
+	assert.Nil(t, appConfig.Expose)
+}
+
+func Test_AppConfig_ValidateWorker(t *testing.T) {
+	appConfig := createMinAppConfig()
+	appConfig.Kind = AppKind_Worker
+	appConfig.Expose = nil
+	appConfig.Autoscale = &AppConfigAutoscale{Min: ptrInt(1), Max: ptrInt(3), Metric: AppAutoscaleMetric_CPU, Target: ptrInt(70)}
+	appConfig.HealthCheck = &AppConfigHealthCheck{
+		AppConfigProbe: AppConfigProbe{Mode: AppHealthCheckMode_Exec, Command: []string{"/bin/healthcheck"}},
+	}
+
+	assert.NoError(t, appConfig.Validate())
+}
+
+func Test_AppConfig_ValidateWorker_ServiceFeatures(t *testing.T) {
+	appConfig := createMinAppConfig()
+	appConfig.Kind = AppKind_Worker
+	appConfig.Domains = []string{"myapp.example.com"}
+	appConfig.Concurrency = ptrInt64(10)
+	appConfig.TimeoutSeconds = ptrInt64(30)
+	appConfig.Autoscale = &AppConfigAutoscale{Min: ptrInt(0), Max: ptrInt(2), Class: AppAutoscaleClass_KPA, StableWindow: "60s"}
+	appConfig.HealthCheck = &AppConfigHealthCheck{AppConfigProbe: AppConfigProbe{Path: "/health"}}
+	appConfig.Access = &AppConfigAccess{
+		Allow: []AppConfigAccessRule{{Namespace: "billing"}},
+	}
+
+	err := appConfig.Validate()
+
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 11)
+	assert.Equal(t, "must not be specified for the worker kind", validationErrors["expose"].Error())
+	assert.Equal(t, "must not be specified for the worker kind", validationErrors["domains"].Error())
+	assert.Equal(t, "must not be specified for the worker kind", validationErrors["concurrency"].Error())
+	assert.Equal(t, "must not be specified for the worker kind", validationErrors["timeoutSeconds"].Error())
+	assert.Equal(t, "must not be specified for the worker kind", validationErrors["access"].Error())
+	assert.Equal(t, "must be no less than 1 when autoscale.max is specified", validationErrors["autoscale.min"].Error())
+	assert.Equal(t, "must be hpa for the worker kind", validationErrors["autoscale.class"].Error())
+	assert.Equal(t, "must only be specified for the kpa class", validationErrors["autoscale.stableWindow"].Error())
+	assert.Equal(t, "must be exec for the worker kind", validationErrors["healthcheck.mode"].Error())
+	assert.Equal(t, "must only be specified for the httpGet mode", validationErrors["healthcheck.path"].Error())
+	assert.Equal(t, "is required for the exec mode", validationErrors["healthcheck.command"].Error())
+}
+
+func Test_AppConfig_ValidateScheduledJob(t *testing.T) {
+	appConfig := createMinAppConfig()
+	appConfig.Kind = AppKind_ScheduledJob
+	appConfig.Expose = nil
+	appConfig.Job = &AppConfigJob{
+		Schedule:                   "*/15 * * * *",
+		ConcurrencyPolicy:          AppJobConcurrencyPolicy_Replace,
+		BackoffLimit:               ptrInt32(0),
+		SuccessfulJobsHistoryLimit: ptrInt32(1),
+		FailedJobsHistoryLimit:     ptrInt32(1),
+	}
+
+	assert.NoError(t, appConfig.Validate())
+}
+
+func Test_AppConfig_ValidateScheduledJob_Schedule(t *testing.T) {
+	var tests = []struct {
+		schedule string
+		valid    bool
+	}{
+		{"0 3 * * *", true},
+		{"0 3 * * 1-5", true},
+		{"@daily", true},
+		{"@every 2h", true},
+		{"0 3 * *", false},
+		{"0 3 * * * *", false},
+		{"@sometimes", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		appConfig := createMinAppConfig()
+		appConfig.Kind = AppKind_ScheduledJob
+		appConfig.Expose = nil
+		appConfig.Job = &AppConfigJob{Schedule: tt.schedule}
+
+		err := appConfig.Validate()
+
+		if tt.valid {
+			assert.NoError(t, err, tt.schedule)
+		} else {
+			require.IsType(t, validation.Errors{}, err, tt.schedule)
+			assert.Len(t, err.(validation.Errors), 1, tt.schedule)
+			assert.NotNil(t, err.(validation.Errors)["job.schedule"], tt.schedule)
 		}
 	}
 }
 
+func Test_AppConfig_ValidateScheduledJob_ServiceFeatures(t *testing.T) {
+	appConfig := createMinAppConfig()
+	appConfig.Kind = AppKind_ScheduledJob
+	appConfig.Concurrency = ptrInt64(10)
+	appConfig.Autoscale = &AppConfigAutoscale{Min: ptrInt(2)}
+	appConfig.HealthCheck = &AppConfigHealthCheck{AppConfigProbe: AppConfigProbe{Path: "/health"}}
+	appConfig.Job = &AppConfigJob{
+		Schedule:                   "@daily",
+		ConcurrencyPolicy:          "sometimes",
+		BackoffLimit:               ptrInt32(-1),
+		SuccessfulJobsHistoryLimit: ptrInt32(-1),
+		FailedJobsHistoryLimit:     ptrInt32(-1),
+	}
+
+	err := appConfig.Validate()
+
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 8)
+	assert.Equal(t, "must not be specified for the scheduledJob kind", validationErrors["expose"].Error())
+	assert.Equal(t, "must not be specified for the scheduledJob kind", validationErrors["concurrency"].Error())
+	assert.Equal(t, "must not be specified for the scheduledJob kind", validationErrors["autoscale"].Error())
+	assert.Equal(t, "must not be specified for the scheduledJob kind", validationErrors["healthcheck"].Error())
+	assert.Equal(t, "must be one of: allow, forbid, replace", validationErrors["job.concurrencyPolicy"].Error())
+	assert.Equal(t, "must be no less than 0", validationErrors["job.backoffLimit"].Error())
+	assert.Equal(t, "must be no less than 0", validationErrors["job.successfulJobsHistoryLimit"].Error())
+	assert.Equal(t, "must be no less than 0", validationErrors["job.failedJobsHistoryLimit"].Error())
+}
+
+func Test_AppConfig_ValidateJob(t *testing.T) {
+	appConfig := createMinAppConfig()
+	appConfig.Job = &AppConfigJob{Schedule: "@daily"}
+
+	err := appConfig.Validate()
+
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 1)
+	assert.Equal(t, "must only be specified for the scheduledJob kind", validationErrors["job"].Error())
+
+	appConfig.Kind = AppKind_ScheduledJob
+	appConfig.Expose = nil
+	appConfig.Job = nil
+
+	err = appConfig.Validate()
+
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors = err.(validation.Errors)
+	assert.Len(t, validationErrors, 1)
+	assert.Equal(t, "cannot be blank", validationErrors["job"].Error())
+}
+
+func Test_ApplyOverrides_JobSchedule(t *testing.T) {
+	appConfig := &AppConfigWithOverrides{
+		AppConfig: AppConfig{
+			Name: "myreport",
+			Kind: AppKind_ScheduledJob,
+			OverrideableAppConfig: OverrideableAppConfig{
+				Job: &AppConfigJob{Schedule: "0 3 * * *", ConcurrencyPolicy: AppJobConcurrencyPolicy_Forbid},
+			},
+		},
+		Overrides: map[string]AppConfigOverride{
+			"dev": {OverrideableAppConfig: OverrideableAppConfig{
+				Job: &AppConfigJob{Schedule: "@hourly"},
+			}},
+		},
+	}
+
+	result, err := appConfig.ApplyOverrides("dev")
+
+	require.NoError(t, err)
+	assert.Equal(t, &AppConfigJob{Schedule: "@hourly", ConcurrencyPolicy: AppJobConcurrencyPolicy_Forbid}, result.Job)
+	assert.Equal(t, "0 3 * * *", appConfig.Job.Schedule)
+}
+
+func Test_AppConfig_ValidatePreDeploy(t *testing.T) {
+	appConfig := createMinAppConfig()
+	appConfig.PreDeploy = &AppConfigPreDeploy{Command: []string{"/bin/migrate"}, Args: []string{"up"}}
+
+	assert.NoError(t, appConfig.Validate())
+
+	appConfig.PreDeploy = &AppConfigPreDeploy{Args: []string{"up"}}
+
+	err := appConfig.Validate()
+
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 1)
+	assert.Equal(t, "cannot be blank", validationErrors["preDeploy.command"].Error())
+}
+
+func Test_AppConfig_ValidatePreDeploy_NotServedByKNative(t *testing.T) {
+	appConfig := createMinAppConfig()
+	appConfig.Expose.Protocol = AppExposeProtocol_TCP
+	appConfig.Expose.Scope = AppExposeScope_Cluster
+	appConfig.PreDeploy = &AppConfigPreDeploy{Command: []string{"/bin/migrate"}}
+
+	err := appConfig.Validate()
+
+	require.IsType(t, validation.Errors{}, err)
+	assert.Equal(t, "must not be specified for the tcp protocol", err.(validation.Errors)["preDeploy"].Error())
+
+	appConfig = createMinAppConfig()
+	appConfig.Kind = AppKind_Worker
+	appConfig.Expose = nil
+	appConfig.PreDeploy = &AppConfigPreDeploy{Command: []string{"/bin/migrate"}}
+
+	err = appConfig.Validate()
+
+	require.IsType(t, validation.Errors{}, err)
+	assert.Equal(t, "must not be specified for the worker kind", err.(validation.Errors)["preDeploy"].Error())
+}
+
+func Test_AppConfig_ValidateScheduling(t *testing.T) {
+	appConfig := createMinAppConfig()
+	appConfig.Scheduling = &AppConfigScheduling{
+		NodeSelector: map[string]string{"node-pool": "highmem"},
+		Tolerations:  []AppConfigToleration{{Key: "dedicated", Value: "highmem", Effect: AppTolerationEffect_NoSchedule}},
+		Affinity:     &AppConfigAffinity{Zones: []string{"us-east1-b"}, ZoneSpread: AppZoneSpread_Required},
+	}
+
+	assert.NoError(t, appConfig.Validate())
+
+	appConfig.Scheduling = &AppConfigScheduling{
+		NodeSelector: map[string]string{"bad label": "highmem", "node-pool": "high mem"},
+		Tolerations: []AppConfigToleration{
+			{Operator: AppTolerationOperator_Exists, Effect: "NoSchedule"},
+			{Key: "dedicated", Operator: AppTolerationOperator_Exists, Value: "highmem"},
+		},
+		Affinity: &AppConfigAffinity{Zones: []string{""}, ZoneSpread: "always"},
+	}
+
+	err := appConfig.Validate()
+
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 7)
+	assert.Contains(t, validationErrors["scheduling.nodeSelector.bad label"].Error(), "invalid label: name part must consist of alphanumeric characters")
+	assert.Contains(t, validationErrors["scheduling.nodeSelector.node-pool"].Error(), "invalid label value: a valid label must be an empty string or consist of alphanumeric characters")
+	assert.Equal(t, "cannot be blank", validationErrors["scheduling.tolerations.0.key"].Error())
+	assert.Equal(t, "must be one of: noSchedule, preferNoSchedule, noExecute", validationErrors["scheduling.tolerations.0.effect"].Error())
+	assert.Equal(t, "must not be specified for the exists operator", validationErrors["scheduling.tolerations.1.value"].Error())
+	assert.Equal(t, "0: cannot be blank.", validationErrors["scheduling.affinity.zones"].Error())
+	assert.Equal(t, "must be one of: preferred, required", validationErrors["scheduling.affinity.zoneSpread"].Error())
+}
+
+func Test_AppConfig_ValidateSecurity(t *testing.T) {
+	runAsNonRoot := true
+	appConfig := createMinAppConfig()
+	appConfig.Security = &AppConfigSecurity{
+		RunAsNonRoot: &runAsNonRoot,
+		RunAsUser:    ptrInt64(1000),
+		FSGroup:      ptrInt64(2000),
+		Capabilities: &AppConfigCapabilities{Add: []string{"NET_BIND_SERVICE"}, Drop: []string{"ALL"}},
+	}
+
+	assert.NoError(t, appConfig.Validate())
+
+	appConfig.Security = &AppConfigSecurity{
+		RunAsNonRoot: &runAsNonRoot,
+		RunAsUser:    ptrInt64(0),
+		RunAsGroup:   ptrInt64(-1),
+		Capabilities: &AppConfigCapabilities{Drop: []string{"net_raw"}},
+	}
+
+	err := appConfig.Validate()
+
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 3)
+	assert.Equal(t, "must not be 0 (root) when runAsNonRoot is true", validationErrors["security.runAsUser"].Error())
+	assert.Equal(t, "must be no less than 0", validationErrors["security.runAsGroup"].Error())
+	assert.Equal(t, "0: must be an uppercase capability name (e.g. NET_BIND_SERVICE).", validationErrors["security.capabilities.drop"].Error())
+}
+
+func Test_AppConfig_ApplyOverrides_Security(t *testing.T) {
+	readOnlyRootFilesystem := true
+	runAsNonRoot := true
+	disabled := false
+	appConfig := &AppConfigWithOverrides{
+		AppConfig: *createMinAppConfig(),
+		Overrides: map[string]AppConfigOverride{
+			"dev": {
+				OverrideableAppConfig: OverrideableAppConfig{
+					Security: &AppConfigSecurity{ReadOnlyRootFilesystem: &disabled},
+				},
+			},
+		},
+	}
+	appConfig.Security = &AppConfigSecurity{RunAsNonRoot: &runAsNonRoot, ReadOnlyRootFilesystem: &readOnlyRootFilesystem}
+
+	result, err := appConfig.ApplyOverrides("dev")
+
+	require.NoError(t, err)
+	assert.True(t, *result.Security.RunAsNonRoot)
+	assert.False(t, *result.Security.ReadOnlyRootFilesystem)
+}
+
+func Test_AppConfig_ValidateKind(t *testing.T) {
+	appConfig := createMinAppConfig()
+	appConfig.Kind = "job"
+
+	err := appConfig.Validate()
+
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 1)
+	assert.Equal(t, "must be one of: service, worker, scheduledJob", validationErrors["kind"].Error())
+}
+
+func Test_AppConfigProbe_ModeOrDefault(t *testing.T) {
+	var tests = []struct {
+		probe    AppConfigProbe
+		protocol string
+		expected string
+	}{
+		{AppConfigProbe{}, "", AppHealthCheckMode_HTTPGet},
+		{AppConfigProbe{}, AppExposeProtocol_HTTP2, AppHealthCheckMode_HTTPGet},
+		{AppConfigProbe{}, AppExposeProtocol_GRPC, AppHealthCheckMode_GRPC},
+		{AppConfigProbe{}, AppExposeProtocol_TCP, AppHealthCheckMode_TCP},
+		{AppConfigProbe{Mode: AppHealthCheckMode_Exec}, AppExposeProtocol_GRPC, AppHealthCheckMode_Exec},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, tt.probe.ModeOrDefault(&AppConfigExpose{Protocol: tt.protocol}), tt.protocol)
+	}
+
+	assert.Equal(t, AppHealthCheckMode_HTTPGet, AppConfigProbe{}.ModeOrDefault(nil))
+}
+
 func Test_ApplyOverrides_NoOverrides(t *testing.T) {
 	appConfig := &AppConfigWithOverrides{
 		AppConfig: AppConfig{
@@ -234,12 +745,12 @@ func Test_ApplyOverrides_NoOverridesForEnvironment(t *testing.T) {
 				},
 			},
 		},
-		Overrides: map[string]OverrideableAppConfig{
-			"dev": {
+		Overrides: map[string]AppConfigOverride{
+			"dev": {OverrideableAppConfig: OverrideableAppConfig{
 				Resources: &AppConfigResources{
 					CpuCores: &cpuCoresDev,
 				},
-			},
+			}},
 		},
 	}
 
@@ -262,13 +773,13 @@ func Test_ApplyOverrides_WithOverrides(t *testing.T) {
 			Id:    appId,
 			Name:  "myapp",
 			Image: "hashicorp/http-echo",
-			HealthCheck: &AppConfigHealthCheck{
-				Path: "/health",
-			},
 			Expose: &AppConfigExpose{
 				ContainerPort: 1337,
 			},
 			OverrideableAppConfig: OverrideableAppConfig{
+				HealthCheck: &AppConfigHealthCheck{
+					AppConfigProbe: AppConfigProbe{Path: "/health"},
+				},
 				Autoscale: &AppConfigAutoscale{
 					Min: &autoscaleMin,
 				},
@@ -281,8 +792,8 @@ func Test_ApplyOverrides_WithOverrides(t *testing.T) {
 				},
 			},
 		},
-		Overrides: map[string]OverrideableAppConfig{
-			"dev": {
+		Overrides: map[string]AppConfigOverride{
+			"dev": {OverrideableAppConfig: OverrideableAppConfig{
 				Autoscale: &AppConfigAutoscale{
 					Min: &autoscaleMinOverride,
 				},
@@ -293,7 +804,7 @@ func Test_ApplyOverrides_WithOverrides(t *testing.T) {
 					"envKey":    intstr.Parse("envValDevOverride"),
 					"envKeyDev": intstr.Parse("envValDev"),
 				},
-			},
+			}},
 		},
 	}
 
@@ -342,55 +853,797 @@ func Test_AppConfig_ValidateExposeScope(t *testing.T) {
 	}
 }
 
-func Test_AppConfig_ValidateEnvironment(t *testing.T) {
+func Test_AppConfig_ValidateHealthCheck(t *testing.T) {
 	var tests = []struct {
-		env   string
-		valid bool
+		name        string
+		healthCheck *AppConfigHealthCheck
+		errors      map[string]string
 	}{
-		// good
-		{"MYENV", true},
-		{"MY_ENV", true},
-		// bad
-		{"myenv", false},
-		{"MY-ENV", false},
-		{"RISER_ENV", false},
-		{"9MYENV", false},
+		{"httpGet default", &AppConfigHealthCheck{AppConfigProbe: AppConfigProbe{Path: "/health"}}, nil},
+		{"tcp", &AppConfigHealthCheck{AppConfigProbe: AppConfigProbe{Mode: "tcp"}}, nil},
+		{"grpc", &AppConfigHealthCheck{AppConfigProbe: AppConfigProbe{Mode: "grpc", Service: "myservice"}}, nil},
+		{"exec", &AppConfigHealthCheck{AppConfigProbe: AppConfigProbe{Mode: "exec", Command: []string{"cat", "/tmp/healthy"}}}, nil},
+		{"tuning", &AppConfigHealthCheck{AppConfigProbe: AppConfigProbe{
+			Mode:                "tcp",
+			InitialDelaySeconds: ptrInt32(0),
+			PeriodSeconds:       ptrInt32(5),
+			TimeoutSeconds:      ptrInt32(1),
+			FailureThreshold:    ptrInt32(3),
+		}}, nil},
+		{"liveness and startup", &AppConfigHealthCheck{
+			AppConfigProbe: AppConfigProbe{Path: "/ready"},
+			Liveness:       &AppConfigProbe{Mode: "tcp"},
+			Startup:        &AppConfigProbe{Path: "/started", FailureThreshold: ptrInt32(30)},
+		}, nil},
+		{"invalid mode", &AppConfigHealthCheck{AppConfigProbe: AppConfigProbe{Mode: "nope"}},
+			map[string]string{"healthcheck.mode": "must be one of: httpGet, tcp, grpc, exec"}},
+		{"httpGet requires path", &AppConfigHealthCheck{},
+			map[string]string{"healthcheck.path": "is required for the httpGet mode"}},
+		{"path must be absolute", &AppConfigHealthCheck{AppConfigProbe: AppConfigProbe{Path: "health"}},
+			map[string]string{"healthcheck.path": `must start with "/"`}},
+		{"path only for httpGet", &AppConfigHealthCheck{AppConfigProbe: AppConfigProbe{Mode: "tcp", Path: "/health"}},
+			map[string]string{"healthcheck.path": "must only be specified for the httpGet mode"}},
+		{"service only for grpc", &AppConfigHealthCheck{AppConfigProbe: AppConfigProbe{Path: "/health", Service: "myservice"}},
+			map[string]string{"healthcheck.service": "must only be specified for the grpc mode"}},
+		{"exec requires command", &AppConfigHealthCheck{AppConfigProbe: AppConfigProbe{Mode: "exec"}},
+			map[string]string{"healthcheck.command": "is required for the exec mode"}},
+		{"command only for exec", &AppConfigHealthCheck{AppConfigProbe: AppConfigProbe{Mode: "tcp", Command: []string{"true"}}},
+			map[string]string{"healthcheck.command": "must only be specified for the exec mode"}},
+		{"invalid tuning", &AppConfigHealthCheck{AppConfigProbe: AppConfigProbe{
+			Mode:                "tcp",
+			InitialDelaySeconds: ptrInt32(-1),
+			PeriodSeconds:       ptrInt32(0),
+			TimeoutSeconds:      ptrInt32(0),
+			FailureThreshold:    ptrInt32(0),
+		}}, map[string]string{
+			"healthcheck.initialDelaySeconds": "must be no less than 0",
+			"healthcheck.periodSeconds":       "must be no less than 1",
+			"healthcheck.timeoutSeconds":      "must be no less than 1",
+			"healthcheck.failureThreshold":    "must be no less than 1",
+		}},
+		{"invalid liveness and startup", &AppConfigHealthCheck{
+			AppConfigProbe: AppConfigProbe{Path: "/ready"},
+			Liveness:       &AppConfigProbe{Mode: "exec"},
+			Startup:        &AppConfigProbe{Mode: "nope"},
+		}, map[string]string{
+			"healthcheck.liveness.command": "is required for the exec mode",
+			"healthcheck.startup.mode":     "must be one of: httpGet, tcp, grpc, exec",
+		}},
 	}
+
 	for _, tt := range tests {
 		appConfig := createMinAppConfig()
-		appConfig.Environment = map[string]intstr.IntOrString{}
-		appConfig.Environment[tt.env] = intstr.FromString("val")
+		appConfig.HealthCheck = tt.healthCheck
 		err := appConfig.Validate()
 
-		if tt.valid {
-			assert.NoError(t, err, tt.env)
+		if tt.errors == nil {
+			assert.NoError(t, err, tt.name)
 		} else {
-			errKey := fmt.Sprintf("env.%s", tt.env)
-			require.IsType(t, validation.Errors{}, err, tt.env)
+			require.IsType(t, validation.Errors{}, err, tt.name)
 			validationErrors := err.(validation.Errors)
-			assert.Len(t, validationErrors, 1, tt.env)
-			assert.Contains(t, validationErrors[errKey].Error(), tt.env)
+			assert.Len(t, validationErrors, len(tt.errors), tt.name)
+			for field, expected := range tt.errors {
+				require.Contains(t, validationErrors, field, tt.name)
+				assert.Equal(t, expected, validationErrors[field].Error(), tt.name)
+			}
 		}
 	}
 }
 
-func Test_AppConfig_ValidateEnvironment_MultipleErrors(t *testing.T) {
-	appConfig := createMinAppConfig()
-	appConfig.Environment = map[string]intstr.IntOrString{
-		"RISER_ENV": intstr.FromString("val"),
-		"9MYENV":    intstr.FromString("val"),
-	}
-
-	err := appConfig.Validate()
-	require.IsType(t, validation.Errors{}, err)
-	validationErrors := err.(validation.Errors)
+func Test_ApplyOverrides_HealthCheck(t *testing.T) {
+	appConfig := &AppConfigWithOverrides{
+		AppConfig: AppConfig{
+			OverrideableAppConfig: OverrideableAppConfig{
+				HealthCheck: &AppConfigHealthCheck{
+					AppConfigProbe: AppConfigProbe{Path: "/health", PeriodSeconds: ptrInt32(10)},
+					Liveness:       &AppConfigProbe{Path: "/live", PeriodSeconds: ptrInt32(30)},
+				},
+			},
+		},
+		Overrides: map[string]AppConfigOverride{
+			"dev": {OverrideableAppConfig: OverrideableAppConfig{
+				Autoscale: &AppConfigAutoscale{Min: ptrInt(0)},
+			}},
+			"staging": {OverrideableAppConfig: OverrideableAppConfig{
+				HealthCheck: &AppConfigHealthCheck{
+					Liveness: &AppConfigProbe{PeriodSeconds: ptrInt32(5)},
+					Startup:  &AppConfigProbe{Path: "/started"},
+				},
+			}},
+			"prod": {
+				OverrideableAppConfig: OverrideableAppConfig{
+					HealthCheck: &AppConfigHealthCheck{
+						AppConfigProbe: AppConfigProbe{Mode: "tcp"},
+					},
+				},
+				Clear: []string{"healthcheck.path", "healthcheck.liveness"},
+			},
+		},
+	}
+
+	dev, err := appConfig.ApplyOverrides("dev")
+
+	require.NoError(t, err)
+	assert.Equal(t, appConfig.HealthCheck, dev.HealthCheck)
+
+	// Nested values are merged with the app config
+	staging, err := appConfig.ApplyOverrides("staging")
+
+	require.NoError(t, err)
+	assert.Equal(t, "/health", staging.HealthCheck.Path)
+	assert.EqualValues(t, 10, *staging.HealthCheck.PeriodSeconds)
+	assert.Equal(t, "/live", staging.HealthCheck.Liveness.Path)
+	assert.EqualValues(t, 5, *staging.HealthCheck.Liveness.PeriodSeconds)
+	assert.Equal(t, "/started", staging.HealthCheck.Startup.Path)
+
+	prod, err := appConfig.ApplyOverrides("prod")
+
+	require.NoError(t, err)
+	assert.Equal(t, "tcp", prod.HealthCheck.Mode)
+	assert.Empty(t, prod.HealthCheck.Path)
+	assert.EqualValues(t, 10, *prod.HealthCheck.PeriodSeconds)
+	assert.Nil(t, prod.HealthCheck.Liveness)
+	// Ensure that we don't mutate the original config
+	assert.Equal(t, "/health", appConfig.HealthCheck.Path)
+	assert.EqualValues(t, 30, *appConfig.HealthCheck.Liveness.PeriodSeconds)
+	assert.Nil(t, appConfig.HealthCheck.Startup)
+}
+
+func Test_ApplyOverrides_ExposeScope(t *testing.T) {
+	appConfig := &AppConfigWithOverrides{
+		AppConfig: AppConfig{
+			Expose: &AppConfigExpose{ContainerPort: 8080, Protocol: AppExposeProtocol_HTTP, Scope: AppExposeScope_External},
+		},
+		Overrides: map[string]AppConfigOverride{
+			"dev":  {Expose: &AppConfigExposeOverride{}},
+			"prod": {Expose: &AppConfigExposeOverride{Scope: AppExposeScope_Cluster}},
+		},
+	}
+
+	dev, err := appConfig.ApplyOverrides("dev")
+
+	require.NoError(t, err)
+	assert.Equal(t, appConfig.Expose, dev.Expose)
+
+	prod, err := appConfig.ApplyOverrides("prod")
+
+	require.NoError(t, err)
+	assert.Equal(t, &AppConfigExpose{ContainerPort: 8080, Protocol: AppExposeProtocol_HTTP, Scope: AppExposeScope_Cluster}, prod.Expose)
+	assert.Equal(t, AppExposeScope_External, appConfig.Expose.Scope)
+}
+
+func Test_ApplyOverrides_Clear(t *testing.T) {
+	appConfig := &AppConfigWithOverrides{
+		AppConfig: AppConfig{
+			OverrideableAppConfig: OverrideableAppConfig{
+				Autoscale:   &AppConfigAutoscale{Min: ptrInt(1), Max: ptrInt(10)},
+				Domains:     []string{"myapp.example.com"},
+				Environment: map[string]intstr.IntOrString{"LOG_LEVEL": intstr.FromString("debug"), "OTHER": intstr.FromString("val")},
+			},
+		},
+		Overrides: map[string]AppConfigOverride{
+			"prod": {
+				OverrideableAppConfig: OverrideableAppConfig{
+					// Cleared values may be replaced by the same override
+					Autoscale: &AppConfigAutoscale{Min: ptrInt(0)},
+				},
+				Clear: []string{"autoscale", "domains", "env.LOG_LEVEL", "env.MISSING", "healthcheck.liveness.path"},
+			},
+		},
+	}
+
+	result, err := appConfig.ApplyOverrides("prod")
+
+	require.NoError(t, err)
+	assert.Equal(t, &AppConfigAutoscale{Min: ptrInt(0)}, result.Autoscale)
+	assert.Nil(t, result.Domains)
+	assert.Equal(t, map[string]intstr.IntOrString{"OTHER": intstr.FromString("val")}, result.Environment)
+	assert.Nil(t, result.HealthCheck)
+	// Ensure that we don't mutate the original config
+	assert.Equal(t, 10, *appConfig.Autoscale.Max)
+	assert.Len(t, appConfig.Environment, 2)
+}
+
+func Test_ApplyOverrides_ClearInvalidPath(t *testing.T) {
+	appConfig := &AppConfigWithOverrides{
+		Overrides: map[string]AppConfigOverride{
+			"prod": {Clear: []string{"autoscale.bad"}},
+		},
+	}
+
+	result, err := appConfig.ApplyOverrides("prod")
+
+	assert.Nil(t, result)
+	assert.Equal(t, `unable to clear "autoscale.bad" for environment "prod": "bad" is not an overrideable field`, err.Error())
+}
+
+func Test_AppConfigWithOverrides_Validate(t *testing.T) {
+	appConfig := &AppConfigWithOverrides{
+		AppConfig: AppConfig{
+			Id:        uuid.New(),
+			Name:      "myapp",
+			Namespace: "apps",
+			Image:     "myapp",
+			Expose:    &AppConfigExpose{ContainerPort: 8080},
+		},
+		Overrides: map[string]AppConfigOverride{
+			"dev":  {Clear: []string{"env.LOG_LEVEL", "healthcheck.startup"}},
+			"prod": {Clear: []string{"expose", "healthcheck.bad", "env.LOG_LEVEL.foo", "timeoutSeconds.foo"}},
+		},
+	}
+
+	err := appConfig.Validate()
+
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 4)
+	assert.Equal(t, `"expose" is not an overrideable field`, validationErrors["environmentOverrides.prod.clear[0]"].Error())
+	assert.Equal(t, `"bad" is not an overrideable field`, validationErrors["environmentOverrides.prod.clear[1]"].Error())
+	assert.Equal(t, `the fields of "LOG_LEVEL" cannot be cleared individually`, validationErrors["environmentOverrides.prod.clear[2]"].Error())
+	assert.Equal(t, `"foo" is not an overrideable field`, validationErrors["environmentOverrides.prod.clear[3]"].Error())
+}
+
+func Test_AppConfig_ValidateResources(t *testing.T) {
+	var tests = []struct {
+		name      string
+		resources *AppConfigResources
+		errors    map[string]string
+	}{
+		{"shorthand", &AppConfigResources{CpuCores: ptrFloat32(1), MemoryMB: ptrInt32(128)}, nil},
+		{"requests and limits", &AppConfigResources{
+			Requests: &AppConfigResourceList{CpuCores: ptrFloat32(0.5), MemoryMB: ptrInt32(128)},
+			Limits:   &AppConfigResourceList{CpuCores: ptrFloat32(0.5), MemoryMB: ptrInt32(256)},
+		}, nil},
+		{"requests without limits", &AppConfigResources{
+			Requests: &AppConfigResourceList{CpuCores: ptrFloat32(4), MemoryMB: ptrInt32(4096)},
+		}, nil},
+		{"request exceeds limit", &AppConfigResources{
+			Requests: &AppConfigResourceList{CpuCores: ptrFloat32(2), MemoryMB: ptrInt32(512)},
+			Limits:   &AppConfigResourceList{CpuCores: ptrFloat32(1), MemoryMB: ptrInt32(256)},
+		}, map[string]string{
+			"resources.requests.cpuCores": "must be less than or equal to the cpuCores limit",
+			"resources.requests.memoryMB": "must be less than or equal to the memoryMB limit",
+		}},
+		{"request exceeds shorthand limit", &AppConfigResources{
+			CpuCores: ptrFloat32(1),
+			Requests: &AppConfigResourceList{CpuCores: ptrFloat32(2)},
+		}, map[string]string{
+			"resources.requests.cpuCores": "must be less than or equal to the cpuCores limit",
+		}},
+		{"shorthand and limits", &AppConfigResources{
+			CpuCores: ptrFloat32(1),
+			MemoryMB: ptrInt32(128),
+			Limits:   &AppConfigResourceList{CpuCores: ptrFloat32(1), MemoryMB: ptrInt32(128)},
+		}, map[string]string{
+			"resources.limits.cpuCores": "must not be specified with cpuCores",
+			"resources.limits.memoryMB": "must not be specified with memoryMB",
+		}},
+		{"non positive values", &AppConfigResources{
+			Requests: &AppConfigResourceList{CpuCores: ptrFloat32(0)},
+			Limits:   &AppConfigResourceList{MemoryMB: ptrInt32(-1)},
+		}, map[string]string{
+			"resources.requests.cpuCores": "must be greater than 0",
+			"resources.limits.memoryMB":   "must be greater than 0",
+		}},
+	}
+
+	for _, tt := range tests {
+		appConfig := createMinAppConfig()
+		appConfig.Resources = tt.resources
+		err := appConfig.Validate()
+
+		if tt.errors == nil {
+			assert.NoError(t, err, tt.name)
+		} else {
+			require.IsType(t, validation.Errors{}, err, tt.name)
+			validationErrors := err.(validation.Errors)
+			assert.Len(t, validationErrors, len(tt.errors), tt.name)
+			for field, expected := range tt.errors {
+				require.Contains(t, validationErrors, field, tt.name)
+				assert.Equal(t, expected, validationErrors[field].Error(), tt.name)
+			}
+		}
+	}
+}
+
+func Test_AppConfigResources_LimitsOrShorthand(t *testing.T) {
+	resources := AppConfigResources{
+		CpuCores: ptrFloat32(1),
+		MemoryMB: ptrInt32(128),
+		Limits:   &AppConfigResourceList{MemoryMB: ptrInt32(256)},
+	}
+
+	result := resources.LimitsOrShorthand()
+
+	assert.Equal(t, float32(1), *result.CpuCores)
+	assert.Equal(t, int32(256), *result.MemoryMB)
+}
+
+func Test_AppConfig_ValidateFiles(t *testing.T) {
+	appConfig := createMinAppConfig()
+	appConfig.Files = map[string]string{
+		"/etc/myapp/config.yaml": "key: value",
+		"relative/path":          "",
+		"/etc/myapp/../escape":   "",
+		"/etc/myapp/":            "",
+		"/":                      "",
+		"/etc/toolarge":          strings.Repeat("a", 1024*1024+1),
+	}
+
+	err := appConfig.Validate()
+
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 5)
+	for _, mountPath := range []string{"relative/path", "/etc/myapp/../escape", "/etc/myapp/", "/"} {
+		errKey := fmt.Sprintf("files.%s", mountPath)
+		require.Contains(t, validationErrors, errKey)
+		assert.Equal(t, `must be an absolute path to a file (e.g. "/etc/myapp/config.yaml")`, validationErrors[errKey].Error(), mountPath)
+	}
+	assert.Equal(t, "must be no larger than 1MiB", validationErrors["files./etc/toolarge"].Error())
+}
+
+func Test_ApplyOverrides_Files(t *testing.T) {
+	appConfig := &AppConfigWithOverrides{
+		AppConfig: AppConfig{
+			Name: "myapp",
+			OverrideableAppConfig: OverrideableAppConfig{
+				Files: map[string]string{
+					"/etc/myapp/a.conf": "base",
+					"/etc/myapp/b.conf": "base",
+				},
+			},
+		},
+		Overrides: map[string]AppConfigOverride{
+			"prod": {OverrideableAppConfig: OverrideableAppConfig{
+				Files: map[string]string{
+					"/etc/myapp/b.conf": "prod",
+					"/etc/myapp/c.conf": "prod",
+				},
+			}},
+		},
+	}
+
+	result, err := appConfig.ApplyOverrides("prod")
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"/etc/myapp/a.conf": "base",
+		"/etc/myapp/b.conf": "prod",
+		"/etc/myapp/c.conf": "prod",
+	}, result.Files)
+}
+
+func Test_AppConfig_ValidateSecretFiles(t *testing.T) {
+	appConfig := createMinAppConfig()
+	appConfig.Files = map[string]string{"/etc/myapp/config.yaml": "key: value"}
+	appConfig.SecretFiles = map[string]AppConfigSecretFile{
+		"good":        {MountPath: "/etc/myapp/tls.key", Mode: ptrInt32(0400)},
+		"goodnomode":  {MountPath: "/etc/myapp/tls.crt"},
+		"relative":    {MountPath: "etc/myapp/key"},
+		"nomountpath": {},
+		"badmode":     {MountPath: "/etc/myapp/other", Mode: ptrInt32(01000)},
+		"conflict":    {MountPath: "/etc/myapp/config.yaml"},
+	}
+
+	err := appConfig.Validate()
+
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 4)
+	assert.Equal(t, `must be an absolute path to a file (e.g. "/etc/myapp/config.yaml")`, validationErrors["secretFiles.relative.mountPath"].Error())
+	assert.Equal(t, "cannot be blank", validationErrors["secretFiles.nomountpath.mountPath"].Error())
+	assert.Equal(t, "must be no greater than 0777", validationErrors["secretFiles.badmode.mode"].Error())
+	assert.Equal(t, "must not be the same as the path of a file", validationErrors["secretFiles.conflict.mountPath"].Error())
+}
+
+func Test_ApplyOverrides_SecretFiles(t *testing.T) {
+	appConfig := &AppConfigWithOverrides{
+		AppConfig: AppConfig{
+			Name: "myapp",
+			OverrideableAppConfig: OverrideableAppConfig{
+				SecretFiles: map[string]AppConfigSecretFile{
+					"a": {MountPath: "/etc/myapp/a"},
+					"b": {MountPath: "/etc/myapp/b"},
+				},
+			},
+		},
+		Overrides: map[string]AppConfigOverride{
+			"prod": {OverrideableAppConfig: OverrideableAppConfig{
+				SecretFiles: map[string]AppConfigSecretFile{
+					"b": {MountPath: "/etc/myapp/prod/b", Mode: ptrInt32(0400)},
+				},
+			}},
+		},
+	}
+
+	result, err := appConfig.ApplyOverrides("prod")
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]AppConfigSecretFile{
+		"a": {MountPath: "/etc/myapp/a"},
+		"b": {MountPath: "/etc/myapp/prod/b", Mode: ptrInt32(0400)},
+	}, result.SecretFiles)
+}
+
+func Test_AppConfig_ValidateAccess(t *testing.T) {
+	appConfig := createMinAppConfig()
+	appConfig.Access = &AppConfigAccess{
+		Allow: []AppConfigAccessRule{
+			{Namespace: "billing", App: "payments", Paths: []string{"/api/*", "*.json"}, Methods: []string{"GET", "POST"}},
+			{App: "BAD"},
+			{Namespace: "apps", Paths: []string{"api"}, Methods: []string{"get"}},
+		},
+	}
+
+	err := appConfig.Validate()
+
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 4)
+	assert.Equal(t, "cannot be blank", validationErrors["access.allow.1.namespace"].Error())
+	assert.Equal(t, "must be lowercase, alphanumeric, and start with a letter", validationErrors["access.allow.1.app"].Error())
+	assert.Equal(t, `path "api" must start with "/" or "*"`, validationErrors["access.allow.2.paths"].Error())
+	assert.Equal(t, `method "get" must be one of: DELETE, GET, HEAD, OPTIONS, PATCH, POST, PUT`, validationErrors["access.allow.2.methods"].Error())
+}
+
+func Test_ApplyOverrides_Access(t *testing.T) {
+	baseAccess := &AppConfigAccess{
+		Allow: []AppConfigAccessRule{
+			{Namespace: "apps"},
+			{Namespace: "billing", App: "payments"},
+		},
+	}
+	prodAccess := &AppConfigAccess{
+		Allow: []AppConfigAccessRule{
+			{Namespace: "billing", App: "payments", Methods: []string{"GET"}},
+		},
+	}
+	appConfig := &AppConfigWithOverrides{
+		AppConfig: AppConfig{
+			Name: "myapp",
+			OverrideableAppConfig: OverrideableAppConfig{
+				Access: baseAccess,
+			},
+		},
+		Overrides: map[string]AppConfigOverride{
+			"prod":    {OverrideableAppConfig: OverrideableAppConfig{Access: prodAccess}},
+			"staging": {},
+		},
+	}
+
+	prod, err := appConfig.ApplyOverrides("prod")
+	require.NoError(t, err)
+	staging, err := appConfig.ApplyOverrides("staging")
+	require.NoError(t, err)
+
+	assert.Equal(t, prodAccess, prod.Access)
+	assert.Equal(t, baseAccess, staging.Access)
+	assert.Len(t, appConfig.Access.Allow, 2)
+}
+
+func Test_ApplyOverrides_KeepsUnspecifiedFields(t *testing.T) {
+	appConfig := &AppConfigWithOverrides{
+		AppConfig: AppConfig{
+			Name: "myapp",
+			OverrideableAppConfig: OverrideableAppConfig{
+				Autoscale:      &AppConfigAutoscale{Min: ptrInt(1)},
+				TimeoutSeconds: ptrInt64(30),
+				Environment:    map[string]intstr.IntOrString{"LOG_LEVEL": intstr.FromString("info")},
+			},
+		},
+		Overrides: map[string]AppConfigOverride{
+			"prod": {OverrideableAppConfig: OverrideableAppConfig{
+				Concurrency: ptrInt64(10),
+			}},
+		},
+	}
+
+	result, err := appConfig.ApplyOverrides("prod")
+
+	require.NoError(t, err)
+	assert.EqualValues(t, 10, *result.Concurrency)
+	assert.Equal(t, 1, *result.Autoscale.Min)
+	assert.EqualValues(t, 30, *result.TimeoutSeconds)
+	assert.Equal(t, "info", result.Environment["LOG_LEVEL"].StrVal)
+	assert.Nil(t, appConfig.Overrides["prod"].Autoscale)
+}
+
+func Test_ApplyEnvironment_AppDefaults(t *testing.T) {
+	appConfig := &AppConfigWithOverrides{
+		AppConfig: AppConfig{
+			Name: "myapp",
+			OverrideableAppConfig: OverrideableAppConfig{
+				Autoscale:   &AppConfigAutoscale{Min: ptrInt(0)},
+				Environment: map[string]intstr.IntOrString{"LOG_LEVEL": intstr.FromString("info")},
+				HealthCheck: &AppConfigHealthCheck{AppConfigProbe: AppConfigProbe{Mode: AppHealthCheckMode_TCP}},
+			},
+		},
+		Overrides: map[string]AppConfigOverride{
+			"prod": {OverrideableAppConfig: OverrideableAppConfig{
+				Environment: map[string]intstr.IntOrString{"TRACING": intstr.FromString("off")},
+			}},
+		},
+	}
+	appDefaults := &OverrideableAppConfig{
+		Autoscale:      &AppConfigAutoscale{Min: ptrInt(2), Max: ptrInt(10)},
+		Environment:    map[string]intstr.IntOrString{"LOG_LEVEL": intstr.FromString("debug"), "TRACING": intstr.FromString("on")},
+		HealthCheck:    &AppConfigHealthCheck{AppConfigProbe: AppConfigProbe{Path: "/health"}},
+		TimeoutSeconds: ptrInt64(30),
+	}
+
+	result, err := appConfig.ApplyEnvironment("prod", appDefaults)
+
+	require.NoError(t, err)
+	// An explicit zero value is not replaced by a default
+	assert.Equal(t, 0, *result.Autoscale.Min)
+	assert.Equal(t, 10, *result.Autoscale.Max)
+	assert.Equal(t, "info", result.Environment["LOG_LEVEL"].StrVal)
+	assert.Equal(t, "off", result.Environment["TRACING"].StrVal)
+	assert.Equal(t, &AppConfigHealthCheck{AppConfigProbe: AppConfigProbe{Mode: AppHealthCheckMode_TCP}}, result.HealthCheck)
+	assert.EqualValues(t, 30, *result.TimeoutSeconds)
+	// Neither the app config nor the defaults are modified
+	assert.Nil(t, appConfig.Autoscale.Max)
+	assert.Len(t, appConfig.Environment, 1)
+	assert.Len(t, appDefaults.Environment, 2)
+}
+
+func Test_ApplyEnvironment_AppDefaults_Worker(t *testing.T) {
+	appConfig := &AppConfigWithOverrides{
+		AppConfig: AppConfig{
+			Name: "myworker",
+			Kind: AppKind_Worker,
+		},
+	}
+	appDefaults := &OverrideableAppConfig{
+		Access:         &AppConfigAccess{Allow: []AppConfigAccessRule{{Namespace: "apps"}}},
+		Autoscale:      &AppConfigAutoscale{Min: ptrInt(2), Max: ptrInt(10), Metric: AppAutoscaleMetric_RPS, StableWindow: "60s"},
+		Concurrency:    ptrInt64(100),
+		Environment:    map[string]intstr.IntOrString{"LOG_LEVEL": intstr.FromString("debug")},
+		HealthCheck:    &AppConfigHealthCheck{AppConfigProbe: AppConfigProbe{Path: "/health"}},
+		TimeoutSeconds: ptrInt64(30),
+	}
+
+	result, err := appConfig.ApplyEnvironment("prod", appDefaults)
+
+	// Only the defaults that apply to a worker are used
+	require.NoError(t, err)
+	assert.Nil(t, result.Access)
+	assert.Equal(t, &AppConfigAutoscale{Min: ptrInt(2), Max: ptrInt(10)}, result.Autoscale)
+	assert.Nil(t, result.Concurrency)
+	assert.Equal(t, "debug", result.Environment["LOG_LEVEL"].StrVal)
+	assert.Nil(t, result.HealthCheck)
+	assert.Nil(t, result.TimeoutSeconds)
+	// The defaults are not modified
+	assert.NotNil(t, appDefaults.Access)
+	assert.Equal(t, AppAutoscaleMetric_RPS, appDefaults.Autoscale.Metric)
+}
+
+func Test_ApplyEnvironment_AppDefaults_ScheduledJob(t *testing.T) {
+	appConfig := &AppConfigWithOverrides{
+		AppConfig: AppConfig{
+			Name: "myreport",
+			Kind: AppKind_ScheduledJob,
+			OverrideableAppConfig: OverrideableAppConfig{
+				Job: &AppConfigJob{Schedule: "@daily"},
+			},
+		},
+	}
+	appDefaults := &OverrideableAppConfig{
+		Autoscale:   &AppConfigAutoscale{Min: ptrInt(2)},
+		HealthCheck: &AppConfigHealthCheck{AppConfigProbe: AppConfigProbe{Mode: AppHealthCheckMode_Exec, Command: []string{"true"}}},
+		Job:         &AppConfigJob{ConcurrencyPolicy: AppJobConcurrencyPolicy_Forbid},
+	}
+
+	result, err := appConfig.ApplyEnvironment("prod", appDefaults)
+
+	require.NoError(t, err)
+	assert.Nil(t, result.Autoscale)
+	assert.Nil(t, result.HealthCheck)
+	assert.Equal(t, &AppConfigJob{Schedule: "@daily", ConcurrencyPolicy: AppJobConcurrencyPolicy_Forbid}, result.Job)
+
+	// The job defaults only apply to a scheduled job
+	appConfig.Kind = AppKind_Worker
+	appConfig.Job = nil
+
+	result, err = appConfig.ApplyEnvironment("prod", appDefaults)
+
+	require.NoError(t, err)
+	assert.Nil(t, result.Job)
+}
+
+func Test_ApplyEnvironment_NoAppDefaults(t *testing.T) {
+	appConfig := &AppConfigWithOverrides{
+		AppConfig: AppConfig{
+			Name: "myapp",
+		},
+	}
+
+	result, err := appConfig.ApplyEnvironment("prod", nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, appConfig.AppConfig, *result)
+}
+
+func Test_ApplyOverrides_CommandAndArgs(t *testing.T) {
+	appConfig := &AppConfigWithOverrides{
+		AppConfig: AppConfig{
+			Name: "myapp",
+			OverrideableAppConfig: OverrideableAppConfig{
+				Command: []string{"/bin/server"},
+				Args:    []string{"--mode=web"},
+			},
+		},
+		Overrides: map[string]AppConfigOverride{
+			"prod": {OverrideableAppConfig: OverrideableAppConfig{
+				Args: []string{"--mode=web", "--workers=4"},
+			}},
+		},
+	}
+
+	result, err := appConfig.ApplyOverrides("prod")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/bin/server"}, result.Command)
+	assert.Equal(t, []string{"--mode=web", "--workers=4"}, result.Args)
+	// Ensure that we don't mutate the original config
+	assert.Equal(t, []string{"--mode=web"}, appConfig.Args)
+}
+
+func Test_AppConfig_ValidateDomains(t *testing.T) {
+	var tests = []struct {
+		name    string
+		domains []string
+		scope   string
+		err     string
+	}{
+		{"valid", []string{"myapp.example.com", "example.com", "a-b.c.example.io"}, AppExposeScope_External, ""},
+		{"single label", []string{"localhost"}, AppExposeScope_External, `domain "localhost" must be a valid lowercase domain name (e.g. myapp.example.com)`},
+		{"wildcard", []string{"*.example.com"}, AppExposeScope_External, `domain "*.example.com" must be a valid lowercase domain name (e.g. myapp.example.com)`},
+		{"uppercase", []string{"MyApp.example.com"}, AppExposeScope_External, `domain "MyApp.example.com" must be a valid lowercase domain name (e.g. myapp.example.com)`},
+		{"duplicate", []string{"myapp.example.com", "myapp.example.com"}, AppExposeScope_External, `domain "myapp.example.com" specified twice`},
+		{"cluster scope", []string{"myapp.example.com"}, AppExposeScope_Cluster, "must only be specified when expose.scope is external"},
+	}
+
+	for _, tt := range tests {
+		appConfig := createMinAppConfig()
+		appConfig.Expose.Scope = tt.scope
+		appConfig.Domains = tt.domains
+		err := appConfig.Validate()
+
+		if tt.err == "" {
+			assert.NoError(t, err, tt.name)
+		} else {
+			require.IsType(t, validation.Errors{}, err, tt.name)
+			validationErrors := err.(validation.Errors)
+			assert.Len(t, validationErrors, 1, tt.name)
+			require.Contains(t, validationErrors, "domains", tt.name)
+			assert.Equal(t, tt.err, validationErrors["domains"].Error(), tt.name)
+		}
+	}
+}
+
+func Test_ApplyOverrides_Domains(t *testing.T) {
+	appConfig := &AppConfigWithOverrides{
+		AppConfig: AppConfig{
+			Name: "myapp",
+			OverrideableAppConfig: OverrideableAppConfig{
+				Domains: []string{"myapp.example.com"},
+			},
+		},
+		Overrides: map[string]AppConfigOverride{
+			"staging": {OverrideableAppConfig: OverrideableAppConfig{
+				Domains: []string{"myapp.staging.example.com"},
+			}},
+		},
+	}
+
+	staging, err := appConfig.ApplyOverrides("staging")
+	require.NoError(t, err)
+	prod, err := appConfig.ApplyOverrides("prod")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"myapp.staging.example.com"}, staging.Domains)
+	assert.Equal(t, []string{"myapp.example.com"}, prod.Domains)
+}
+
+func Test_AppConfig_ValidateEnvironment(t *testing.T) {
+	var tests = []struct {
+		env   string
+		valid bool
+	}{
+		// good
+		{"MYENV", true},
+		{"MY_ENV", true},
+		// bad
+		{"myenv", false},
+		{"MY-ENV", false},
+		{"RISER_ENV", false},
+		{"9MYENV", false},
+	}
+	for _, tt := range tests {
+		appConfig := createMinAppConfig()
+		appConfig.Environment = map[string]intstr.IntOrString{}
+		appConfig.Environment[tt.env] = intstr.FromString("val")
+		err := appConfig.Validate()
+
+		if tt.valid {
+			assert.NoError(t, err, tt.env)
+		} else {
+			errKey := fmt.Sprintf("env.%s", tt.env)
+			require.IsType(t, validation.Errors{}, err, tt.env)
+			validationErrors := err.(validation.Errors)
+			assert.Len(t, validationErrors, 1, tt.env)
+			assert.Contains(t, validationErrors[errKey].Error(), tt.env)
+		}
+	}
+}
+
+func Test_AppConfig_ValidateEnvironment_MultipleErrors(t *testing.T) {
+	appConfig := createMinAppConfig()
+	appConfig.Environment = map[string]intstr.IntOrString{
+		"RISER_ENV": intstr.FromString("val"),
+		"9MYENV":    intstr.FromString("val"),
+	}
+
+	err := appConfig.Validate()
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
 	require.Len(t, validationErrors, 2)
 	assert.Equal(t, `The env var "RISER_ENV" is not valid: Must not start with the reserved word "RISER_"`, validationErrors["env.RISER_ENV"].Error())
 	assert.Equal(t, `The env var "9MYENV" is not valid: Must start with A-Z and only contain A-Z, 0-9, and underscores (_)`, validationErrors["env.9MYENV"].Error())
 }
 
+func Test_AppConfig_ValidateEnvironment_AppReferences(t *testing.T) {
+	var tests = []struct {
+		value string
+		valid bool
+	}{
+		// good
+		{"${app:mydep.myns.url}", true},
+		{"${app:mydep.myns.url}/api,${app:mydep2.myns.url}", true},
+		{"${other}", true},
+		// bad
+		{"${app:mydep.url}", false},
+		{"${app:mydep.myns.host}", false},
+		{"${app:MYDEP.myns.url}", false},
+		{"${app:mydep.myns.url", false},
+	}
+	for _, tt := range tests {
+		appConfig := createMinAppConfig()
+		appConfig.Environment = map[string]intstr.IntOrString{"MYENV": intstr.FromString(tt.value)}
+		err := appConfig.Validate()
+
+		if tt.valid {
+			assert.NoError(t, err, tt.value)
+		} else {
+			require.IsType(t, validation.Errors{}, err, tt.value)
+			validationErrors := err.(validation.Errors)
+			assert.Len(t, validationErrors, 1, tt.value)
+			assert.Equal(t, `The env var "MYENV" is not valid: App references must be in the form ${app:<deployment>.<namespace>.url}`,
+				validationErrors["env.MYENV"].Error(), tt.value)
+		}
+	}
+}
+
 func createMinAppConfig() *AppConfig {
 	appConfig := &AppConfig{}
 	_ = copier.Copy(appConfig, minimumValidAppConfig)
 	return appConfig
 }
+
+func ptrInt(v int) *int {
+	return &v
+}
+
+func ptrInt32(v int32) *int32 {
+	return &v
+}
+
+func ptrInt64(v int64) *int64 {
+	return &v
+}
+
+func ptrFloat32(v float32) *float32 {
+	return &v
+}
@@ -0,0 +1,118 @@
+package model
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// AppConfigV1Alpha1 is the riser.dev/v1alpha1 app config. Do not add fields to this version.
+type AppConfigV1Alpha1 struct {
+	ApiVersion                    string                        `json:"apiVersion"`
+	Id                            uuid.UUID                     `json:"id"`
+	Name                          AppName                       `json:"name"`
+	Namespace                     NamespaceName                 `json:"namespace"`
+	Expose                        *AppConfigExposeV1Alpha1      `json:"expose,omitempty"`
+	HealthCheck                   *AppConfigHealthCheckV1Alpha1 `json:"healthcheck,omitempty"`
+	Image                         string                        `json:"image"`
+	OverrideableAppConfigV1Alpha1 `json:",inline"`
+	Overrides                     map[string]OverrideableAppConfigV1Alpha1 `json:"environmentOverrides,omitempty"`
+}
+
+type OverrideableAppConfigV1Alpha1 struct {
+	Autoscale   *AppConfigAutoscaleV1Alpha1   `json:"autoscale,omitempty"`
+	Environment map[string]intstr.IntOrString `json:"env,omitempty"`
+	Resources   *AppConfigResourcesV1Alpha1   `json:"resources,omitempty"`
+}
+
+type AppConfigAutoscaleV1Alpha1 struct {
+	Min *int `json:"min,omitempty"`
+	Max *int `json:"max,omitempty"`
+}
+
+type AppConfigExposeV1Alpha1 struct {
+	ContainerPort int32  `json:"containerPort"`
+	Protocol      string `json:"protocol,omitempty"`
+	Scope         string `json:"scope,omitempty"`
+}
+
+type AppConfigHealthCheckV1Alpha1 struct {
+	Path string `json:"path,omitempty"`
+}
+
+type AppConfigResourcesV1Alpha1 struct {
+	CpuCores *float32 `json:"cpuCores,omitempty"`
+	MemoryMB *int32   `json:"memoryMB,omitempty"`
+}
+
+// ConvertToLatest converts the app config to the latest version. The cpuCores and memoryMB resources are converted to limits.
+func (v1alpha1 *AppConfigV1Alpha1) ConvertToLatest() (*AppConfigWithOverrides, []DeprecatedField) {
+	latest := &AppConfigWithOverrides{
+		AppConfig: AppConfig{
+			ApiVersion: AppConfigApiVersion_Latest,
+			Id:         v1alpha1.Id,
+			Name:       v1alpha1.Name,
+			Namespace:  v1alpha1.Namespace,
+			Image:      v1alpha1.Image,
+		},
+	}
+
+	if v1alpha1.Expose != nil {
+		latest.Expose = &AppConfigExpose{
+			ContainerPort: v1alpha1.Expose.ContainerPort,
+			Protocol:      v1alpha1.Expose.Protocol,
+			Scope:         v1alpha1.Expose.Scope,
+		}
+	}
+
+	if v1alpha1.HealthCheck != nil {
+		latest.HealthCheck = &AppConfigHealthCheck{
+			AppConfigProbe: AppConfigProbe{Path: v1alpha1.HealthCheck.Path},
+		}
+	}
+
+	deprecatedFields := v1alpha1.OverrideableAppConfigV1Alpha1.convertToLatest(&latest.OverrideableAppConfig, "")
+	if v1alpha1.Overrides != nil {
+		latest.Overrides = map[string]AppConfigOverride{}
+		envNames := []string{}
+		for envName := range v1alpha1.Overrides {
+			envNames = append(envNames, envName)
+		}
+		sort.Strings(envNames)
+		for _, envName := range envNames {
+			override := AppConfigOverride{}
+			deprecatedFields = append(deprecatedFields,
+				v1alpha1.Overrides[envName].convertToLatest(&override.OverrideableAppConfig, "environmentOverrides."+envName+".")...)
+			latest.Overrides[envName] = override
+		}
+	}
+
+	return latest, deprecatedFields
+}
+
+func (v1alpha1 OverrideableAppConfigV1Alpha1) convertToLatest(latest *OverrideableAppConfig, prefix string) []DeprecatedField {
+	var deprecatedFields []DeprecatedField
+	latest.Environment = v1alpha1.Environment
+	if v1alpha1.Autoscale != nil {
+		latest.Autoscale = &AppConfigAutoscale{
+			Min: v1alpha1.Autoscale.Min,
+			Max: v1alpha1.Autoscale.Max,
+		}
+	}
+	if v1alpha1.Resources != nil {
+		latest.Resources = &AppConfigResources{
+			Limits: &AppConfigResourceList{
+				CpuCores: v1alpha1.Resources.CpuCores,
+				MemoryMB: v1alpha1.Resources.MemoryMB,
+			},
+		}
+		if v1alpha1.Resources.CpuCores != nil {
+			deprecatedFields = append(deprecatedFields, deprecatedResourceShorthand(prefix, "cpuCores"))
+		}
+		if v1alpha1.Resources.MemoryMB != nil {
+			deprecatedFields = append(deprecatedFields, deprecatedResourceShorthand(prefix, "memoryMB"))
+		}
+	}
+	return deprecatedFields
+}
@@ -0,0 +1,121 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+const (
+	AppConfigApiVersion_V1Alpha1 = "riser.dev/v1alpha1"
+	AppConfigApiVersion_V1Alpha2 = "riser.dev/v1alpha2"
+	// AppConfigApiVersion_Latest is the version of AppConfigWithOverrides. An app config without an apiVersion is treated as the latest version.
+	AppConfigApiVersion_Latest = AppConfigApiVersion_V1Alpha2
+)
+
+// appConfigConversions contains a constructor for each older app config version. Each version must be convertible to the latest version.
+var appConfigConversions = map[string]func() versionedAppConfig{
+	AppConfigApiVersion_V1Alpha1: func() versionedAppConfig { return &AppConfigV1Alpha1{} },
+}
+
+// versionedAppConfig is an older version of the app config
+type versionedAppConfig interface {
+	// ConvertToLatest converts the app config to the latest version and returns any deprecated fields that were used
+	ConvertToLatest() (*AppConfigWithOverrides, []DeprecatedField)
+}
+
+// DeprecatedField describes the use of a field that has been deprecated or replaced in the latest app config version
+type DeprecatedField struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type ValidateAppConfigResponse struct {
+	// DeprecatedFields contains any deprecated app config fields that were used
+	DeprecatedFields []DeprecatedField `json:"deprecatedFields,omitempty"`
+}
+
+type appConfigVersionOnly struct {
+	ApiVersion string `json:"apiVersion"`
+}
+
+// UnmarshalJSON decodes the app config, converting older versions to the latest version
+func (cfg *AppConfigWithOverrides) UnmarshalJSON(data []byte) error {
+	// Prevents infinite recursion when decoding the latest version
+	type appConfigWithOverridesLatest AppConfigWithOverrides
+
+	version := &appConfigVersionOnly{}
+	err := json.Unmarshal(data, version)
+	if err != nil {
+		return err
+	}
+
+	newFn, ok := appConfigConversions[version.ApiVersion]
+	if !ok {
+		// The latest version. Unknown versions are also decoded as the latest version and rejected by Validate.
+		latest := appConfigWithOverridesLatest{}
+		err = json.Unmarshal(data, &latest)
+		if err != nil {
+			return err
+		}
+		*cfg = AppConfigWithOverrides(latest)
+		cfg.deprecatedFields = cfg.latestDeprecatedFields()
+		return nil
+	}
+
+	versioned := newFn()
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// Fields from newer versions would otherwise be silently ignored
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(versioned)
+	if err != nil {
+		return fmt.Errorf("invalid app config for apiVersion %q: %s", version.ApiVersion, err)
+	}
+
+	converted, deprecatedFields := versioned.ConvertToLatest()
+	*cfg = *converted
+	cfg.deprecatedFields = deprecatedFields
+	return nil
+}
+
+// DeprecatedFields returns the deprecated fields that were used when the app config was decoded
+func (cfg *AppConfigWithOverrides) DeprecatedFields() []DeprecatedField {
+	return cfg.deprecatedFields
+}
+
+// latestDeprecatedFields returns the deprecated fields of the latest version that are in use
+func (cfg *AppConfigWithOverrides) latestDeprecatedFields() []DeprecatedField {
+	deprecatedFields := resourcesDeprecatedFields("", cfg.Resources)
+	envNames := []string{}
+	for envName := range cfg.Overrides {
+		envNames = append(envNames, envName)
+	}
+	sort.Strings(envNames)
+	for _, envName := range envNames {
+		deprecatedFields = append(deprecatedFields,
+			resourcesDeprecatedFields(fmt.Sprintf("environmentOverrides.%s.", envName), cfg.Overrides[envName].Resources)...)
+	}
+	return deprecatedFields
+}
+
+func resourcesDeprecatedFields(prefix string, resources *AppConfigResources) []DeprecatedField {
+	var deprecatedFields []DeprecatedField
+	if resources == nil {
+		return nil
+	}
+	if resources.CpuCores != nil {
+		deprecatedFields = append(deprecatedFields, deprecatedResourceShorthand(prefix, "cpuCores"))
+	}
+	if resources.MemoryMB != nil {
+		deprecatedFields = append(deprecatedFields, deprecatedResourceShorthand(prefix, "memoryMB"))
+	}
+	return deprecatedFields
+}
+
+func deprecatedResourceShorthand(prefix, name string) DeprecatedField {
+	return DeprecatedField{
+		Field:   fmt.Sprintf("%sresources.%s", prefix, name),
+		Message: fmt.Sprintf("use %sresources.limits.%s instead", prefix, name),
+	}
+}
@@ -0,0 +1,118 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func Test_AppConfigWithOverrides_UnmarshalJSON_Latest(t *testing.T) {
+	data := `{"name":"myapp","image":"myimage","resources":{"limits":{"cpuCores":1}},"environmentOverrides":{"prod":{"autoscale":{"min":2}}}}`
+	cfg := &AppConfigWithOverrides{}
+
+	err := json.Unmarshal([]byte(data), cfg)
+
+	require.NoError(t, err)
+	assert.Empty(t, cfg.ApiVersion)
+	assert.EqualValues(t, "myapp", cfg.Name)
+	assert.Equal(t, float32(1), *cfg.Resources.Limits.CpuCores)
+	assert.Equal(t, 2, *cfg.Overrides["prod"].Autoscale.Min)
+	assert.Empty(t, cfg.DeprecatedFields())
+}
+
+func Test_AppConfigWithOverrides_UnmarshalJSON_Latest_DeprecatedFields(t *testing.T) {
+	data := `{
+		"apiVersion": "riser.dev/v1alpha2",
+		"resources": {"cpuCores": 1, "memoryMB": 128},
+		"environmentOverrides": {
+			"prod": {"resources": {"memoryMB": 256}},
+			"dev": {"resources": {"cpuCores": 0.5}}
+		}
+	}`
+	cfg := &AppConfigWithOverrides{}
+
+	err := json.Unmarshal([]byte(data), cfg)
+
+	require.NoError(t, err)
+	assert.Equal(t, AppConfigApiVersion_V1Alpha2, cfg.ApiVersion)
+	assert.Equal(t, []DeprecatedField{
+		{Field: "resources.cpuCores", Message: "use resources.limits.cpuCores instead"},
+		{Field: "resources.memoryMB", Message: "use resources.limits.memoryMB instead"},
+		{Field: "environmentOverrides.dev.resources.cpuCores", Message: "use environmentOverrides.dev.resources.limits.cpuCores instead"},
+		{Field: "environmentOverrides.prod.resources.memoryMB", Message: "use environmentOverrides.prod.resources.limits.memoryMB instead"},
+	}, cfg.DeprecatedFields())
+}
+
+func Test_AppConfigWithOverrides_UnmarshalJSON_V1Alpha1(t *testing.T) {
+	data := `{
+		"apiVersion": "riser.dev/v1alpha1",
+		"id": "e3aa2e2a-dfb6-4cb1-9e3f-6c1a5a3ae9b4",
+		"name": "myapp",
+		"namespace": "myns",
+		"image": "myimage",
+		"expose": {"containerPort": 8000, "protocol": "http2", "scope": "cluster"},
+		"healthcheck": {"path": "/health"},
+		"autoscale": {"min": 1, "max": 2},
+		"env": {"MYVAR": "myval"},
+		"resources": {"cpuCores": 1, "memoryMB": 128},
+		"environmentOverrides": {
+			"prod": {"resources": {"memoryMB": 256}, "env": {"MYVAR": "prodval"}}
+		}
+	}`
+	cfg := &AppConfigWithOverrides{}
+
+	err := json.Unmarshal([]byte(data), cfg)
+
+	require.NoError(t, err)
+	assert.Equal(t, AppConfigApiVersion_Latest, cfg.ApiVersion)
+	assert.EqualValues(t, "myapp", cfg.Name)
+	assert.EqualValues(t, "myns", cfg.Namespace)
+	assert.Equal(t, "myimage", cfg.Image)
+	assert.Equal(t, &AppConfigExpose{ContainerPort: 8000, Protocol: "http2", Scope: AppExposeScope_Cluster}, cfg.Expose)
+	assert.Equal(t, "/health", cfg.HealthCheck.Path)
+	assert.Equal(t, 1, *cfg.Autoscale.Min)
+	assert.Equal(t, 2, *cfg.Autoscale.Max)
+	assert.Equal(t, intstr.FromString("myval"), cfg.Environment["MYVAR"])
+	assert.Nil(t, cfg.Resources.CpuCores)
+	assert.Nil(t, cfg.Resources.MemoryMB)
+	assert.Equal(t, float32(1), *cfg.Resources.Limits.CpuCores)
+	assert.Equal(t, int32(128), *cfg.Resources.Limits.MemoryMB)
+	assert.Equal(t, int32(256), *cfg.Overrides["prod"].Resources.Limits.MemoryMB)
+	assert.Nil(t, cfg.Overrides["prod"].Resources.Limits.CpuCores)
+	assert.Equal(t, intstr.FromString("prodval"), cfg.Overrides["prod"].Environment["MYVAR"])
+	assert.Equal(t, []DeprecatedField{
+		{Field: "resources.cpuCores", Message: "use resources.limits.cpuCores instead"},
+		{Field: "resources.memoryMB", Message: "use resources.limits.memoryMB instead"},
+		{Field: "environmentOverrides.prod.resources.memoryMB", Message: "use environmentOverrides.prod.resources.limits.memoryMB instead"},
+	}, cfg.DeprecatedFields())
+	assert.NoError(t, cfg.Validate())
+}
+
+func Test_AppConfigWithOverrides_UnmarshalJSON_V1Alpha1_NewerField(t *testing.T) {
+	data := `{"apiVersion": "riser.dev/v1alpha1", "name": "myapp", "domains": ["myapp.example.com"]}`
+	cfg := &AppConfigWithOverrides{}
+
+	err := json.Unmarshal([]byte(data), cfg)
+
+	assert.EqualError(t, err, `invalid app config for apiVersion "riser.dev/v1alpha1": json: unknown field "domains"`)
+}
+
+func Test_AppConfigWithOverrides_UnmarshalJSON_UnknownVersion(t *testing.T) {
+	data := `{"apiVersion": "riser.dev/v2", "id": "e3aa2e2a-dfb6-4cb1-9e3f-6c1a5a3ae9b4", "name": "myapp", "image": "myimage", "expose": {"containerPort": 8000}}`
+	cfg := &AppConfigWithOverrides{}
+
+	err := json.Unmarshal([]byte(data), cfg)
+	require.NoError(t, err)
+	require.NoError(t, cfg.ApplyDefaults())
+
+	err = cfg.Validate()
+
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 1)
+	assert.EqualError(t, validationErrors["apiVersion"], "must be riser.dev/v1alpha2")
+}
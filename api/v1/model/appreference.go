@@ -0,0 +1,53 @@
+package model
+
+import (
+	"regexp"
+)
+
+const (
+	AppReferenceProperty_URL = "url"
+)
+
+var (
+	// Matches a reference to another app's deployment in an env var value (e.g. ${app:mydeployment.mynamespace.url})
+	appReferencePattern = regexp.MustCompile(`\$\{app:([a-z][a-z0-9-]*)\.([a-z][a-z0-9-]*)\.([a-zA-Z]+)\}`)
+	// Matches the start of a reference so that a malformed reference is not silently passed through as a literal value
+	appReferencePrefixPattern = regexp.MustCompile(`\$\{app:`)
+)
+
+// AppReference is a reference to a property of another app's deployment in the same environment
+type AppReference struct {
+	DeploymentName string
+	Namespace      string
+	Property       string
+}
+
+// FindAppReferences returns each reference to another app's deployment in the value
+func FindAppReferences(value string) []AppReference {
+	references := []AppReference{}
+	for _, match := range appReferencePattern.FindAllStringSubmatch(value, -1) {
+		references = append(references, AppReference{DeploymentName: match[1], Namespace: match[2], Property: match[3]})
+	}
+	return references
+}
+
+// ReplaceAppReferences replaces each reference to another app's deployment in the value with the result of resolveFn
+func ReplaceAppReferences(value string, resolveFn func(AppReference) string) string {
+	return appReferencePattern.ReplaceAllStringFunc(value, func(match string) string {
+		submatches := appReferencePattern.FindStringSubmatch(match)
+		return resolveFn(AppReference{DeploymentName: submatches[1], Namespace: submatches[2], Property: submatches[3]})
+	})
+}
+
+// validAppReferences validates that each reference in the value is well formed and references a supported property
+func validAppReferences(value string) bool {
+	if len(appReferencePrefixPattern.FindAllStringIndex(value, -1)) != len(appReferencePattern.FindAllStringIndex(value, -1)) {
+		return false
+	}
+	for _, reference := range FindAppReferences(value) {
+		if reference.Property != AppReferenceProperty_URL {
+			return false
+		}
+	}
+	return true
+}
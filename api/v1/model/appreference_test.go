@@ -0,0 +1,31 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FindAppReferences(t *testing.T) {
+	result := FindAppReferences("${app:payments.billing.url}/api,${app:orders.apps.url}")
+
+	assert.Equal(t, []AppReference{
+		{DeploymentName: "payments", Namespace: "billing", Property: "url"},
+		{DeploymentName: "orders", Namespace: "apps", Property: "url"},
+	}, result)
+}
+
+func Test_FindAppReferences_None(t *testing.T) {
+	result := FindAppReferences("http://payments.billing.svc.cluster.local")
+
+	assert.Empty(t, result)
+}
+
+func Test_ReplaceAppReferences(t *testing.T) {
+	result := ReplaceAppReferences("${app:payments.billing.url}/api", func(reference AppReference) string {
+		return fmt.Sprintf("http://%s.%s", reference.DeploymentName, reference.Namespace)
+	})
+
+	assert.Equal(t, "http://payments.billing/api", result)
+}
@@ -1,6 +1,21 @@
 package model
 
-import validation "github.com/go-ozzo/ozzo-validation/v3"
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+
+	validation "github.com/go-ozzo/ozzo-validation/v3"
+	"github.com/pkg/errors"
+)
+
+const (
+	DryRunFileChange_Added   = "added"
+	DryRunFileChange_Removed = "removed"
+	DryRunFileChange_Changed = "changed"
+)
 
 type DeploymentRequest struct {
 	DeploymentMeta `json:",inline"`
@@ -20,15 +35,34 @@ func (d DeploymentRequest) Validate() error {
 		validation.Field(&d.App, validation.Required))
 }
 
+const maxDeploymentMetadataValueLength = 4096
+
+// Matches the name segment of a Kubernetes annotation
+var deploymentMetadataKeyExp = regexp.MustCompile("^[A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?$")
+
+// reservedDeploymentMetadataKeys are riser.dev annotations set by riser
+var reservedDeploymentMetadataKeys = map[string]bool{
+	"revision":       true,
+	"server-version": true,
+}
+
 type DeploymentResponse struct {
-	RiserRevision int64          `json:"riserRevision"`
-	Message       string         `json:"message"`
-	DryRunCommits []DryRunCommit `json:"dryRunCommits,omitempty"`
+	RiserRevision int64 `json:"riserRevision"`
+	// RiserRevisions contains the riser revision for each environment when deploying to multiple environments
+	RiserRevisions map[string]int64 `json:"riserRevisions,omitempty"`
+	Message        string           `json:"message"`
+	DryRunCommits  []DryRunCommit   `json:"dryRunCommits,omitempty"`
+	// ScheduledJobId is set when the deployment was scheduled to run at a later time
+	ScheduledJobId *uuid.UUID `json:"scheduledJobId,omitempty"`
+	// DeprecatedFields contains any deprecated app config fields that were used
+	DeprecatedFields []DeprecatedField `json:"deprecatedFields,omitempty"`
 }
 
 type DryRunCommit struct {
 	Message string       `json:"message"`
 	Files   []DryRunFile `json:"files"`
+	// Diffs contains a unified diff for each file that would change in the state repo. Unchanged files are omitted.
+	Diffs []DryRunFileDiff `json:"diffs,omitempty"`
 }
 
 type DryRunFile struct {
@@ -36,20 +70,92 @@ type DryRunFile struct {
 	Contents string `json:"contents"`
 }
 
+type DryRunFileDiff struct {
+	Name string `json:"name"`
+	// Change is one of: added, removed, changed
+	Change string `json:"change"`
+	Diff   string `json:"diff"`
+}
+
 type DeploymentMeta struct {
 	Name string `json:"name"`
 	// Namespace is an intentional omission. We always use the app's namespace as we do not allow an app to deploy to multiple namespaces at
 	// this time.
-	Environment   string           `json:"environment"`
+	Environment string `json:"environment,omitempty"`
+	// Environments deploys to multiple environments in a single atomic commit. Mutually exclusive with Environment.
+	Environments  []string         `json:"environments,omitempty"`
 	Docker        DeploymentDocker `json:"docker"`
 	ManualRollout bool             `json:"manualRollout"`
+	// Schedule defers the deployment until the specified time. The deployment is applied immediately when not specified.
+	Schedule *time.Time `json:"schedule,omitempty"`
+	// Metadata contains build and source information for the revision (e.g. git-sha, git-branch, build-url, changelog).
+	// Each entry is rendered as a riser.dev/<key> annotation.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Command and Args override the app's command and args for this deployment (e.g. to run an admin deployment from the same image)
+	Command []string `json:"command,omitempty"`
+	Args    []string `json:"args,omitempty"`
+}
+
+// EnvironmentNames returns the names of all environments targeted by the deployment
+func (d DeploymentMeta) EnvironmentNames() []string {
+	if len(d.Environments) > 0 {
+		return d.Environments
+	}
+	return []string{d.Environment}
 }
 
 func (d DeploymentMeta) Validate() error {
+	environmentRules := []validation.Rule{}
+	environmentsRules := []validation.Rule{validation.By(validUniqueEnvironments)}
+	if len(d.Environments) == 0 {
+		environmentRules = append(environmentRules, validation.Required)
+	} else {
+		environmentRules = append(environmentRules, validation.By(func(value interface{}) error {
+			if envName, _ := value.(string); envName != "" {
+				return errors.New("must not be specified with environments")
+			}
+			return nil
+		}))
+	}
+
 	return validation.ValidateStruct(&d,
 		// There's a separate RuneLength rule here to reserve 8 characters for the deployment prefix (e.g. for myapp: r100-myapp)
 		validation.Field(&d.Name, append(RulesNamingIdentifier(), validation.RuneLength(3, 55), validation.Required)...),
-		validation.Field(&d.Environment, validation.Required))
+		validation.Field(&d.Environment, environmentRules...),
+		validation.Field(&d.Environments, environmentsRules...),
+		validation.Field(&d.Schedule, validation.By(validFutureTime)),
+		validation.Field(&d.Metadata, validation.By(validDeploymentMetadata)))
+}
+
+func validDeploymentMetadata(value interface{}) error {
+	metadata, _ := value.(map[string]string)
+	for key, val := range metadata {
+		if !deploymentMetadataKeyExp.MatchString(key) {
+			return fmt.Errorf("key %q must be 63 characters or less, alphanumeric (\"-\", \"_\", and \".\" are allowed), and begin and end with an alphanumeric character", key)
+		}
+		if reservedDeploymentMetadataKeys[key] {
+			return fmt.Errorf("key %q is reserved", key)
+		}
+		if len(val) > maxDeploymentMetadataValueLength {
+			return fmt.Errorf("value for key %q must be %d bytes or less", key, maxDeploymentMetadataValueLength)
+		}
+	}
+	return nil
+}
+
+func validUniqueEnvironments(value interface{}) error {
+	envNames, _ := value.([]string)
+	seen := map[string]bool{}
+	for _, envName := range envNames {
+		if envName == "" {
+			return errors.New("must not contain an empty environment")
+		}
+		if seen[envName] {
+			return fmt.Errorf("environment %q specified twice", envName)
+		}
+		seen[envName] = true
+	}
+	return nil
 }
 
 type DeploymentDocker struct {
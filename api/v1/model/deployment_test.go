@@ -1,12 +1,16 @@
 package model
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v3"
 	"github.com/jinzhu/copier"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var minimumValidDeploymentRequest = &DeploymentRequest{
@@ -56,6 +60,116 @@ func Test_DeploymentRequest_ValidateRequired(t *testing.T) {
 	assertFieldsRequired(t, validationErrors, "app", "name", "environment")
 }
 
+func Test_DeploymentRequest_ValidateEnvironments(t *testing.T) {
+	model := createMinDeploymentRequest()
+	model.Environment = ""
+	model.Environments = []string{"dev", "prod"}
+
+	err := model.Validate()
+
+	assert.NoError(t, err)
+}
+
+func Test_DeploymentRequest_ValidateEnvironments_Duplicate(t *testing.T) {
+	model := createMinDeploymentRequest()
+	model.Environment = ""
+	model.Environments = []string{"dev", "dev"}
+
+	err := model.Validate()
+
+	assert.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 1)
+	assert.Equal(t, `environment "dev" specified twice`, validationErrors["environments"].Error())
+}
+
+func Test_DeploymentRequest_ValidateEnvironments_Empty(t *testing.T) {
+	model := createMinDeploymentRequest()
+	model.Environment = ""
+	model.Environments = []string{"dev", ""}
+
+	err := model.Validate()
+
+	assert.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 1)
+	assert.Equal(t, "must not contain an empty environment", validationErrors["environments"].Error())
+}
+
+func Test_DeploymentRequest_ValidateEnvironments_WithEnvironment(t *testing.T) {
+	model := createMinDeploymentRequest()
+	model.Environments = []string{"dev"}
+
+	err := model.Validate()
+
+	assert.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 1)
+	assert.Equal(t, "must not be specified with environments", validationErrors["environment"].Error())
+}
+
+func Test_DeploymentRequest_ValidateSchedule(t *testing.T) {
+	model := createMinDeploymentRequest()
+	schedule := time.Now().Add(time.Hour)
+	model.Schedule = &schedule
+
+	err := model.Validate()
+
+	assert.NoError(t, err)
+}
+
+func Test_DeploymentRequest_ValidateSchedule_Past(t *testing.T) {
+	model := createMinDeploymentRequest()
+	schedule := time.Now().Add(-time.Minute)
+	model.Schedule = &schedule
+
+	err := model.Validate()
+
+	assert.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 1)
+	assert.Equal(t, "must be in the future", validationErrors["schedule"].Error())
+}
+
+func Test_DeploymentRequest_ValidateMetadata(t *testing.T) {
+	model := createMinDeploymentRequest()
+	model.Metadata = map[string]string{"git-sha": "9c2e6c5", "build_url": "https://ci", "changelog.md": "- fixed things"}
+
+	err := model.Validate()
+
+	assert.NoError(t, err)
+}
+
+func Test_DeploymentRequest_ValidateMetadata_Invalid(t *testing.T) {
+	tt := []struct {
+		metadata map[string]string
+		expected string
+	}{
+		{map[string]string{"git sha": "a"}, `key "git sha" must be 63 characters or less, alphanumeric ("-", "_", and "." are allowed), and begin and end with an alphanumeric character`},
+		{map[string]string{"-sha": "a"}, `key "-sha" must be 63 characters or less, alphanumeric ("-", "_", and "." are allowed), and begin and end with an alphanumeric character`},
+		{map[string]string{strings.Repeat("a", 64): "a"}, fmt.Sprintf("key %q must be 63 characters or less, alphanumeric (\"-\", \"_\", and \".\" are allowed), and begin and end with an alphanumeric character", strings.Repeat("a", 64))},
+		{map[string]string{"revision": "1"}, `key "revision" is reserved`},
+		{map[string]string{"changelog": strings.Repeat("a", 4097)}, `value for key "changelog" must be 4096 bytes or less`},
+	}
+
+	for _, test := range tt {
+		model := createMinDeploymentRequest()
+		model.Metadata = test.metadata
+
+		err := model.Validate()
+
+		require.IsType(t, validation.Errors{}, err)
+		validationErrors := err.(validation.Errors)
+		assert.Len(t, validationErrors, 1)
+		assert.Equal(t, test.expected, validationErrors["metadata"].Error())
+	}
+}
+
+func Test_DeploymentMeta_EnvironmentNames(t *testing.T) {
+	assert.Equal(t, []string{"dev"}, DeploymentMeta{Environment: "dev"}.EnvironmentNames())
+	assert.Equal(t, []string{"dev", "prod"}, DeploymentMeta{Environments: []string{"dev", "prod"}}.EnvironmentNames())
+}
+
 func Test_DeploymentRequest_ValidateEmptyApp(t *testing.T) {
 	model := &DeploymentRequest{
 		App: &AppConfigWithOverrides{},
@@ -1,5 +1,14 @@
 package model
 
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v3"
+)
+
 type EnvironmentMeta struct {
 	Name string
 }
@@ -7,4 +16,436 @@ type EnvironmentMeta struct {
 type EnvironmentConfig struct {
 	SealedSecretCert  []byte `json:"sealedSecretCert,omitempty"`
 	PublicGatewayHost string `json:"publicGatewayHost,omitempty"`
+	// CertificateIssuer is the name of the cert-manager ClusterIssuer used to issue certificates for custom domains
+	CertificateIssuer string `json:"certificateIssuer,omitempty"`
+	// DefaultResources are applied to apps in the environment that do not specify their own requests or limits
+	DefaultResources *EnvironmentDefaultResources `json:"defaultResources,omitempty"`
+	// DefaultDenyAccess denies calls to apps that do not specify which apps and namespaces may call them. Unlike the fields above, which
+	// are only updated when specified, the value is replaced on each update.
+	DefaultDenyAccess bool `json:"defaultDenyAccess,omitempty"`
+	// KNativeScheduling declares that KNative's kubernetes.podspec-nodeselector, kubernetes.podspec-tolerations,
+	// kubernetes.podspec-affinity, and kubernetes.podspec-topologyspreadconstraints features are enabled. An app served by KNative may
+	// only specify scheduling when declared since KNative rejects the scheduling fields otherwise. The value is replaced on each update.
+	KNativeScheduling bool `json:"knativeScheduling,omitempty"`
+	// AppDefaults are merged with each app config deployed to the environment. Any value specified by the app config, including its
+	// environment overrides, takes precedence over a default. The defaults are replaced as a whole on each update.
+	AppDefaults *OverrideableAppConfig `json:"appDefaults,omitempty"`
+	// AppConstraints are enforced on each app config deployed to the environment after defaults and overrides are applied. The
+	// constraints are replaced as a whole on each update.
+	AppConstraints *EnvironmentAppConstraints `json:"appConstraints,omitempty"`
+	// AdmissionPolicies are evaluated against each deployment to the environment. A deployment is rejected if any policy is violated.
+	// The policies are replaced as a whole on each update.
+	AdmissionPolicies []EnvironmentAdmissionPolicy `json:"admissionPolicies,omitempty"`
+	// SecurityBaseline hardens each app deployed to the environment. The baseline is applied to the security settings that an app does not
+	// specify, and a deployment is rejected if the app weakens the baseline. The baseline is replaced as a whole on each update.
+	SecurityBaseline *EnvironmentSecurityBaseline `json:"securityBaseline,omitempty"`
+}
+
+type EnvironmentSecurityBaseline struct {
+	// RunAsNonRoot requires each app to run as a user other than root
+	RunAsNonRoot bool `json:"runAsNonRoot,omitempty"`
+	// ReadOnlyRootFilesystem requires each app to use a read-only root filesystem
+	ReadOnlyRootFilesystem bool `json:"readOnlyRootFilesystem,omitempty"`
+	// DenyPrivilegeEscalation requires each app to disallow privilege escalation
+	DenyPrivilegeEscalation bool `json:"denyPrivilegeEscalation,omitempty"`
+	// DropCapabilities are the capabilities that each app must drop (e.g. ALL)
+	DropCapabilities []string `json:"dropCapabilities,omitempty"`
+	// AllowedCapabilities are the only capabilities that an app may add. An app may not add any capabilities when not specified.
+	AllowedCapabilities []string `json:"allowedCapabilities,omitempty"`
+}
+
+type EnvironmentAdmissionPolicy struct {
+	Name string `json:"name"`
+	// Expression must evaluate to true for a deployment to be admitted (e.g. "image =~ '^registry.example.com/'")
+	Expression string `json:"expression"`
+	// Message is returned when a deployment violates the policy. Defaults to the expression when not specified.
+	Message string `json:"message,omitempty"`
+}
+
+type EnvironmentDefaultResources struct {
+	Requests *AppConfigResourceList `json:"requests,omitempty"`
+	Limits   *AppConfigResourceList `json:"limits,omitempty"`
+}
+
+type EnvironmentAppConstraints struct {
+	Autoscale *EnvironmentAutoscaleConstraints `json:"autoscale,omitempty"`
+	Resources *EnvironmentResourceConstraints  `json:"resources,omitempty"`
+	// Env requires env vars to have a specific value (e.g. LOG_LEVEL=info)
+	Env map[string]string `json:"env,omitempty"`
+	// Scheduling restricts the node selectors and tolerations that an app may use
+	Scheduling *EnvironmentSchedulingConstraints `json:"scheduling,omitempty"`
+}
+
+type EnvironmentAutoscaleConstraints struct {
+	// MinAtLeast requires autoscale.min to be at least the value
+	MinAtLeast *int `json:"minAtLeast,omitempty"`
+	// MaxAtMost requires autoscale.max to be specified and be at most the value
+	MaxAtMost *int `json:"maxAtMost,omitempty"`
+}
+
+// EnvironmentSchedulingConstraints only allows the node selectors and tolerations that are listed. An app may not use any node selector or
+// toleration when the list is not specified.
+type EnvironmentSchedulingConstraints struct {
+	// AllowedNodeSelectors maps each node label that an app may select to the label's allowed values. Any value is allowed when no values
+	// are specified. scheduling.affinity.zones selects the topology.kubernetes.io/zone label.
+	AllowedNodeSelectors map[string][]string `json:"allowedNodeSelectors,omitempty"`
+	// AllowedTolerations are the taint keys that an app may tolerate
+	AllowedTolerations []string `json:"allowedTolerations,omitempty"`
+}
+
+type EnvironmentResourceConstraints struct {
+	// MaxLimits caps each resource limit. An app must have a limit for each capped resource, either its own or the environment default.
+	MaxLimits *AppConfigResourceList `json:"maxLimits,omitempty"`
+}
+
+func (cfg EnvironmentConfig) Validate() error {
+	var validationErrors error
+	if cfg.DefaultResources != nil {
+		validationErrors = mergeValidationErrors(validationErrors,
+			validResourceRequirements(cfg.DefaultResources.Requests, cfg.DefaultResources.Limits), "defaultResources")
+	}
+
+	if cfg.AppDefaults != nil && len(cfg.AppDefaults.Domains) > 0 {
+		// A domain may only be reserved by a single app
+		validationErrors = mergeValidationErrors(validationErrors,
+			validation.Errors{"domains": errors.New("must not be specified")}, "appDefaults")
+	}
+
+	if cfg.AppConstraints != nil {
+		validationErrors = mergeValidationErrors(validationErrors, cfg.AppConstraints.validate(), "appConstraints")
+	}
+
+	if cfg.SecurityBaseline != nil {
+		capabilityRule := validation.Match(capabilityPattern).Error("must be an uppercase capability name (e.g. NET_BIND_SERVICE)")
+		baselineErr := validation.ValidateStruct(cfg.SecurityBaseline,
+			validation.Field(&cfg.SecurityBaseline.DropCapabilities, validation.Each(capabilityRule)),
+			validation.Field(&cfg.SecurityBaseline.AllowedCapabilities, validation.Each(capabilityRule)),
+		)
+		validationErrors = mergeValidationErrors(validationErrors, baselineErr, "securityBaseline")
+	}
+
+	policyNames := map[string]bool{}
+	for idx, policy := range cfg.AdmissionPolicies {
+		policyErr := validation.ValidateStruct(&policy,
+			validation.Field(&policy.Name, validation.Required),
+			validation.Field(&policy.Expression, validation.Required),
+		)
+		if policyNames[policy.Name] {
+			policyErr = mergeValidationErrors(policyErr,
+				validation.Errors{"name": fmt.Errorf("policy %q specified twice", policy.Name)}, "")
+		}
+		policyNames[policy.Name] = true
+		validationErrors = mergeValidationErrors(validationErrors, policyErr, fmt.Sprintf("admissionPolicies[%d]", idx))
+	}
+
+	return validationErrors
+}
+
+func (constraints EnvironmentAppConstraints) validate() error {
+	var validationErrors error
+	if constraints.Autoscale != nil {
+		maxMinRule := validation.Min(1)
+		if constraints.Autoscale.MinAtLeast != nil {
+			maxMinRule = validation.Min(*constraints.Autoscale.MinAtLeast).Error("must be greater than or equal to autoscale.minAtLeast")
+		}
+		autoscaleErr := validation.ValidateStruct(constraints.Autoscale,
+			validation.Field(&constraints.Autoscale.MinAtLeast, validation.Min(0)),
+			// We have to customize the NilOrEmpty error to match "Min" since "Min" does not get applied to nillable 0 value
+			validation.Field(&constraints.Autoscale.MaxAtMost, validation.NilOrNotEmpty.Error("must be no less than 1"), maxMinRule),
+		)
+		validationErrors = mergeValidationErrors(validationErrors, autoscaleErr, "autoscale")
+	}
+
+	if constraints.Resources != nil && constraints.Resources.MaxLimits != nil {
+		maxLimits := constraints.Resources.MaxLimits
+		resourcesErrors := validation.Errors{}
+		if maxLimits.CpuCores != nil && *maxLimits.CpuCores <= 0 {
+			resourcesErrors["maxLimits.cpuCores"] = errors.New("must be greater than 0")
+		}
+		if maxLimits.MemoryMB != nil && *maxLimits.MemoryMB <= 0 {
+			resourcesErrors["maxLimits.memoryMB"] = errors.New("must be greater than 0")
+		}
+		validationErrors = mergeValidationErrors(validationErrors, resourcesErrors.Filter(), "resources")
+	}
+
+	if constraints.Scheduling != nil {
+		schedulingErrors := validation.Errors{}
+		for key, values := range constraints.Scheduling.AllowedNodeSelectors {
+			err := validation.Validate(key, validation.By(validLabelKey))
+			if err == nil {
+				err = validation.Validate(values, validation.Each(validation.By(validLabelValue)))
+			}
+			schedulingErrors[fmt.Sprintf("allowedNodeSelectors.%s", key)] = err
+		}
+		schedulingErrors["allowedTolerations"] = validation.Validate(constraints.Scheduling.AllowedTolerations,
+			validation.Each(validation.Required, validation.By(validLabelKey)))
+		validationErrors = mergeValidationErrors(validationErrors, schedulingErrors.Filter(), "scheduling")
+	}
+
+	envErrors := validation.Errors{}
+	for key := range constraints.Env {
+		envErrors[key] = validation.Validate(key,
+			validation.Match(envVarKeyPattern).Error("must start with A-Z and only contain A-Z, 0-9, and underscores (_)"))
+	}
+	return mergeValidationErrors(validationErrors, envErrors.Filter(), "env")
+}
+
+// ValidateApp validates that the app config meets the constraints. The app config must have the environment's defaults and overrides
+// applied. defaultLimits are the environment's default resource limits, which apply when the app does not specify its own.
+func (constraints EnvironmentAppConstraints) ValidateApp(app *AppConfig, defaultLimits *AppConfigResourceList) error {
+	validationErrors := validation.Errors{}
+
+	// A scheduled job runs to completion so it is not scaled
+	if constraints.Autoscale != nil && app.KindOrDefault() != AppKind_ScheduledJob {
+		autoscale := AppConfigAutoscale{}
+		if app.Autoscale != nil {
+			autoscale = *app.Autoscale
+		}
+		// The tcp protocol is not autoscaled. It always runs autoscale.min replicas (default 1). A worker runs at least 1 replica and is only
+		// autoscaled when autoscale.max is specified.
+		isTCP := app.Expose != nil && app.Expose.Protocol == AppExposeProtocol_TCP
+		isWorker := app.KindOrDefault() == AppKind_Worker
+		fixedReplicas := isTCP || (isWorker && autoscale.Max == nil)
+		min := 0
+		if isTCP || isWorker {
+			min = 1
+		}
+		if autoscale.Min != nil {
+			min = *autoscale.Min
+		}
+
+		if constraints.Autoscale.MinAtLeast != nil && min < *constraints.Autoscale.MinAtLeast {
+			validationErrors["autoscale.min"] = fmt.Errorf("must be no less than %d", *constraints.Autoscale.MinAtLeast)
+		}
+		if constraints.Autoscale.MaxAtMost != nil {
+			if fixedReplicas {
+				if min > *constraints.Autoscale.MaxAtMost {
+					validationErrors["autoscale.min"] = fmt.Errorf("must be no greater than %d", *constraints.Autoscale.MaxAtMost)
+				}
+			} else if autoscale.Max == nil {
+				validationErrors["autoscale.max"] = fmt.Errorf("is required and must be no greater than %d", *constraints.Autoscale.MaxAtMost)
+			} else if *autoscale.Max > *constraints.Autoscale.MaxAtMost {
+				validationErrors["autoscale.max"] = fmt.Errorf("must be no greater than %d", *constraints.Autoscale.MaxAtMost)
+			}
+		}
+	}
+
+	if constraints.Resources != nil && constraints.Resources.MaxLimits != nil {
+		limits := effectiveResourceLimits(app.Resources, defaultLimits)
+		maxLimits := constraints.Resources.MaxLimits
+		if maxLimits.CpuCores != nil {
+			if limits.CpuCores == nil {
+				validationErrors["resources.limits.cpuCores"] = fmt.Errorf("is required and must be no greater than %v", *maxLimits.CpuCores)
+			} else if *limits.CpuCores > *maxLimits.CpuCores {
+				validationErrors["resources.limits.cpuCores"] = fmt.Errorf("must be no greater than %v", *maxLimits.CpuCores)
+			}
+		}
+		if maxLimits.MemoryMB != nil {
+			if limits.MemoryMB == nil {
+				validationErrors["resources.limits.memoryMB"] = fmt.Errorf("is required and must be no greater than %d", *maxLimits.MemoryMB)
+			} else if *limits.MemoryMB > *maxLimits.MemoryMB {
+				validationErrors["resources.limits.memoryMB"] = fmt.Errorf("must be no greater than %d", *maxLimits.MemoryMB)
+			}
+		}
+	}
+
+	if constraints.Scheduling != nil && app.Scheduling != nil {
+		allowedNodeSelectors := constraints.Scheduling.AllowedNodeSelectors
+		for key, value := range app.Scheduling.NodeSelector {
+			if err := allowedLabelValue(allowedNodeSelectors, key, value); err != nil {
+				validationErrors[fmt.Sprintf("scheduling.nodeSelector.%s", key)] = err
+			}
+		}
+		if app.Scheduling.Affinity != nil {
+			for _, zone := range app.Scheduling.Affinity.Zones {
+				if err := allowedLabelValue(allowedNodeSelectors, AppSchedulingZoneLabel, zone); err != nil {
+					validationErrors["scheduling.affinity.zones"] = err
+					break
+				}
+			}
+		}
+		allowedTolerations := map[string]bool{}
+		for _, key := range constraints.Scheduling.AllowedTolerations {
+			allowedTolerations[key] = true
+		}
+		for idx, toleration := range app.Scheduling.Tolerations {
+			if !allowedTolerations[toleration.Key] {
+				validationErrors[fmt.Sprintf("scheduling.tolerations.%d.key", idx)] = fmt.Errorf("%q is not allowed in this environment", toleration.Key)
+			}
+		}
+	}
+
+	envKeys := []string{}
+	for key := range constraints.Env {
+		envKeys = append(envKeys, key)
+	}
+	sort.Strings(envKeys)
+	for _, key := range envKeys {
+		value, ok := app.Environment[key]
+		if !ok || value.String() != constraints.Env[key] {
+			validationErrors[fmt.Sprintf("env.%s", key)] = fmt.Errorf("must be %q", constraints.Env[key])
+		}
+	}
+
+	return validationErrors.Filter()
+}
+
+// ApplyDefaults applies the baseline to the security settings that the app does not specify
+func (baseline EnvironmentSecurityBaseline) ApplyDefaults(app *AppConfig) {
+	if !baseline.RunAsNonRoot && !baseline.ReadOnlyRootFilesystem && !baseline.DenyPrivilegeEscalation && len(baseline.DropCapabilities) == 0 {
+		return
+	}
+	if app.Security == nil {
+		app.Security = &AppConfigSecurity{}
+	}
+	security := app.Security
+
+	if baseline.RunAsNonRoot && security.RunAsNonRoot == nil {
+		runAsNonRoot := true
+		security.RunAsNonRoot = &runAsNonRoot
+	}
+	if baseline.ReadOnlyRootFilesystem && security.ReadOnlyRootFilesystem == nil {
+		readOnlyRootFilesystem := true
+		security.ReadOnlyRootFilesystem = &readOnlyRootFilesystem
+	}
+	if baseline.DenyPrivilegeEscalation && security.AllowPrivilegeEscalation == nil {
+		allowPrivilegeEscalation := false
+		security.AllowPrivilegeEscalation = &allowPrivilegeEscalation
+	}
+	if len(baseline.DropCapabilities) > 0 {
+		if security.Capabilities == nil {
+			security.Capabilities = &AppConfigCapabilities{}
+		}
+		if len(security.Capabilities.Drop) == 0 {
+			security.Capabilities.Drop = append([]string{}, baseline.DropCapabilities...)
+		}
+	}
+}
+
+// ValidateApp validates that the app config does not weaken the baseline. The baseline must already be applied (see ApplyDefaults).
+func (baseline EnvironmentSecurityBaseline) ValidateApp(app *AppConfig) error {
+	validationErrors := validation.Errors{}
+	security := AppConfigSecurity{}
+	if app.Security != nil {
+		security = *app.Security
+	}
+
+	if baseline.RunAsNonRoot {
+		if security.RunAsNonRoot == nil || !*security.RunAsNonRoot {
+			validationErrors["security.runAsNonRoot"] = errors.New("must be true")
+		}
+		if security.RunAsUser != nil && *security.RunAsUser == 0 {
+			validationErrors["security.runAsUser"] = errors.New("must not be 0 (root)")
+		}
+	}
+	if baseline.ReadOnlyRootFilesystem && (security.ReadOnlyRootFilesystem == nil || !*security.ReadOnlyRootFilesystem) {
+		validationErrors["security.readOnlyRootFilesystem"] = errors.New("must be true")
+	}
+	if baseline.DenyPrivilegeEscalation && (security.AllowPrivilegeEscalation == nil || *security.AllowPrivilegeEscalation) {
+		validationErrors["security.allowPrivilegeEscalation"] = errors.New("must be false")
+	}
+
+	capabilities := AppConfigCapabilities{}
+	if security.Capabilities != nil {
+		capabilities = *security.Capabilities
+	}
+	dropped := map[string]bool{}
+	for _, capability := range capabilities.Drop {
+		dropped[capability] = true
+	}
+	missingDrops := []string{}
+	for _, capability := range baseline.DropCapabilities {
+		// Dropping all capabilities includes any capability required by the baseline
+		if !dropped[capability] && !dropped["ALL"] {
+			missingDrops = append(missingDrops, capability)
+		}
+	}
+	if len(missingDrops) > 0 {
+		validationErrors["security.capabilities.drop"] = fmt.Errorf("must include: %s", strings.Join(missingDrops, ", "))
+	}
+
+	allowedAdds := map[string]bool{}
+	for _, capability := range baseline.AllowedCapabilities {
+		allowedAdds[capability] = true
+	}
+	for _, capability := range capabilities.Add {
+		if !allowedAdds[capability] {
+			if len(baseline.AllowedCapabilities) == 0 {
+				validationErrors["security.capabilities.add"] = errors.New("must not be specified")
+			} else {
+				validationErrors["security.capabilities.add"] = fmt.Errorf("must only include: %s", strings.Join(baseline.AllowedCapabilities, ", "))
+			}
+			break
+		}
+	}
+
+	return validationErrors.Filter()
+}
+
+func allowedLabelValue(allowedLabels map[string][]string, key string, value string) error {
+	allowedValues, ok := allowedLabels[key]
+	if !ok {
+		return fmt.Errorf("%q is not allowed in this environment", key)
+	}
+	if len(allowedValues) == 0 {
+		return nil
+	}
+	for _, allowedValue := range allowedValues {
+		if value == allowedValue {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of: %s", strings.Join(allowedValues, ", "))
+}
+
+// effectiveResourceLimits returns the limits that are applied to the app's container
+func effectiveResourceLimits(resources *AppConfigResources, defaultLimits *AppConfigResourceList) AppConfigResourceList {
+	limits := AppConfigResourceList{}
+	if resources != nil {
+		limits = resources.LimitsOrShorthand()
+	}
+	if defaultLimits == nil {
+		return limits
+	}
+
+	if limits.CpuCores == nil {
+		limits.CpuCores = defaultLimits.CpuCores
+	}
+	if limits.MemoryMB == nil {
+		limits.MemoryMB = defaultLimits.MemoryMB
+	}
+	return limits
+}
+
+// ValidateScheduling validates that an app served by KNative only specifies scheduling when the environment declares that KNative's
+// scheduling features are enabled (see EnvironmentConfig.KNativeScheduling). Other apps are rendered as Kubernetes resources which
+// always support scheduling.
+func ValidateScheduling(app *AppConfig, knativeScheduling bool) error {
+	if app.Scheduling == nil || knativeScheduling || app.KindOrDefault() != AppKind_Service ||
+		(app.Expose != nil && app.Expose.Protocol == AppExposeProtocol_TCP) {
+		return nil
+	}
+
+	return validation.Errors{
+		"scheduling": errors.New("is not supported for apps served by KNative in this environment"),
+	}
+}
+
+// ValidateResourceRequests validates that the app does not request more than the environment's default limits. A default limit is never
+// raised to the app's request, so an app that requests more than a default limit must specify its own limit.
+func ValidateResourceRequests(app *AppConfig, defaultLimits *AppConfigResourceList) error {
+	if app.Resources == nil || app.Resources.Requests == nil || defaultLimits == nil {
+		return nil
+	}
+
+	validationErrors := validation.Errors{}
+	requests := app.Resources.Requests
+	limits := app.Resources.LimitsOrShorthand()
+	if limits.CpuCores == nil && requests.CpuCores != nil && defaultLimits.CpuCores != nil && *requests.CpuCores > *defaultLimits.CpuCores {
+		validationErrors["resources.requests.cpuCores"] = fmt.Errorf("must be no greater than the default limit of %v unless a cpuCores limit is specified", *defaultLimits.CpuCores)
+	}
+	if limits.MemoryMB == nil && requests.MemoryMB != nil && defaultLimits.MemoryMB != nil && *requests.MemoryMB > *defaultLimits.MemoryMB {
+		validationErrors["resources.requests.memoryMB"] = fmt.Errorf("must be no greater than the default limit of %d unless a memoryMB limit is specified", *defaultLimits.MemoryMB)
+	}
+	return validationErrors.Filter()
 }
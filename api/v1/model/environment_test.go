@@ -0,0 +1,437 @@
+package model
+
+import (
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func Test_EnvironmentConfig_Validate(t *testing.T) {
+	config := EnvironmentConfig{
+		DefaultResources: &EnvironmentDefaultResources{
+			Requests: &AppConfigResourceList{CpuCores: ptrFloat32(0.1), MemoryMB: ptrInt32(64)},
+			Limits:   &AppConfigResourceList{CpuCores: ptrFloat32(1), MemoryMB: ptrInt32(512)},
+		},
+	}
+
+	assert.NoError(t, config.Validate())
+}
+
+func Test_EnvironmentConfig_Validate_NoDefaultResources(t *testing.T) {
+	config := EnvironmentConfig{PublicGatewayHost: "myhost"}
+
+	assert.NoError(t, config.Validate())
+}
+
+func Test_EnvironmentConfig_Validate_RequestExceedsLimit(t *testing.T) {
+	config := EnvironmentConfig{
+		DefaultResources: &EnvironmentDefaultResources{
+			Requests: &AppConfigResourceList{CpuCores: ptrFloat32(2), MemoryMB: ptrInt32(64)},
+			Limits:   &AppConfigResourceList{CpuCores: ptrFloat32(1), MemoryMB: ptrInt32(512)},
+		},
+	}
+
+	err := config.Validate()
+
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 1)
+	assert.Equal(t, "must be less than or equal to the cpuCores limit", validationErrors["defaultResources.requests.cpuCores"].Error())
+}
+
+func Test_EnvironmentConfig_Validate_AppDefaultsAndConstraints(t *testing.T) {
+	config := EnvironmentConfig{
+		AppDefaults: &OverrideableAppConfig{
+			Domains: []string{"myapp.example.com"},
+		},
+		AppConstraints: &EnvironmentAppConstraints{
+			Autoscale: &EnvironmentAutoscaleConstraints{MinAtLeast: ptrInt(2), MaxAtMost: ptrInt(1)},
+			Resources: &EnvironmentResourceConstraints{MaxLimits: &AppConfigResourceList{MemoryMB: ptrInt32(0)}},
+			Env:       map[string]string{"log-level": "debug"},
+			Scheduling: &EnvironmentSchedulingConstraints{
+				AllowedNodeSelectors: map[string][]string{"node pool": nil},
+				AllowedTolerations:   []string{""},
+			},
+		},
+	}
+
+	err := config.Validate()
+
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 6)
+	assert.Contains(t, validationErrors["appConstraints.scheduling.allowedNodeSelectors.node pool"].Error(), "name part must consist of alphanumeric characters")
+	assert.Equal(t, "0: cannot be blank.", validationErrors["appConstraints.scheduling.allowedTolerations"].Error())
+	assert.Equal(t, "must not be specified", validationErrors["appDefaults.domains"].Error())
+	assert.Equal(t, "must be greater than or equal to autoscale.minAtLeast", validationErrors["appConstraints.autoscale.maxAtMost"].Error())
+	assert.Equal(t, "must be greater than 0", validationErrors["appConstraints.resources.maxLimits.memoryMB"].Error())
+	assert.Equal(t, "must start with A-Z and only contain A-Z, 0-9, and underscores (_)", validationErrors["appConstraints.env.log-level"].Error())
+}
+
+func Test_EnvironmentConfig_Validate_AdmissionPolicies(t *testing.T) {
+	config := EnvironmentConfig{
+		AdmissionPolicies: []EnvironmentAdmissionPolicy{
+			{Name: "registry", Expression: "image =~ '^registry.example.com/'"},
+			{Name: "registry", Expression: "name != 'test'"},
+			{Expression: ""},
+		},
+	}
+
+	err := config.Validate()
+
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 3)
+	assert.Equal(t, `policy "registry" specified twice`, validationErrors["admissionPolicies[1].name"].Error())
+	assert.Equal(t, "cannot be blank", validationErrors["admissionPolicies[2].name"].Error())
+	assert.Equal(t, "cannot be blank", validationErrors["admissionPolicies[2].expression"].Error())
+}
+
+func Test_EnvironmentAppConstraints_ValidateApp(t *testing.T) {
+	constraints := EnvironmentAppConstraints{
+		Autoscale: &EnvironmentAutoscaleConstraints{MinAtLeast: ptrInt(2), MaxAtMost: ptrInt(10)},
+		Resources: &EnvironmentResourceConstraints{MaxLimits: &AppConfigResourceList{CpuCores: ptrFloat32(2), MemoryMB: ptrInt32(1024)}},
+		Env:       map[string]string{"LOG_LEVEL": "info"},
+	}
+
+	var tests = []struct {
+		name   string
+		app    OverrideableAppConfig
+		errors map[string]string
+	}{
+		{"valid", OverrideableAppConfig{
+			Autoscale:   &AppConfigAutoscale{Min: ptrInt(2), Max: ptrInt(10)},
+			Resources:   &AppConfigResources{Limits: &AppConfigResourceList{CpuCores: ptrFloat32(1), MemoryMB: ptrInt32(512)}},
+			Environment: map[string]intstr.IntOrString{"LOG_LEVEL": intstr.FromString("info")},
+		}, nil},
+		{"unspecified", OverrideableAppConfig{}, map[string]string{
+			"autoscale.min":             "must be no less than 2",
+			"autoscale.max":             "is required and must be no greater than 10",
+			"resources.limits.cpuCores": "is required and must be no greater than 2",
+			"env.LOG_LEVEL":             `must be "info"`,
+		}},
+		{"exceeds", OverrideableAppConfig{
+			Autoscale:   &AppConfigAutoscale{Min: ptrInt(1), Max: ptrInt(11)},
+			Resources:   &AppConfigResources{CpuCores: ptrFloat32(4), Requests: &AppConfigResourceList{MemoryMB: ptrInt32(2048)}},
+			Environment: map[string]intstr.IntOrString{"LOG_LEVEL": intstr.FromString("debug")},
+		}, map[string]string{
+			"autoscale.min":             "must be no less than 2",
+			"autoscale.max":             "must be no greater than 10",
+			"resources.limits.cpuCores": "must be no greater than 2",
+			"env.LOG_LEVEL":             `must be "info"`,
+		}},
+	}
+
+	for _, tt := range tests {
+		app := createMinAppConfig()
+		app.OverrideableAppConfig = tt.app
+		// The environment's default memory limit applies when the app does not specify a memory limit
+		err := constraints.ValidateApp(app, &AppConfigResourceList{MemoryMB: ptrInt32(256)})
+
+		if tt.errors == nil {
+			assert.NoError(t, err, tt.name)
+		} else {
+			require.IsType(t, validation.Errors{}, err, tt.name)
+			validationErrors := err.(validation.Errors)
+			assert.Len(t, validationErrors, len(tt.errors), tt.name)
+			for field, expected := range tt.errors {
+				require.Contains(t, validationErrors, field, tt.name)
+				assert.Equal(t, expected, validationErrors[field].Error(), tt.name)
+			}
+		}
+	}
+}
+
+func Test_ValidateResourceRequests(t *testing.T) {
+	defaultLimits := &AppConfigResourceList{CpuCores: ptrFloat32(0.5), MemoryMB: ptrInt32(256)}
+	var tests = []struct {
+		name      string
+		resources *AppConfigResources
+		errors    map[string]string
+	}{
+		{"unspecified", nil, nil},
+		{"within default limits", &AppConfigResources{Requests: &AppConfigResourceList{CpuCores: ptrFloat32(0.5), MemoryMB: ptrInt32(256)}}, nil},
+		{"exceeds default limits", &AppConfigResources{Requests: &AppConfigResourceList{CpuCores: ptrFloat32(1), MemoryMB: ptrInt32(512)}}, map[string]string{
+			"resources.requests.cpuCores": "must be no greater than the default limit of 0.5 unless a cpuCores limit is specified",
+			"resources.requests.memoryMB": "must be no greater than the default limit of 256 unless a memoryMB limit is specified",
+		}},
+		{"own limits", &AppConfigResources{
+			Requests: &AppConfigResourceList{CpuCores: ptrFloat32(1), MemoryMB: ptrInt32(512)},
+			Limits:   &AppConfigResourceList{CpuCores: ptrFloat32(1), MemoryMB: ptrInt32(512)},
+		}, nil},
+	}
+
+	for _, tt := range tests {
+		app := createMinAppConfig()
+		app.Resources = tt.resources
+
+		err := ValidateResourceRequests(app, defaultLimits)
+
+		if tt.errors == nil {
+			assert.NoError(t, err, tt.name)
+		} else {
+			require.IsType(t, validation.Errors{}, err, tt.name)
+			validationErrors := err.(validation.Errors)
+			assert.Len(t, validationErrors, len(tt.errors), tt.name)
+			for field, expected := range tt.errors {
+				require.Contains(t, validationErrors, field, tt.name)
+				assert.Equal(t, expected, validationErrors[field].Error(), tt.name)
+			}
+		}
+	}
+}
+
+func Test_ValidateScheduling(t *testing.T) {
+	scheduling := &AppConfigScheduling{NodeSelector: map[string]string{"node-pool": "highmem"}}
+	var tests = []struct {
+		name              string
+		kind              string
+		protocol          string
+		scheduling        *AppConfigScheduling
+		knativeScheduling bool
+		expectErr         bool
+	}{
+		{"unspecified", "", "", nil, false, false},
+		{"knative", "", "", scheduling, false, true},
+		{"knative scheduling enabled", "", "", scheduling, true, false},
+		{"tcp", "", AppExposeProtocol_TCP, scheduling, false, false},
+		{"worker", AppKind_Worker, "", scheduling, false, false},
+		{"scheduledJob", AppKind_ScheduledJob, "", scheduling, false, false},
+	}
+
+	for _, tt := range tests {
+		app := createMinAppConfig()
+		app.Kind = tt.kind
+		app.Expose.Protocol = tt.protocol
+		app.Scheduling = tt.scheduling
+
+		err := ValidateScheduling(app, tt.knativeScheduling)
+
+		if tt.expectErr {
+			require.IsType(t, validation.Errors{}, err, tt.name)
+			assert.Equal(t, "is not supported for apps served by KNative in this environment", err.(validation.Errors)["scheduling"].Error(), tt.name)
+		} else {
+			assert.NoError(t, err, tt.name)
+		}
+	}
+}
+
+func Test_EnvironmentAppConstraints_ValidateApp_TCP(t *testing.T) {
+	constraints := EnvironmentAppConstraints{
+		Autoscale: &EnvironmentAutoscaleConstraints{MinAtLeast: ptrInt(2), MaxAtMost: ptrInt(3)},
+	}
+	app := createMinAppConfig()
+	app.Expose.Protocol = AppExposeProtocol_TCP
+	app.Autoscale = &AppConfigAutoscale{Min: ptrInt(4)}
+
+	err := constraints.ValidateApp(app, nil)
+
+	// The tcp protocol is not autoscaled so autoscale.min is the number of replicas
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 1)
+	assert.Equal(t, "must be no greater than 3", validationErrors["autoscale.min"].Error())
+}
+
+func Test_EnvironmentAppConstraints_ValidateApp_Worker(t *testing.T) {
+	constraints := EnvironmentAppConstraints{
+		Autoscale: &EnvironmentAutoscaleConstraints{MinAtLeast: ptrInt(2), MaxAtMost: ptrInt(3)},
+	}
+	app := createMinAppConfig()
+	app.Kind = AppKind_Worker
+	app.Expose = nil
+
+	err := constraints.ValidateApp(app, nil)
+
+	// A worker that is not autoscaled runs a single replica by default
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 1)
+	assert.Equal(t, "must be no less than 2", validationErrors["autoscale.min"].Error())
+
+	app.Autoscale = &AppConfigAutoscale{Min: ptrInt(2), Max: ptrInt(3)}
+
+	assert.NoError(t, constraints.ValidateApp(app, nil))
+}
+
+func Test_EnvironmentAppConstraints_ValidateApp_ScheduledJob(t *testing.T) {
+	constraints := EnvironmentAppConstraints{
+		Autoscale: &EnvironmentAutoscaleConstraints{MinAtLeast: ptrInt(2), MaxAtMost: ptrInt(3)},
+	}
+	app := createMinAppConfig()
+	app.Kind = AppKind_ScheduledJob
+	app.Expose = nil
+	app.Job = &AppConfigJob{Schedule: "@daily"}
+
+	assert.NoError(t, constraints.ValidateApp(app, nil))
+}
+
+func Test_EnvironmentAppConstraints_ValidateApp_Scheduling(t *testing.T) {
+	constraints := EnvironmentAppConstraints{
+		Scheduling: &EnvironmentSchedulingConstraints{
+			AllowedNodeSelectors: map[string][]string{
+				"node-pool":            {"highmem", "general"},
+				"example.com/gpu":      nil,
+				AppSchedulingZoneLabel: {"us-east1-b"},
+			},
+			AllowedTolerations: []string{"dedicated"},
+		},
+	}
+	app := createMinAppConfig()
+	app.Scheduling = &AppConfigScheduling{
+		NodeSelector: map[string]string{"node-pool": "highmem", "example.com/gpu": "any"},
+		Tolerations:  []AppConfigToleration{{Key: "dedicated", Value: "highmem"}},
+		Affinity:     &AppConfigAffinity{Zones: []string{"us-east1-b"}, ZoneSpread: AppZoneSpread_Required},
+	}
+
+	assert.NoError(t, constraints.ValidateApp(app, nil))
+
+	app.Scheduling = &AppConfigScheduling{
+		NodeSelector: map[string]string{"node-pool": "lowmem", "disk": "ssd"},
+		Tolerations:  []AppConfigToleration{{Key: "dedicated"}, {Key: "example.com/maintenance", Operator: AppTolerationOperator_Exists}},
+		Affinity:     &AppConfigAffinity{Zones: []string{"us-east1-b", "us-east1-c"}},
+	}
+
+	err := constraints.ValidateApp(app, nil)
+
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 4)
+	assert.Equal(t, "must be one of: highmem, general", validationErrors["scheduling.nodeSelector.node-pool"].Error())
+	assert.Equal(t, `"disk" is not allowed in this environment`, validationErrors["scheduling.nodeSelector.disk"].Error())
+	assert.Equal(t, `"example.com/maintenance" is not allowed in this environment`, validationErrors["scheduling.tolerations.1.key"].Error())
+	assert.Equal(t, "must be one of: us-east1-b", validationErrors["scheduling.affinity.zones"].Error())
+}
+
+func Test_EnvironmentAppConstraints_ValidateApp_SchedulingNotAllowed(t *testing.T) {
+	constraints := EnvironmentAppConstraints{
+		Scheduling: &EnvironmentSchedulingConstraints{},
+	}
+	app := createMinAppConfig()
+	app.Scheduling = &AppConfigScheduling{
+		Tolerations: []AppConfigToleration{{Key: "dedicated"}},
+		Affinity:    &AppConfigAffinity{ZoneSpread: AppZoneSpread_Preferred},
+	}
+
+	err := constraints.ValidateApp(app, nil)
+
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 1)
+	assert.Equal(t, `"dedicated" is not allowed in this environment`, validationErrors["scheduling.tolerations.0.key"].Error())
+}
+
+func Test_EnvironmentConfig_Validate_SecurityBaseline(t *testing.T) {
+	config := EnvironmentConfig{
+		SecurityBaseline: &EnvironmentSecurityBaseline{
+			DropCapabilities:    []string{"ALL"},
+			AllowedCapabilities: []string{"net_bind_service"},
+		},
+	}
+
+	err := config.Validate()
+
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 1)
+	assert.Equal(t, "0: must be an uppercase capability name (e.g. NET_BIND_SERVICE).", validationErrors["securityBaseline.allowedCapabilities"].Error())
+}
+
+func Test_EnvironmentSecurityBaseline_ApplyDefaults(t *testing.T) {
+	baseline := EnvironmentSecurityBaseline{
+		RunAsNonRoot:            true,
+		ReadOnlyRootFilesystem:  true,
+		DenyPrivilegeEscalation: true,
+		DropCapabilities:        []string{"ALL"},
+	}
+	app := createMinAppConfig()
+
+	baseline.ApplyDefaults(app)
+
+	require.NotNil(t, app.Security)
+	assert.True(t, *app.Security.RunAsNonRoot)
+	assert.True(t, *app.Security.ReadOnlyRootFilesystem)
+	assert.False(t, *app.Security.AllowPrivilegeEscalation)
+	assert.Equal(t, []string{"ALL"}, app.Security.Capabilities.Drop)
+	assert.NoError(t, baseline.ValidateApp(app))
+}
+
+// Settings that the app specifies are never replaced by the baseline so that weakening the baseline is rejected by ValidateApp
+func Test_EnvironmentSecurityBaseline_ApplyDefaults_KeepsAppSettings(t *testing.T) {
+	baseline := EnvironmentSecurityBaseline{
+		RunAsNonRoot:     true,
+		DropCapabilities: []string{"ALL"},
+	}
+	runAsNonRoot := false
+	app := createMinAppConfig()
+	app.Security = &AppConfigSecurity{
+		RunAsNonRoot: &runAsNonRoot,
+		RunAsUser:    ptrInt64(1000),
+		Capabilities: &AppConfigCapabilities{Drop: []string{"NET_RAW"}},
+	}
+
+	baseline.ApplyDefaults(app)
+
+	assert.False(t, *app.Security.RunAsNonRoot)
+	assert.EqualValues(t, 1000, *app.Security.RunAsUser)
+	assert.Nil(t, app.Security.ReadOnlyRootFilesystem)
+	assert.Equal(t, []string{"NET_RAW"}, app.Security.Capabilities.Drop)
+}
+
+func Test_EnvironmentSecurityBaseline_ApplyDefaults_EmptyBaseline(t *testing.T) {
+	app := createMinAppConfig()
+
+	EnvironmentSecurityBaseline{}.ApplyDefaults(app)
+
+	assert.Nil(t, app.Security)
+}
+
+func Test_EnvironmentSecurityBaseline_ValidateApp(t *testing.T) {
+	baseline := EnvironmentSecurityBaseline{
+		RunAsNonRoot:            true,
+		ReadOnlyRootFilesystem:  true,
+		DenyPrivilegeEscalation: true,
+		DropCapabilities:        []string{"NET_RAW", "SYS_ADMIN"},
+		AllowedCapabilities:     []string{"NET_BIND_SERVICE"},
+	}
+	disabled := false
+	enabled := true
+	app := createMinAppConfig()
+	app.Security = &AppConfigSecurity{
+		RunAsNonRoot:             &disabled,
+		RunAsUser:                ptrInt64(0),
+		ReadOnlyRootFilesystem:   &disabled,
+		AllowPrivilegeEscalation: &enabled,
+		Capabilities:             &AppConfigCapabilities{Add: []string{"NET_BIND_SERVICE", "SYS_ADMIN"}, Drop: []string{"NET_RAW"}},
+	}
+
+	err := baseline.ValidateApp(app)
+
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 6)
+	assert.Equal(t, "must be true", validationErrors["security.runAsNonRoot"].Error())
+	assert.Equal(t, "must not be 0 (root)", validationErrors["security.runAsUser"].Error())
+	assert.Equal(t, "must be true", validationErrors["security.readOnlyRootFilesystem"].Error())
+	assert.Equal(t, "must be false", validationErrors["security.allowPrivilegeEscalation"].Error())
+	assert.Equal(t, "must include: SYS_ADMIN", validationErrors["security.capabilities.drop"].Error())
+	assert.Equal(t, "must only include: NET_BIND_SERVICE", validationErrors["security.capabilities.add"].Error())
+}
+
+func Test_EnvironmentSecurityBaseline_ValidateApp_DropAll(t *testing.T) {
+	baseline := EnvironmentSecurityBaseline{DropCapabilities: []string{"NET_RAW"}}
+	app := createMinAppConfig()
+	app.Security = &AppConfigSecurity{
+		Capabilities: &AppConfigCapabilities{Add: []string{"NET_BIND_SERVICE"}, Drop: []string{"ALL"}},
+	}
+
+	err := baseline.ValidateApp(app)
+
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 1)
+	assert.Equal(t, "must not be specified", validationErrors["security.capabilities.add"].Error())
+}
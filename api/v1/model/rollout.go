@@ -2,6 +2,7 @@ package model
 
 import (
 	"fmt"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v3"
 	"github.com/pkg/errors"
@@ -9,6 +10,8 @@ import (
 
 type RolloutRequest struct {
 	Traffic []TrafficRule `json:"traffic"`
+	// Schedule defers the rollout until the specified time. The rollout is applied immediately when not specified.
+	Schedule *time.Time `json:"schedule,omitempty"`
 }
 
 type TrafficRule struct {
@@ -34,15 +37,18 @@ func (rolloutRequest *RolloutRequest) Validate() error {
 		}
 	}
 
-	rolloutErr := validation.ValidateStruct(rolloutRequest, validation.Field(&rolloutRequest.Traffic,
-		validation.Required.Error("must specify one or more traffic rules"),
-		validation.By(func(interface{}) error {
-			if percentage != 100 {
-				return errors.New("rule percentages must add up to 100")
-			}
-			return nil
-		}),
-	))
+	rolloutErr := validation.ValidateStruct(rolloutRequest,
+		validation.Field(&rolloutRequest.Traffic,
+			validation.Required.Error("must specify one or more traffic rules"),
+			validation.By(func(interface{}) error {
+				if percentage != 100 {
+					return errors.New("rule percentages must add up to 100")
+				}
+				return nil
+			}),
+		),
+		validation.Field(&rolloutRequest.Schedule, validation.By(validFutureTime)),
+	)
 
 	if rolloutErr != nil {
 		err = mergeValidationErrors(err, rolloutErr, "")
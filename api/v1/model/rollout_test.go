@@ -2,6 +2,7 @@ package model
 
 import (
 	"testing"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v3"
 	"github.com/stretchr/testify/assert"
@@ -27,6 +28,18 @@ func Test_RolloutRequest_Valid(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func Test_RolloutRequest_ValidateSchedule_Past(t *testing.T) {
+	schedule := time.Now().Add(-time.Minute)
+	rolloutRequest := &RolloutRequest{
+		Traffic:  []TrafficRule{{RiserRevision: 1, Percent: 100}},
+		Schedule: &schedule,
+	}
+
+	err := rolloutRequest.Validate()
+
+	assert.Equal(t, "schedule: must be in the future.", err.Error())
+}
+
 func Test_RolloutRequest_ValidateTrafficRequired(t *testing.T) {
 	rolloutRequest := &RolloutRequest{}
 
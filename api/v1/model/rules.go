@@ -1,7 +1,9 @@
 package model
 
 import (
+	"errors"
 	"regexp"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v3"
 )
@@ -9,6 +11,11 @@ import (
 // Ideally these rules would be in pkg/... for reuse with the service layer but this causes a circular dependency.
 // Most validation happens in the API model so this works for now.
 
+// Change with care as we use naming identifiers for DNS names that must conform to RFC 1035
+// Note that depending on the TLD the spec allows for more characters than allowed below. This restriction is
+// designed for maximum portability.
+var namingIdentifierPattern = regexp.MustCompile("^[a-z][a-z0-9-]*[a-z0-9]+$")
+
 func RulesAppName() []validation.Rule {
 	rules := []validation.Rule{
 		validation.Required,
@@ -22,9 +29,14 @@ func RulesAppName() []validation.Rule {
 func RulesNamingIdentifier() []validation.Rule {
 	return []validation.Rule{
 		validation.RuneLength(3, 63),
-		// Change with care as we use naming identifiers for DNS names that must conform to RFC 1035
-		// Note that depending on the TLD the spec allows for more characters than allowed below. This restriction is
-		// designed for maximum portability.
-		validation.Match(regexp.MustCompile("^[a-z][a-z0-9-]*[a-z0-9]+$")).Error("must be lowercase, alphanumeric, and start with a letter"),
+		validation.Match(namingIdentifierPattern).Error("must be lowercase, alphanumeric, and start with a letter"),
+	}
+}
+
+func validFutureTime(value interface{}) error {
+	t, _ := value.(*time.Time)
+	if t != nil && !t.After(time.Now()) {
+		return errors.New("must be in the future")
 	}
+	return nil
 }
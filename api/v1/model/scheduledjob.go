@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ScheduledJobKind_Deployment = "deployment"
+	ScheduledJobKind_Rollout    = "rollout"
+
+	ScheduledJobStatus_Pending   = "pending"
+	ScheduledJobStatus_Running   = "running"
+	ScheduledJobStatus_Succeeded = "succeeded"
+	ScheduledJobStatus_Failed    = "failed"
+	ScheduledJobStatus_Cancelled = "cancelled"
+)
+
+type ScheduledJob struct {
+	Id uuid.UUID `json:"id"`
+	// Kind is one of: deployment, rollout
+	Kind string `json:"kind"`
+	// Status is one of: pending, running, succeeded, failed, cancelled
+	Status       string              `json:"status"`
+	RunAt        time.Time           `json:"runAt"`
+	CreatedAt    time.Time           `json:"createdAt"`
+	Name         string              `json:"name"`
+	Namespace    string              `json:"namespace"`
+	Environments []string            `json:"environments"`
+	Result       *ScheduledJobResult `json:"result,omitempty"`
+}
+
+type ScheduledJobResult struct {
+	StartedAt      time.Time        `json:"startedAt"`
+	CompletedAt    time.Time        `json:"completedAt"`
+	Message        string           `json:"message"`
+	RiserRevisions map[string]int64 `json:"riserRevisions,omitempty"`
+}
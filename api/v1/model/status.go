@@ -1,12 +1,20 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 const (
 	RevisionStatusWaiting   = "Waiting"
 	RevisionStatusReady     = "Ready"
 	RevisionStatusUnhealthy = "Unhealthy"
 	RevisionStatusUnknown   = "Unknown"
+
+	PreDeployJobStatusRunning   = "Running"
+	PreDeployJobStatusSucceeded = "Succeeded"
+	PreDeployJobStatusFailed    = "Failed"
 )
 
 type AppStatus struct {
@@ -15,20 +23,47 @@ type AppStatus struct {
 }
 
 type DeploymentStatus struct {
-	AppId                   uuid.UUID `json:"appId"`
-	DeploymentName          string    `json:"deployment"`
-	Namespace               string    `json:"namespace"`
-	EnvironmentName         string    `json:"environment"`
-	RiserRevision           int64     `json:"riserRevision"`
+	AppId           uuid.UUID `json:"appId"`
+	DeploymentName  string    `json:"deployment"`
+	Namespace       string    `json:"namespace"`
+	EnvironmentName string    `json:"environment"`
+	RiserRevision   int64     `json:"riserRevision"`
+	// Metadata contains the build and source metadata of the current riser revision
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// RevisionHistory contains the most recent riser revisions with the most recent revision first
+	RevisionHistory         []DeploymentRevision `json:"revisionHistory,omitempty"`
 	DeploymentStatusMutable `json:",inline"`
 }
 
+type DeploymentRevision struct {
+	RiserRevision int64             `json:"riserRevision"`
+	CreatedAt     time.Time         `json:"createdAt"`
+	DockerTag     string            `json:"dockerTag"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+}
+
+// DeploymentStatusMutable is the status reported by the controller. The fields are modeled on KNative's Configuration and Route status.
+// A deployment that KNative does not serve (e.g. a worker) is reported as follows:
+//   - Revisions contains a revision named "<deployment>-<riserRevision>" for the riser revision of each of the Deployment's ReplicaSets
+//   - LatestCreatedRevisionName is the revision of the Deployment's current pod template
+//   - LatestReadyRevisionName is the revision of the Deployment's current pod template once its rollout completes
+//   - Traffic is empty for a worker since it does not receive requests
 type DeploymentStatusMutable struct {
 	ObservedRiserRevision     int64                      `json:"observedRiserRevision"`
 	Revisions                 []DeploymentRevisionStatus `json:"revisions,omitempty"`
 	Traffic                   []DeploymentTrafficStatus  `json:"traffic,omitempty"`
 	LatestCreatedRevisionName string                     `json:"latestCreatedRevisionName"`
 	LatestReadyRevisionName   string                     `json:"latestReadyRevisionName"`
+	// PreDeployJob is the status of the pre-deploy job of the latest riser revision. The revision receives traffic once the job succeeds
+	// and never receives traffic if the job fails.
+	PreDeployJob *DeploymentPreDeployJobStatus `json:"preDeployJob,omitempty"`
+}
+
+type DeploymentPreDeployJobStatus struct {
+	RiserRevision int64 `json:"riserRevision"`
+	// Status is one of: Running, Succeeded, Failed
+	Status       string `json:"status"`
+	StatusReason string `json:"statusReason,omitempty"`
 }
 
 type DeploymentTrafficStatus struct {
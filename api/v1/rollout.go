@@ -6,6 +6,7 @@ import (
 
 	"github.com/riser-platform/riser-server/pkg/environment"
 	"github.com/riser-platform/riser-server/pkg/git"
+	"github.com/riser-platform/riser-server/pkg/scheduledjob"
 
 	"github.com/riser-platform/riser-server/pkg/state"
 
@@ -16,7 +17,7 @@ import (
 	"github.com/riser-platform/riser-server/pkg/rollout"
 )
 
-func PutRollout(c echo.Context, rolloutService rollout.Service, environmentService environment.Service, stateRepo git.Repo) error {
+func PutRollout(c echo.Context, rolloutService rollout.Service, environmentService environment.Service, scheduledJobService scheduledjob.Service, stateRepo git.Repo) error {
 	rolloutRequest := &model.RolloutRequest{}
 
 	deploymentName := c.Param("deploymentName")
@@ -39,9 +40,16 @@ func PutRollout(c echo.Context, rolloutService rollout.Service, environmentServi
 		return core.NewValidationError("Invalid rollout request", err)
 	}
 
-	err = rolloutService.UpdateTraffic(core.NewNamespacedName(deploymentName, namespace), envName,
-		mapTrafficRulesToDomain(deploymentName, rolloutRequest.Traffic),
-		state.NewGitCommitter(stateRepo))
+	traffic := mapTrafficRulesToDomain(deploymentName, rolloutRequest.Traffic)
+	if rolloutRequest.Schedule != nil {
+		job, err := scheduledJobService.ScheduleRollout(*rolloutRequest.Schedule, core.NewNamespacedName(deploymentName, namespace), envName, traffic)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusAccepted, mapScheduledJobFromDomain(job))
+	}
+
+	err = rolloutService.UpdateTraffic(core.NewNamespacedName(deploymentName, namespace), envName, traffic, state.NewGitCommitter(stateRepo))
 	if err != nil {
 		if err == git.ErrNoChanges {
 			return c.JSON(http.StatusOK, model.APIResponse{Message: "No changes to rollout"})
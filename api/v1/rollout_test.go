@@ -1,11 +1,17 @@
 package v1
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/riser-platform/riser-server/pkg/scheduledjob"
+	"github.com/stretchr/testify/require"
 
 	"github.com/riser-platform/riser-server/api/v1/model"
 	"github.com/riser-platform/riser-server/pkg/environment"
@@ -24,7 +30,7 @@ func Test_PutRollout_ValidatesEnvironment(t *testing.T) {
 		},
 	}
 
-	err := PutRollout(ctx, nil, service, nil)
+	err := PutRollout(ctx, nil, service, nil, nil)
 
 	assert.Equal(t, "test", err.Error())
 }
@@ -41,11 +47,55 @@ func Test_PutRollout_ValidatesTraffic(t *testing.T) {
 		},
 	}
 
-	err := PutRollout(ctx, nil, service, nil)
+	err := PutRollout(ctx, nil, service, nil, nil)
 
 	assert.Equal(t, "Invalid rollout request: traffic: must specify one or more traffic rules.", err.Error())
 }
 
+func Test_PutRollout_Schedule(t *testing.T) {
+	schedule := time.Now().Add(time.Hour)
+	rollout := model.RolloutRequest{
+		Traffic:  []model.TrafficRule{{RiserRevision: 2, Percent: 100}},
+		Schedule: &schedule,
+	}
+	req := httptest.NewRequest(http.MethodPut, "/", safeMarshal(rollout))
+	req.Header.Add("CONTENT-TYPE", "application/json")
+	ctx, rec := newContextWithRecorder(req)
+	ctx.SetParamNames("envName", "namespace", "deploymentName")
+	ctx.SetParamValues("dev", "myns", "myapp")
+
+	environmentService := &environment.FakeService{
+		ValidateDeployableFn: func(envName string) error {
+			return nil
+		},
+	}
+	scheduledJobService := &scheduledjob.FakeService{
+		ScheduleRolloutFn: func(runAt time.Time, name *core.NamespacedName, envName string, traffic core.TrafficConfig) (*core.ScheduledJob, error) {
+			assert.True(t, schedule.Equal(runAt))
+			assert.Equal(t, "myapp.myns", name.String())
+			assert.Equal(t, "dev", envName)
+			assert.Equal(t, "myapp-2", traffic[0].RevisionName)
+			return &core.ScheduledJob{
+				Id:     uuid.MustParse("2C5B0D4E-3A5E-4B8C-9C1D-6E4F2A1B3C5D"),
+				Kind:   core.ScheduledJobKindRollout,
+				Status: core.ScheduledJobStatusPending,
+				Doc:    core.ScheduledJobDoc{Rollout: &core.ScheduledRollout{Name: *name, EnvironmentName: envName, Traffic: traffic}},
+			}, nil
+		},
+	}
+
+	err := PutRollout(ctx, nil, environmentService, scheduledJobService, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, scheduledJobService.ScheduleRolloutCallCount)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	response := model.ScheduledJob{}
+	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	require.NoError(t, err)
+	assert.Equal(t, "2c5b0d4e-3a5e-4b8c-9c1d-6e4f2a1b3c5d", response.Id.String())
+	assert.Equal(t, []string{"dev"}, response.Environments)
+}
+
 func Test_mapTrafficRulesToDomain(t *testing.T) {
 	in := []model.TrafficRule{
 		{
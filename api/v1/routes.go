@@ -1,122 +1,102 @@
 package v1
 
 import (
-	"database/sql"
-
-	"github.com/riser-platform/riser-server/pkg/deploymentreservation"
-	"github.com/riser-platform/riser-server/pkg/environment"
-
-	"github.com/riser-platform/riser-server/pkg/namespace"
-
-	"github.com/riser-platform/riser-server/pkg/rollout"
-
 	"github.com/labstack/echo/v4/middleware"
 
-	"github.com/riser-platform/riser-server/pkg/app"
-	"github.com/riser-platform/riser-server/pkg/deployment"
-	"github.com/riser-platform/riser-server/pkg/deploymentstatus"
 	"github.com/riser-platform/riser-server/pkg/git"
-	"github.com/riser-platform/riser-server/pkg/login"
-	"github.com/riser-platform/riser-server/pkg/postgres"
-	"github.com/riser-platform/riser-server/pkg/secret"
 
 	"github.com/labstack/echo/v4"
 )
 
-func RegisterRoutes(e *echo.Echo, repo git.Repo, db *sql.DB) {
+func RegisterRoutes(e *echo.Echo, repo git.Repo, s *Services) {
 	v1 := e.Group("/api/v1")
 
-	// TODO: Refactor dependency management
-	environmentRepository := postgres.NewEnvironmentRepository(db)
-	environmentService := environment.NewService(environmentRepository)
-	namespaceRepository := postgres.NewNamespaceRepository(db)
-	namespaceService := namespace.NewService(namespaceRepository, environmentRepository)
-	deploymentReservationRepository := postgres.NewDeploymentReservationRepository(db)
-	appRepository := postgres.NewAppRepository(db)
-	appService := app.NewService(appRepository, namespaceService)
-	secretMetaRepository := postgres.NewSecretMetaRepository(db)
-	secretService := secret.NewService(secretMetaRepository, environmentRepository)
-	deploymentReservationService := deploymentreservation.NewService(deploymentReservationRepository)
-	deploymentRepository := postgres.NewDeploymentRepository(db)
-	deploymentService := deployment.NewService(appRepository, namespaceService, secretMetaRepository, environmentRepository, deploymentRepository, deploymentReservationService)
-	deploymentStatusService := deploymentstatus.NewService(deploymentRepository, environmentService)
-	rolloutService := rollout.NewService(appRepository, deploymentRepository)
-	userRepository := postgres.NewUserRepository(db)
-	apiKeyRepository := postgres.NewApiKeyRepository(db)
-	loginService := login.NewService(userRepository, apiKeyRepository)
-
 	e.Use(middleware.KeyAuthWithConfig(middleware.KeyAuthConfig{
 		// We will probably use the "Bearer" scheme for OIDC
 		AuthScheme: "Apikey",
 		Validator: func(apikey string, c echo.Context) (bool, error) {
-			return loginWithApiKey(c, loginService, apikey)
+			return loginWithApiKey(c, s.LoginService, apikey)
 		},
 	}))
 
 	v1.GET("/apps", func(c echo.Context) error {
-		return ListApps(c, appRepository)
+		return ListApps(c, s.AppRepository)
 	})
 
 	v1.GET("/apps/:namespace/:appName", func(c echo.Context) error {
-		return GetApp(c, appRepository)
+		return GetApp(c, s.AppRepository)
 	})
 
 	v1.GET("/apps/:namespace/:appName/status", func(c echo.Context) error {
-		return GetAppStatus(c, appService, deploymentStatusService)
+		return GetAppStatus(c, s.AppService, s.DeploymentStatusService)
 	})
 
 	v1.POST("/apps", func(c echo.Context) error {
-		return PostApp(c, appService)
+		return PostApp(c, s.AppService)
 	})
 
 	v1.POST("/deployments", func(c echo.Context) error {
-		return PostDeployment(c, repo, appService, deploymentService, environmentService)
+		return PostDeployment(c, repo, s.AppService, s.DeploymentService, s.EnvironmentService, s.ScheduledJobService)
 	})
 	v1.PUT("/deployments", func(c echo.Context) error {
-		return PostDeployment(c, repo, appService, deploymentService, environmentService)
+		return PostDeployment(c, repo, s.AppService, s.DeploymentService, s.EnvironmentService, s.ScheduledJobService)
 	})
 
 	v1.DELETE("/deployments/:envName/:namespace/:deploymentName", func(c echo.Context) error {
-		return DeleteDeployment(c, repo, deploymentService)
+		return DeleteDeployment(c, repo, s.DeploymentService)
 	})
 
 	v1.PUT("/deployments/:envName/:namespace/:deploymentName/status", func(c echo.Context) error {
-		return PutDeploymentStatus(c, deploymentRepository)
+		return PutDeploymentStatus(c, s.DeploymentRepository, s.RolloutService, repo)
 	})
 
 	v1.PUT("/rollout/:envName/:namespace/:deploymentName", func(c echo.Context) error {
-		return PutRollout(c, rolloutService, environmentService, repo)
+		return PutRollout(c, s.RolloutService, s.EnvironmentService, s.ScheduledJobService, repo)
+	})
+
+	v1.GET("/scheduledjobs", func(c echo.Context) error {
+		return ListScheduledJobs(c, s.ScheduledJobRepository)
+	})
+
+	v1.GET("/scheduledjobs/:id", func(c echo.Context) error {
+		return GetScheduledJob(c, s.ScheduledJobRepository)
+	})
+
+	v1.DELETE("/scheduledjobs/:id", func(c echo.Context) error {
+		return DeleteScheduledJob(c, s.ScheduledJobService)
 	})
 
 	v1.PUT("/secrets", func(c echo.Context) error {
-		return PutSecret(c, repo, secretService, environmentService)
+		return PutSecret(c, repo, s.SecretService, s.EnvironmentService)
 	})
 
 	v1.GET("/secrets/:envName/:namespace/:appName", func(c echo.Context) error {
-		return GetSecrets(c, secretMetaRepository, environmentService)
+		return GetSecrets(c, s.SecretMetaRepository, s.EnvironmentService)
 	})
 
 	v1.GET("/namespaces", func(c echo.Context) error {
-		return GetNamespaces(c, namespaceRepository)
+		return GetNamespaces(c, s.NamespaceRepository)
 	})
 
 	v1.POST("/namespaces", func(c echo.Context) error {
-		return PostNamespace(c, namespaceService, repo)
+		return PostNamespace(c, s.NamespaceService, repo)
 	})
 
 	v1.PUT("/environments/:envName/config", func(c echo.Context) error {
-		return PutEnvironmentConfig(c, environmentService)
+		return PutEnvironmentConfig(c, s.EnvironmentService)
 	})
 
 	v1.POST("/environments/:envName/ping", func(c echo.Context) error {
-		return PostEnvironmentPing(c, environmentService)
+		return PostEnvironmentPing(c, s.EnvironmentService)
 	})
 
 	v1.GET("/environments", func(c echo.Context) error {
-		return ListEnvironments(c, environmentRepository)
+		return ListEnvironments(c, s.EnvironmentRepository)
 	})
 
 	v1.POST("/validate/appconfig", func(c echo.Context) error {
-		return PostValidateAppConfig(c, appService, environmentService)
+		return PostValidateAppConfig(c, s.AppService, s.EnvironmentService)
 	})
+
+	v1.GET("/schemas/appconfig", GetAppConfigSchema)
 }
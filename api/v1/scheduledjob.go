@@ -0,0 +1,104 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/riser-platform/riser-server/api/v1/model"
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/riser-platform/riser-server/pkg/scheduledjob"
+)
+
+func ListScheduledJobs(c echo.Context, jobs core.ScheduledJobRepository) error {
+	domainJobs, err := jobs.List(c.QueryParam("status"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, mapScheduledJobArrayFromDomain(domainJobs))
+}
+
+func GetScheduledJob(c echo.Context, jobs core.ScheduledJobRepository) error {
+	id, err := parseScheduledJobId(c)
+	if err != nil {
+		return err
+	}
+
+	job, err := jobs.Get(id)
+	if err != nil {
+		if err == core.ErrNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "Scheduled job not found")
+		}
+		return err
+	}
+
+	return c.JSON(http.StatusOK, mapScheduledJobFromDomain(job))
+}
+
+func DeleteScheduledJob(c echo.Context, scheduledJobService scheduledjob.Service) error {
+	id, err := parseScheduledJobId(c)
+	if err != nil {
+		return err
+	}
+
+	err = scheduledJobService.Cancel(id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, model.APIResponse{Message: "Scheduled job cancelled"})
+}
+
+func parseScheduledJobId(c echo.Context) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.Nil, core.NewValidationError("Invalid scheduled job id", err)
+	}
+	return id, nil
+}
+
+func mapScheduledJobFromDomain(domain *core.ScheduledJob) model.ScheduledJob {
+	out := model.ScheduledJob{
+		Id:           domain.Id,
+		Kind:         domain.Kind,
+		Status:       domain.Status,
+		RunAt:        domain.RunAt,
+		CreatedAt:    domain.Doc.CreatedAt,
+		Environments: []string{},
+	}
+
+	if domain.Doc.Deployment != nil {
+		for _, deploymentConfig := range domain.Doc.Deployment.Deployments {
+			out.Name = deploymentConfig.Name
+			out.Namespace = deploymentConfig.Namespace
+			out.Environments = append(out.Environments, deploymentConfig.EnvironmentName)
+		}
+	}
+
+	if domain.Doc.Rollout != nil {
+		out.Name = domain.Doc.Rollout.Name.Name
+		out.Namespace = domain.Doc.Rollout.Name.Namespace
+		out.Environments = append(out.Environments, domain.Doc.Rollout.EnvironmentName)
+	}
+
+	if domain.Doc.Result != nil {
+		out.Result = &model.ScheduledJobResult{
+			StartedAt:      domain.Doc.Result.StartedAt,
+			CompletedAt:    domain.Doc.Result.CompletedAt,
+			Message:        domain.Doc.Result.Message,
+			RiserRevisions: domain.Doc.Result.RiserRevisions,
+		}
+	}
+
+	return out
+}
+
+func mapScheduledJobArrayFromDomain(domainArray []core.ScheduledJob) []model.ScheduledJob {
+	jobs := []model.ScheduledJob{}
+	for idx := range domainArray {
+		jobs = append(jobs, mapScheduledJobFromDomain(&domainArray[idx]))
+	}
+
+	return jobs
+}
@@ -0,0 +1,80 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/riser-platform/riser-server/pkg/scheduledjob"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetScheduledJob_NotFound(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx, _ := newContextWithRecorder(req)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(uuid.New().String())
+
+	jobs := &core.FakeScheduledJobRepository{
+		GetFn: func(uuid.UUID) (*core.ScheduledJob, error) {
+			return nil, core.ErrNotFound
+		},
+	}
+
+	err := GetScheduledJob(ctx, jobs)
+
+	assert.IsType(t, &echo.HTTPError{}, err)
+	assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+}
+
+func Test_DeleteScheduledJob_InvalidId(t *testing.T) {
+	req := httptest.NewRequest(http.MethodDelete, "/", nil)
+	ctx, _ := newContextWithRecorder(req)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("bad")
+
+	err := DeleteScheduledJob(ctx, &scheduledjob.FakeService{})
+
+	assert.IsType(t, &core.ValidationError{}, err)
+	assert.Equal(t, "Invalid scheduled job id: invalid UUID length: 3", err.Error())
+}
+
+func Test_mapScheduledJobFromDomain_Deployment(t *testing.T) {
+	runAt := time.Now().UTC()
+	domain := &core.ScheduledJob{
+		Id:     uuid.New(),
+		Kind:   core.ScheduledJobKindDeployment,
+		Status: core.ScheduledJobStatusSucceeded,
+		RunAt:  runAt,
+		Doc: core.ScheduledJobDoc{
+			CreatedAt: runAt.Add(-time.Hour),
+			Deployment: &core.ScheduledDeployment{
+				Deployments: []core.DeploymentConfig{
+					{Name: "myapp", Namespace: "myns", EnvironmentName: "dev"},
+					{Name: "myapp", Namespace: "myns", EnvironmentName: "prod"},
+				},
+			},
+			Result: &core.ScheduledJobResult{
+				Message:        "Deployment applied",
+				RiserRevisions: map[string]int64{"dev": 1, "prod": 2},
+			},
+		},
+	}
+
+	result := mapScheduledJobFromDomain(domain)
+
+	assert.Equal(t, domain.Id, result.Id)
+	assert.Equal(t, "deployment", result.Kind)
+	assert.Equal(t, "succeeded", result.Status)
+	assert.Equal(t, runAt, result.RunAt)
+	assert.Equal(t, runAt.Add(-time.Hour), result.CreatedAt)
+	assert.Equal(t, "myapp", result.Name)
+	assert.Equal(t, "myns", result.Namespace)
+	assert.Equal(t, []string{"dev", "prod"}, result.Environments)
+	assert.Equal(t, "Deployment applied", result.Result.Message)
+	assert.Equal(t, map[string]int64{"dev": 1, "prod": 2}, result.Result.RiserRevisions)
+}
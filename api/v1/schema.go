@@ -0,0 +1,12 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/riser-platform/riser-server/api/v1/model"
+)
+
+func GetAppConfigSchema(c echo.Context) error {
+	return c.JSON(http.StatusOK, model.AppConfigSchema())
+}
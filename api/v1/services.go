@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"database/sql"
+
+	"github.com/riser-platform/riser-server/pkg/admission"
+	"github.com/riser-platform/riser-server/pkg/app"
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/riser-platform/riser-server/pkg/deployment"
+	"github.com/riser-platform/riser-server/pkg/deploymentreservation"
+	"github.com/riser-platform/riser-server/pkg/deploymentstatus"
+	"github.com/riser-platform/riser-server/pkg/domainreservation"
+	"github.com/riser-platform/riser-server/pkg/environment"
+	"github.com/riser-platform/riser-server/pkg/git"
+	"github.com/riser-platform/riser-server/pkg/login"
+	"github.com/riser-platform/riser-server/pkg/namespace"
+	"github.com/riser-platform/riser-server/pkg/postgres"
+	"github.com/riser-platform/riser-server/pkg/rollout"
+	"github.com/riser-platform/riser-server/pkg/scheduledjob"
+	"github.com/riser-platform/riser-server/pkg/secret"
+	"github.com/riser-platform/riser-server/pkg/state"
+)
+
+// Services are the repositories and services shared by the API and by background workers (e.g. the scheduled job worker)
+type Services struct {
+	EnvironmentRepository   core.EnvironmentRepository
+	NamespaceRepository     core.NamespaceRepository
+	AppRepository           core.AppRepository
+	SecretMetaRepository    core.SecretMetaRepository
+	DeploymentRepository    core.DeploymentRepository
+	ScheduledJobRepository  core.ScheduledJobRepository
+	EnvironmentService      environment.Service
+	NamespaceService        namespace.Service
+	AppService              app.Service
+	SecretService           secret.Service
+	DeploymentService       deployment.Service
+	DeploymentStatusService deploymentstatus.Service
+	RolloutService          rollout.Service
+	LoginService            login.Service
+	ScheduledJobService     scheduledjob.Service
+}
+
+func NewServices(repo git.Repo, db *sql.DB) *Services {
+	// TODO: Refactor dependency management
+	s := &Services{
+		EnvironmentRepository:  postgres.NewEnvironmentRepository(db),
+		NamespaceRepository:    postgres.NewNamespaceRepository(db),
+		AppRepository:          postgres.NewAppRepository(db),
+		SecretMetaRepository:   postgres.NewSecretMetaRepository(db),
+		DeploymentRepository:   postgres.NewDeploymentRepository(db),
+		ScheduledJobRepository: postgres.NewScheduledJobRepository(db),
+	}
+	s.EnvironmentService = environment.NewService(s.EnvironmentRepository)
+	s.NamespaceService = namespace.NewService(s.NamespaceRepository, s.EnvironmentRepository)
+	s.AppService = app.NewService(s.AppRepository, s.NamespaceService)
+	s.SecretService = secret.NewService(s.SecretMetaRepository, s.EnvironmentRepository)
+	s.DeploymentService = deployment.NewService(s.AppRepository, s.NamespaceService, s.SecretMetaRepository, s.EnvironmentRepository,
+		s.DeploymentRepository, deploymentreservation.NewService(postgres.NewDeploymentReservationRepository(db)),
+		domainreservation.NewService(postgres.NewDomainReservationRepository(db)), admission.NewService())
+	s.DeploymentStatusService = deploymentstatus.NewService(s.DeploymentRepository, s.EnvironmentService)
+	s.RolloutService = rollout.NewService(s.AppRepository, s.DeploymentRepository)
+	s.LoginService = login.NewService(postgres.NewUserRepository(db), postgres.NewApiKeyRepository(db))
+	s.ScheduledJobService = scheduledjob.NewService(s.ScheduledJobRepository, s.DeploymentService, s.EnvironmentService, s.RolloutService, state.NewGitCommitter(repo))
+	return s
+}
@@ -36,7 +36,7 @@ func mapAppStatusFromDomain(domain *core.AppStatus) *model.AppStatus {
 	}
 
 	for _, deployment := range domain.Deployments {
-		out.Deployments = append(out.Deployments, *mapDeploymentToStatusModel(&deployment))
+		out.Deployments = append(out.Deployments, *mapDeploymentToStatusModel(&deployment, domain.DeploymentRevisions[deployment.DeploymentRecord.Id]))
 	}
 
 	return out
@@ -55,7 +55,7 @@ func mapEnvironmentStatusesFromDomain(domain []core.EnvironmentStatus) []model.E
 	return out
 }
 
-func mapDeploymentToStatusModel(domain *core.Deployment) *model.DeploymentStatus {
+func mapDeploymentToStatusModel(domain *core.Deployment, revisions []core.DeploymentRevision) *model.DeploymentStatus {
 	status := &model.DeploymentStatus{
 		AppId:           domain.AppId,
 		DeploymentName:  domain.Name,
@@ -63,6 +63,19 @@ func mapDeploymentToStatusModel(domain *core.Deployment) *model.DeploymentStatus
 		EnvironmentName: domain.EnvironmentName,
 		RiserRevision:   domain.RiserRevision,
 	}
+
+	for _, revision := range revisions {
+		if revision.RiserRevision == domain.RiserRevision {
+			status.Metadata = revision.Doc.Metadata
+		}
+		status.RevisionHistory = append(status.RevisionHistory, model.DeploymentRevision{
+			RiserRevision: revision.RiserRevision,
+			CreatedAt:     revision.Doc.CreatedAt,
+			DockerTag:     revision.Doc.DockerTag,
+			Metadata:      revision.Doc.Metadata,
+		})
+	}
+
 	if domain.Doc.Status == nil {
 		status.DeploymentStatusMutable = model.DeploymentStatusMutable{}
 	} else {
@@ -72,6 +85,14 @@ func mapDeploymentToStatusModel(domain *core.Deployment) *model.DeploymentStatus
 			LatestReadyRevisionName:   domain.Doc.Status.LatestReadyRevisionName,
 		}
 
+		if domain.Doc.Status.PreDeployJob != nil {
+			status.PreDeployJob = &model.DeploymentPreDeployJobStatus{
+				RiserRevision: domain.Doc.Status.PreDeployJob.RiserRevision,
+				Status:        domain.Doc.Status.PreDeployJob.Status,
+				StatusReason:  domain.Doc.Status.PreDeployJob.StatusReason,
+			}
+		}
+
 		status.Revisions = make([]model.DeploymentRevisionStatus, len(domain.Doc.Status.Revisions))
 		for idx, revision := range domain.Doc.Status.Revisions {
 			status.Revisions[idx] = model.DeploymentRevisionStatus{
@@ -103,6 +124,14 @@ func mapDeploymentStatusFromModel(in *model.DeploymentStatusMutable) *core.Deplo
 		LastUpdated:               time.Now().UTC(),
 	}
 
+	if in.PreDeployJob != nil {
+		out.PreDeployJob = &core.DeploymentPreDeployJobStatus{
+			RiserRevision: in.PreDeployJob.RiserRevision,
+			Status:        in.PreDeployJob.Status,
+			StatusReason:  in.PreDeployJob.StatusReason,
+		}
+	}
+
 	out.Revisions = make([]core.DeploymentRevisionStatus, len(in.Revisions))
 	for idx, revision := range in.Revisions {
 		out.Revisions[idx] = core.DeploymentRevisionStatus{
@@ -57,17 +57,41 @@ func Test_mapDeploymentToStatusModel(t *testing.T) {
 							Tag:          "r2",
 						},
 					},
+					PreDeployJob: &core.DeploymentPreDeployJobStatus{
+						RiserRevision: 4,
+						Status:        model.PreDeployJobStatusFailed,
+						StatusReason:  "BackoffLimitExceeded",
+					},
 				},
 			},
 		},
 	}
 
-	result := mapDeploymentToStatusModel(deployment)
+	createdAt := time.Now().UTC()
+	revisions := []core.DeploymentRevision{
+		{
+			RiserRevision: 4,
+			Doc: core.DeploymentRevisionDoc{
+				CreatedAt: createdAt,
+				DockerTag: "v2",
+				Metadata:  map[string]string{"git-sha": "9c2e6c5"},
+			},
+		},
+		{
+			RiserRevision: 3,
+			Doc: core.DeploymentRevisionDoc{
+				DockerTag: "v1",
+			},
+		},
+	}
+
+	result := mapDeploymentToStatusModel(deployment, revisions)
 
 	assert.Equal(t, deployment.AppId, result.AppId)
 	assert.Equal(t, "mydeployment", result.DeploymentName)
 	assert.Equal(t, "myns", result.Namespace)
 	assert.Equal(t, "myenv", result.EnvironmentName)
+	assert.Equal(t, map[string]string{"git-sha": "9c2e6c5"}, result.Metadata)
 	assert.Equal(t, int64(3), result.ObservedRiserRevision)
 	assert.Equal(t, int64(4), result.RiserRevision)
 	assert.Equal(t, "rev2", result.LatestCreatedRevisionName)
@@ -82,6 +106,13 @@ func Test_mapDeploymentToStatusModel(t *testing.T) {
 	assert.Equal(t, int64(10), *result.Traffic[1].Percent)
 	assert.Equal(t, "r2", result.Traffic[1].Tag)
 
+	// Pre-deploy job
+	assert.Equal(t, &model.DeploymentPreDeployJobStatus{
+		RiserRevision: 4,
+		Status:        model.PreDeployJobStatusFailed,
+		StatusReason:  "BackoffLimitExceeded",
+	}, result.PreDeployJob)
+
 	// Revisions
 	assert.Len(t, result.Revisions, 2)
 	assert.Equal(t, "rev1", result.Revisions[0].Name)
@@ -94,6 +125,16 @@ func Test_mapDeploymentToStatusModel(t *testing.T) {
 	assert.Equal(t, "myrevisionstatusreason2", result.Revisions[1].RevisionStatusReason)
 	assert.Equal(t, "mydockerimage2", result.Revisions[1].DockerImage)
 	assert.Equal(t, int64(4), result.Revisions[1].RiserRevision)
+
+	// Revision History
+	assert.Len(t, result.RevisionHistory, 2)
+	assert.Equal(t, int64(4), result.RevisionHistory[0].RiserRevision)
+	assert.Equal(t, createdAt, result.RevisionHistory[0].CreatedAt)
+	assert.Equal(t, "v2", result.RevisionHistory[0].DockerTag)
+	assert.Equal(t, map[string]string{"git-sha": "9c2e6c5"}, result.RevisionHistory[0].Metadata)
+	assert.Equal(t, int64(3), result.RevisionHistory[1].RiserRevision)
+	assert.Equal(t, "v1", result.RevisionHistory[1].DockerTag)
+	assert.Nil(t, result.RevisionHistory[1].Metadata)
 }
 
 func Test_mapDeploymentToStatusModel_NilStatus(t *testing.T) {
@@ -110,12 +151,13 @@ func Test_mapDeploymentToStatusModel_NilStatus(t *testing.T) {
 		},
 	}
 
-	result := mapDeploymentToStatusModel(deployment)
+	result := mapDeploymentToStatusModel(deployment, nil)
 
 	assert.Equal(t, deployment.AppId, result.AppId)
 	assert.Equal(t, "mydeployment", result.DeploymentName)
 	assert.Equal(t, "myns", result.Namespace)
 	assert.Equal(t, "myenv", result.EnvironmentName)
+	assert.Nil(t, result.PreDeployJob)
 }
 
 func Test_mapDeploymentStatusFromModel(t *testing.T) {
@@ -151,6 +193,10 @@ func Test_mapDeploymentStatusFromModel(t *testing.T) {
 				Tag:          "r2",
 			},
 		},
+		PreDeployJob: &model.DeploymentPreDeployJobStatus{
+			RiserRevision: 3,
+			Status:        model.PreDeployJobStatusSucceeded,
+		},
 	}
 
 	now := time.Now().Unix()
@@ -181,6 +227,9 @@ func Test_mapDeploymentStatusFromModel(t *testing.T) {
 	assert.Equal(t, "rev2", result.Traffic[1].RevisionName)
 	assert.Equal(t, int64(10), *result.Traffic[1].Percent)
 	assert.Equal(t, "r2", result.Traffic[1].Tag)
+
+	// Pre-deploy job
+	assert.Equal(t, &core.DeploymentPreDeployJobStatus{RiserRevision: 3, Status: model.PreDeployJobStatusSucceeded}, result.PreDeployJob)
 }
 
 func Test_mapEnvironmentStatusFromDomain(t *testing.T) {
@@ -25,10 +25,13 @@ func PostValidateAppConfig(c echo.Context, appService app.Service, environmentSe
 	}
 
 	err = validateAppConfig(appConfig, appService, environmentService)
-	if err == nil {
-		return c.NoContent(http.StatusNoContent)
+	if err != nil {
+		return err
+	}
+	if len(appConfig.DeprecatedFields()) > 0 {
+		return c.JSON(http.StatusOK, model.ValidateAppConfigResponse{DeprecatedFields: appConfig.DeprecatedFields()})
 	}
-	return err
+	return c.NoContent(http.StatusNoContent)
 }
 
 // validateAppConfig performs additional validation beyond type validation of the appConfig model (i.e. appConfig.Validate())
@@ -93,9 +93,9 @@ func Test_validateAppConfig_InvalidAppName(t *testing.T) {
 
 func Test_validateAppConfig_InvalidEnvOverride(t *testing.T) {
 	appConfig := *validAppConfig
-	appConfig.Overrides = map[string]model.OverrideableAppConfig{}
-	appConfig.Overrides["prod"] = model.OverrideableAppConfig{}
-	appConfig.Overrides["foo"] = model.OverrideableAppConfig{}
+	appConfig.Overrides = map[string]model.AppConfigOverride{}
+	appConfig.Overrides["prod"] = model.AppConfigOverride{}
+	appConfig.Overrides["foo"] = model.AppConfigOverride{}
 
 	appService := &app.FakeService{
 		CheckIDFn: func(id uuid.UUID, name *core.NamespacedName) error {
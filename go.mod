@@ -5,6 +5,7 @@ replace github.com/riser-platform/riser-server/api/v1/model => ./api/v1/model
 go 1.14
 
 require (
+	github.com/Knetic/govaluate v3.0.0+incompatible
 	github.com/bitnami-labs/sealed-secrets v0.12.4
 	github.com/dustin/go-humanize v1.0.0
 	github.com/go-ozzo/ozzo-validation/v3 v3.8.1
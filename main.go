@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 
+	"github.com/riser-platform/riser-server/pkg/scheduledjob"
+
 	"github.com/riser-platform/riser-server/pkg/state"
 	"github.com/riser-platform/riser-server/pkg/util"
 
@@ -81,6 +84,9 @@ func main() {
 	bootstrapApiKey(postgresDb, &rc)
 	bootstrapDefaultNamespace(postgresDb, repo)
 
+	services := apiv1.NewServices(repo, postgresDb)
+	startScheduledJobWorker(services.ScheduledJobService)
+
 	e := echo.New()
 	e.HideBanner = true
 
@@ -89,7 +95,7 @@ func main() {
 	e.HTTPErrorHandler = api.ErrorHandler
 	e.Binder = &api.DataBinder{}
 
-	apiv1.RegisterRoutes(e, repo, postgresDb)
+	apiv1.RegisterRoutes(e, repo, services)
 	err = e.Start(rc.BindAddress)
 	exitIfError(err, "Error starting server")
 }
@@ -100,6 +106,11 @@ func bootstrapDefaultNamespace(db *sql.DB, repo git.Repo) {
 	exitIfError(err, "Error ensuring default namespace")
 }
 
+func startScheduledJobWorker(scheduledJobService scheduledjob.Service) {
+	logger.Info("Starting scheduled job worker")
+	go scheduledjob.RunWorker(context.Background(), scheduledJobService, scheduledjob.DefaultWorkerInterval, logger)
+}
+
 func bootstrapApiKey(db *sql.DB, rc *core.RuntimeConfig) {
 	loginService := login.NewService(postgres.NewUserRepository(db), postgres.NewApiKeyRepository(db))
 	err := loginService.BootstrapRootUser(rc.BootstrapApikey)
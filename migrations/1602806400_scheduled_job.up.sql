@@ -0,0 +1,11 @@
+CREATE TABLE scheduled_job
+(
+  id uuid NOT NULL,
+  kind character varying(63) NOT NULL,
+  run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  status character varying(63) NOT NULL,
+  doc jsonb NOT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE INDEX ix_scheduled_job_status_run_at ON scheduled_job(status, run_at);
@@ -0,0 +1,7 @@
+CREATE TABLE deployment_revision
+(
+  deployment_id uuid NOT NULL REFERENCES deployment(id),
+  riser_revision integer NOT NULL,
+  doc jsonb NOT NULL,
+  PRIMARY KEY (deployment_id, riser_revision)
+);
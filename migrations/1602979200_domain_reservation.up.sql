@@ -0,0 +1,6 @@
+CREATE TABLE domain_reservation
+(
+  domain character varying(253) NOT NULL,
+  app_id uuid NOT NULL REFERENCES app(id),
+  PRIMARY KEY(domain)
+);
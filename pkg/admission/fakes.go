@@ -0,0 +1,13 @@
+package admission
+
+import "github.com/riser-platform/riser-server/pkg/core"
+
+type FakeService struct {
+	AdmitFn        func(ctx *core.DeploymentContext) error
+	AdmitCallCount int
+}
+
+func (f *FakeService) Admit(ctx *core.DeploymentContext) error {
+	f.AdmitCallCount++
+	return f.AdmitFn(ctx)
+}
@@ -0,0 +1,154 @@
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Knetic/govaluate"
+	validation "github.com/go-ozzo/ozzo-validation/v3"
+	"github.com/pkg/errors"
+	"github.com/riser-platform/riser-server/api/v1/model"
+	"github.com/riser-platform/riser-server/pkg/core"
+)
+
+// Service admits a deployment by evaluating it against the admission policies of its environment.
+//
+// Policies are govaluate expressions (https://github.com/Knetic/govaluate/blob/master/MANUAL.md) which must evaluate to true for
+// the deployment to be admitted. The following parameters are available:
+//
+//	name, namespace, environment, image, tag
+//	revision: the riser revision that the deployment has once applied, including for a dry run
+//	app.*: the app config after environment defaults and overrides are applied (e.g. [app.expose.scope]). Parameters containing
+//	a "." must be wrapped in square brackets.
+//
+// Referencing a parameter that does not exist fails the policy. Use the "has" function to check for optional parameters
+// (e.g. "[app.expose.scope] != 'external' || has('app.healthcheck')").
+type Service interface {
+	Admit(ctx *core.DeploymentContext) error
+}
+
+type service struct{}
+
+func NewService() Service {
+	return &service{}
+}
+
+func (s *service) Admit(ctx *core.DeploymentContext) error {
+	if len(ctx.EnvironmentConfig.AdmissionPolicies) == 0 {
+		return nil
+	}
+
+	parameters, err := createParameters(ctx)
+	if err != nil {
+		return err
+	}
+
+	violations := validation.Errors{}
+	for _, policy := range ctx.EnvironmentConfig.AdmissionPolicies {
+		err = evaluatePolicy(policy, parameters)
+		if err != nil {
+			violations[policy.Name] = err
+		}
+	}
+
+	if len(violations) > 0 {
+		return core.NewValidationError(
+			fmt.Sprintf("deployment violates the admission policies of environment %q", ctx.DeploymentConfig.EnvironmentName), violations)
+	}
+
+	return nil
+}
+
+// ValidatePolicies validates that each policy expression is syntactically correct
+func ValidatePolicies(policies []model.EnvironmentAdmissionPolicy) error {
+	validationErrors := validation.Errors{}
+	for idx, policy := range policies {
+		_, err := govaluate.NewEvaluableExpressionWithFunctions(policy.Expression, createFunctions(map[string]interface{}{}))
+		if err != nil {
+			validationErrors[fmt.Sprintf("admissionPolicies[%d].expression", idx)] = err
+		}
+	}
+
+	return validationErrors.Filter()
+}
+
+func evaluatePolicy(policy model.EnvironmentAdmissionPolicy, parameters map[string]interface{}) error {
+	expression, err := govaluate.NewEvaluableExpressionWithFunctions(policy.Expression, createFunctions(parameters))
+	if err != nil {
+		return fmt.Errorf("invalid expression: %v", err)
+	}
+
+	result, err := expression.Evaluate(parameters)
+	if err != nil {
+		return fmt.Errorf("error evaluating expression: %v", err)
+	}
+
+	admitted, ok := result.(bool)
+	if !ok {
+		return fmt.Errorf("expression must evaluate to true or false but was %v", result)
+	}
+
+	if !admitted {
+		if policy.Message != "" {
+			return errors.New(policy.Message)
+		}
+		return fmt.Errorf("must satisfy %q", policy.Expression)
+	}
+
+	return nil
+}
+
+func createFunctions(parameters map[string]interface{}) map[string]govaluate.ExpressionFunction {
+	return map[string]govaluate.ExpressionFunction{
+		// has returns true if the parameter exists (e.g. has('app.healthcheck'))
+		"has": func(args ...interface{}) (interface{}, error) {
+			if len(args) != 1 {
+				return nil, errors.New("has requires one parameter name")
+			}
+			name, ok := args[0].(string)
+			if !ok {
+				return nil, errors.New("has requires the parameter name as a string")
+			}
+			_, exists := parameters[name]
+			return exists, nil
+		},
+	}
+}
+
+func createParameters(ctx *core.DeploymentContext) (map[string]interface{}, error) {
+	deployment := ctx.DeploymentConfig
+	parameters := map[string]interface{}{
+		"name":        deployment.Name,
+		"namespace":   deployment.Namespace,
+		"environment": deployment.EnvironmentName,
+		"image":       deployment.App.Image,
+		"tag":         deployment.Docker.Tag,
+		// govaluate treats all numbers as float64
+		"revision": float64(ctx.RiserRevision),
+	}
+
+	appJson, err := json.Marshal(deployment.App)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating admission policy parameters")
+	}
+	var app map[string]interface{}
+	err = json.Unmarshal(appJson, &app)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating admission policy parameters")
+	}
+
+	flattenParameters(parameters, "app", app)
+	return parameters, nil
+}
+
+// flattenParameters adds each value nested in a map as a parameter using a "." separated name. Arrays are left as is for use with the
+// IN operator.
+func flattenParameters(parameters map[string]interface{}, prefix string, values map[string]interface{}) {
+	for key, value := range values {
+		name := fmt.Sprintf("%s.%s", prefix, key)
+		parameters[name] = value
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenParameters(parameters, name, nested)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package admission
+
+import (
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v3"
+	"github.com/riser-platform/riser-server/api/v1/model"
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Admit(t *testing.T) {
+	ctx := createAdmissionContext(
+		model.EnvironmentAdmissionPolicy{Name: "registry", Expression: "image =~ '^registry.example.com/'"},
+		model.EnvironmentAdmissionPolicy{Name: "no-test", Expression: "!(name =~ '-test$') || environment != 'prod'"},
+		model.EnvironmentAdmissionPolicy{Name: "healthcheck", Expression: "[app.expose.scope] != 'external' || has('app.healthcheck')"},
+		model.EnvironmentAdmissionPolicy{Name: "port", Expression: "[app.expose.containerPort] >= 1024"},
+		model.EnvironmentAdmissionPolicy{Name: "domains", Expression: "'myapp.example.com' IN [app.domains]"},
+	)
+
+	err := NewService().Admit(ctx)
+
+	assert.NoError(t, err)
+}
+
+func Test_Admit_Violations(t *testing.T) {
+	ctx := createAdmissionContext(
+		model.EnvironmentAdmissionPolicy{Name: "registry", Expression: "image =~ '^other.example.com/'", Message: "images must come from other.example.com"},
+		model.EnvironmentAdmissionPolicy{Name: "revision", Expression: "revision > 3"},
+		model.EnvironmentAdmissionPolicy{Name: "passes", Expression: "namespace == 'apps'"},
+	)
+
+	err := NewService().Admit(ctx)
+
+	require.IsType(t, &core.ValidationError{}, err)
+	validationErr := err.(*core.ValidationError)
+	assert.Equal(t, `deployment violates the admission policies of environment "prod"`, validationErr.Message)
+	require.IsType(t, validation.Errors{}, validationErr.ValidationError)
+	violations := validationErr.ValidationError.(validation.Errors)
+	assert.Len(t, violations, 2)
+	assert.Equal(t, "images must come from other.example.com", violations["registry"].Error())
+	assert.Equal(t, `must satisfy "revision > 3"`, violations["revision"].Error())
+}
+
+func Test_Admit_MissingParameterFailsPolicy(t *testing.T) {
+	ctx := createAdmissionContext(
+		model.EnvironmentAdmissionPolicy{Name: "healthcheck", Expression: "[app.healthcheck.path] == '/health'"},
+	)
+	ctx.DeploymentConfig.App.HealthCheck = nil
+
+	err := NewService().Admit(ctx)
+
+	require.IsType(t, &core.ValidationError{}, err)
+	violations := err.(*core.ValidationError).ValidationError.(validation.Errors)
+	assert.Equal(t, "error evaluating expression: No parameter 'app.healthcheck.path' found.", violations["healthcheck"].Error())
+}
+
+func Test_Admit_NonBoolResultFailsPolicy(t *testing.T) {
+	ctx := createAdmissionContext(
+		model.EnvironmentAdmissionPolicy{Name: "name", Expression: "name"},
+	)
+
+	err := NewService().Admit(ctx)
+
+	require.IsType(t, &core.ValidationError{}, err)
+	violations := err.(*core.ValidationError).ValidationError.(validation.Errors)
+	assert.Equal(t, "expression must evaluate to true or false but was myapp", violations["name"].Error())
+}
+
+func Test_Admit_NoPolicies(t *testing.T) {
+	ctx := createAdmissionContext()
+
+	err := NewService().Admit(ctx)
+
+	assert.NoError(t, err)
+}
+
+func Test_ValidatePolicies(t *testing.T) {
+	err := ValidatePolicies([]model.EnvironmentAdmissionPolicy{
+		{Name: "valid", Expression: "has('app.healthcheck')"},
+		{Name: "invalid", Expression: "name == ("},
+	})
+
+	require.IsType(t, validation.Errors{}, err)
+	validationErrors := err.(validation.Errors)
+	assert.Len(t, validationErrors, 1)
+	assert.NotNil(t, validationErrors["admissionPolicies[1].expression"])
+}
+
+func Test_ValidatePolicies_Valid(t *testing.T) {
+	err := ValidatePolicies([]model.EnvironmentAdmissionPolicy{
+		{Name: "valid", Expression: "[app.expose.scope] != 'external' || has('app.healthcheck')"},
+	})
+
+	assert.NoError(t, err)
+}
+
+func createAdmissionContext(policies ...model.EnvironmentAdmissionPolicy) *core.DeploymentContext {
+	return &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp",
+			Namespace:       "apps",
+			EnvironmentName: "prod",
+			Docker:          core.DeploymentDocker{Tag: "0.0.1"},
+			App: &model.AppConfig{
+				Name:  "myapp",
+				Image: "registry.example.com/myapp",
+				Expose: &model.AppConfigExpose{
+					ContainerPort: 8080,
+					Scope:         model.AppExposeScope_External,
+				},
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					HealthCheck: &model.AppConfigHealthCheck{
+						AppConfigProbe: model.AppConfigProbe{Path: "/health"},
+					},
+					Domains: []string{"myapp.example.com"},
+				},
+			},
+		},
+		EnvironmentConfig: &core.EnvironmentConfig{AdmissionPolicies: policies},
+		RiserRevision:     3,
+	}
+}
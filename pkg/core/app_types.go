@@ -15,4 +15,6 @@ type AppStatus struct {
 	// Deployments returns the whole deployment. We should probably use a different type here with less data, but we can't just pass
 	// Deployment.Doc.Status as we also need the DeploymentName and the environment.
 	Deployments []Deployment
+	// DeploymentRevisions contains the revision history of each deployment keyed by the deployment id
+	DeploymentRevisions map[uuid.UUID][]DeploymentRevision
 }
@@ -12,24 +12,42 @@ type DeploymentRepository interface {
 	UpdateTraffic(name *NamespacedName, envName string, riserRevision int64, traffic TrafficConfig) error
 	IncrementRevision(name *NamespacedName, envName string) (int64, error)
 	RollbackRevision(name *NamespacedName, envName string, failedRevision int64) (int64, error)
+	// SaveRevision creates or replaces a revision. Revisions are replaced since a riser revision is reused after a rollback.
+	SaveRevision(revision *DeploymentRevision) error
+	// DeleteRevision removes a revision that was saved for a deployment that failed
+	DeleteRevision(deploymentId uuid.UUID, riserRevision int64) error
+	// FindRevisions returns a deployment's revisions with the most recent revision first
+	FindRevisions(deploymentId uuid.UUID, limit int) ([]DeploymentRevision, error)
+	// FindRevisionsByApp returns up to limit revisions for each of an app's active deployments with the most recent revision first
+	FindRevisionsByApp(appId uuid.UUID, limit int) ([]DeploymentRevision, error)
 }
 
 type FakeDeploymentRepository struct {
-	CreateFn                   func(newDeployment *DeploymentRecord) error
-	CreateCallCount            int
-	DeleteFn                   func(name *NamespacedName, envName string) error
-	DeleteCallCount            int
-	GetByNameFn                func(name *NamespacedName, envName string) (*Deployment, error)
-	GetByReservationFn         func(reservationId uuid.UUID, envName string) (*Deployment, error)
-	GetByReservationCallCount  int
-	FindByAppFn                func(uuid.UUID) ([]Deployment, error)
-	IncrementRevisionFn        func(name *NamespacedName, envName string) (int64, error)
-	IncrementRevisionCallCount int
-	RollbackRevisionFn         func(name *NamespacedName, envName string, failedRevision int64) (int64, error)
-	UpdateStatusFn             func(name *NamespacedName, envName string, status *DeploymentStatus) error
-	UpdateStatusCallCount      int
-	UpdateTrafficFn            func(name *NamespacedName, envName string, riserRevision int64, traffic TrafficConfig) error
-	UpdateTrafficCallCount     int
+	CreateFn                    func(newDeployment *DeploymentRecord) error
+	CreateCallCount             int
+	DeleteFn                    func(name *NamespacedName, envName string) error
+	DeleteCallCount             int
+	GetByNameFn                 func(name *NamespacedName, envName string) (*Deployment, error)
+	GetByNameCallCount          int
+	GetByReservationFn          func(reservationId uuid.UUID, envName string) (*Deployment, error)
+	GetByReservationCallCount   int
+	FindByAppFn                 func(uuid.UUID) ([]Deployment, error)
+	IncrementRevisionFn         func(name *NamespacedName, envName string) (int64, error)
+	IncrementRevisionCallCount  int
+	RollbackRevisionFn          func(name *NamespacedName, envName string, failedRevision int64) (int64, error)
+	RollbackRevisionCallCount   int
+	UpdateStatusFn              func(name *NamespacedName, envName string, status *DeploymentStatus) error
+	UpdateStatusCallCount       int
+	UpdateTrafficFn             func(name *NamespacedName, envName string, riserRevision int64, traffic TrafficConfig) error
+	UpdateTrafficCallCount      int
+	SaveRevisionFn              func(revision *DeploymentRevision) error
+	SaveRevisionCallCount       int
+	DeleteRevisionFn            func(deploymentId uuid.UUID, riserRevision int64) error
+	DeleteRevisionCallCount     int
+	FindRevisionsFn             func(deploymentId uuid.UUID, limit int) ([]DeploymentRevision, error)
+	FindRevisionsCallCount      int
+	FindRevisionsByAppFn        func(appId uuid.UUID, limit int) ([]DeploymentRevision, error)
+	FindRevisionsByAppCallCount int
 }
 
 func (f *FakeDeploymentRepository) Create(newDeployment *DeploymentRecord) error {
@@ -43,6 +61,7 @@ func (f *FakeDeploymentRepository) Delete(name *NamespacedName, envName string)
 }
 
 func (f *FakeDeploymentRepository) GetByName(name *NamespacedName, envName string) (*Deployment, error) {
+	f.GetByNameCallCount++
 	return f.GetByNameFn(name, envName)
 }
 
@@ -61,6 +80,7 @@ func (fake *FakeDeploymentRepository) IncrementRevision(name *NamespacedName, en
 }
 
 func (fake *FakeDeploymentRepository) RollbackRevision(name *NamespacedName, envName string, failedRevision int64) (int64, error) {
+	fake.RollbackRevisionCallCount++
 	return fake.RollbackRevisionFn(name, envName, failedRevision)
 }
 
@@ -73,3 +93,23 @@ func (fake *FakeDeploymentRepository) UpdateTraffic(name *NamespacedName, envNam
 	fake.UpdateTrafficCallCount++
 	return fake.UpdateTrafficFn(name, envName, riserRevision, traffic)
 }
+
+func (fake *FakeDeploymentRepository) SaveRevision(revision *DeploymentRevision) error {
+	fake.SaveRevisionCallCount++
+	return fake.SaveRevisionFn(revision)
+}
+
+func (fake *FakeDeploymentRepository) DeleteRevision(deploymentId uuid.UUID, riserRevision int64) error {
+	fake.DeleteRevisionCallCount++
+	return fake.DeleteRevisionFn(deploymentId, riserRevision)
+}
+
+func (fake *FakeDeploymentRepository) FindRevisions(deploymentId uuid.UUID, limit int) ([]DeploymentRevision, error) {
+	fake.FindRevisionsCallCount++
+	return fake.FindRevisionsFn(deploymentId, limit)
+}
+
+func (fake *FakeDeploymentRepository) FindRevisionsByApp(appId uuid.UUID, limit int) ([]DeploymentRevision, error) {
+	fake.FindRevisionsByAppCallCount++
+	return fake.FindRevisionsByAppFn(appId, limit)
+}
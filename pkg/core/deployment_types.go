@@ -26,15 +26,18 @@ type DeploymentRecord struct {
 	Doc           DeploymentDoc
 }
 
+// DeploymentConfig is serialized when a deployment is scheduled to run at a later time (see ScheduledJob)
 type DeploymentConfig struct {
-	Name            string
-	Namespace       string
-	EnvironmentName string
-	Docker          DeploymentDocker
+	Name            string           `json:"name"`
+	Namespace       string           `json:"namespace"`
+	EnvironmentName string           `json:"environmentName"`
+	Docker          DeploymentDocker `json:"docker"`
 	// TODO: Move to core and remove api/v1/model dependency
-	App           *model.AppConfig
-	Traffic       TrafficConfig
-	ManualRollout bool
+	App           *model.AppConfig `json:"app"`
+	Traffic       TrafficConfig    `json:"traffic,omitempty"`
+	ManualRollout bool             `json:"manualRollout"`
+	// Metadata contains build and source information (e.g. git sha, build url) for the revision
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 type DeploymentDocker struct {
@@ -56,12 +59,19 @@ type DeploymentDoc struct {
 }
 
 type DeploymentStatus struct {
-	ObservedRiserRevision     int64                      `json:"observedRiserRevision"`
-	LastUpdated               time.Time                  `json:"lastUpdated"`
-	Revisions                 []DeploymentRevisionStatus `json:"revisions"`
-	LatestReadyRevisionName   string                     `json:"latestReadyRevisionName"`
-	LatestCreatedRevisionName string                     `json:"latestCreatedRevisionName"`
-	Traffic                   []DeploymentTrafficStatus  `json:"traffic"`
+	ObservedRiserRevision     int64                         `json:"observedRiserRevision"`
+	LastUpdated               time.Time                     `json:"lastUpdated"`
+	Revisions                 []DeploymentRevisionStatus    `json:"revisions"`
+	LatestReadyRevisionName   string                        `json:"latestReadyRevisionName"`
+	LatestCreatedRevisionName string                        `json:"latestCreatedRevisionName"`
+	Traffic                   []DeploymentTrafficStatus     `json:"traffic"`
+	PreDeployJob              *DeploymentPreDeployJobStatus `json:"preDeployJob,omitempty"`
+}
+
+type DeploymentPreDeployJobStatus struct {
+	RiserRevision int64  `json:"riserRevision"`
+	Status        string `json:"status"`
+	StatusReason  string `json:"statusReason,omitempty"`
 }
 
 type DeploymentTrafficStatus struct {
@@ -78,6 +88,31 @@ type DeploymentRevisionStatus struct {
 	RevisionStatusReason string `json:"revisionStatusReason"`
 }
 
+// DeploymentRevision records each riser revision of a deployment
+type DeploymentRevision struct {
+	DeploymentId  uuid.UUID
+	RiserRevision int64
+	Doc           DeploymentRevisionDoc
+}
+
+type DeploymentRevisionDoc struct {
+	CreatedAt time.Time         `json:"createdAt"`
+	DockerTag string            `json:"dockerTag"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	// ExposeScope is recorded so that other apps can reference the deployment's URL
+	ExposeScope string `json:"exposeScope,omitempty"`
+	// Kind is recorded so that other apps can only reference a deployment that exposes a service. Kind is empty for a revision recorded
+	// before the kind was tracked, which is always a service.
+	Kind string `json:"kind,omitempty"`
+	// PendingTraffic is the traffic applied once the revision's pre-deploy job succeeds. It is cleared once applied.
+	PendingTraffic TrafficConfig `json:"pendingTraffic,omitempty"`
+	// ConfigMaps are the names of the ConfigMaps used by the revision so that they can be removed once no longer used
+	ConfigMaps []string `json:"configMaps,omitempty"`
+	// Domains are the custom domains of the revision so that they can be removed and released once no longer used. Domains is nil for a
+	// revision recorded before domains were tracked.
+	Domains []string `json:"domains"`
+}
+
 type StatusProblem struct {
 	Count   int    `json:"count"`
 	Message string `json:"message"`
@@ -89,6 +124,18 @@ type DeploymentContext struct {
 	RiserRevision     int64
 	Secrets           []SecretMeta
 	ManualRollout     bool
+	// ReferencedDeployments are the deployments in the same environment referenced by the app's env vars
+	ReferencedDeployments []ReferencedDeployment
+	// PreviousRevisions are the most recent revisions of the deployment prior to this deployment, most recent first
+	PreviousRevisions []DeploymentRevision
+}
+
+// ReferencedDeployment is a deployment referenced by another app (e.g. ${app:mydeployment.mynamespace.url})
+type ReferencedDeployment struct {
+	Name      string
+	Namespace string
+	// ExposeScope is the expose scope of the deployment's latest revision
+	ExposeScope string
 }
 
 // Needed for sql.Scanner interface
@@ -101,6 +148,16 @@ func (a *DeploymentDoc) Scan(value interface{}) error {
 	return jsonbSqlUnmarshal(value, &a)
 }
 
+// Needed for sql.Scanner interface
+func (a *DeploymentRevisionDoc) Value() (driver.Value, error) {
+	return json.Marshal(a)
+}
+
+// Needed for sql.Scanner interface
+func (a *DeploymentRevisionDoc) Scan(value interface{}) error {
+	return jsonbSqlUnmarshal(value, &a)
+}
+
 // Needed for sql.Scanner interface. Normally this is only needed on the "Doc" object but we need this here since we do status only updates.
 func (a *DeploymentStatus) Value() (driver.Value, error) {
 	return json.Marshal(a)
@@ -0,0 +1,42 @@
+package core
+
+import "github.com/google/uuid"
+
+type DomainReservationRepository interface {
+	// Create returns ErrAlreadyExists if the domain is already reserved
+	Create(reservation *DomainReservation) error
+	Delete(domain string) error
+	GetByDomain(domain string) (*DomainReservation, error)
+	FindByApp(appId uuid.UUID) ([]DomainReservation, error)
+}
+
+type FakeDomainReservationRepository struct {
+	CreateFn             func(reservation *DomainReservation) error
+	CreateCallCount      int
+	DeleteFn             func(domain string) error
+	DeleteCallCount      int
+	GetByDomainFn        func(domain string) (*DomainReservation, error)
+	GetByDomainCallCount int
+	FindByAppFn          func(appId uuid.UUID) ([]DomainReservation, error)
+	FindByAppCallCount   int
+}
+
+func (f *FakeDomainReservationRepository) Create(reservation *DomainReservation) error {
+	f.CreateCallCount++
+	return f.CreateFn(reservation)
+}
+
+func (f *FakeDomainReservationRepository) Delete(domain string) error {
+	f.DeleteCallCount++
+	return f.DeleteFn(domain)
+}
+
+func (f *FakeDomainReservationRepository) GetByDomain(domain string) (*DomainReservation, error) {
+	f.GetByDomainCallCount++
+	return f.GetByDomainFn(domain)
+}
+
+func (f *FakeDomainReservationRepository) FindByApp(appId uuid.UUID) ([]DomainReservation, error) {
+	f.FindByAppCallCount++
+	return f.FindByAppFn(appId)
+}
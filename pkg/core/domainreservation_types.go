@@ -0,0 +1,11 @@
+package core
+
+import (
+	"github.com/google/uuid"
+)
+
+// DomainReservation represents a reservation of a custom domain (e.g. myapp.example.com) to an app
+type DomainReservation struct {
+	Domain string
+	AppId  uuid.UUID
+}
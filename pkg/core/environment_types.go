@@ -4,6 +4,8 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"time"
+
+	"github.com/riser-platform/riser-server/api/v1/model"
 )
 
 type Environment struct {
@@ -17,8 +19,28 @@ type EnvironmentDoc struct {
 }
 
 type EnvironmentConfig struct {
-	SealedSecretCert  []byte `json:"sealedSecretCert"`
-	PublicGatewayHost string `json:"publicGatewayHost"`
+	SealedSecretCert  []byte                       `json:"sealedSecretCert"`
+	PublicGatewayHost string                       `json:"publicGatewayHost"`
+	CertificateIssuer string                       `json:"certificateIssuer,omitempty"`
+	DefaultResources  *EnvironmentDefaultResources `json:"defaultResources,omitempty"`
+	// DefaultDenyAccess denies calls to apps that do not specify which apps and namespaces may call them
+	DefaultDenyAccess bool `json:"defaultDenyAccess,omitempty"`
+	// KNativeScheduling declares that KNative's pod spec scheduling features are enabled
+	KNativeScheduling bool `json:"knativeScheduling,omitempty"`
+	// AppDefaults are merged with each app config deployed to the environment
+	AppDefaults *model.OverrideableAppConfig `json:"appDefaults,omitempty"`
+	// AppConstraints are enforced on each app config deployed to the environment
+	AppConstraints *model.EnvironmentAppConstraints `json:"appConstraints,omitempty"`
+	// AdmissionPolicies are evaluated against each deployment to the environment
+	AdmissionPolicies []model.EnvironmentAdmissionPolicy `json:"admissionPolicies,omitempty"`
+	// SecurityBaseline hardens each app config deployed to the environment
+	SecurityBaseline *model.EnvironmentSecurityBaseline `json:"securityBaseline,omitempty"`
+}
+
+// EnvironmentDefaultResources are the resource requests and limits used when an app does not specify its own
+type EnvironmentDefaultResources struct {
+	Requests *model.AppConfigResourceList `json:"requests,omitempty"`
+	Limits   *model.AppConfigResourceList `json:"limits,omitempty"`
 }
 
 // Needed for sql.Scanner interface
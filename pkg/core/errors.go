@@ -8,6 +8,7 @@ import (
 
 var ErrNotFound = errors.New("the object could not be found")
 var ErrConflictNewerVersion = errors.New("a newer version of the object exists")
+var ErrAlreadyExists = errors.New("the object already exists")
 
 // ValidationError provides an error consumable by a client. This is safe to return to the API as the errorHandler is aware of this error
 type ValidationError struct {
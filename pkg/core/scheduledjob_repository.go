@@ -0,0 +1,60 @@
+package core
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ScheduledJobRepository interface {
+	Create(job *ScheduledJob) error
+	Get(id uuid.UUID) (*ScheduledJob, error)
+	// List returns jobs with the most recent RunAt first. Pass an empty status to return jobs in any status.
+	List(status string) ([]ScheduledJob, error)
+	// Cancel cancels a pending job. Returns ErrConflictNewerVersion if the job is no longer pending.
+	Cancel(id uuid.UUID) error
+	// ClaimNext marks the next pending job due at or before runAt as running and returns it. Returns ErrNotFound if there are no due jobs.
+	ClaimNext(runAt time.Time) (*ScheduledJob, error)
+	Complete(id uuid.UUID, status string, result *ScheduledJobResult) error
+}
+
+type FakeScheduledJobRepository struct {
+	CreateFn           func(job *ScheduledJob) error
+	CreateCallCount    int
+	GetFn              func(id uuid.UUID) (*ScheduledJob, error)
+	ListFn             func(status string) ([]ScheduledJob, error)
+	CancelFn           func(id uuid.UUID) error
+	CancelCallCount    int
+	ClaimNextFn        func(runAt time.Time) (*ScheduledJob, error)
+	ClaimNextCallCount int
+	CompleteFn         func(id uuid.UUID, status string, result *ScheduledJobResult) error
+	CompleteCallCount  int
+}
+
+func (fake *FakeScheduledJobRepository) Create(job *ScheduledJob) error {
+	fake.CreateCallCount++
+	return fake.CreateFn(job)
+}
+
+func (fake *FakeScheduledJobRepository) Get(id uuid.UUID) (*ScheduledJob, error) {
+	return fake.GetFn(id)
+}
+
+func (fake *FakeScheduledJobRepository) List(status string) ([]ScheduledJob, error) {
+	return fake.ListFn(status)
+}
+
+func (fake *FakeScheduledJobRepository) Cancel(id uuid.UUID) error {
+	fake.CancelCallCount++
+	return fake.CancelFn(id)
+}
+
+func (fake *FakeScheduledJobRepository) ClaimNext(runAt time.Time) (*ScheduledJob, error) {
+	fake.ClaimNextCallCount++
+	return fake.ClaimNextFn(runAt)
+}
+
+func (fake *FakeScheduledJobRepository) Complete(id uuid.UUID, status string, result *ScheduledJobResult) error {
+	fake.CompleteCallCount++
+	return fake.CompleteFn(id, status, result)
+}
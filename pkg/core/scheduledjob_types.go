@@ -0,0 +1,76 @@
+package core
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riser-platform/riser-server/api/v1/model"
+)
+
+const (
+	ScheduledJobKindDeployment = "deployment"
+	ScheduledJobKindRollout    = "rollout"
+
+	ScheduledJobStatusPending   = "pending"
+	ScheduledJobStatusRunning   = "running"
+	ScheduledJobStatusSucceeded = "succeeded"
+	ScheduledJobStatusFailed    = "failed"
+	ScheduledJobStatusCancelled = "cancelled"
+)
+
+// ScheduledJob is a deployment or rollout that is queued to run at a specific time
+type ScheduledJob struct {
+	Id     uuid.UUID
+	Kind   string
+	RunAt  time.Time
+	Status string
+	Doc    ScheduledJobDoc
+}
+
+type ScheduledJobDoc struct {
+	CreatedAt time.Time `json:"createdAt"`
+	// Only one of Deployment or Rollout is set depending on the Kind
+	Deployment *ScheduledDeployment `json:"deployment,omitempty"`
+	Rollout    *ScheduledRollout    `json:"rollout,omitempty"`
+	Result     *ScheduledJobResult  `json:"result,omitempty"`
+}
+
+type ScheduledDeployment struct {
+	// Deployments contains a deployment for each target environment
+	Deployments []DeploymentConfig `json:"deployments"`
+	// Request is the deployment request that the deployments were created from. The environment config is applied to the request
+	// again when the job runs. Jobs scheduled before the request was stored run the deployments as is.
+	Request *model.DeploymentRequest `json:"request,omitempty"`
+}
+
+type ScheduledRollout struct {
+	Name            NamespacedName `json:"name"`
+	EnvironmentName string         `json:"environmentName"`
+	Traffic         TrafficConfig  `json:"traffic"`
+}
+
+// ScheduledJobResult records the outcome of running a ScheduledJob
+type ScheduledJobResult struct {
+	StartedAt   time.Time `json:"startedAt"`
+	CompletedAt time.Time `json:"completedAt"`
+	Message     string    `json:"message"`
+	// RiserRevisions contains the riser revision for each environment of a deployment job
+	RiserRevisions map[string]int64 `json:"riserRevisions,omitempty"`
+}
+
+// Needed for sql.Scanner interface
+func (a *ScheduledJobDoc) Value() (driver.Value, error) {
+	return json.Marshal(a)
+}
+
+// Needed for sql.Scanner interface
+func (a *ScheduledJobDoc) Scan(value interface{}) error {
+	return jsonbSqlUnmarshal(value, &a)
+}
+
+// Needed for sql.Scanner interface. Normally this is only needed on the "Doc" object but we need this here since we do result only updates.
+func (a *ScheduledJobResult) Value() (driver.Value, error) {
+	return json.Marshal(a)
+}
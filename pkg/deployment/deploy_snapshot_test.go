@@ -42,17 +42,33 @@ func Test_update_snapshot_simple(t *testing.T) {
 				Protocol:      "http",
 				Scope:         model.AppExposeScope_External,
 			},
-			HealthCheck: &model.AppConfigHealthCheck{
-				Path: "/health",
-			},
 			OverrideableAppConfig: model.OverrideableAppConfig{
+				// The knative-serving namespace is allowed without path or method restrictions in the access-allow policy. Since
+				// autoscale.min is 0, calls proxied by the KNative activator bypass this rule (see AppConfig.Access).
+				Access: &model.AppConfigAccess{
+					Allow: []model.AppConfigAccessRule{
+						{Namespace: "billing", App: "payments", Paths: []string{"/api/*"}, Methods: []string{"GET"}},
+					},
+				},
+				HealthCheck: &model.AppConfigHealthCheck{
+					AppConfigProbe: model.AppConfigProbe{Path: "/health"},
+				},
 				Autoscale: &model.AppConfigAutoscale{
 					Min: util.PtrInt(0),
 					Max: util.PtrInt(1),
 				},
+				Concurrency: util.PtrInt64(10),
+				Domains:     []string{"myapp.example.com"},
 				Environment: map[string]intstr.IntOrString{
 					"myenv": intstr.FromString("myval"),
 				},
+				Files: map[string]string{
+					"/etc/myapp/config.yaml": "key: value\n",
+				},
+				SecretFiles: map[string]model.AppConfigSecretFile{
+					"mytlskey": {MountPath: "/etc/myapp/tls.key", Mode: util.PtrInt32(0400)},
+				},
+				TimeoutSeconds: util.PtrInt64(60),
 			},
 		},
 		Traffic: core.TrafficConfig{
@@ -64,7 +80,7 @@ func Test_update_snapshot_simple(t *testing.T) {
 		},
 	}
 
-	secrets := []core.SecretMeta{{Name: "mysecret", Revision: 1}}
+	secrets := []core.SecretMeta{{Name: "mysecret", Revision: 1}, {Name: "mytlskey", Revision: 2}}
 
 	dryRunCommitter := state.NewDryRunCommitter()
 	var committer state.Committer
@@ -94,3 +110,238 @@ func Test_update_snapshot_simple(t *testing.T) {
 		util.AssertSnapshot(t, snapshotDir, dryRunCommitter.Commits[0].Files)
 	}
 }
+
+func Test_update_snapshot_tcp(t *testing.T) {
+	newDeployment := &core.DeploymentConfig{
+		Name:            "mydb",
+		Namespace:       "apps",
+		EnvironmentName: "dev",
+		Docker: core.DeploymentDocker{
+			Tag: "0.0.1",
+		},
+		App: &model.AppConfig{
+			Name:      "mydb",
+			Namespace: "apps",
+			Id:        uuid.MustParse("0c9d6b94-1c4b-4f14-8a4f-5e9f0ab8e1ad"),
+			Image:     "mydb",
+			Expose: &model.AppConfigExpose{
+				ContainerPort: 5432,
+				Protocol:      model.AppExposeProtocol_TCP,
+				Scope:         model.AppExposeScope_Cluster,
+			},
+			OverrideableAppConfig: model.OverrideableAppConfig{
+				HealthCheck: &model.AppConfigHealthCheck{},
+				Autoscale: &model.AppConfigAutoscale{
+					Min: util.PtrInt(2),
+				},
+			},
+		},
+		Traffic: core.TrafficConfig{
+			core.TrafficConfigRule{
+				RiserRevision: 1,
+				RevisionName:  "mydb-1",
+				Percent:       100,
+			},
+		},
+	}
+
+	dryRunCommitter := state.NewDryRunCommitter()
+	var committer state.Committer
+	snapshotDir, err := filepath.Abs("testdata/snapshots/tcp")
+	require.NoError(t, err)
+	if util.ShouldUpdateSnapshot() {
+		fmt.Printf("Updating snapshot for %q", snapshotDir)
+		err = os.RemoveAll(snapshotDir)
+		require.NoError(t, err)
+		committer = state.NewFileCommitter(snapshotDir)
+	} else {
+		committer = dryRunCommitter
+	}
+
+	ctx := &core.DeploymentContext{
+		DeploymentConfig:  newDeployment,
+		EnvironmentConfig: &core.EnvironmentConfig{},
+		RiserRevision:     1,
+	}
+	err = deploy(ctx, committer)
+
+	assert.NoError(t, err)
+	if !util.ShouldUpdateSnapshot() {
+		require.Len(t, dryRunCommitter.Commits, 1)
+		// The other workload resources are removed in case the deployment previously used a KNative protocol or another kind
+		deleted := []string{}
+		for _, file := range dryRunCommitter.Commits[0].Files {
+			if file.Delete {
+				deleted = append(deleted, file.Name)
+			}
+		}
+		assert.Equal(t, []string{
+			"state/dev/riser-managed/apps/deployments/mydb/security.istio.io.authorizationpolicy.mydb-healthcheck-deny.yaml",
+			"state/dev/riser-managed/apps/deployments/mydb/security.istio.io.authorizationpolicy.mydb-access-allow.yaml",
+			"state/dev/riser-managed/apps/deployments/mydb/serving.knative.dev.configuration.mydb.yaml",
+			"state/dev/riser-managed/apps/deployments/mydb/serving.knative.dev.route.mydb.yaml",
+			"state/dev/riser-managed/apps/deployments/mydb/autoscaling.horizontalpodautoscaler.mydb.yaml",
+			"state/dev/riser-managed/apps/deployments/mydb/batch.cronjob.mydb.yaml",
+		}, deleted)
+		util.AssertSnapshot(t, snapshotDir, dryRunCommitter.Commits[0].Files)
+	}
+}
+
+func Test_update_snapshot_worker(t *testing.T) {
+	newDeployment := &core.DeploymentConfig{
+		Name:            "myworker",
+		Namespace:       "apps",
+		EnvironmentName: "dev",
+		Docker: core.DeploymentDocker{
+			Tag: "0.0.1",
+		},
+		App: &model.AppConfig{
+			Name:      "myworker",
+			Namespace: "apps",
+			Id:        uuid.MustParse("7a3e6c1b-2f0d-4e8a-9b5c-1d2e3f4a5b6c"),
+			Kind:      model.AppKind_Worker,
+			Image:     "myworker",
+			OverrideableAppConfig: model.OverrideableAppConfig{
+				Environment: map[string]intstr.IntOrString{
+					"QUEUE": intstr.FromString("orders"),
+				},
+				HealthCheck: &model.AppConfigHealthCheck{
+					AppConfigProbe: model.AppConfigProbe{
+						Mode:    model.AppHealthCheckMode_Exec,
+						Command: []string{"/bin/healthcheck"},
+					},
+				},
+				Autoscale: &model.AppConfigAutoscale{
+					Min:    util.PtrInt(2),
+					Max:    util.PtrInt(5),
+					Target: util.PtrInt(70),
+				},
+			},
+		},
+		Traffic: core.TrafficConfig{
+			core.TrafficConfigRule{
+				RiserRevision: 1,
+				RevisionName:  "myworker-1",
+				Percent:       100,
+			},
+		},
+	}
+
+	dryRunCommitter := state.NewDryRunCommitter()
+	var committer state.Committer
+	snapshotDir, err := filepath.Abs("testdata/snapshots/worker")
+	require.NoError(t, err)
+	if util.ShouldUpdateSnapshot() {
+		fmt.Printf("Updating snapshot for %q", snapshotDir)
+		err = os.RemoveAll(snapshotDir)
+		require.NoError(t, err)
+		committer = state.NewFileCommitter(snapshotDir)
+	} else {
+		committer = dryRunCommitter
+	}
+
+	ctx := &core.DeploymentContext{
+		DeploymentConfig:  newDeployment,
+		EnvironmentConfig: &core.EnvironmentConfig{},
+		RiserRevision:     1,
+		Secrets:           []core.SecretMeta{{Name: "token", Revision: 1}},
+	}
+	err = deploy(ctx, committer)
+
+	assert.NoError(t, err)
+	if !util.ShouldUpdateSnapshot() {
+		require.Len(t, dryRunCommitter.Commits, 1)
+		deleted := []string{}
+		for _, file := range dryRunCommitter.Commits[0].Files {
+			if file.Delete {
+				deleted = append(deleted, file.Name)
+			}
+		}
+		assert.Equal(t, []string{
+			"state/dev/riser-managed/apps/deployments/myworker/security.istio.io.authorizationpolicy.myworker-healthcheck-deny.yaml",
+			"state/dev/riser-managed/apps/deployments/myworker/security.istio.io.authorizationpolicy.myworker-access-allow.yaml",
+			"state/dev/riser-managed/apps/deployments/myworker/serving.knative.dev.configuration.myworker.yaml",
+			"state/dev/riser-managed/apps/deployments/myworker/serving.knative.dev.route.myworker.yaml",
+			"state/dev/riser-managed/apps/deployments/myworker/service.myworker.yaml",
+			"state/dev/riser-managed/apps/deployments/myworker/batch.cronjob.myworker.yaml",
+		}, deleted)
+		util.AssertSnapshot(t, snapshotDir, dryRunCommitter.Commits[0].Files)
+	}
+}
+
+func Test_update_snapshot_scheduledJob(t *testing.T) {
+	newDeployment := &core.DeploymentConfig{
+		Name:            "myreport",
+		Namespace:       "apps",
+		EnvironmentName: "dev",
+		Docker: core.DeploymentDocker{
+			Tag: "0.0.1",
+		},
+		App: &model.AppConfig{
+			Name:      "myreport",
+			Namespace: "apps",
+			Id:        uuid.MustParse("3f6b2d8e-9c1a-4b7e-8d2f-5a6c7b8d9e0f"),
+			Kind:      model.AppKind_ScheduledJob,
+			Image:     "myreport",
+			OverrideableAppConfig: model.OverrideableAppConfig{
+				Environment: map[string]intstr.IntOrString{
+					"REPORT": intstr.FromString("daily"),
+				},
+				Job: &model.AppConfigJob{
+					Schedule:          "0 3 * * *",
+					ConcurrencyPolicy: model.AppJobConcurrencyPolicy_Forbid,
+					BackoffLimit:      util.PtrInt32(2),
+				},
+			},
+		},
+		Traffic: core.TrafficConfig{
+			core.TrafficConfigRule{
+				RiserRevision: 1,
+				RevisionName:  "myreport-1",
+				Percent:       100,
+			},
+		},
+	}
+
+	dryRunCommitter := state.NewDryRunCommitter()
+	var committer state.Committer
+	snapshotDir, err := filepath.Abs("testdata/snapshots/scheduledjob")
+	require.NoError(t, err)
+	if util.ShouldUpdateSnapshot() {
+		fmt.Printf("Updating snapshot for %q", snapshotDir)
+		err = os.RemoveAll(snapshotDir)
+		require.NoError(t, err)
+		committer = state.NewFileCommitter(snapshotDir)
+	} else {
+		committer = dryRunCommitter
+	}
+
+	ctx := &core.DeploymentContext{
+		DeploymentConfig:  newDeployment,
+		EnvironmentConfig: &core.EnvironmentConfig{},
+		RiserRevision:     1,
+		Secrets:           []core.SecretMeta{{Name: "dbpassword", Revision: 1}},
+	}
+	err = deploy(ctx, committer)
+
+	assert.NoError(t, err)
+	if !util.ShouldUpdateSnapshot() {
+		require.Len(t, dryRunCommitter.Commits, 1)
+		deleted := []string{}
+		for _, file := range dryRunCommitter.Commits[0].Files {
+			if file.Delete {
+				deleted = append(deleted, file.Name)
+			}
+		}
+		assert.Equal(t, []string{
+			"state/dev/riser-managed/apps/deployments/myreport/security.istio.io.authorizationpolicy.myreport-healthcheck-deny.yaml",
+			"state/dev/riser-managed/apps/deployments/myreport/security.istio.io.authorizationpolicy.myreport-access-allow.yaml",
+			"state/dev/riser-managed/apps/deployments/myreport/serving.knative.dev.configuration.myreport.yaml",
+			"state/dev/riser-managed/apps/deployments/myreport/serving.knative.dev.route.myreport.yaml",
+			"state/dev/riser-managed/apps/deployments/myreport/apps.deployment.myreport.yaml",
+			"state/dev/riser-managed/apps/deployments/myreport/service.myreport.yaml",
+			"state/dev/riser-managed/apps/deployments/myreport/autoscaling.horizontalpodautoscaler.myreport.yaml",
+		}, deleted)
+		util.AssertSnapshot(t, snapshotDir, dryRunCommitter.Commits[0].Files)
+	}
+}
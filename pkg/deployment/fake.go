@@ -6,15 +6,32 @@ import (
 )
 
 type FakeService struct {
-	DeleteFn        func(name *core.NamespacedName, envName string, committer state.Committer) error
-	DeleteCallCount int
+	UpdateFn            func(deployment *core.DeploymentConfig, committer state.Committer, dryRun bool) (int64, error)
+	UpdateCallCount     int
+	DeleteFn            func(name *core.NamespacedName, envName string, committer state.Committer) error
+	DeleteCallCount     int
+	UpdateManyFn        func(deployments []*core.DeploymentConfig, committer state.Committer, dryRun bool) (map[string]int64, error)
+	UpdateManyCallCount int
+	PlanFn              func(deployments []*core.DeploymentConfig) error
+	PlanCallCount       int
 }
 
 func (f *FakeService) Update(deployment *core.DeploymentConfig, committer state.Committer, dryRun bool) (int64, error) {
-	panic("NI!")
+	f.UpdateCallCount++
+	return f.UpdateFn(deployment, committer, dryRun)
+}
+
+func (f *FakeService) UpdateMany(deployments []*core.DeploymentConfig, committer state.Committer, dryRun bool) (map[string]int64, error) {
+	f.UpdateManyCallCount++
+	return f.UpdateManyFn(deployments, committer, dryRun)
 }
 
 func (f *FakeService) Delete(name *core.NamespacedName, envName string, committer state.Committer) error {
 	f.DeleteCallCount++
 	return f.DeleteFn(name, envName, committer)
 }
+
+func (f *FakeService) Plan(deployments []*core.DeploymentConfig) error {
+	f.PlanCallCount++
+	return f.PlanFn(deployments)
+}
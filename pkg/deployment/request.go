@@ -0,0 +1,81 @@
+package deployment
+
+import (
+	"fmt"
+
+	"github.com/riser-platform/riser-server/api/v1/model"
+	"github.com/riser-platform/riser-server/pkg/core"
+)
+
+// NewDeploymentConfig creates the deployment config of an environment from a deployment request. The environment's app defaults, overrides,
+// and security baseline are applied and the result is validated against the environment's constraints. A scheduled deployment calls this
+// again when it runs so that the environment config at that time is used.
+func NewDeploymentConfig(deploymentRequest *model.DeploymentRequest, envName string, environmentConfig *core.EnvironmentConfig) (*core.DeploymentConfig, error) {
+	newDeployment, err := mapDeploymentRequestToDomain(deploymentRequest, envName, environmentConfig.AppDefaults)
+	if err != nil {
+		return nil, err
+	}
+
+	// The app config is validated before overrides are applied. Validate each environment so that an invalid override is caught
+	// before any changes are made.
+	err = newDeployment.App.Validate()
+	if err != nil {
+		return nil, core.NewValidationError(fmt.Sprintf("invalid app config for environment %q", envName), err)
+	}
+
+	err = validateEnvironmentAppConstraints(newDeployment.App, environmentConfig)
+	if err != nil {
+		return nil, core.NewValidationError(fmt.Sprintf("app config does not meet the constraints of environment %q", envName), err)
+	}
+
+	if environmentConfig.SecurityBaseline != nil {
+		environmentConfig.SecurityBaseline.ApplyDefaults(newDeployment.App)
+		err = environmentConfig.SecurityBaseline.ValidateApp(newDeployment.App)
+		if err != nil {
+			return nil, core.NewValidationError(fmt.Sprintf("app config weakens the security baseline of environment %q", envName), err)
+		}
+	}
+
+	return newDeployment, nil
+}
+
+func validateEnvironmentAppConstraints(app *model.AppConfig, environmentConfig *core.EnvironmentConfig) error {
+	var defaultLimits *model.AppConfigResourceList
+	if environmentConfig.DefaultResources != nil {
+		defaultLimits = environmentConfig.DefaultResources.Limits
+	}
+	err := model.ValidateResourceRequests(app, defaultLimits)
+	if err != nil {
+		return err
+	}
+	err = model.ValidateScheduling(app, environmentConfig.KNativeScheduling)
+	if err != nil || environmentConfig.AppConstraints == nil {
+		return err
+	}
+	return environmentConfig.AppConstraints.ValidateApp(app, defaultLimits)
+}
+
+func mapDeploymentRequestToDomain(deploymentRequest *model.DeploymentRequest, envName string, appDefaults *model.OverrideableAppConfig) (*core.DeploymentConfig, error) {
+	app, err := deploymentRequest.App.ApplyEnvironment(envName, appDefaults)
+	if err != nil {
+		return nil, err
+	}
+	if deploymentRequest.Command != nil {
+		app.Command = deploymentRequest.Command
+	}
+	if deploymentRequest.Args != nil {
+		app.Args = deploymentRequest.Args
+	}
+
+	return &core.DeploymentConfig{
+		Name:            deploymentRequest.Name,
+		Namespace:       string(app.Namespace),
+		EnvironmentName: envName,
+		Docker: core.DeploymentDocker{
+			Tag: deploymentRequest.Docker.Tag,
+		},
+		App:           app,
+		ManualRollout: deploymentRequest.ManualRollout,
+		Metadata:      deploymentRequest.Metadata,
+	}, nil
+}
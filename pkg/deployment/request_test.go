@@ -0,0 +1,235 @@
+package deployment
+
+import (
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v3"
+	"github.com/riser-platform/riser-server/api/v1/model"
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/riser-platform/riser-server/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func Test_mapDeploymentRequestToDomain(t *testing.T) {
+	request := &model.DeploymentRequest{
+		DeploymentMeta: model.DeploymentMeta{
+			Name:        "mydeployment",
+			Environment: "myenv",
+			Docker: model.DeploymentDocker{
+				Tag: "mytag",
+			},
+			ManualRollout: true,
+			Metadata:      map[string]string{"git-sha": "9c2e6c5"},
+		},
+		App: &model.AppConfigWithOverrides{
+			AppConfig: model.AppConfig{
+				Name:      "myapp",
+				Namespace: "myns",
+			},
+		},
+	}
+
+	result, err := mapDeploymentRequestToDomain(request, "myenv", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "mydeployment", result.Name)
+	assert.Equal(t, "myns", result.Namespace)
+	assert.Equal(t, "myenv", result.EnvironmentName)
+	assert.Equal(t, "mytag", result.Docker.Tag)
+	assert.Equal(t, request.App.AppConfig, *result.App)
+	assert.True(t, result.ManualRollout)
+	assert.Equal(t, map[string]string{"git-sha": "9c2e6c5"}, result.Metadata)
+}
+
+func Test_mapDeploymentRequestToDomain_Overrides(t *testing.T) {
+	request := &model.DeploymentRequest{
+		DeploymentMeta: model.DeploymentMeta{
+			Name:        "mydeployment",
+			Environment: "myenv",
+			Docker: model.DeploymentDocker{
+				Tag: "mytag",
+			},
+		},
+		App: &model.AppConfigWithOverrides{
+			AppConfig: model.AppConfig{},
+			Overrides: map[string]model.AppConfigOverride{
+				"myenv": {OverrideableAppConfig: model.OverrideableAppConfig{
+					Autoscale: &model.AppConfigAutoscale{
+						Min: util.PtrInt(1),
+					},
+				}},
+			},
+		},
+	}
+
+	result, err := mapDeploymentRequestToDomain(request, "myenv", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, *result.App.Autoscale.Min)
+
+}
+
+func Test_mapDeploymentRequestToDomain_AppDefaults(t *testing.T) {
+	request := &model.DeploymentRequest{
+		DeploymentMeta: model.DeploymentMeta{
+			Name:        "mydeployment",
+			Environment: "myenv",
+		},
+		App: &model.AppConfigWithOverrides{
+			AppConfig: model.AppConfig{
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Environment: map[string]intstr.IntOrString{"LOG_LEVEL": intstr.FromString("info")},
+				},
+			},
+			Overrides: map[string]model.AppConfigOverride{
+				"myenv": {OverrideableAppConfig: model.OverrideableAppConfig{
+					Environment: map[string]intstr.IntOrString{"TRACING": intstr.FromString("off")},
+				}},
+			},
+		},
+	}
+	appDefaults := &model.OverrideableAppConfig{
+		Autoscale: &model.AppConfigAutoscale{Min: util.PtrInt(2)},
+		Environment: map[string]intstr.IntOrString{
+			"LOG_LEVEL": intstr.FromString("debug"),
+			"TRACING":   intstr.FromString("on"),
+			"REGION":    intstr.FromString("us-east-1"),
+		},
+	}
+
+	result, err := mapDeploymentRequestToDomain(request, "myenv", appDefaults)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, *result.App.Autoscale.Min)
+	assert.Equal(t, "us-east-1", result.App.Environment["REGION"].StrVal)
+	// The app config takes precedence over defaults
+	assert.Equal(t, "info", result.App.Environment["LOG_LEVEL"].StrVal)
+	// Overrides take precedence over defaults
+	assert.Equal(t, "off", result.App.Environment["TRACING"].StrVal)
+	// Neither the request nor the defaults are modified
+	assert.Nil(t, request.App.Autoscale)
+	assert.Len(t, request.App.Environment, 1)
+	assert.Len(t, appDefaults.Environment, 3)
+}
+
+func Test_validateEnvironmentAppConstraints(t *testing.T) {
+	app := &model.AppConfig{}
+	environmentConfig := &core.EnvironmentConfig{
+		DefaultResources: &core.EnvironmentDefaultResources{
+			Limits: &model.AppConfigResourceList{MemoryMB: util.PtrInt32(1024)},
+		},
+		AppConstraints: &model.EnvironmentAppConstraints{
+			Resources: &model.EnvironmentResourceConstraints{
+				MaxLimits: &model.AppConfigResourceList{MemoryMB: util.PtrInt32(512)},
+			},
+		},
+	}
+
+	err := validateEnvironmentAppConstraints(app, environmentConfig)
+
+	// The environment's default limit is used when the app does not specify its own
+	require.IsType(t, validation.Errors{}, err)
+	assert.Equal(t, "must be no greater than 512", err.(validation.Errors)["resources.limits.memoryMB"].Error())
+}
+
+func Test_validateEnvironmentAppConstraints_RequestExceedsDefaultLimit(t *testing.T) {
+	app := &model.AppConfig{
+		OverrideableAppConfig: model.OverrideableAppConfig{
+			Resources: &model.AppConfigResources{
+				Requests: &model.AppConfigResourceList{MemoryMB: util.PtrInt32(2048)},
+			},
+		},
+	}
+	environmentConfig := &core.EnvironmentConfig{
+		DefaultResources: &core.EnvironmentDefaultResources{
+			Limits: &model.AppConfigResourceList{MemoryMB: util.PtrInt32(1024)},
+		},
+	}
+
+	err := validateEnvironmentAppConstraints(app, environmentConfig)
+
+	// Validated even when the environment has no constraints
+	require.IsType(t, validation.Errors{}, err)
+	assert.Equal(t, "must be no greater than the default limit of 1024 unless a memoryMB limit is specified",
+		err.(validation.Errors)["resources.requests.memoryMB"].Error())
+}
+
+func Test_validateEnvironmentAppConstraints_SchedulingNotSupportedByKNative(t *testing.T) {
+	app := &model.AppConfig{
+		Expose: &model.AppConfigExpose{ContainerPort: 8080},
+		OverrideableAppConfig: model.OverrideableAppConfig{
+			Scheduling: &model.AppConfigScheduling{NodeSelector: map[string]string{"node-pool": "highmem"}},
+		},
+	}
+
+	err := validateEnvironmentAppConstraints(app, &core.EnvironmentConfig{})
+
+	require.IsType(t, validation.Errors{}, err)
+	assert.Equal(t, "is not supported for apps served by KNative in this environment", err.(validation.Errors)["scheduling"].Error())
+}
+
+func Test_validateEnvironmentAppConstraints_NoConstraints(t *testing.T) {
+	err := validateEnvironmentAppConstraints(&model.AppConfig{}, &core.EnvironmentConfig{})
+
+	assert.NoError(t, err)
+}
+
+func Test_mapDeploymentRequestToDomain_CommandAndArgs(t *testing.T) {
+	request := &model.DeploymentRequest{
+		DeploymentMeta: model.DeploymentMeta{
+			Name:        "myapp-admin",
+			Environment: "myenv",
+			Args:        []string{"--mode=admin"},
+		},
+		App: &model.AppConfigWithOverrides{
+			AppConfig: model.AppConfig{
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Command: []string{"/bin/server"},
+					Args:    []string{"--mode=web"},
+				},
+			},
+			Overrides: map[string]model.AppConfigOverride{
+				"myenv": {OverrideableAppConfig: model.OverrideableAppConfig{
+					Args: []string{"--mode=web", "--debug"},
+				}},
+			},
+		},
+	}
+
+	result, err := mapDeploymentRequestToDomain(request, "myenv", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/bin/server"}, result.App.Command)
+	assert.Equal(t, []string{"--mode=admin"}, result.App.Args)
+}
+
+func Test_mapDeploymentRequestToDomain_MultipleEnvironments(t *testing.T) {
+	request := &model.DeploymentRequest{
+		DeploymentMeta: model.DeploymentMeta{
+			Name:         "mydeployment",
+			Environments: []string{"dev", "prod"},
+		},
+		App: &model.AppConfigWithOverrides{
+			AppConfig: model.AppConfig{},
+			Overrides: map[string]model.AppConfigOverride{
+				"prod": {OverrideableAppConfig: model.OverrideableAppConfig{
+					Autoscale: &model.AppConfigAutoscale{
+						Min: util.PtrInt(2),
+					},
+				}},
+			},
+		},
+	}
+
+	devResult, err := mapDeploymentRequestToDomain(request, "dev", nil)
+	assert.NoError(t, err)
+	prodResult, err := mapDeploymentRequestToDomain(request, "prod", nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "dev", devResult.EnvironmentName)
+	assert.Nil(t, devResult.App.Autoscale)
+	assert.Equal(t, "prod", prodResult.EnvironmentName)
+	assert.Equal(t, 2, *prodResult.App.Autoscale.Min)
+}
@@ -3,9 +3,15 @@ package deployment
 import (
 	"fmt"
 	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/riser-platform/riser-server/api/v1/model"
+	"github.com/riser-platform/riser-server/pkg/admission"
 	"github.com/riser-platform/riser-server/pkg/deploymentreservation"
+	"github.com/riser-platform/riser-server/pkg/domainreservation"
 	"github.com/riser-platform/riser-server/pkg/namespace"
 
 	validation "github.com/go-ozzo/ozzo-validation/v3"
@@ -15,21 +21,32 @@ import (
 	"github.com/riser-platform/riser-server/pkg/state/resources"
 
 	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/riser-platform/riser-server/pkg/state"
 )
 
 type Service interface {
 	Update(deployment *core.DeploymentConfig, committer state.Committer, dryRun bool) (riserRevision int64, err error)
+	// UpdateMany updates a deployment in multiple environments using a single atomic commit. Each deployment must target a different
+	// environment. If any environment fails, no changes are committed or persisted.
+	UpdateMany(deployments []*core.DeploymentConfig, committer state.Committer, dryRun bool) (riserRevisions map[string]int64, err error)
 	Delete(name *core.NamespacedName, envName string, committer state.Committer) error
+	// Plan validates and admits each deployment as a dry run without making any changes. The deployments are not modified.
+	Plan(deployments []*core.DeploymentConfig) error
 }
 
+// previousRevisionLimit is the number of previous revisions considered when removing resources that are no longer used
+const previousRevisionLimit = 10
+
 type service struct {
 	namespaceService   namespace.Service
 	secrets            core.SecretMetaRepository
 	environments       core.EnvironmentRepository
 	deployments        core.DeploymentRepository
 	reservationService deploymentreservation.Service
+	domainService      domainreservation.Service
+	admissionService   admission.Service
 }
 
 func NewService(
@@ -38,13 +55,23 @@ func NewService(
 	secrets core.SecretMetaRepository,
 	environments core.EnvironmentRepository,
 	deployments core.DeploymentRepository,
-	reservationService deploymentreservation.Service) Service {
-	return &service{namespaceService, secrets, environments, deployments, reservationService}
+	reservationService deploymentreservation.Service,
+	domainService domainreservation.Service,
+	admissionService admission.Service) Service {
+	return &service{namespaceService, secrets, environments, deployments, reservationService, domainService, admissionService}
 }
 
 func (s *service) Delete(name *core.NamespacedName, envName string, committer state.Committer) error {
+	deployment, err := s.deployments.GetByName(name, envName)
+	if err != nil {
+		if err == core.ErrNotFound {
+			return core.NewValidationErrorMessage(fmt.Sprintf("There is no deployment by the name %q in environment %q", name, envName))
+		}
+		return errors.Wrap(err, "error retrieving deployment")
+	}
+
 	// Deleting the deployment is safe to do before we perform the commit since it's a soft delete and therefore idempotent
-	err := s.deployments.Delete(name, envName)
+	err = s.deployments.Delete(name, envName)
 	if err != nil {
 		if err == core.ErrNotFound {
 			return core.NewValidationErrorMessage(fmt.Sprintf("There is no deployment by the name %q in environment %q", name, envName))
@@ -53,7 +80,14 @@ func (s *service) Delete(name *core.NamespacedName, envName string, committer st
 	}
 
 	files := state.RenderDeleteDeployment(name.Name, name.Namespace, envName)
-	return committer.Commit(fmt.Sprintf("Deleting deployment %q", name), files)
+	err = committer.Commit(fmt.Sprintf("Deleting deployment %q", name), files)
+	if err != nil {
+		return err
+	}
+
+	// TODO: Log release error but don't return since the deployment has been deleted
+	_ = s.releaseUnusedDomains(deployment.AppId)
+	return nil
 }
 
 func (s *service) Update(deploymentConfig *core.DeploymentConfig, committer state.Committer, dryRun bool) (riserRevision int64, err error) {
@@ -62,119 +96,442 @@ func (s *service) Update(deploymentConfig *core.DeploymentConfig, committer stat
 		return 0, err
 	}
 
-	riserRevision, err = s.prepareForDeployment(deploymentConfig, dryRun)
+	plan, err := s.planDeployment(deploymentConfig, dryRun)
 	if err != nil {
 		return 0, err
 	}
-	environment, err := s.environments.Get(deploymentConfig.EnvironmentName)
+
+	err = s.applyDeployment(plan)
+	if err == nil {
+		err = deploy(plan.ctx, committer)
+		if err != nil {
+			s.rollbackDeployment(plan)
+		}
+	}
+	if !dryRun {
+		// TODO: Log release error but don't return since we want the original deployment error to flow to caller
+		_ = s.releaseUnusedDomains(deploymentConfig.App.Id)
+	}
 	if err != nil {
 		return 0, err
 	}
 
-	secrets, err := s.secrets.ListByAppInEnvironment(core.NewNamespacedName(deploymentConfig.Name, deploymentConfig.Namespace), deploymentConfig.EnvironmentName)
+	return plan.ctx.RiserRevision, nil
+}
+
+func (s *service) UpdateMany(deploymentConfigs []*core.DeploymentConfig, committer state.Committer, dryRun bool) (riserRevisions map[string]int64, err error) {
+	// Validate all environments up front so that we catch as many problems as possible before making any changes
+	envNames := []string{}
+	for _, deploymentConfig := range deploymentConfigs {
+		if err := validateDeploymentConfig(deploymentConfig); err != nil {
+			return nil, err
+		}
+		_, err = s.environments.Get(deploymentConfig.EnvironmentName)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("Error retrieving environment %q", deploymentConfig.EnvironmentName))
+		}
+		envNames = append(envNames, fmt.Sprintf("%q", deploymentConfig.EnvironmentName))
+	}
+
+	batchCommitter := state.NewBatchCommitter(committer)
+	// Every environment is planned and admitted before any changes are persisted
+	plans := []*deploymentPlan{}
+	for _, deploymentConfig := range deploymentConfigs {
+		err = s.namespaceService.EnsureNamespaceInEnvironment(deploymentConfig.Namespace, deploymentConfig.EnvironmentName, batchCommitter)
+		if err != nil {
+			return nil, err
+		}
+		plan, err := s.planDeployment(deploymentConfig, dryRun)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, plan)
+	}
+
+	err = s.deployAll(plans, batchCommitter, fmt.Sprintf("Updating resources for \"%s.%s\" in environments %s",
+		deploymentConfigs[0].Name, deploymentConfigs[0].Namespace, strings.Join(envNames, ", ")))
+	if !dryRun {
+		// TODO: Log release error but don't return since we want the original deployment error to flow to caller
+		_ = s.releaseUnusedDomains(deploymentConfigs[0].App.Id)
+	}
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+
+	riserRevisions = map[string]int64{}
+	for _, plan := range plans {
+		riserRevisions[plan.ctx.DeploymentConfig.EnvironmentName] = plan.ctx.RiserRevision
+	}
+	return riserRevisions, nil
+}
+
+func (s *service) Plan(deploymentConfigs []*core.DeploymentConfig) error {
+	for _, deploymentConfig := range deploymentConfigs {
+		// planDeployment computes the traffic of the deployment config so plan a copy
+		planned := *deploymentConfig
+		_, err := s.planDeployment(&planned, true)
+		if err != nil {
+			return err
+		}
 	}
-	ctx := &core.DeploymentContext{
-		DeploymentConfig:  deploymentConfig,
-		EnvironmentConfig: &environment.Doc.Config,
-		RiserRevision:     riserRevision,
-		Secrets:           secrets,
+	return nil
+}
+
+// deployAll applies and deploys each plan in a single commit. The plans are rolled back when any plan fails.
+func (s *service) deployAll(plans []*deploymentPlan, batchCommitter *state.BatchCommitter, message string) error {
+	for idx, plan := range plans {
+		err := s.applyDeployment(plan)
+		if err != nil {
+			s.rollbackDeployments(plans[:idx])
+			return err
+		}
 	}
-	err = deploy(ctx, committer)
+
+	for _, plan := range plans {
+		err := deploy(plan.ctx, batchCommitter)
+		if err != nil {
+			s.rollbackDeployments(plans)
+			return err
+		}
+	}
+
+	err := batchCommitter.Flush(message)
 	if err != nil {
-		// TODO: Log rollback error but don't return since we want the original deployment error to flow to caller
-		_, _ = s.deployments.RollbackRevision(
-			core.NewNamespacedName(deploymentConfig.Name, deploymentConfig.Namespace), deploymentConfig.EnvironmentName, riserRevision)
-		return 0, err
+		s.rollbackDeployments(plans)
+		return err
 	}
+	return nil
+}
 
-	return riserRevision, nil
+func (s *service) rollbackDeployments(plans []*deploymentPlan) {
+	for _, plan := range plans {
+		s.rollbackDeployment(plan)
+	}
 }
 
-func (s *service) prepareForDeployment(deploymentConfig *core.DeploymentConfig, dryRun bool) (riserRevision int64, err error) {
+// deploymentPlan is a deployment that has been validated and admitted but not yet persisted
+type deploymentPlan struct {
+	ctx          *core.DeploymentContext
+	deploymentId uuid.UUID
+	// existingDeployment is nil when the deployment has never existed in the environment
+	existingDeployment *core.Deployment
+	pendingTraffic     core.TrafficConfig
+	dryRun             bool
+}
+
+// planDeployment builds and admits the deployment context without changing any state so that a rejected deployment leaves
+// no trace. The riser revision is always "0" for a dry-run of an existing deployment.
+func (s *service) planDeployment(deploymentConfig *core.DeploymentConfig, dryRun bool) (*deploymentPlan, error) {
 	if err := validateDeploymentConfig(deploymentConfig); err != nil {
-		return 0, err
+		return nil, err
+	}
+
+	referencedDeployments, err := s.resolveReferencedDeployments(deploymentConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// Not wrapped so that a domain owned by another app is returned to the client as a validation error
+	err = s.domainService.EnsureReservations(deploymentConfig.App.Id, deploymentConfig.App.Domains, true)
+	if err != nil {
+		return nil, err
 	}
 
-	reservation, err := s.reservationService.EnsureReservation(
-		deploymentConfig.App.Id,
-		core.NewNamespacedName(deploymentConfig.Name, deploymentConfig.Namespace))
+	err = s.validateDomainsNotUsed(deploymentConfig)
 	if err != nil {
-		return 0, errors.Wrap(err, "Error ensuring deployment reservation")
+		return nil, err
 	}
 
-	existingDeployment, err := s.deployments.GetByReservation(reservation.Id, deploymentConfig.EnvironmentName)
+	name := core.NewNamespacedName(deploymentConfig.Name, deploymentConfig.Namespace)
+	plan := &deploymentPlan{dryRun: dryRun}
+	existingDeployment, err := s.deployments.GetByName(name, deploymentConfig.EnvironmentName)
 	if err != nil && err != core.ErrNotFound {
-		return 0, errors.Wrap(err, fmt.Sprintf("Error retrieving deployment %q in environment %q", deploymentConfig.Name, deploymentConfig.EnvironmentName))
+		return nil, errors.Wrap(err, fmt.Sprintf("Error retrieving deployment %q in environment %q", deploymentConfig.Name, deploymentConfig.EnvironmentName))
 	}
+	var riserRevision int64
+	// admittedRevision is the revision that the deployment has once applied. A dry run does not increment the revision.
+	var admittedRevision int64
+	var previousRevisions []core.DeploymentRevision
 	if err == core.ErrNotFound {
 		riserRevision = 1
-		deploymentConfig.Traffic = computeTraffic(riserRevision, deploymentConfig, nil)
+		admittedRevision = riserRevision
+		plan.deploymentId = uuid.New()
+		deploymentConfig.Traffic, plan.pendingTraffic = computeDeploymentTraffic(riserRevision, deploymentConfig, nil)
+	} else if existingDeployment.AppId != deploymentConfig.App.Id {
+		return nil, &core.ValidationError{Message: fmt.Sprintf("A deployment with the name %q is owned by app %q", deploymentConfig.Name, existingDeployment.AppId)}
+	} else {
+		plan.existingDeployment = existingDeployment
+		plan.deploymentId = existingDeployment.DeploymentRecord.Id
+		admittedRevision = existingDeployment.RiserRevision + 1
+		if !dryRun {
+			riserRevision = admittedRevision
+		}
+
+		// When a deployment was previously deleted, we don't want to compute traffic with the old traffic rules
+		if existingDeployment.DeletedAt == nil {
+			deploymentConfig.Traffic, plan.pendingTraffic = computeDeploymentTraffic(riserRevision, deploymentConfig, &existingDeployment.DeploymentRecord)
+			previousRevisions, err = s.deployments.FindRevisions(plan.deploymentId, previousRevisionLimit)
+			if err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("Error retrieving revisions for deployment %q in environment %q", deploymentConfig.Name, deploymentConfig.EnvironmentName))
+			}
+		} else {
+			deploymentConfig.Traffic, plan.pendingTraffic = computeDeploymentTraffic(riserRevision, deploymentConfig, nil)
+		}
+	}
+
+	environment, err := s.environments.Get(deploymentConfig.EnvironmentName)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets, err := s.secrets.ListByAppInEnvironment(name, deploymentConfig.EnvironmentName)
+	if err != nil {
+		return nil, err
+	}
+
+	plan.ctx = &core.DeploymentContext{
+		DeploymentConfig:      deploymentConfig,
+		EnvironmentConfig:     &environment.Doc.Config,
+		RiserRevision:         riserRevision,
+		Secrets:               secrets,
+		ReferencedDeployments: referencedDeployments,
+		PreviousRevisions:     previousRevisions,
+	}
+	// Admission is evaluated against the final deployment context so that dry runs report violations as well. The context is copied so
+	// that a dry run is admitted with the revision of a real deployment without changing the revision it renders.
+	admissionCtx := *plan.ctx
+	admissionCtx.RiserRevision = admittedRevision
+	err = s.admissionService.Admit(&admissionCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// applyDeployment persists a deployment plan. Nothing is persisted during a dry run.
+func (s *service) applyDeployment(plan *deploymentPlan) error {
+	if plan.dryRun {
+		return nil
+	}
+
+	deploymentConfig := plan.ctx.DeploymentConfig
+	name := core.NewNamespacedName(deploymentConfig.Name, deploymentConfig.Namespace)
+	reservation, err := s.reservationService.EnsureReservation(deploymentConfig.App.Id, name)
+	if err != nil {
+		return errors.Wrap(err, "Error ensuring deployment reservation")
+	}
+
+	// Not wrapped so that a domain owned by another app is returned to the client as a validation error
+	err = s.domainService.EnsureReservations(deploymentConfig.App.Id, deploymentConfig.App.Domains, false)
+	if err != nil {
+		return err
+	}
+
+	if plan.existingDeployment == nil {
 		err = s.deployments.Create(&core.DeploymentRecord{
-			Id:              uuid.New(),
+			Id:              plan.deploymentId,
 			ReservationId:   reservation.Id,
 			EnvironmentName: deploymentConfig.EnvironmentName,
-			RiserRevision:   riserRevision,
+			RiserRevision:   plan.ctx.RiserRevision,
 			Doc: core.DeploymentDoc{
 				Traffic: deploymentConfig.Traffic,
 			},
 		})
 		if err != nil {
-			return 0, errors.Wrap(err, fmt.Sprintf("Error creating deployment %q in environment %q", deploymentConfig.Name, deploymentConfig.EnvironmentName))
+			return errors.Wrap(err, fmt.Sprintf("Error creating deployment %q in environment %q", deploymentConfig.Name, deploymentConfig.EnvironmentName))
 		}
-	} else if existingDeployment.AppId != deploymentConfig.App.Id {
-		return 0, &core.ValidationError{Message: fmt.Sprintf("A deployment with the name %q is owned by app %q", deploymentConfig.Name, existingDeployment.AppId)}
 	} else {
-		if !dryRun {
-			riserRevision, err = s.deployments.IncrementRevision(
-				core.NewNamespacedName(deploymentConfig.Name, deploymentConfig.Namespace), deploymentConfig.EnvironmentName)
-			if err != nil {
-				return 0, errors.Wrap(err, "Error incrementing deployment revision")
-			}
+		riserRevision, err := s.deployments.IncrementRevision(name, deploymentConfig.EnvironmentName)
+		if err != nil {
+			return errors.Wrap(err, "Error incrementing deployment revision")
+		}
+		// The traffic and the admitted deployment context refer to the planned revision
+		if riserRevision != plan.ctx.RiserRevision {
+			// TODO: Log rollback error but don't return since we want the original deployment error to flow to caller
+			_, _ = s.deployments.RollbackRevision(name, deploymentConfig.EnvironmentName, riserRevision)
+			return errors.New("Deployment has been updated by another process")
 		}
 
-		// When a deployment was previously deleted, we don't want to compute traffic with the old traffic rules
-		if existingDeployment.DeletedAt == nil {
-			deploymentConfig.Traffic = computeTraffic(riserRevision, deploymentConfig, &existingDeployment.DeploymentRecord)
-		} else {
-			deploymentConfig.Traffic = computeTraffic(riserRevision, deploymentConfig, nil)
+		err = s.deployments.UpdateTraffic(name, deploymentConfig.EnvironmentName, plan.ctx.RiserRevision, deploymentConfig.Traffic)
+		if err != nil {
+			s.rollbackDeployment(plan)
+			return errors.Wrap(err, "Error updating traffic")
 		}
+	}
 
-		if !dryRun {
-			err = s.deployments.UpdateTraffic(
-				core.NewNamespacedName(deploymentConfig.Name, deploymentConfig.Namespace),
-				deploymentConfig.EnvironmentName,
-				riserRevision,
-				deploymentConfig.Traffic)
+	err = s.deployments.SaveRevision(&core.DeploymentRevision{
+		DeploymentId:  plan.deploymentId,
+		RiserRevision: plan.ctx.RiserRevision,
+		Doc: core.DeploymentRevisionDoc{
+			CreatedAt:      time.Now().UTC(),
+			DockerTag:      deploymentConfig.Docker.Tag,
+			Metadata:       deploymentConfig.Metadata,
+			ExposeScope:    exposeScope(deploymentConfig),
+			Kind:           deploymentConfig.App.KindOrDefault(),
+			PendingTraffic: plan.pendingTraffic,
+			ConfigMaps:     fileConfigMapNames(plan.ctx),
+			Domains:        append([]string{}, deploymentConfig.App.Domains...),
+		},
+	})
+	if err != nil {
+		s.rollbackDeployment(plan)
+		return errors.Wrap(err, "Error saving deployment revision")
+	}
+
+	return nil
+}
+
+// releaseUnusedDomains releases the app's domain reservations that are not used by the latest revision of any of the app's deployments
+func (s *service) releaseUnusedDomains(appId uuid.UUID) error {
+	deployments, err := s.deployments.FindByApp(appId)
+	if err != nil {
+		return errors.Wrap(err, "Error retrieving deployments")
+	}
+	revisions, err := s.deployments.FindRevisionsByApp(appId, 1)
+	if err != nil {
+		return errors.Wrap(err, "Error retrieving deployment revisions")
+	}
+	latestRevisions := map[uuid.UUID]core.DeploymentRevision{}
+	for _, revision := range revisions {
+		latestRevisions[revision.DeploymentId] = revision
+	}
+
+	usedDomains := []string{}
+	for _, deployment := range deployments {
+		revision, ok := latestRevisions[deployment.DeploymentRecord.Id]
+		// The domains of a deployment are unknown when its latest revision was recorded before domains were tracked
+		if !ok || revision.Doc.Domains == nil {
+			return nil
+		}
+		usedDomains = append(usedDomains, revision.Doc.Domains...)
+	}
+	return s.domainService.ReleaseUnusedReservations(appId, usedDomains)
+}
+
+// rollbackDeployment restores the state of a deployment from before its plan was applied
+func (s *service) rollbackDeployment(plan *deploymentPlan) {
+	if plan.dryRun {
+		return
+	}
+
+	// TODO: Log rollback errors but don't return since we want the original deployment error to flow to caller
+	deploymentConfig := plan.ctx.DeploymentConfig
+	name := core.NewNamespacedName(deploymentConfig.Name, deploymentConfig.Namespace)
+	_, _ = s.deployments.RollbackRevision(name, deploymentConfig.EnvironmentName, plan.ctx.RiserRevision)
+	_ = s.deployments.DeleteRevision(plan.deploymentId, plan.ctx.RiserRevision)
+	if plan.existingDeployment == nil || plan.existingDeployment.DeletedAt != nil {
+		_ = s.deployments.Delete(name, deploymentConfig.EnvironmentName)
+	} else {
+		_ = s.deployments.UpdateTraffic(name, deploymentConfig.EnvironmentName, plan.existingDeployment.RiserRevision, plan.existingDeployment.Doc.Traffic)
+	}
+}
+
+// resolveReferencedDeployments returns each deployment referenced by the app's env vars (e.g. ${app:mydeployment.mynamespace.url}).
+// A referenced deployment must exist in the same environment and must expose a service.
+func (s *service) resolveReferencedDeployments(deploymentConfig *core.DeploymentConfig) ([]core.ReferencedDeployment, error) {
+	envKeys := []string{}
+	for key := range deploymentConfig.App.Environment {
+		envKeys = append(envKeys, key)
+	}
+	sort.Strings(envKeys)
+
+	referencedDeployments := []core.ReferencedDeployment{}
+	seen := map[core.NamespacedName]bool{}
+	for _, key := range envKeys {
+		val := deploymentConfig.App.Environment[key]
+		for _, reference := range model.FindAppReferences(val.String()) {
+			name := core.NewNamespacedName(reference.DeploymentName, reference.Namespace)
+			if seen[*name] {
+				continue
+			}
+			seen[*name] = true
+
+			deployment, err := s.deployments.GetByName(name, deploymentConfig.EnvironmentName)
+			if err == core.ErrNotFound || (err == nil && deployment.DeletedAt != nil) {
+				return nil, core.NewValidationErrorMessage(fmt.Sprintf("The env var %q references the deployment %q which does not exist in environment %q",
+					key, name, deploymentConfig.EnvironmentName))
+			}
 			if err != nil {
-				return 0, errors.Wrap(err, "Error updating traffic")
+				return nil, errors.Wrap(err, fmt.Sprintf("Error retrieving deployment %q in environment %q", name, deploymentConfig.EnvironmentName))
 			}
+
+			revisions, err := s.deployments.FindRevisions(deployment.DeploymentRecord.Id, 1)
+			if err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("Error retrieving revisions for deployment %q in environment %q", name, deploymentConfig.EnvironmentName))
+			}
+			// Revisions recorded before the expose scope was tracked use the default scope
+			scope := model.AppExposeScope_External
+			if len(revisions) > 0 {
+				kind := revisions[0].Doc.Kind
+				if kind == model.AppKind_Worker || kind == model.AppKind_ScheduledJob {
+					return nil, core.NewValidationErrorMessage(fmt.Sprintf("The env var %q references the deployment %q which is a %s and does not expose a service",
+						key, name, kind))
+				}
+				if revisions[0].Doc.ExposeScope != "" {
+					scope = revisions[0].Doc.ExposeScope
+				}
+			}
+
+			referencedDeployments = append(referencedDeployments, core.ReferencedDeployment{
+				Name:        name.Name,
+				Namespace:   name.Namespace,
+				ExposeScope: scope,
+			})
 		}
 	}
 
-	return riserRevision, nil
+	return referencedDeployments, nil
 }
 
-func computeTraffic(riserRevision int64, deploymentConfig *core.DeploymentConfig, existingDeployment *core.DeploymentRecord) core.TrafficConfig {
-	newRule := core.TrafficConfigRule{
-		RiserRevision: riserRevision,
-		RevisionName:  fmt.Sprintf("%s-%d", deploymentConfig.Name, riserRevision),
+func exposeScope(deploymentConfig *core.DeploymentConfig) string {
+	if deploymentConfig.App.Expose == nil {
+		return ""
 	}
+	return deploymentConfig.App.Expose.Scope
+}
+
+// computeDeploymentTraffic returns the traffic for the new revision. An app with a pre-deploy job keeps the traffic of the existing
+// revisions until the job succeeds, at which point the pending traffic is applied (see rollout.Service.ApplyPreDeployTraffic).
+func computeDeploymentTraffic(riserRevision int64, deploymentConfig *core.DeploymentConfig, existingDeployment *core.DeploymentRecord) (traffic, pendingTraffic core.TrafficConfig) {
+	traffic = computeTraffic(riserRevision, deploymentConfig, existingDeployment)
+	// A manual rollout already keeps the traffic of the existing revisions
+	if deploymentConfig.App.PreDeploy == nil || (deploymentConfig.ManualRollout && existingDeployment != nil) {
+		return traffic, nil
+	}
+	return holdTraffic(newTrafficRule(riserRevision, deploymentConfig), existingDeployment), traffic
+}
+
+func computeTraffic(riserRevision int64, deploymentConfig *core.DeploymentConfig, existingDeployment *core.DeploymentRecord) core.TrafficConfig {
+	newRule := newTrafficRule(riserRevision, deploymentConfig)
 
 	if deploymentConfig.ManualRollout && existingDeployment != nil {
-		newRule.Percent = 0
-		trafficConfig := core.TrafficConfig{newRule}
+		return holdTraffic(newRule, existingDeployment)
+	}
+
+	newRule.Percent = 100
+	return core.TrafficConfig{newRule}
+}
+
+// holdTraffic keeps the traffic of the existing revisions. The new revision does not receive any traffic.
+func holdTraffic(newRule core.TrafficConfigRule, existingDeployment *core.DeploymentRecord) core.TrafficConfig {
+	newRule.Percent = 0
+	trafficConfig := core.TrafficConfig{newRule}
+	if existingDeployment != nil {
 		for _, rule := range existingDeployment.Doc.Traffic {
 			if rule.Percent > 0 {
 				trafficConfig = append(trafficConfig, rule)
 			}
 		}
-		return trafficConfig
 	}
+	return trafficConfig
+}
 
-	newRule.Percent = 100
-	return core.TrafficConfig{newRule}
+func newTrafficRule(riserRevision int64, deploymentConfig *core.DeploymentConfig) core.TrafficConfigRule {
+	return core.TrafficConfigRule{
+		RiserRevision: riserRevision,
+		RevisionName:  fmt.Sprintf("%s-%d", deploymentConfig.Name, riserRevision),
+	}
 }
 
 // This is a one-off validation until we rationalize our validation strategy (API layer or service layer).
@@ -198,14 +555,266 @@ func deploy(ctx *core.DeploymentContext, committer state.Committer) error {
 	if err != nil {
 		return err
 	}
+	resourceFiles = append(resourceFiles, state.RenderDeleteDeploymentResources(ctx.DeploymentConfig, createStaleDeployResources(ctx)...)...)
+
+	appFiles, err := state.RenderApp(string(ctx.DeploymentConfig.App.Name), ctx.DeploymentConfig.Namespace, ctx.DeploymentConfig.EnvironmentName,
+		resources.CreateAppServiceAccount(ctx))
+	if err != nil {
+		return err
+	}
+	resourceFiles = append(resourceFiles, appFiles...)
 
 	return committer.Commit(fmt.Sprintf("Updating resources for \"%s.%s\" in environment %q", ctx.DeploymentConfig.Name, ctx.DeploymentConfig.Namespace, ctx.DeploymentConfig.EnvironmentName), resourceFiles)
 }
 
 func createDeployResources(ctx *core.DeploymentContext) []state.KubeResource {
-	return []state.KubeResource{
+	deployResources := []state.KubeResource{
 		resources.CreateHealthcheckDenyPolicy(ctx),
-		resources.CreateKNativeConfiguration(ctx),
-		resources.CreateKNativeRoute(ctx),
+		resources.CreateAccessAllowPolicy(ctx),
+		resources.CreatePreDeployJob(ctx),
+	}
+	deployResources = append(deployResources, createWorkloadResources(ctx)...)
+	for _, configMap := range resources.CreateFileConfigMaps(ctx) {
+		deployResources = append(deployResources, configMap)
+	}
+	for _, domainMapping := range resources.CreateDomainMappings(ctx) {
+		deployResources = append(deployResources, domainMapping)
+	}
+	for _, certificate := range resources.CreateDomainCertificates(ctx) {
+		deployResources = append(deployResources, certificate)
+	}
+	return deployResources
+}
+
+// workloadKinds are the kinds of every resource that may be created by createWorkloadResources
+var workloadKinds = []metav1.TypeMeta{
+	{APIVersion: "serving.knative.dev/v1", Kind: "Configuration"},
+	{APIVersion: "serving.knative.dev/v1", Kind: "Route"},
+	{APIVersion: "apps/v1", Kind: "Deployment"},
+	{APIVersion: "v1", Kind: "Service"},
+	{APIVersion: "autoscaling/v2beta2", Kind: "HorizontalPodAutoscaler"},
+	{APIVersion: "batch/v1beta1", Kind: "CronJob"},
+}
+
+// domainKinds are the kinds of the resources created for each custom domain. Each resource is named after its domain.
+var domainKinds = []metav1.TypeMeta{
+	{APIVersion: "serving.knative.dev/v1alpha1", Kind: "DomainMapping"},
+	{APIVersion: "cert-manager.io/v1", Kind: "Certificate"},
+}
+
+// createStaleDeployResources returns the resources no longer used by the deployment so that they are removed when the deployment
+// changes (e.g. when changing to the tcp protocol or to another kind, or when removing a file, a domain or access rules). Only the
+// metadata is needed to remove a resource.
+func createStaleDeployResources(ctx *core.DeploymentContext) []state.KubeResource {
+	current := map[string]bool{}
+	for _, resource := range createWorkloadResources(ctx) {
+		current[resource.GetObjectKind().GroupVersionKind().Kind] = true
+	}
+
+	staleResources := []state.KubeResource{}
+	// A Job cannot be updated so each revision's pre-deploy job is a new Job. The job of the previous revision is no longer needed.
+	if ctx.RiserRevision > 1 {
+		staleResources = append(staleResources, &metav1.PartialObjectMetadata{
+			TypeMeta: metav1.TypeMeta{APIVersion: "batch/v1", Kind: "Job"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      resources.PreDeployJobName(ctx.DeploymentConfig.Name, ctx.RiserRevision-1),
+				Namespace: ctx.DeploymentConfig.Namespace,
+			},
+		})
+	}
+	// The DENY policy is only created when the app has an httpGet probe so it must be removed when the app no longer has one (e.g. when
+	// changing to the tcp protocol or to the worker kind).
+	if resources.CreateHealthcheckDenyPolicy(ctx) == nil {
+		staleResources = append(staleResources, &metav1.PartialObjectMetadata{
+			TypeMeta: metav1.TypeMeta{APIVersion: "security.istio.io/v1beta1", Kind: "AuthorizationPolicy"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      resources.HealthcheckDenyPolicyName(ctx.DeploymentConfig.Name),
+				Namespace: ctx.DeploymentConfig.Namespace,
+			},
+		})
+	}
+	// The ALLOW policy is only created when the app allows access (or the environment requires default deny) so it must be removed when
+	// access is removed, otherwise the old rules would keep being enforced.
+	if resources.CreateAccessAllowPolicy(ctx) == nil {
+		staleResources = append(staleResources, &metav1.PartialObjectMetadata{
+			TypeMeta: metav1.TypeMeta{APIVersion: "security.istio.io/v1beta1", Kind: "AuthorizationPolicy"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      resources.AccessAllowPolicyName(ctx.DeploymentConfig.Name),
+				Namespace: ctx.DeploymentConfig.Namespace,
+			},
+		})
+	}
+	for _, name := range staleConfigMapNames(ctx) {
+		staleResources = append(staleResources, &metav1.PartialObjectMetadata{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: ctx.DeploymentConfig.Namespace,
+			},
+		})
+	}
+	for _, domain := range staleDomains(ctx) {
+		for _, typeMeta := range domainKinds {
+			staleResources = append(staleResources, &metav1.PartialObjectMetadata{
+				TypeMeta: typeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      domain,
+					Namespace: ctx.DeploymentConfig.Namespace,
+				},
+			})
+		}
+	}
+	for _, kind := range workloadKinds {
+		if current[kind.Kind] {
+			continue
+		}
+		staleResources = append(staleResources, &metav1.PartialObjectMetadata{
+			TypeMeta: kind,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      ctx.DeploymentConfig.Name,
+				Namespace: ctx.DeploymentConfig.Namespace,
+			},
+		})
+	}
+	return staleResources
+}
+
+// validateDomainsNotUsed rejects a domain that is used by another deployment of the app in the same environment. Domains are reserved
+// per app but the resources of a domain are named after the domain, so two deployments using the same domain would overwrite each other.
+func (s *service) validateDomainsNotUsed(deploymentConfig *core.DeploymentConfig) error {
+	if len(deploymentConfig.App.Domains) == 0 {
+		return nil
+	}
+
+	deployments, err := s.deployments.FindByApp(deploymentConfig.App.Id)
+	if err != nil {
+		return errors.Wrap(err, "Error retrieving deployments")
+	}
+
+	otherDeployments := map[uuid.UUID]string{}
+	for _, deployment := range deployments {
+		if deployment.EnvironmentName == deploymentConfig.EnvironmentName && deployment.Name != deploymentConfig.Name {
+			otherDeployments[deployment.DeploymentRecord.Id] = deployment.Name
+		}
+	}
+	if len(otherDeployments) == 0 {
+		return nil
+	}
+
+	revisions, err := s.deployments.FindRevisionsByApp(deploymentConfig.App.Id, 1)
+	if err != nil {
+		return errors.Wrap(err, "Error retrieving deployment revisions")
+	}
+
+	domains := map[string]bool{}
+	for _, domain := range deploymentConfig.App.Domains {
+		domains[domain] = true
+	}
+	for _, revision := range revisions {
+		otherDeploymentName, ok := otherDeployments[revision.DeploymentId]
+		if !ok {
+			continue
+		}
+		for _, domain := range revision.Doc.Domains {
+			if domains[domain] {
+				return core.NewValidationErrorMessage(
+					fmt.Sprintf("The domain %q is already used by the deployment %q in environment %q", domain, otherDeploymentName, deploymentConfig.EnvironmentName))
+			}
+		}
+	}
+
+	return nil
+}
+
+// staleDomains returns the domains of previous revisions that are no longer used
+func staleDomains(ctx *core.DeploymentContext) []string {
+	seen := map[string]bool{}
+	for _, domain := range ctx.DeploymentConfig.App.Domains {
+		seen[domain] = true
+	}
+	staleDomains := []string{}
+	for _, revision := range ctx.PreviousRevisions {
+		for _, domain := range revision.Doc.Domains {
+			if !seen[domain] {
+				seen[domain] = true
+				staleDomains = append(staleDomains, domain)
+			}
+		}
+	}
+	return staleDomains
+}
+
+func fileConfigMapNames(ctx *core.DeploymentContext) []string {
+	names := []string{}
+	for _, configMap := range resources.CreateFileConfigMaps(ctx) {
+		names = append(names, configMap.Name)
+	}
+	return names
+}
+
+// staleConfigMapNames returns the ConfigMaps of previous revisions that are no longer used. A ConfigMap is used when it's used by this
+// revision or by a revision that's still routed by the traffic config.
+func staleConfigMapNames(ctx *core.DeploymentContext) []string {
+	used := map[string]bool{}
+	for _, name := range fileConfigMapNames(ctx) {
+		used[name] = true
+	}
+	routedRevisions := map[int64]bool{}
+	for _, rule := range ctx.DeploymentConfig.Traffic {
+		routedRevisions[rule.RiserRevision] = true
+	}
+	for _, revision := range ctx.PreviousRevisions {
+		if routedRevisions[revision.RiserRevision] {
+			for _, name := range revision.Doc.ConfigMaps {
+				used[name] = true
+			}
+		}
+	}
+
+	staleNames := []string{}
+	for _, revision := range ctx.PreviousRevisions {
+		for _, name := range revision.Doc.ConfigMaps {
+			if !used[name] {
+				used[name] = true
+				staleNames = append(staleNames, name)
+			}
+		}
+	}
+	return staleNames
+}
+
+func createWorkloadResources(ctx *core.DeploymentContext) []state.KubeResource {
+	app := ctx.DeploymentConfig.App
+	switch {
+	case app.KindOrDefault() == model.AppKind_ScheduledJob:
+		return []state.KubeResource{resources.CreateCronJob(ctx)}
+	case app.KindOrDefault() == model.AppKind_Worker:
+		workloadResources := []state.KubeResource{resources.CreateDeployment(ctx)}
+		if hpa := resources.CreateHorizontalPodAutoscaler(ctx); hpa != nil {
+			workloadResources = append(workloadResources, hpa)
+		}
+		return workloadResources
+	// KNative cannot serve the tcp protocol
+	case app.Expose.Protocol == model.AppExposeProtocol_TCP:
+		return []state.KubeResource{
+			resources.CreateDeployment(ctx),
+			resources.CreateKubeService(ctx),
+		}
+	default:
+		workloadResources := []state.KubeResource{resources.CreateKNativeConfiguration(ctx)}
+		// A route requires traffic. A new deployment with a pre-deploy job does not have a route until the job succeeds.
+		if hasTraffic(ctx.DeploymentConfig.Traffic) {
+			workloadResources = append(workloadResources, resources.CreateKNativeRoute(ctx))
+		}
+		return workloadResources
+	}
+}
+
+func hasTraffic(traffic core.TrafficConfig) bool {
+	for _, rule := range traffic {
+		if rule.Percent > 0 {
+			return true
+		}
 	}
+	return false
 }
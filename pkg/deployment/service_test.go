@@ -1,9 +1,13 @@
 package deployment
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/riser-platform/riser-server/pkg/admission"
 	"github.com/riser-platform/riser-server/pkg/deploymentreservation"
+	"github.com/riser-platform/riser-server/pkg/domainreservation"
+	"github.com/riser-platform/riser-server/pkg/namespace"
 	"github.com/riser-platform/riser-server/pkg/state"
 
 	"github.com/google/uuid"
@@ -12,26 +16,48 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/riser-platform/riser-server/api/v1/model"
 	"github.com/riser-platform/riser-server/pkg/core"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // Note: See snapshot_test for state based testing of deployment artifacts
 
 func Test_Delete(t *testing.T) {
 	name := core.NewNamespacedName("mydep", "apps")
+	appId := uuid.New()
 	deploymentRepository := &core.FakeDeploymentRepository{
+		GetByNameFn: func(nameArg *core.NamespacedName, envName string) (*core.Deployment, error) {
+			assert.Equal(t, name, nameArg)
+			assert.Equal(t, "myenv", envName)
+			return &core.Deployment{DeploymentReservation: core.DeploymentReservation{AppId: appId}}, nil
+		},
 		DeleteFn: func(nameArg *core.NamespacedName, envName string) error {
 			assert.Equal(t, name, nameArg)
 			assert.Equal(t, "myenv", envName)
 			return nil
 		},
+		FindByAppFn: func(appIdArg uuid.UUID) ([]core.Deployment, error) {
+			assert.Equal(t, appId, appIdArg)
+			return []core.Deployment{}, nil
+		},
+		FindRevisionsByAppFn: func(uuid.UUID, int) ([]core.DeploymentRevision, error) {
+			return []core.DeploymentRevision{}, nil
+		},
+	}
+	domainService := &domainreservation.FakeService{
+		ReleaseUnusedReservationsFn: func(appIdArg uuid.UUID, usedDomains []string) error {
+			assert.Equal(t, appId, appIdArg)
+			assert.Empty(t, usedDomains)
+			return nil
+		},
 	}
 
 	committer := state.NewDryRunCommitter()
 
-	service := service{deployments: deploymentRepository}
+	service := service{deployments: deploymentRepository, domainService: domainService}
 
 	err := service.Delete(name, "myenv", committer)
 
@@ -44,10 +70,15 @@ func Test_Delete(t *testing.T) {
 	assert.True(t, committer.Commits[0].Files[0].Delete)
 	assert.Equal(t, "riser-config/myenv/apps/mydep.yaml", committer.Commits[0].Files[1].Name)
 	assert.True(t, committer.Commits[0].Files[1].Delete)
+	// The deployment's domains are released once no other deployment uses them
+	assert.Equal(t, 1, domainService.ReleaseUnusedReservationsCallCount)
 }
 
 func Test_Delete_SoftDeleteFails(t *testing.T) {
 	deploymentRepository := &core.FakeDeploymentRepository{
+		GetByNameFn: func(*core.NamespacedName, string) (*core.Deployment, error) {
+			return &core.Deployment{}, nil
+		},
 		DeleteFn: func(*core.NamespacedName, string) error {
 			return errors.New("test")
 		},
@@ -64,8 +95,8 @@ func Test_Delete_SoftDeleteFails(t *testing.T) {
 
 func Test_Delete_DeploymentNotFound(t *testing.T) {
 	deploymentRepository := &core.FakeDeploymentRepository{
-		DeleteFn: func(*core.NamespacedName, string) error {
-			return core.ErrNotFound
+		GetByNameFn: func(*core.NamespacedName, string) (*core.Deployment, error) {
+			return nil, core.ErrNotFound
 		},
 	}
 
@@ -75,57 +106,66 @@ func Test_Delete_DeploymentNotFound(t *testing.T) {
 
 	assert.Equal(t, `There is no deployment by the name "mydep.myns" in environment "myenv"`, err.Error())
 	assert.IsType(t, &core.ValidationError{}, err)
+	assert.Equal(t, 0, deploymentRepository.DeleteCallCount)
 }
 
-func Test_prepareForDeployment_whenNewDeploymentCreates(t *testing.T) {
-	deployment := &core.DeploymentConfig{
-		Name:            "myapp-mydep",
-		Namespace:       "myns",
-		EnvironmentName: "myenv",
-		App: &model.AppConfig{
-			Id:   uuid.New(),
-			Name: "myapp",
-		},
+func Test_releaseUnusedDomains(t *testing.T) {
+	appId := uuid.New()
+	deployments := []core.Deployment{
+		{DeploymentRecord: core.DeploymentRecord{Id: uuid.New()}},
+		{DeploymentRecord: core.DeploymentRecord{Id: uuid.New()}},
 	}
-
-	reservation := &core.DeploymentReservation{
-		Id: uuid.New(),
+	deploymentRepository := &core.FakeDeploymentRepository{
+		FindByAppFn: func(uuid.UUID) ([]core.Deployment, error) {
+			return deployments, nil
+		},
+		FindRevisionsByAppFn: func(appIdArg uuid.UUID, limit int) ([]core.DeploymentRevision, error) {
+			assert.Equal(t, appId, appIdArg)
+			assert.Equal(t, 1, limit)
+			return []core.DeploymentRevision{
+				{DeploymentId: deployments[0].DeploymentRecord.Id, Doc: core.DeploymentRevisionDoc{Domains: []string{"a.example.com"}}},
+				{DeploymentId: deployments[1].DeploymentRecord.Id, Doc: core.DeploymentRevisionDoc{Domains: []string{"b.example.com"}}},
+			}, nil
+		},
 	}
-
-	reservationService := &deploymentreservation.FakeService{
-		EnsureReservationFn: func(appIdArg uuid.UUID, nameArg *core.NamespacedName) (*core.DeploymentReservation, error) {
-			assert.Equal(t, deployment.App.Id, appIdArg)
-			assert.Equal(t, core.NewNamespacedName(deployment.Name, deployment.Namespace), nameArg)
-			return reservation, nil
+	domainService := &domainreservation.FakeService{
+		ReleaseUnusedReservationsFn: func(appIdArg uuid.UUID, usedDomains []string) error {
+			assert.Equal(t, appId, appIdArg)
+			assert.Equal(t, []string{"a.example.com", "b.example.com"}, usedDomains)
+			return nil
 		},
 	}
 
+	service := service{deployments: deploymentRepository, domainService: domainService}
+
+	err := service.releaseUnusedDomains(appId)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, domainService.ReleaseUnusedReservationsCallCount)
+}
+
+func Test_releaseUnusedDomains_UntrackedDomains(t *testing.T) {
+	deploymentId := uuid.New()
 	deploymentRepository := &core.FakeDeploymentRepository{
-		GetByReservationFn: func(reservationId uuid.UUID, envNameArg string) (*core.Deployment, error) {
-			assert.Equal(t, reservation.Id, reservationId)
-			assert.Equal(t, "myenv", envNameArg)
-			return nil, core.ErrNotFound
+		FindByAppFn: func(uuid.UUID) ([]core.Deployment, error) {
+			return []core.Deployment{{DeploymentRecord: core.DeploymentRecord{Id: deploymentId}}}, nil
 		},
-		CreateFn: func(deploymentArg *core.DeploymentRecord) error {
-			assert.NotEqual(t, uuid.Nil, deploymentArg.Id)
-			assert.Equal(t, reservation.Id, deploymentArg.ReservationId)
-			assert.Equal(t, "myenv", deploymentArg.EnvironmentName)
-			assert.Equal(t, int64(1), deploymentArg.RiserRevision)
-			return nil
+		FindRevisionsByAppFn: func(uuid.UUID, int) ([]core.DeploymentRevision, error) {
+			// Recorded before domains were tracked
+			return []core.DeploymentRevision{{DeploymentId: deploymentId}}, nil
 		},
 	}
+	domainService := &domainreservation.FakeService{}
+
+	service := service{deployments: deploymentRepository, domainService: domainService}
 
-	service := service{deployments: deploymentRepository, reservationService: reservationService}
-	result, err := service.prepareForDeployment(deployment, false)
+	err := service.releaseUnusedDomains(uuid.New())
 
 	assert.NoError(t, err)
-	assert.Equal(t, "myns", deployment.Namespace)
-	assert.Equal(t, int64(1), result)
-	assert.Equal(t, 1, deploymentRepository.GetByReservationCallCount)
-	assert.Equal(t, 1, deploymentRepository.CreateCallCount)
+	assert.Equal(t, 0, domainService.ReleaseUnusedReservationsCallCount)
 }
 
-func Test_prepareForDeployment_whenExistingDeployment(t *testing.T) {
+func Test_planDeployment_NewDeployment(t *testing.T) {
 	deployment := &core.DeploymentConfig{
 		Name:            "myapp-mydep",
 		Namespace:       "myns",
@@ -136,61 +176,29 @@ func Test_prepareForDeployment_whenExistingDeployment(t *testing.T) {
 		},
 	}
 
-	deploymentId := uuid.New()
-	reservation := core.DeploymentReservation{
-		Id:        uuid.New(),
-		AppId:     deployment.App.Id,
-		Name:      deployment.Name,
-		Namespace: deployment.Namespace,
-	}
-
-	reservationService := &deploymentreservation.FakeService{
-		EnsureReservationFn: func(appIdArg uuid.UUID, nameArg *core.NamespacedName) (*core.DeploymentReservation, error) {
-			return &reservation, nil
-		},
-	}
-
 	deploymentRepository := &core.FakeDeploymentRepository{
-		GetByReservationFn: func(reservationId uuid.UUID, envNameArg string) (*core.Deployment, error) {
-			return &core.Deployment{
-				DeploymentReservation: reservation,
-				DeploymentRecord: core.DeploymentRecord{
-					Id:              deploymentId,
-					ReservationId:   reservation.Id,
-					EnvironmentName: "myenv"}}, nil
-		},
-		IncrementRevisionFn: func(name *core.NamespacedName, envName string) (int64, error) {
-			assert.Equal(t, "myapp-mydep", name.Name)
-			assert.Equal(t, "myns", name.Namespace)
-			assert.Equal(t, "myenv", envName)
-			return 3, nil
-		},
-		UpdateTrafficFn: func(name *core.NamespacedName, envName string, riserRevision int64, traffic core.TrafficConfig) error {
-			assert.Equal(t, "myapp-mydep", name.Name)
-			assert.Equal(t, "myns", name.Namespace)
-			assert.Equal(t, "myenv", envName)
-			assert.Len(t, traffic, 1)
-			assert.Equal(t, int64(3), traffic[0].RiserRevision)
-			assert.Equal(t, "myapp-mydep-3", traffic[0].RevisionName)
-			assert.Equal(t, 100, traffic[0].Percent)
-			return nil
+		GetByNameFn: func(nameArg *core.NamespacedName, envNameArg string) (*core.Deployment, error) {
+			assert.Equal(t, core.NewNamespacedName("myapp-mydep", "myns"), nameArg)
+			assert.Equal(t, "myenv", envNameArg)
+			return nil, core.ErrNotFound
 		},
 	}
 
-	service := service{deployments: deploymentRepository, reservationService: reservationService}
-	result, err := service.prepareForDeployment(deployment, false)
+	service := newPlanTestService(deploymentRepository, nil)
+	result, err := service.planDeployment(deployment, false)
 
 	assert.NoError(t, err)
-	assert.Equal(t, int64(3), result)
-	assert.Equal(t, 1, deploymentRepository.GetByReservationCallCount)
-	assert.Equal(t, 1, deploymentRepository.IncrementRevisionCallCount)
-	assert.Equal(t, 1, deploymentRepository.UpdateTrafficCallCount)
-	assert.Equal(t, 0, deploymentRepository.CreateCallCount)
+	assert.Nil(t, result.existingDeployment)
+	assert.NotEqual(t, uuid.Nil, result.deploymentId)
+	assert.Equal(t, int64(1), result.ctx.RiserRevision)
+	assert.Equal(t, deployment, result.ctx.DeploymentConfig)
+	require.Len(t, deployment.Traffic, 1)
+	assert.Equal(t, "myapp-mydep-1", deployment.Traffic[0].RevisionName)
+	assert.Equal(t, 100, deployment.Traffic[0].Percent)
+	assert.Equal(t, 1, deploymentRepository.GetByNameCallCount)
 }
 
-// If a manual rollout is requested for a previously deleted deployment, don't try to update traffic rules with
-// the old deployment as they will not be valid. ManualRollout is effectively ignored in this case.
-func Test_prepareForDeployment_manualRollout_previouslyDeletedDeployment(t *testing.T) {
+func Test_planDeployment_ExistingDeployment(t *testing.T) {
 	deployment := &core.DeploymentConfig{
 		Name:            "myapp-mydep",
 		Namespace:       "myns",
@@ -199,80 +207,38 @@ func Test_prepareForDeployment_manualRollout_previouslyDeletedDeployment(t *test
 			Id:   uuid.New(),
 			Name: "myapp",
 		},
-		ManualRollout: true,
 	}
-
-	deploymentId := uuid.New()
-	reservation := core.DeploymentReservation{
-		Id:        uuid.New(),
-		AppId:     deployment.App.Id,
-		Name:      deployment.Name,
-		Namespace: deployment.Namespace,
+	existingDeployment := &core.Deployment{
+		DeploymentReservation: core.DeploymentReservation{AppId: deployment.App.Id},
+		DeploymentRecord:      core.DeploymentRecord{Id: uuid.New(), RiserRevision: 2},
 	}
 
-	reservationService := &deploymentreservation.FakeService{
-		EnsureReservationFn: func(appIdArg uuid.UUID, nameArg *core.NamespacedName) (*core.DeploymentReservation, error) {
-			assert.Equal(t, deployment.App.Id, appIdArg)
-			assert.Equal(t, core.NewNamespacedName(deployment.Name, deployment.Namespace), nameArg)
-			return &reservation, nil
-		},
-	}
+	previousRevisions := []core.DeploymentRevision{{DeploymentId: existingDeployment.DeploymentRecord.Id, RiserRevision: 2}}
 
 	deploymentRepository := &core.FakeDeploymentRepository{
-		GetByReservationFn: func(reservationId uuid.UUID, envNameArg string) (*core.Deployment, error) {
-			deletedAt := time.Now()
-			return &core.Deployment{
-				DeploymentReservation: reservation,
-				DeploymentRecord: core.DeploymentRecord{
-					Id:              deploymentId,
-					ReservationId:   reservation.Id,
-					EnvironmentName: "myenv",
-					DeletedAt:       &deletedAt,
-					Doc: core.DeploymentDoc{
-						// This rule should be ignored since the deployment was previously deleted
-						Traffic: core.TrafficConfig{
-							core.TrafficConfigRule{
-								RevisionName:  "myapp-mydep-2",
-								Percent:       100,
-								RiserRevision: 2,
-							},
-						},
-					},
-				},
-			}, nil
-		},
-		IncrementRevisionFn: func(name *core.NamespacedName, envName string) (int64, error) {
-			assert.Equal(t, "myapp-mydep", name.Name)
-			assert.Equal(t, "myns", name.Namespace)
-			assert.Equal(t, "myenv", envName)
-			return 3, nil
+		GetByNameFn: func(*core.NamespacedName, string) (*core.Deployment, error) {
+			return existingDeployment, nil
 		},
-		UpdateTrafficFn: func(name *core.NamespacedName, envName string, riserRevision int64, traffic core.TrafficConfig) error {
-			assert.Equal(t, "myapp-mydep", name.Name)
-			assert.Equal(t, "myns", name.Namespace)
-			assert.Equal(t, "myenv", envName)
-			// Even though a manual rollout is requested, a previously deleted deployment is treated as if there are no previous traffic rules
-			// Therefore we route all traffic to the new revision.
-			assert.Len(t, traffic, 1)
-			assert.Equal(t, int64(3), traffic[0].RiserRevision)
-			assert.Equal(t, "myapp-mydep-3", traffic[0].RevisionName)
-			assert.Equal(t, 100, traffic[0].Percent)
-			return nil
+		FindRevisionsFn: func(deploymentId uuid.UUID, limit int) ([]core.DeploymentRevision, error) {
+			assert.Equal(t, existingDeployment.DeploymentRecord.Id, deploymentId)
+			assert.Equal(t, 10, limit)
+			return previousRevisions, nil
 		},
 	}
 
-	service := service{deployments: deploymentRepository, reservationService: reservationService}
-	result, err := service.prepareForDeployment(deployment, false)
+	service := newPlanTestService(deploymentRepository, nil)
+	result, err := service.planDeployment(deployment, false)
 
 	assert.NoError(t, err)
-	assert.Equal(t, int64(3), result)
-	assert.Equal(t, 1, deploymentRepository.GetByReservationCallCount)
-	assert.Equal(t, 1, deploymentRepository.IncrementRevisionCallCount)
-	assert.Equal(t, 1, deploymentRepository.UpdateTrafficCallCount)
-	assert.Equal(t, 0, deploymentRepository.CreateCallCount)
+	assert.Equal(t, existingDeployment, result.existingDeployment)
+	assert.Equal(t, existingDeployment.DeploymentRecord.Id, result.deploymentId)
+	assert.Equal(t, int64(3), result.ctx.RiserRevision)
+	assert.Equal(t, previousRevisions, result.ctx.PreviousRevisions)
+	require.Len(t, deployment.Traffic, 1)
+	assert.Equal(t, "myapp-mydep-3", deployment.Traffic[0].RevisionName)
 }
 
-func Test_prepareForDeployment_whenIncrementRevisionFails(t *testing.T) {
+func Test_planDeployment_whenOwnedByAnotherApp(t *testing.T) {
 	deployment := &core.DeploymentConfig{
 		Name:            "myapp-mydep",
 		EnvironmentName: "myenv",
@@ -281,43 +247,23 @@ func Test_prepareForDeployment_whenIncrementRevisionFails(t *testing.T) {
 			Name: "myapp",
 		},
 	}
-
-	deploymentId := uuid.New()
-	reservation := core.DeploymentReservation{
-		Id:        uuid.New(),
-		AppId:     deployment.App.Id,
-		Name:      deployment.Name,
-		Namespace: deployment.Namespace,
-	}
-
-	reservationService := &deploymentreservation.FakeService{
-		EnsureReservationFn: func(appIdArg uuid.UUID, nameArg *core.NamespacedName) (*core.DeploymentReservation, error) {
-			return &reservation, nil
-		},
-	}
+	otherAppId := uuid.New()
 
 	deploymentRepository := &core.FakeDeploymentRepository{
-		GetByReservationFn: func(reservationId uuid.UUID, envNameArg string) (*core.Deployment, error) {
-			return &core.Deployment{
-				DeploymentReservation: reservation,
-				DeploymentRecord: core.DeploymentRecord{
-					Id:              deploymentId,
-					ReservationId:   reservation.Id,
-					EnvironmentName: "myenv"}}, nil
-		},
-		IncrementRevisionFn: func(name *core.NamespacedName, envName string) (int64, error) {
-			return 0, errors.New("test")
+		GetByNameFn: func(*core.NamespacedName, string) (*core.Deployment, error) {
+			return &core.Deployment{DeploymentReservation: core.DeploymentReservation{AppId: otherAppId}}, nil
 		},
 	}
 
-	service := service{deployments: deploymentRepository, reservationService: reservationService}
-	result, err := service.prepareForDeployment(deployment, false)
+	service := newPlanTestService(deploymentRepository, nil)
+	result, err := service.planDeployment(deployment, false)
 
-	assert.Zero(t, result)
-	assert.Equal(t, "Error incrementing deployment revision: test", err.Error())
+	assert.Nil(t, result)
+	require.IsType(t, &core.ValidationError{}, err)
+	assert.Equal(t, fmt.Sprintf(`A deployment with the name "myapp-mydep" is owned by app %q`, otherAppId), err.Error())
 }
 
-func Test_prepareForDeployment_doesNotUpdateWhenDryRun(t *testing.T) {
+func Test_planDeployment_doesNotIncrementRevisionWhenDryRun(t *testing.T) {
 	deployment := &core.DeploymentConfig{
 		Name:            "myapp-mydep",
 		EnvironmentName: "myenv",
@@ -327,41 +273,25 @@ func Test_prepareForDeployment_doesNotUpdateWhenDryRun(t *testing.T) {
 		},
 	}
 
-	deploymentId := uuid.New()
-	reservation := core.DeploymentReservation{
-		Id:        uuid.New(),
-		AppId:     deployment.App.Id,
-		Name:      deployment.Name,
-		Namespace: deployment.Namespace,
-	}
-
-	reservationService := &deploymentreservation.FakeService{
-		EnsureReservationFn: func(appIdArg uuid.UUID, nameArg *core.NamespacedName) (*core.DeploymentReservation, error) {
-			return &reservation, nil
-		},
-	}
-
 	deploymentRepository := &core.FakeDeploymentRepository{
-		GetByReservationFn: func(reservationId uuid.UUID, envNameArg string) (*core.Deployment, error) {
+		GetByNameFn: func(*core.NamespacedName, string) (*core.Deployment, error) {
 			return &core.Deployment{
-				DeploymentReservation: reservation,
-				DeploymentRecord: core.DeploymentRecord{
-					Id:              deploymentId,
-					ReservationId:   reservation.Id,
-					EnvironmentName: "myenv"}}, nil
+				DeploymentReservation: core.DeploymentReservation{AppId: deployment.App.Id},
+				DeploymentRecord:      core.DeploymentRecord{Id: uuid.New(), RiserRevision: 2},
+			}, nil
+		},
+		FindRevisionsFn: func(uuid.UUID, int) ([]core.DeploymentRevision, error) {
+			return []core.DeploymentRevision{}, nil
 		},
 	}
 
-	service := service{deployments: deploymentRepository, reservationService: reservationService}
-	result, err := service.prepareForDeployment(deployment, true)
+	service := newPlanTestService(deploymentRepository, nil)
+	result, err := service.planDeployment(deployment, true)
 
 	assert.NoError(t, err)
 	// The RiserRevision is always "0" for a dry-run
-	assert.Equal(t, int64(0), result)
-	assert.Equal(t, 1, deploymentRepository.GetByReservationCallCount)
-	assert.Equal(t, 0, deploymentRepository.IncrementRevisionCallCount)
-	assert.Equal(t, 0, deploymentRepository.UpdateTrafficCallCount)
-	assert.Equal(t, 0, deploymentRepository.CreateCallCount)
+	assert.Equal(t, int64(0), result.ctx.RiserRevision)
+	assert.True(t, result.dryRun)
 	// Traffic should still be computed in a dry-run, just not persisted
 	assert.Len(t, deployment.Traffic, 1)
 	assert.Equal(t, int64(0), deployment.Traffic[0].RiserRevision)
@@ -369,7 +299,7 @@ func Test_prepareForDeployment_doesNotUpdateWhenDryRun(t *testing.T) {
 	assert.Equal(t, 100, deployment.Traffic[0].Percent)
 }
 
-func Test_prepareForDeployment_whenUpdateTrafficFails(t *testing.T) {
+func Test_planDeployment_admitsNextRevisionWhenDryRun(t *testing.T) {
 	deployment := &core.DeploymentConfig{
 		Name:            "myapp-mydep",
 		EnvironmentName: "myenv",
@@ -379,45 +309,35 @@ func Test_prepareForDeployment_whenUpdateTrafficFails(t *testing.T) {
 		},
 	}
 
-	deploymentId := uuid.New()
-	reservation := core.DeploymentReservation{
-		Id:        uuid.New(),
-		AppId:     deployment.App.Id,
-		Name:      deployment.Name,
-		Namespace: deployment.Namespace,
-	}
-
-	reservationService := &deploymentreservation.FakeService{
-		EnsureReservationFn: func(appIdArg uuid.UUID, nameArg *core.NamespacedName) (*core.DeploymentReservation, error) {
-			return &reservation, nil
-		},
-	}
-
 	deploymentRepository := &core.FakeDeploymentRepository{
-		GetByReservationFn: func(reservationId uuid.UUID, envNameArg string) (*core.Deployment, error) {
+		GetByNameFn: func(*core.NamespacedName, string) (*core.Deployment, error) {
 			return &core.Deployment{
-				DeploymentReservation: reservation,
-				DeploymentRecord: core.DeploymentRecord{
-					Id:              deploymentId,
-					ReservationId:   reservation.Id,
-					EnvironmentName: "myenv"}}, nil
+				DeploymentReservation: core.DeploymentReservation{AppId: deployment.App.Id},
+				DeploymentRecord:      core.DeploymentRecord{Id: uuid.New(), RiserRevision: 2},
+			}, nil
 		},
-		IncrementRevisionFn: func(name *core.NamespacedName, envName string) (int64, error) {
-			return 1, nil
+		FindRevisionsFn: func(uuid.UUID, int) ([]core.DeploymentRevision, error) {
+			return []core.DeploymentRevision{}, nil
 		},
-		UpdateTrafficFn: func(*core.NamespacedName, string, int64, core.TrafficConfig) error {
-			return errors.New("broke")
+	}
+	admissionService := &admission.FakeService{
+		AdmitFn: func(ctx *core.DeploymentContext) error {
+			// The same revision as a real deployment so that policies using the revision have the same result
+			assert.EqualValues(t, 3, ctx.RiserRevision)
+			return nil
 		},
 	}
 
-	service := service{deployments: deploymentRepository, reservationService: reservationService}
-	result, err := service.prepareForDeployment(deployment, false)
+	service := newPlanTestService(deploymentRepository, admissionService)
+	result, err := service.planDeployment(deployment, true)
 
-	assert.Zero(t, result)
-	assert.Equal(t, "Error updating traffic: broke", err.Error())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, admissionService.AdmitCallCount)
+	assert.Equal(t, int64(0), result.ctx.RiserRevision)
+	assert.Equal(t, 0, deploymentRepository.IncrementRevisionCallCount)
 }
 
-func Test_prepareForDeployment_whenEnsureReservationErr(t *testing.T) {
+func Test_Plan(t *testing.T) {
 	deployment := &core.DeploymentConfig{
 		Name:            "myapp-mydep",
 		EnvironmentName: "myenv",
@@ -427,95 +347,670 @@ func Test_prepareForDeployment_whenEnsureReservationErr(t *testing.T) {
 		},
 	}
 
-	reservationService := &deploymentreservation.FakeService{
-		EnsureReservationFn: func(appIdArg uuid.UUID, nameArg *core.NamespacedName) (*core.DeploymentReservation, error) {
-			return nil, errors.New("test")
+	deploymentRepository := &core.FakeDeploymentRepository{
+		GetByNameFn: func(*core.NamespacedName, string) (*core.Deployment, error) {
+			return nil, core.ErrNotFound
+		},
+	}
+	admissionService := &admission.FakeService{
+		AdmitFn: func(ctx *core.DeploymentContext) error {
+			return &core.ValidationError{Message: "rejected"}
 		},
 	}
 
-	service := service{reservationService: reservationService}
-	result, err := service.prepareForDeployment(deployment, false)
+	service := newPlanTestService(deploymentRepository, admissionService)
+	err := service.Plan([]*core.DeploymentConfig{deployment})
 
-	assert.Zero(t, result)
-	assert.Equal(t, `Error ensuring deployment reservation: test`, err.Error())
+	assert.Equal(t, "rejected", err.Error())
+	assert.Equal(t, 1, admissionService.AdmitCallCount)
+	assert.Nil(t, deployment.Traffic)
+	assert.Equal(t, 0, deploymentRepository.CreateCallCount)
 }
 
-func Test_prepareForDeployment_whenGetFails(t *testing.T) {
+// If a manual rollout is requested for a previously deleted deployment, don't try to update traffic rules with
+// the old deployment as they will not be valid. ManualRollout is effectively ignored in this case.
+func Test_planDeployment_manualRollout_previouslyDeletedDeployment(t *testing.T) {
 	deployment := &core.DeploymentConfig{
 		Name:            "myapp-mydep",
+		Namespace:       "myns",
 		EnvironmentName: "myenv",
 		App: &model.AppConfig{
+			Id:   uuid.New(),
 			Name: "myapp",
 		},
-	}
-
-	reservation := core.DeploymentReservation{
-		Id:        uuid.New(),
-		AppId:     deployment.App.Id,
-		Name:      deployment.Name,
-		Namespace: deployment.Namespace,
-	}
-
-	reservationService := &deploymentreservation.FakeService{
-		EnsureReservationFn: func(appIdArg uuid.UUID, nameArg *core.NamespacedName) (*core.DeploymentReservation, error) {
-			return &reservation, nil
-		},
+		ManualRollout: true,
 	}
 
 	deploymentRepository := &core.FakeDeploymentRepository{
-		GetByReservationFn: func(uuid.UUID, string) (*core.Deployment, error) {
-			return nil, errors.New("test")
+		GetByNameFn: func(*core.NamespacedName, string) (*core.Deployment, error) {
+			deletedAt := time.Now()
+			return &core.Deployment{
+				DeploymentReservation: core.DeploymentReservation{AppId: deployment.App.Id},
+				DeploymentRecord: core.DeploymentRecord{
+					Id:              uuid.New(),
+					EnvironmentName: "myenv",
+					RiserRevision:   2,
+					DeletedAt:       &deletedAt,
+					Doc: core.DeploymentDoc{
+						// This rule should be ignored since the deployment was previously deleted
+						Traffic: core.TrafficConfig{
+							core.TrafficConfigRule{
+								RevisionName:  "myapp-mydep-2",
+								Percent:       100,
+								RiserRevision: 2,
+							},
+						},
+					},
+				},
+			}, nil
 		},
 	}
 
-	service := service{deployments: deploymentRepository, reservationService: reservationService}
-	result, err := service.prepareForDeployment(deployment, false)
+	service := newPlanTestService(deploymentRepository, nil)
+	result, err := service.planDeployment(deployment, false)
 
-	assert.Zero(t, result)
-	assert.Equal(t, `Error retrieving deployment "myapp-mydep" in environment "myenv": test`, err.Error())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), result.ctx.RiserRevision)
+	// Even though a manual rollout is requested, a previously deleted deployment is treated as if there are no previous traffic rules
+	// Therefore we route all traffic to the new revision.
+	assert.Len(t, deployment.Traffic, 1)
+	assert.Equal(t, int64(3), deployment.Traffic[0].RiserRevision)
+	assert.Equal(t, "myapp-mydep-3", deployment.Traffic[0].RevisionName)
+	assert.Equal(t, 100, deployment.Traffic[0].Percent)
 }
 
-func Test_prepareForDeployment_whenCreateFails(t *testing.T) {
+func Test_planDeployment_whenDomainReservedByAnotherApp(t *testing.T) {
 	deployment := &core.DeploymentConfig{
-		Name:            "myapp-mydep",
+		Name:            "myapp",
+		Namespace:       "myns",
 		EnvironmentName: "myenv",
 		App: &model.AppConfig{
+			Id:   uuid.New(),
 			Name: "myapp",
+			OverrideableAppConfig: model.OverrideableAppConfig{
+				Domains: []string{"myapp.example.com"},
+			},
 		},
 	}
 
-	reservation := core.DeploymentReservation{
-		Id:        uuid.New(),
-		AppId:     deployment.App.Id,
-		Name:      deployment.Name,
-		Namespace: deployment.Namespace,
+	expectedErr := core.NewValidationErrorMessage("test")
+	domainService := &domainreservation.FakeService{
+		EnsureReservationsFn: func(appIdArg uuid.UUID, domainsArg []string, dryRunArg bool) error {
+			assert.Equal(t, deployment.App.Id, appIdArg)
+			assert.Equal(t, []string{"myapp.example.com"}, domainsArg)
+			// Domains are only checked while planning
+			assert.True(t, dryRunArg)
+			return expectedErr
+		},
 	}
 
-	reservationService := &deploymentreservation.FakeService{
-		EnsureReservationFn: func(appIdArg uuid.UUID, nameArg *core.NamespacedName) (*core.DeploymentReservation, error) {
-			return &reservation, nil
+	service := service{domainService: domainService}
+	result, err := service.planDeployment(deployment, false)
+
+	assert.Nil(t, result)
+	// Must not be wrapped so that the API returns a validation error
+	assert.Equal(t, expectedErr, err)
+	assert.Equal(t, 1, domainService.EnsureReservationsCallCount)
+}
+
+func Test_planDeployment_whenDomainUsedByAnotherDeployment(t *testing.T) {
+	appId := uuid.New()
+	canaryId := uuid.New()
+	otherEnvId := uuid.New()
+	deployment := &core.DeploymentConfig{
+		Name:            "myapp",
+		Namespace:       "myns",
+		EnvironmentName: "myenv",
+		App: &model.AppConfig{
+			Id:   appId,
+			Name: "myapp",
+			OverrideableAppConfig: model.OverrideableAppConfig{
+				Domains: []string{"myapp.example.com"},
+			},
 		},
 	}
 
 	deploymentRepository := &core.FakeDeploymentRepository{
-		GetByReservationFn: func(reservationId uuid.UUID, envNameArg string) (*core.Deployment, error) {
-			return nil, core.ErrNotFound
+		FindByAppFn: func(appIdArg uuid.UUID) ([]core.Deployment, error) {
+			assert.Equal(t, appId, appIdArg)
+			return []core.Deployment{
+				{
+					DeploymentReservation: core.DeploymentReservation{Name: "myapp"},
+					DeploymentRecord:      core.DeploymentRecord{Id: uuid.New(), EnvironmentName: "myenv"},
+				},
+				{
+					DeploymentReservation: core.DeploymentReservation{Name: "myapp"},
+					DeploymentRecord:      core.DeploymentRecord{Id: otherEnvId, EnvironmentName: "myotherenv"},
+				},
+				{
+					DeploymentReservation: core.DeploymentReservation{Name: "myapp-canary"},
+					DeploymentRecord:      core.DeploymentRecord{Id: canaryId, EnvironmentName: "myenv"},
+				},
+			}, nil
 		},
-		CreateFn: func(newDeploymentArg *core.DeploymentRecord) error {
-			return errors.New("test")
+		FindRevisionsByAppFn: func(appIdArg uuid.UUID, limit int) ([]core.DeploymentRevision, error) {
+			assert.Equal(t, appId, appIdArg)
+			assert.Equal(t, 1, limit)
+			return []core.DeploymentRevision{
+				{DeploymentId: otherEnvId, Doc: core.DeploymentRevisionDoc{Domains: []string{"myapp.example.com"}}},
+				{DeploymentId: canaryId, Doc: core.DeploymentRevisionDoc{Domains: []string{"canary.example.com", "myapp.example.com"}}},
+			}, nil
 		},
 	}
 
-	service := service{deployments: deploymentRepository, reservationService: reservationService}
-	result, err := service.prepareForDeployment(deployment, false)
+	service := newPlanTestService(deploymentRepository, nil)
+	result, err := service.planDeployment(deployment, false)
 
-	assert.Zero(t, result)
-	assert.Equal(t, `Error creating deployment "myapp-mydep" in environment "myenv": test`, err.Error())
+	assert.Nil(t, result)
+	require.IsType(t, &core.ValidationError{}, err)
+	assert.Equal(t, `The domain "myapp.example.com" is already used by the deployment "myapp-canary" in environment "myenv"`, err.Error())
+	assert.Equal(t, 0, deploymentRepository.GetByNameCallCount)
 }
 
-func Test_computeTraffic_NewDeployment(t *testing.T) {
-	cfg := &core.DeploymentConfig{
-		Name: "myapp",
+func Test_planDeployment_whenDomainUsedInAnotherEnvironment(t *testing.T) {
+	otherEnvId := uuid.New()
+	deployment := &core.DeploymentConfig{
+		Name:            "myapp",
+		Namespace:       "myns",
+		EnvironmentName: "myenv",
+		App: &model.AppConfig{
+			Id:   uuid.New(),
+			Name: "myapp",
+			OverrideableAppConfig: model.OverrideableAppConfig{
+				Domains: []string{"myapp.example.com"},
+			},
+		},
+	}
+
+	deploymentRepository := &core.FakeDeploymentRepository{
+		FindByAppFn: func(uuid.UUID) ([]core.Deployment, error) {
+			return []core.Deployment{
+				{
+					DeploymentReservation: core.DeploymentReservation{Name: "myapp-canary"},
+					DeploymentRecord:      core.DeploymentRecord{Id: otherEnvId, EnvironmentName: "myotherenv"},
+				},
+			}, nil
+		},
+		GetByNameFn: func(*core.NamespacedName, string) (*core.Deployment, error) {
+			return nil, core.ErrNotFound
+		},
+	}
+
+	service := newPlanTestService(deploymentRepository, nil)
+	result, err := service.planDeployment(deployment, false)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	// No other deployment in the environment so revisions are not retrieved
+	assert.Equal(t, 0, deploymentRepository.FindRevisionsByAppCallCount)
+}
+
+func Test_planDeployment_whenGetFails(t *testing.T) {
+	deployment := &core.DeploymentConfig{
+		Name:            "myapp-mydep",
+		EnvironmentName: "myenv",
+		App: &model.AppConfig{
+			Name: "myapp",
+		},
+	}
+
+	deploymentRepository := &core.FakeDeploymentRepository{
+		GetByNameFn: func(*core.NamespacedName, string) (*core.Deployment, error) {
+			return nil, errors.New("test")
+		},
+	}
+
+	service := newPlanTestService(deploymentRepository, nil)
+	result, err := service.planDeployment(deployment, false)
+
+	assert.Nil(t, result)
+	assert.Equal(t, `Error retrieving deployment "myapp-mydep" in environment "myenv": test`, err.Error())
+}
+
+func Test_applyDeployment_NewDeployment(t *testing.T) {
+	deployment := &core.DeploymentConfig{
+		Name:            "myapp-mydep",
+		Namespace:       "myns",
+		EnvironmentName: "myenv",
+		Docker:          core.DeploymentDocker{Tag: "v1"},
+		Metadata:        map[string]string{"git-sha": "abc123"},
+		Traffic:         core.TrafficConfig{{RiserRevision: 1, RevisionName: "myapp-mydep-1", Percent: 100}},
+		App: &model.AppConfig{
+			Id:     uuid.New(),
+			Name:   "myapp",
+			Expose: &model.AppConfigExpose{Scope: model.AppExposeScope_Cluster},
+			OverrideableAppConfig: model.OverrideableAppConfig{
+				Domains: []string{"myapp.example.com"},
+			},
+		},
+	}
+	plan := &deploymentPlan{
+		ctx:          &core.DeploymentContext{DeploymentConfig: deployment, RiserRevision: 1},
+		deploymentId: uuid.New(),
+	}
+
+	reservation := &core.DeploymentReservation{
+		Id: uuid.New(),
+	}
+
+	reservationService := &deploymentreservation.FakeService{
+		EnsureReservationFn: func(appIdArg uuid.UUID, nameArg *core.NamespacedName) (*core.DeploymentReservation, error) {
+			assert.Equal(t, deployment.App.Id, appIdArg)
+			assert.Equal(t, core.NewNamespacedName(deployment.Name, deployment.Namespace), nameArg)
+			return reservation, nil
+		},
+	}
+
+	deploymentRepository := &core.FakeDeploymentRepository{
+		CreateFn: func(deploymentArg *core.DeploymentRecord) error {
+			assert.Equal(t, plan.deploymentId, deploymentArg.Id)
+			assert.Equal(t, reservation.Id, deploymentArg.ReservationId)
+			assert.Equal(t, "myenv", deploymentArg.EnvironmentName)
+			assert.Equal(t, int64(1), deploymentArg.RiserRevision)
+			assert.Equal(t, deployment.Traffic, core.TrafficConfig(deploymentArg.Doc.Traffic))
+			return nil
+		},
+		SaveRevisionFn: func(revision *core.DeploymentRevision) error {
+			assert.Equal(t, plan.deploymentId, revision.DeploymentId)
+			assert.Equal(t, int64(1), revision.RiserRevision)
+			assert.Equal(t, "v1", revision.Doc.DockerTag)
+			assert.Equal(t, map[string]string{"git-sha": "abc123"}, revision.Doc.Metadata)
+			assert.Equal(t, model.AppExposeScope_Cluster, revision.Doc.ExposeScope)
+			assert.Equal(t, []string{}, revision.Doc.ConfigMaps)
+			assert.Equal(t, []string{"myapp.example.com"}, revision.Doc.Domains)
+			assert.Equal(t, model.AppKind_Service, revision.Doc.Kind)
+			assert.False(t, revision.Doc.CreatedAt.IsZero())
+			return nil
+		},
+	}
+	domainService := &domainreservation.FakeService{
+		EnsureReservationsFn: func(appIdArg uuid.UUID, domainsArg []string, dryRunArg bool) error {
+			assert.False(t, dryRunArg)
+			return nil
+		},
+	}
+
+	service := service{deployments: deploymentRepository, reservationService: reservationService, domainService: domainService}
+	err := service.applyDeployment(plan)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, domainService.EnsureReservationsCallCount)
+	assert.Equal(t, 1, deploymentRepository.CreateCallCount)
+	assert.Equal(t, 1, deploymentRepository.SaveRevisionCallCount)
+	assert.Equal(t, 0, deploymentRepository.IncrementRevisionCallCount)
+}
+
+func Test_applyDeployment_ExistingDeployment(t *testing.T) {
+	deployment := &core.DeploymentConfig{
+		Name:            "myapp-mydep",
+		Namespace:       "myns",
+		EnvironmentName: "myenv",
+		Traffic:         core.TrafficConfig{{RiserRevision: 3, RevisionName: "myapp-mydep-3", Percent: 100}},
+		App: &model.AppConfig{
+			Id:   uuid.New(),
+			Name: "myapp",
+		},
+	}
+	plan := newExistingDeploymentPlan(deployment, 3)
+
+	deploymentRepository := &core.FakeDeploymentRepository{
+		IncrementRevisionFn: func(name *core.NamespacedName, envName string) (int64, error) {
+			assert.Equal(t, "myapp-mydep", name.Name)
+			assert.Equal(t, "myns", name.Namespace)
+			assert.Equal(t, "myenv", envName)
+			return 3, nil
+		},
+		UpdateTrafficFn: func(name *core.NamespacedName, envName string, riserRevision int64, traffic core.TrafficConfig) error {
+			assert.Equal(t, "myapp-mydep", name.Name)
+			assert.Equal(t, "myns", name.Namespace)
+			assert.Equal(t, "myenv", envName)
+			assert.Equal(t, int64(3), riserRevision)
+			assert.Equal(t, deployment.Traffic, traffic)
+			return nil
+		},
+		SaveRevisionFn: func(revision *core.DeploymentRevision) error {
+			assert.Equal(t, plan.deploymentId, revision.DeploymentId)
+			assert.Equal(t, int64(3), revision.RiserRevision)
+			return nil
+		},
+	}
+
+	service := service{deployments: deploymentRepository, reservationService: newFakeReservationService(), domainService: newFakeDomainService()}
+	err := service.applyDeployment(plan)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deploymentRepository.IncrementRevisionCallCount)
+	assert.Equal(t, 1, deploymentRepository.UpdateTrafficCallCount)
+	assert.Equal(t, 1, deploymentRepository.SaveRevisionCallCount)
+	assert.Equal(t, 0, deploymentRepository.CreateCallCount)
+}
+
+func Test_applyDeployment_doesNotUpdateWhenDryRun(t *testing.T) {
+	plan := &deploymentPlan{dryRun: true}
+	deploymentRepository := &core.FakeDeploymentRepository{}
+
+	service := service{deployments: deploymentRepository}
+	err := service.applyDeployment(plan)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, deploymentRepository.IncrementRevisionCallCount)
+	assert.Equal(t, 0, deploymentRepository.UpdateTrafficCallCount)
+	assert.Equal(t, 0, deploymentRepository.CreateCallCount)
+	assert.Equal(t, 0, deploymentRepository.SaveRevisionCallCount)
+}
+
+func Test_applyDeployment_whenIncrementRevisionFails(t *testing.T) {
+	deployment := &core.DeploymentConfig{
+		Name:            "myapp-mydep",
+		EnvironmentName: "myenv",
+		App: &model.AppConfig{
+			Id:   uuid.New(),
+			Name: "myapp",
+		},
+	}
+
+	deploymentRepository := &core.FakeDeploymentRepository{
+		IncrementRevisionFn: func(name *core.NamespacedName, envName string) (int64, error) {
+			return 0, errors.New("test")
+		},
+	}
+
+	service := service{deployments: deploymentRepository, reservationService: newFakeReservationService(), domainService: newFakeDomainService()}
+	err := service.applyDeployment(newExistingDeploymentPlan(deployment, 3))
+
+	assert.Equal(t, "Error incrementing deployment revision: test", err.Error())
+	assert.Equal(t, 0, deploymentRepository.RollbackRevisionCallCount)
+}
+
+func Test_applyDeployment_whenRevisionChangedByAnotherProcess(t *testing.T) {
+	deployment := &core.DeploymentConfig{
+		Name:            "myapp-mydep",
+		EnvironmentName: "myenv",
+		App: &model.AppConfig{
+			Id:   uuid.New(),
+			Name: "myapp",
+		},
+	}
+
+	deploymentRepository := &core.FakeDeploymentRepository{
+		IncrementRevisionFn: func(name *core.NamespacedName, envName string) (int64, error) {
+			return 4, nil
+		},
+		RollbackRevisionFn: func(name *core.NamespacedName, envName string, failedRevision int64) (int64, error) {
+			assert.Equal(t, int64(4), failedRevision)
+			return 3, nil
+		},
+	}
+
+	service := service{deployments: deploymentRepository, reservationService: newFakeReservationService(), domainService: newFakeDomainService()}
+	err := service.applyDeployment(newExistingDeploymentPlan(deployment, 3))
+
+	assert.Equal(t, "Deployment has been updated by another process", err.Error())
+	assert.Equal(t, 1, deploymentRepository.RollbackRevisionCallCount)
+	assert.Equal(t, 0, deploymentRepository.UpdateTrafficCallCount)
+}
+
+func Test_applyDeployment_whenUpdateTrafficFails(t *testing.T) {
+	deployment := &core.DeploymentConfig{
+		Name:            "myapp-mydep",
+		EnvironmentName: "myenv",
+		App: &model.AppConfig{
+			Id:   uuid.New(),
+			Name: "myapp",
+		},
+	}
+	plan := newExistingDeploymentPlan(deployment, 3)
+
+	deploymentRepository := newRollbackDeploymentRepository(t, plan)
+	deploymentRepository.IncrementRevisionFn = func(name *core.NamespacedName, envName string) (int64, error) {
+		return 3, nil
+	}
+	deploymentRepository.UpdateTrafficFn = func(name *core.NamespacedName, envName string, riserRevision int64, traffic core.TrafficConfig) error {
+		if riserRevision == 3 {
+			return errors.New("broke")
+		}
+		return nil
+	}
+
+	service := service{deployments: deploymentRepository, reservationService: newFakeReservationService(), domainService: newFakeDomainService()}
+	err := service.applyDeployment(plan)
+
+	assert.Equal(t, "Error updating traffic: broke", err.Error())
+	assert.Equal(t, 1, deploymentRepository.RollbackRevisionCallCount)
+	assert.Equal(t, 0, deploymentRepository.SaveRevisionCallCount)
+}
+
+func Test_applyDeployment_whenSaveRevisionFails(t *testing.T) {
+	deployment := &core.DeploymentConfig{
+		Name:            "myapp-mydep",
+		EnvironmentName: "myenv",
+		App: &model.AppConfig{
+			Id:   uuid.New(),
+			Name: "myapp",
+		},
+	}
+	plan := newExistingDeploymentPlan(deployment, 3)
+
+	deploymentRepository := newRollbackDeploymentRepository(t, plan)
+	deploymentRepository.IncrementRevisionFn = func(name *core.NamespacedName, envName string) (int64, error) {
+		return 3, nil
+	}
+	deploymentRepository.SaveRevisionFn = func(*core.DeploymentRevision) error {
+		return errors.New("broke")
+	}
+
+	service := service{deployments: deploymentRepository, reservationService: newFakeReservationService(), domainService: newFakeDomainService()}
+	err := service.applyDeployment(plan)
+
+	assert.Equal(t, "Error saving deployment revision: broke", err.Error())
+	assert.Equal(t, 1, deploymentRepository.RollbackRevisionCallCount)
+	assert.Equal(t, 1, deploymentRepository.DeleteRevisionCallCount)
+	// The traffic is restored to the traffic of the existing deployment
+	assert.Equal(t, 2, deploymentRepository.UpdateTrafficCallCount)
+}
+
+func Test_applyDeployment_whenEnsureReservationErr(t *testing.T) {
+	deployment := &core.DeploymentConfig{
+		Name:            "myapp-mydep",
+		EnvironmentName: "myenv",
+		App: &model.AppConfig{
+			Id:   uuid.New(),
+			Name: "myapp",
+		},
+	}
+
+	reservationService := &deploymentreservation.FakeService{
+		EnsureReservationFn: func(appIdArg uuid.UUID, nameArg *core.NamespacedName) (*core.DeploymentReservation, error) {
+			return nil, errors.New("test")
+		},
+	}
+
+	service := service{reservationService: reservationService}
+	err := service.applyDeployment(&deploymentPlan{ctx: &core.DeploymentContext{DeploymentConfig: deployment, RiserRevision: 1}})
+
+	assert.Equal(t, `Error ensuring deployment reservation: test`, err.Error())
+}
+
+func Test_applyDeployment_whenCreateFails(t *testing.T) {
+	deployment := &core.DeploymentConfig{
+		Name:            "myapp-mydep",
+		EnvironmentName: "myenv",
+		App: &model.AppConfig{
+			Name: "myapp",
+		},
+	}
+
+	deploymentRepository := &core.FakeDeploymentRepository{
+		CreateFn: func(newDeploymentArg *core.DeploymentRecord) error {
+			return errors.New("test")
+		},
+	}
+
+	service := service{deployments: deploymentRepository, reservationService: newFakeReservationService(), domainService: newFakeDomainService()}
+	err := service.applyDeployment(&deploymentPlan{ctx: &core.DeploymentContext{DeploymentConfig: deployment, RiserRevision: 1}})
+
+	assert.Equal(t, `Error creating deployment "myapp-mydep" in environment "myenv": test`, err.Error())
+	assert.Equal(t, 0, deploymentRepository.RollbackRevisionCallCount)
+}
+
+func Test_rollbackDeployment_NewDeployment(t *testing.T) {
+	deployment := &core.DeploymentConfig{
+		Name:            "myapp-mydep",
+		Namespace:       "myns",
+		EnvironmentName: "myenv",
+	}
+	plan := &deploymentPlan{
+		ctx:          &core.DeploymentContext{DeploymentConfig: deployment, RiserRevision: 1},
+		deploymentId: uuid.New(),
+	}
+
+	deploymentRepository := newRollbackDeploymentRepository(t, plan)
+	deploymentRepository.DeleteFn = func(name *core.NamespacedName, envName string) error {
+		assert.Equal(t, core.NewNamespacedName("myapp-mydep", "myns"), name)
+		assert.Equal(t, "myenv", envName)
+		return nil
+	}
+
+	service := service{deployments: deploymentRepository}
+	service.rollbackDeployment(plan)
+
+	assert.Equal(t, 1, deploymentRepository.RollbackRevisionCallCount)
+	assert.Equal(t, 1, deploymentRepository.DeleteRevisionCallCount)
+	// A new deployment is removed since it did not exist before
+	assert.Equal(t, 1, deploymentRepository.DeleteCallCount)
+	assert.Equal(t, 0, deploymentRepository.UpdateTrafficCallCount)
+}
+
+func Test_rollbackDeployment_ExistingDeployment(t *testing.T) {
+	deployment := &core.DeploymentConfig{
+		Name:            "myapp-mydep",
+		Namespace:       "myns",
+		EnvironmentName: "myenv",
+	}
+	plan := newExistingDeploymentPlan(deployment, 3)
+	plan.existingDeployment.Doc.Traffic = core.TrafficConfig{{RiserRevision: 2, RevisionName: "myapp-mydep-2", Percent: 100}}
+
+	deploymentRepository := newRollbackDeploymentRepository(t, plan)
+	deploymentRepository.UpdateTrafficFn = func(name *core.NamespacedName, envName string, riserRevision int64, traffic core.TrafficConfig) error {
+		assert.Equal(t, int64(2), riserRevision)
+		assert.Equal(t, core.TrafficConfig(plan.existingDeployment.Doc.Traffic), traffic)
+		return nil
+	}
+
+	service := service{deployments: deploymentRepository}
+	service.rollbackDeployment(plan)
+
+	assert.Equal(t, 1, deploymentRepository.RollbackRevisionCallCount)
+	assert.Equal(t, 1, deploymentRepository.DeleteRevisionCallCount)
+	assert.Equal(t, 1, deploymentRepository.UpdateTrafficCallCount)
+	assert.Equal(t, 0, deploymentRepository.DeleteCallCount)
+}
+
+func Test_Update_AdmissionRejected_DoesNotChangeState(t *testing.T) {
+	appId := uuid.New()
+	deployment := createMultiEnvDeploymentConfigs(appId, "dev")[0]
+	deployment.App.Domains = []string{"myapp.example.com"}
+	s, deploymentRepository := createUpdateManyService(appId, map[string]int64{"dev": 3})
+	s.admissionService = &admission.FakeService{
+		AdmitFn: func(ctx *core.DeploymentContext) error {
+			assert.Equal(t, int64(3), ctx.RiserRevision)
+			return core.NewValidationErrorMessage("rejected")
+		},
+	}
+	domainService := &domainreservation.FakeService{
+		EnsureReservationsFn: func(appIdArg uuid.UUID, domainsArg []string, dryRunArg bool) error {
+			// Domains may only be checked before admission
+			assert.True(t, dryRunArg)
+			return nil
+		},
+	}
+	s.domainService = domainService
+	s.reservationService = &deploymentreservation.FakeService{
+		EnsureReservationFn: func(uuid.UUID, *core.NamespacedName) (*core.DeploymentReservation, error) {
+			assert.Fail(t, "the deployment name must not be reserved")
+			return nil, nil
+		},
+	}
+
+	dryRunCommitter := state.NewDryRunCommitter()
+	result, err := s.Update(deployment, dryRunCommitter, false)
+
+	assert.Zero(t, result)
+	assert.Equal(t, "rejected", err.Error())
+	assert.Equal(t, 1, domainService.EnsureReservationsCallCount)
+	assert.Equal(t, 0, deploymentRepository.CreateCallCount)
+	assert.Equal(t, 0, deploymentRepository.IncrementRevisionCallCount)
+	assert.Equal(t, 0, deploymentRepository.UpdateTrafficCallCount)
+	assert.Equal(t, 0, deploymentRepository.SaveRevisionCallCount)
+	assert.Equal(t, 0, deploymentRepository.RollbackRevisionCallCount)
+	assert.Empty(t, dryRunCommitter.Commits)
+}
+
+func newPlanTestService(deploymentRepository *core.FakeDeploymentRepository, admissionService admission.Service) *service {
+	if admissionService == nil {
+		admissionService = &admission.FakeService{
+			AdmitFn: func(*core.DeploymentContext) error {
+				return nil
+			},
+		}
+	}
+	return &service{
+		secrets: &core.FakeSecretMetaRepository{
+			ListByAppInEnvironmentFn: func(*core.NamespacedName, string) ([]core.SecretMeta, error) {
+				return []core.SecretMeta{}, nil
+			},
+		},
+		environments: &core.FakeEnvironmentRepository{
+			GetFn: func(envName string) (*core.Environment, error) {
+				return &core.Environment{Name: envName}, nil
+			},
+		},
+		deployments:      deploymentRepository,
+		domainService:    newFakeDomainService(),
+		admissionService: admissionService,
+	}
+}
+
+func newExistingDeploymentPlan(deployment *core.DeploymentConfig, riserRevision int64) *deploymentPlan {
+	deploymentId := uuid.New()
+	return &deploymentPlan{
+		ctx:          &core.DeploymentContext{DeploymentConfig: deployment, RiserRevision: riserRevision},
+		deploymentId: deploymentId,
+		existingDeployment: &core.Deployment{
+			DeploymentRecord: core.DeploymentRecord{Id: deploymentId, RiserRevision: riserRevision - 1},
+		},
+	}
+}
+
+// newRollbackDeploymentRepository returns a repository that expects the rollback of the plan's revision
+func newRollbackDeploymentRepository(t *testing.T, plan *deploymentPlan) *core.FakeDeploymentRepository {
+	return &core.FakeDeploymentRepository{
+		RollbackRevisionFn: func(name *core.NamespacedName, envName string, failedRevision int64) (int64, error) {
+			assert.Equal(t, plan.ctx.RiserRevision, failedRevision)
+			return failedRevision - 1, nil
+		},
+		DeleteRevisionFn: func(deploymentId uuid.UUID, riserRevision int64) error {
+			assert.Equal(t, plan.deploymentId, deploymentId)
+			assert.Equal(t, plan.ctx.RiserRevision, riserRevision)
+			return nil
+		},
+		UpdateTrafficFn: func(*core.NamespacedName, string, int64, core.TrafficConfig) error {
+			return nil
+		},
+	}
+}
+
+func newFakeReservationService() *deploymentreservation.FakeService {
+	return &deploymentreservation.FakeService{
+		EnsureReservationFn: func(appId uuid.UUID, name *core.NamespacedName) (*core.DeploymentReservation, error) {
+			return &core.DeploymentReservation{Id: uuid.New(), AppId: appId, Name: name.Name, Namespace: name.Namespace}, nil
+		},
+	}
+}
+
+func Test_computeTraffic_NewDeployment(t *testing.T) {
+	cfg := &core.DeploymentConfig{
+		Name: "myapp",
 	}
 
 	result := computeTraffic(1, cfg, nil)
@@ -526,25 +1021,100 @@ func Test_computeTraffic_NewDeployment(t *testing.T) {
 	assert.EqualValues(t, result[0].Percent, 100)
 }
 
-// Manual rollout for a first time deployment is effectively not allowed
-func Test_computeTraffic_NewDeployment_ManualRollout(t *testing.T) {
+// Manual rollout for a first time deployment is effectively not allowed
+func Test_computeTraffic_NewDeployment_ManualRollout(t *testing.T) {
+	cfg := &core.DeploymentConfig{
+		Name:          "myapp",
+		ManualRollout: true,
+	}
+
+	result := computeTraffic(1, cfg, nil)
+
+	assert.Len(t, result, 1)
+	assert.EqualValues(t, result[0].RiserRevision, 1)
+	assert.Equal(t, result[0].RevisionName, "myapp-1")
+	assert.EqualValues(t, result[0].Percent, 100)
+}
+
+func Test_computeTraffic_ExistingDeployment_ManualRollout(t *testing.T) {
+	cfg := &core.DeploymentConfig{
+		Name:          "myapp",
+		ManualRollout: true,
+	}
+
+	existingDeployment := &core.DeploymentRecord{
+		Doc: core.DeploymentDoc{
+			Traffic: core.TrafficConfig{
+				core.TrafficConfigRule{
+					RiserRevision: 1,
+					RevisionName:  "myapp-1",
+					Percent:       100,
+				},
+			},
+		},
+	}
+
+	result := computeTraffic(2, cfg, existingDeployment)
+
+	assert.Len(t, result, 2)
+	assert.EqualValues(t, result[0].RiserRevision, 2)
+	assert.Equal(t, result[0].RevisionName, "myapp-2")
+	assert.EqualValues(t, result[0].Percent, 0)
+	assert.EqualValues(t, result[1].RiserRevision, 1)
+	assert.Equal(t, result[1].RevisionName, "myapp-1")
+	assert.EqualValues(t, result[1].Percent, 100)
+}
+
+func Test_computeTraffic_ExistingDeployment_ManualRollout_RemovesExistingZeroPercentRules(t *testing.T) {
+	cfg := &core.DeploymentConfig{
+		Name:          "myapp",
+		ManualRollout: true,
+	}
+
+	existingDeployment := &core.DeploymentRecord{
+		Doc: core.DeploymentDoc{
+			Traffic: core.TrafficConfig{
+				core.TrafficConfigRule{
+					RiserRevision: 1,
+					RevisionName:  "myapp-1",
+					Percent:       100,
+				},
+				core.TrafficConfigRule{
+					RiserRevision: 2,
+					RevisionName:  "myapp-2",
+					Percent:       0,
+				},
+			},
+		},
+	}
+
+	result := computeTraffic(3, cfg, existingDeployment)
+
+	assert.Len(t, result, 2)
+	assert.EqualValues(t, result[0].RiserRevision, 3)
+	assert.Equal(t, result[0].RevisionName, "myapp-3")
+	assert.EqualValues(t, result[0].Percent, 0)
+	assert.EqualValues(t, result[1].RiserRevision, 1)
+	assert.Equal(t, result[1].RevisionName, "myapp-1")
+	assert.EqualValues(t, result[1].Percent, 100)
+}
+
+func Test_computeDeploymentTraffic_NewDeployment_PreDeploy(t *testing.T) {
 	cfg := &core.DeploymentConfig{
-		Name:          "myapp",
-		ManualRollout: true,
+		Name: "myapp",
+		App:  &model.AppConfig{OverrideableAppConfig: model.OverrideableAppConfig{PreDeploy: &model.AppConfigPreDeploy{Command: []string{"migrate"}}}},
 	}
 
-	result := computeTraffic(1, cfg, nil)
+	traffic, pendingTraffic := computeDeploymentTraffic(1, cfg, nil)
 
-	assert.Len(t, result, 1)
-	assert.EqualValues(t, result[0].RiserRevision, 1)
-	assert.Equal(t, result[0].RevisionName, "myapp-1")
-	assert.EqualValues(t, result[0].Percent, 100)
+	assert.Equal(t, core.TrafficConfig{{RiserRevision: 1, RevisionName: "myapp-1", Percent: 0}}, traffic)
+	assert.Equal(t, core.TrafficConfig{{RiserRevision: 1, RevisionName: "myapp-1", Percent: 100}}, pendingTraffic)
 }
 
-func Test_computeTraffic_ExistingDeployment_ManualRollout(t *testing.T) {
+func Test_computeDeploymentTraffic_ExistingDeployment_PreDeploy(t *testing.T) {
 	cfg := &core.DeploymentConfig{
-		Name:          "myapp",
-		ManualRollout: true,
+		Name: "myapp",
+		App:  &model.AppConfig{OverrideableAppConfig: model.OverrideableAppConfig{PreDeploy: &model.AppConfigPreDeploy{Command: []string{"migrate"}}}},
 	}
 
 	existingDeployment := &core.DeploymentRecord{
@@ -559,21 +1129,21 @@ func Test_computeTraffic_ExistingDeployment_ManualRollout(t *testing.T) {
 		},
 	}
 
-	result := computeTraffic(2, cfg, existingDeployment)
+	traffic, pendingTraffic := computeDeploymentTraffic(2, cfg, existingDeployment)
 
-	assert.Len(t, result, 2)
-	assert.EqualValues(t, result[0].RiserRevision, 2)
-	assert.Equal(t, result[0].RevisionName, "myapp-2")
-	assert.EqualValues(t, result[0].Percent, 0)
-	assert.EqualValues(t, result[1].RiserRevision, 1)
-	assert.Equal(t, result[1].RevisionName, "myapp-1")
-	assert.EqualValues(t, result[1].Percent, 100)
+	assert.Equal(t, core.TrafficConfig{
+		{RiserRevision: 2, RevisionName: "myapp-2", Percent: 0},
+		{RiserRevision: 1, RevisionName: "myapp-1", Percent: 100},
+	}, traffic)
+	assert.Equal(t, core.TrafficConfig{{RiserRevision: 2, RevisionName: "myapp-2", Percent: 100}}, pendingTraffic)
 }
 
-func Test_computeTraffic_ExistingDeployment_ManualRollout_RemovesExistingZeroPercentRules(t *testing.T) {
+// A manual rollout already holds the traffic so there is nothing to apply once the pre-deploy job succeeds
+func Test_computeDeploymentTraffic_ExistingDeployment_PreDeploy_ManualRollout(t *testing.T) {
 	cfg := &core.DeploymentConfig{
 		Name:          "myapp",
 		ManualRollout: true,
+		App:           &model.AppConfig{OverrideableAppConfig: model.OverrideableAppConfig{PreDeploy: &model.AppConfigPreDeploy{Command: []string{"migrate"}}}},
 	}
 
 	existingDeployment := &core.DeploymentRecord{
@@ -584,24 +1154,226 @@ func Test_computeTraffic_ExistingDeployment_ManualRollout_RemovesExistingZeroPer
 					RevisionName:  "myapp-1",
 					Percent:       100,
 				},
-				core.TrafficConfigRule{
-					RiserRevision: 2,
-					RevisionName:  "myapp-2",
-					Percent:       0,
+			},
+		},
+	}
+
+	traffic, pendingTraffic := computeDeploymentTraffic(2, cfg, existingDeployment)
+
+	assert.Len(t, traffic, 2)
+	assert.EqualValues(t, 0, traffic[0].Percent)
+	assert.Nil(t, pendingTraffic)
+}
+
+func Test_computeDeploymentTraffic_NoPreDeploy(t *testing.T) {
+	cfg := &core.DeploymentConfig{
+		Name: "myapp",
+		App:  &model.AppConfig{},
+	}
+
+	traffic, pendingTraffic := computeDeploymentTraffic(1, cfg, nil)
+
+	assert.Equal(t, core.TrafficConfig{{RiserRevision: 1, RevisionName: "myapp-1", Percent: 100}}, traffic)
+	assert.Nil(t, pendingTraffic)
+}
+
+func Test_createWorkloadResources_OmitsRouteWithoutTraffic(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:      "myapp",
+			Namespace: "myns",
+			App:       &model.AppConfig{Expose: &model.AppConfigExpose{ContainerPort: 8080}},
+			Traffic:   core.TrafficConfig{{RiserRevision: 1, RevisionName: "myapp-1", Percent: 0}},
+		},
+		EnvironmentConfig: &core.EnvironmentConfig{},
+		RiserRevision:     1,
+	}
+
+	result := createWorkloadResources(ctx)
+
+	require.Len(t, result, 1)
+	assert.Equal(t, "Configuration", result[0].GetObjectKind().GroupVersionKind().Kind)
+}
+
+func Test_createStaleDeployResources_PreviousPreDeployJob(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:      "myapp",
+			Namespace: "myns",
+			App:       &model.AppConfig{Expose: &model.AppConfigExpose{ContainerPort: 8080}},
+			Traffic:   core.TrafficConfig{{RiserRevision: 2, RevisionName: "myapp-2", Percent: 100}},
+		},
+		EnvironmentConfig: &core.EnvironmentConfig{},
+		RiserRevision:     2,
+	}
+
+	result := createStaleDeployResources(ctx)
+
+	require.NotEmpty(t, result)
+	assert.Equal(t, "Job", result[0].GetObjectKind().GroupVersionKind().Kind)
+	assert.Equal(t, "myapp-1-predeploy", result[0].GetName())
+	assert.Equal(t, "myns", result[0].GetNamespace())
+}
+
+func Test_createStaleDeployResources_RemovedAccess(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:      "myapp",
+			Namespace: "myns",
+			App: &model.AppConfig{
+				Expose: &model.AppConfigExpose{ContainerPort: 8080},
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					HealthCheck: &model.AppConfigHealthCheck{AppConfigProbe: model.AppConfigProbe{Path: "/health"}},
 				},
 			},
+			Traffic: core.TrafficConfig{{RiserRevision: 2, RevisionName: "myapp-2", Percent: 100}},
 		},
+		EnvironmentConfig: &core.EnvironmentConfig{},
+		RiserRevision:     2,
 	}
 
-	result := computeTraffic(3, cfg, existingDeployment)
+	result := createStaleDeployResources(ctx)
 
-	assert.Len(t, result, 2)
-	assert.EqualValues(t, result[0].RiserRevision, 3)
-	assert.Equal(t, result[0].RevisionName, "myapp-3")
-	assert.EqualValues(t, result[0].Percent, 0)
-	assert.EqualValues(t, result[1].RiserRevision, 1)
-	assert.Equal(t, result[1].RevisionName, "myapp-1")
-	assert.EqualValues(t, result[1].Percent, 100)
+	policies := []string{}
+	for _, resource := range result {
+		if resource.GetObjectKind().GroupVersionKind().Kind == "AuthorizationPolicy" {
+			assert.Equal(t, "security.istio.io/v1beta1", resource.GetObjectKind().GroupVersionKind().GroupVersion().String())
+			assert.Equal(t, "myns", resource.GetNamespace())
+			policies = append(policies, resource.GetName())
+		}
+	}
+	assert.Equal(t, []string{"myapp-access-allow"}, policies)
+}
+
+func Test_createStaleDeployResources_Access(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:      "myapp",
+			Namespace: "myns",
+			App: &model.AppConfig{
+				Expose: &model.AppConfigExpose{ContainerPort: 8080},
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					HealthCheck: &model.AppConfigHealthCheck{AppConfigProbe: model.AppConfigProbe{Path: "/health"}},
+					Access: &model.AppConfigAccess{
+						Allow: []model.AppConfigAccessRule{{Namespace: "myotherns"}},
+					},
+				},
+			},
+			Traffic: core.TrafficConfig{{RiserRevision: 2, RevisionName: "myapp-2", Percent: 100}},
+		},
+		EnvironmentConfig: &core.EnvironmentConfig{},
+		RiserRevision:     2,
+	}
+
+	result := createStaleDeployResources(ctx)
+
+	for _, resource := range result {
+		assert.NotEqual(t, "AuthorizationPolicy", resource.GetObjectKind().GroupVersionKind().Kind)
+	}
+}
+
+func Test_createStaleDeployResources_RemovedHealthcheck(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:      "myapp",
+			Namespace: "myns",
+			App: &model.AppConfig{
+				Expose: &model.AppConfigExpose{ContainerPort: 8080, Protocol: model.AppExposeProtocol_TCP},
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Access: &model.AppConfigAccess{
+						Allow: []model.AppConfigAccessRule{{Namespace: "myotherns"}},
+					},
+				},
+			},
+			Traffic: core.TrafficConfig{{RiserRevision: 2, RevisionName: "myapp-2", Percent: 100}},
+		},
+		EnvironmentConfig: &core.EnvironmentConfig{},
+		RiserRevision:     2,
+	}
+
+	result := createStaleDeployResources(ctx)
+
+	policies := []string{}
+	for _, resource := range result {
+		if resource.GetObjectKind().GroupVersionKind().Kind == "AuthorizationPolicy" {
+			assert.Equal(t, "security.istio.io/v1beta1", resource.GetObjectKind().GroupVersionKind().GroupVersion().String())
+			assert.Equal(t, "myns", resource.GetNamespace())
+			policies = append(policies, resource.GetName())
+		}
+	}
+	assert.Equal(t, []string{"myapp-healthcheck-deny"}, policies)
+}
+
+func Test_createStaleDeployResources_UnusedConfigMaps(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:      "myapp",
+			Namespace: "myns",
+			App: &model.AppConfig{
+				Expose: &model.AppConfigExpose{ContainerPort: 8080},
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Files: map[string]string{"/etc/myapp/app.conf": "level=info"},
+				},
+			},
+			// A manual rollout keeps routing revision 2
+			Traffic: core.TrafficConfig{
+				{RiserRevision: 4, RevisionName: "myapp-4", Percent: 0},
+				{RiserRevision: 2, RevisionName: "myapp-2", Percent: 100},
+			},
+		},
+		EnvironmentConfig: &core.EnvironmentConfig{},
+		RiserRevision:     4,
+	}
+	currentConfigMap := fileConfigMapNames(ctx)[0]
+	ctx.PreviousRevisions = []core.DeploymentRevision{
+		{RiserRevision: 3, Doc: core.DeploymentRevisionDoc{ConfigMaps: []string{"myapp-files-3", currentConfigMap}}},
+		{RiserRevision: 2, Doc: core.DeploymentRevisionDoc{ConfigMaps: []string{"myapp-files-2"}}},
+		{RiserRevision: 1, Doc: core.DeploymentRevisionDoc{ConfigMaps: []string{"myapp-files-1", "myapp-files-3"}}},
+	}
+
+	result := createStaleDeployResources(ctx)
+
+	staleConfigMaps := []string{}
+	for _, resource := range result {
+		if resource.GetObjectKind().GroupVersionKind().Kind == "ConfigMap" {
+			assert.Equal(t, "myns", resource.GetNamespace())
+			staleConfigMaps = append(staleConfigMaps, resource.GetName())
+		}
+	}
+	assert.Equal(t, []string{"myapp-files-3", "myapp-files-1"}, staleConfigMaps)
+}
+
+func Test_createStaleDeployResources_RemovedDomains(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:      "myapp",
+			Namespace: "myns",
+			App: &model.AppConfig{
+				Expose: &model.AppConfigExpose{ContainerPort: 8080},
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Domains: []string{"b.example.com"},
+				},
+			},
+			Traffic: core.TrafficConfig{{RiserRevision: 2, RevisionName: "myapp-2", Percent: 100}},
+		},
+		EnvironmentConfig: &core.EnvironmentConfig{},
+		RiserRevision:     2,
+		PreviousRevisions: []core.DeploymentRevision{
+			{RiserRevision: 1, Doc: core.DeploymentRevisionDoc{Domains: []string{"a.example.com", "b.example.com"}}},
+		},
+	}
+
+	result := createStaleDeployResources(ctx)
+
+	staleDomainResources := []string{}
+	for _, resource := range result {
+		kind := resource.GetObjectKind().GroupVersionKind().Kind
+		if kind == "DomainMapping" || kind == "Certificate" {
+			assert.Equal(t, "myns", resource.GetNamespace())
+			staleDomainResources = append(staleDomainResources, fmt.Sprintf("%s/%s", kind, resource.GetName()))
+		}
+	}
+	assert.Equal(t, []string{"DomainMapping/a.example.com", "Certificate/a.example.com"}, staleDomainResources)
 }
 
 func Test_validateDeploymentConfig_ValidatesName(t *testing.T) {
@@ -636,3 +1408,356 @@ func Test_validateDeploymentConfig_ValidatesName(t *testing.T) {
 		}
 	}
 }
+
+func Test_UpdateMany(t *testing.T) {
+	appId := uuid.New()
+	deployments := createMultiEnvDeploymentConfigs(appId, "dev", "prod")
+	revisions := map[string]int64{"dev": 3, "prod": 7}
+	s, deploymentRepository := createUpdateManyService(appId, revisions)
+
+	dryRunCommitter := state.NewDryRunCommitter()
+	result, err := s.UpdateMany(deployments, dryRunCommitter, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, revisions, result)
+	assert.Equal(t, 2, deploymentRepository.IncrementRevisionCallCount)
+	assert.Equal(t, 0, deploymentRepository.RollbackRevisionCallCount)
+	require.Len(t, dryRunCommitter.Commits, 1)
+	assert.Equal(t, `Updating resources for "myapp.apps" in environments "dev", "prod"
+
+- Updating resources for "myapp.apps" in environment "dev"
+- Updating resources for "myapp.apps" in environment "prod"`, dryRunCommitter.Commits[0].Message)
+	fileNames := []string{}
+	for _, file := range dryRunCommitter.Commits[0].Files {
+		fileNames = append(fileNames, file.Name)
+	}
+	assert.Contains(t, fileNames, "state/dev/riser-managed/apps/deployments/myapp/serving.knative.dev.configuration.myapp.yaml")
+	assert.Contains(t, fileNames, "state/prod/riser-managed/apps/deployments/myapp/serving.knative.dev.configuration.myapp.yaml")
+	assert.Contains(t, fileNames, "riser-config/dev/apps/myapp.yaml")
+	assert.Contains(t, fileNames, "riser-config/prod/apps/myapp.yaml")
+}
+
+func Test_UpdateMany_InvalidEnvironment_DoesNotCommit(t *testing.T) {
+	appId := uuid.New()
+	deployments := createMultiEnvDeploymentConfigs(appId, "dev", "prod")
+	s, deploymentRepository := createUpdateManyService(appId, map[string]int64{"dev": 3})
+	s.environments = &core.FakeEnvironmentRepository{
+		GetFn: func(envName string) (*core.Environment, error) {
+			if envName == "prod" {
+				return nil, core.ErrNotFound
+			}
+			return &core.Environment{Name: envName}, nil
+		},
+	}
+
+	dryRunCommitter := state.NewDryRunCommitter()
+	result, err := s.UpdateMany(deployments, dryRunCommitter, false)
+
+	assert.Nil(t, result)
+	assert.Equal(t, `Error retrieving environment "prod": the object could not be found`, err.Error())
+	assert.Equal(t, 0, deploymentRepository.IncrementRevisionCallCount)
+	assert.Empty(t, dryRunCommitter.Commits)
+}
+
+func Test_UpdateMany_SecondEnvironmentFails_RollsBackAndDoesNotCommit(t *testing.T) {
+	appId := uuid.New()
+	deployments := createMultiEnvDeploymentConfigs(appId, "dev", "prod")
+	s, deploymentRepository := createUpdateManyService(appId, map[string]int64{"dev": 3, "prod": 7})
+	deploymentRepository.SaveRevisionFn = func(revision *core.DeploymentRevision) error {
+		if revision.RiserRevision == 7 {
+			return errors.New("test")
+		}
+		return nil
+	}
+	rolledBack := map[string]int64{}
+	deploymentRepository.RollbackRevisionFn = func(name *core.NamespacedName, envName string, failedRevision int64) (int64, error) {
+		rolledBack[envName] = failedRevision
+		return failedRevision - 1, nil
+	}
+	restoredTraffic := map[string]int64{}
+	deploymentRepository.UpdateTrafficFn = func(name *core.NamespacedName, envName string, riserRevision int64, traffic core.TrafficConfig) error {
+		restoredTraffic[envName] = riserRevision
+		return nil
+	}
+
+	dryRunCommitter := state.NewDryRunCommitter()
+	result, err := s.UpdateMany(deployments, dryRunCommitter, false)
+
+	assert.Nil(t, result)
+	assert.Equal(t, "Error saving deployment revision: test", err.Error())
+	// Both environments are restored to the revision and the traffic prior to the deployment
+	assert.Equal(t, map[string]int64{"dev": 3, "prod": 7}, rolledBack)
+	assert.Equal(t, map[string]int64{"dev": 2, "prod": 6}, restoredTraffic)
+	assert.Equal(t, 2, deploymentRepository.DeleteRevisionCallCount)
+	// Domains reserved by the failed deployment are released
+	assert.Equal(t, 1, s.domainService.(*domainreservation.FakeService).ReleaseUnusedReservationsCallCount)
+	assert.Empty(t, dryRunCommitter.Commits)
+}
+
+func Test_UpdateMany_SecondEnvironmentNotAdmitted_DoesNotChangeState(t *testing.T) {
+	appId := uuid.New()
+	deployments := createMultiEnvDeploymentConfigs(appId, "dev", "prod")
+	s, deploymentRepository := createUpdateManyService(appId, map[string]int64{"dev": 3, "prod": 7})
+	s.admissionService = &admission.FakeService{
+		AdmitFn: func(ctx *core.DeploymentContext) error {
+			if ctx.DeploymentConfig.EnvironmentName == "prod" {
+				return core.NewValidationErrorMessage("rejected")
+			}
+			return nil
+		},
+	}
+
+	dryRunCommitter := state.NewDryRunCommitter()
+	result, err := s.UpdateMany(deployments, dryRunCommitter, false)
+
+	assert.Nil(t, result)
+	assert.Equal(t, "rejected", err.Error())
+	assert.Equal(t, 0, deploymentRepository.IncrementRevisionCallCount)
+	assert.Equal(t, 0, deploymentRepository.UpdateTrafficCallCount)
+	assert.Equal(t, 0, deploymentRepository.SaveRevisionCallCount)
+	assert.Equal(t, 0, deploymentRepository.RollbackRevisionCallCount)
+	assert.Empty(t, dryRunCommitter.Commits)
+}
+
+func Test_UpdateMany_DryRun_AdmissionFails_DoesNotCommit(t *testing.T) {
+	appId := uuid.New()
+	deployments := createMultiEnvDeploymentConfigs(appId, "dev", "prod")
+	s, _ := createUpdateManyService(appId, map[string]int64{"dev": 3, "prod": 7})
+	s.environments = &core.FakeEnvironmentRepository{
+		GetFn: func(envName string) (*core.Environment, error) {
+			environment := &core.Environment{Name: envName}
+			if envName == "prod" {
+				environment.Doc.Config.AdmissionPolicies = []model.EnvironmentAdmissionPolicy{
+					{Name: "no-myapp", Expression: "name != 'myapp'", Message: "myapp is not allowed in prod"},
+				}
+			}
+			return environment, nil
+		},
+	}
+	s.admissionService = admission.NewService()
+
+	dryRunCommitter := state.NewDryRunCommitter()
+	result, err := s.UpdateMany(deployments, dryRunCommitter, true)
+
+	assert.Nil(t, result)
+	require.IsType(t, &core.ValidationError{}, err)
+	assert.Equal(t, `deployment violates the admission policies of environment "prod": no-myapp: myapp is not allowed in prod.`, err.Error())
+	assert.Empty(t, dryRunCommitter.Commits)
+}
+
+func createMultiEnvDeploymentConfigs(appId uuid.UUID, envNames ...string) []*core.DeploymentConfig {
+	deployments := []*core.DeploymentConfig{}
+	for _, envName := range envNames {
+		deployments = append(deployments, &core.DeploymentConfig{
+			Name:            "myapp",
+			Namespace:       "apps",
+			EnvironmentName: envName,
+			App: &model.AppConfig{
+				Id:        appId,
+				Name:      "myapp",
+				Namespace: "apps",
+				Expose: &model.AppConfigExpose{
+					ContainerPort: 8080,
+				},
+			},
+		})
+	}
+	return deployments
+}
+
+func createUpdateManyService(appId uuid.UUID, revisions map[string]int64) (*service, *core.FakeDeploymentRepository) {
+	reservation := core.DeploymentReservation{Id: uuid.New(), AppId: appId, Name: "myapp", Namespace: "apps"}
+	deploymentRepository := &core.FakeDeploymentRepository{
+		GetByNameFn: func(name *core.NamespacedName, envName string) (*core.Deployment, error) {
+			return &core.Deployment{
+				DeploymentReservation: reservation,
+				DeploymentRecord:      core.DeploymentRecord{ReservationId: reservation.Id, EnvironmentName: envName, RiserRevision: revisions[envName] - 1}}, nil
+		},
+		IncrementRevisionFn: func(name *core.NamespacedName, envName string) (int64, error) {
+			return revisions[envName], nil
+		},
+		UpdateTrafficFn: func(name *core.NamespacedName, envName string, riserRevision int64, traffic core.TrafficConfig) error {
+			return nil
+		},
+		SaveRevisionFn: func(*core.DeploymentRevision) error {
+			return nil
+		},
+		DeleteRevisionFn: func(uuid.UUID, int64) error {
+			return nil
+		},
+		FindRevisionsFn: func(uuid.UUID, int) ([]core.DeploymentRevision, error) {
+			return []core.DeploymentRevision{}, nil
+		},
+		FindByAppFn: func(uuid.UUID) ([]core.Deployment, error) {
+			return []core.Deployment{}, nil
+		},
+		FindRevisionsByAppFn: func(uuid.UUID, int) ([]core.DeploymentRevision, error) {
+			return []core.DeploymentRevision{}, nil
+		},
+	}
+	return &service{
+		namespaceService: &namespace.FakeService{
+			EnsureNamespaceInEnvironmentFn: func(namespaceName string, envName string, committer state.Committer) error {
+				return nil
+			},
+		},
+		secrets: &core.FakeSecretMetaRepository{
+			ListByAppInEnvironmentFn: func(*core.NamespacedName, string) ([]core.SecretMeta, error) {
+				return []core.SecretMeta{}, nil
+			},
+		},
+		environments: &core.FakeEnvironmentRepository{
+			GetFn: func(envName string) (*core.Environment, error) {
+				return &core.Environment{Name: envName}, nil
+			},
+		},
+		deployments: deploymentRepository,
+		reservationService: &deploymentreservation.FakeService{
+			EnsureReservationFn: func(uuid.UUID, *core.NamespacedName) (*core.DeploymentReservation, error) {
+				return &reservation, nil
+			},
+		},
+		domainService: newFakeDomainService(),
+		admissionService: &admission.FakeService{
+			AdmitFn: func(*core.DeploymentContext) error {
+				return nil
+			},
+		},
+	}, deploymentRepository
+}
+
+func newFakeDomainService() *domainreservation.FakeService {
+	return &domainreservation.FakeService{
+		EnsureReservationsFn: func(uuid.UUID, []string, bool) error {
+			return nil
+		},
+		ReleaseUnusedReservationsFn: func(uuid.UUID, []string) error {
+			return nil
+		},
+	}
+}
+
+func Test_resolveReferencedDeployments(t *testing.T) {
+	deploymentConfig := &core.DeploymentConfig{
+		EnvironmentName: "myenv",
+		App: &model.AppConfig{
+			OverrideableAppConfig: model.OverrideableAppConfig{
+				Environment: map[string]intstr.IntOrString{
+					"BILLING_URL":  intstr.FromString("${app:payments.billing.url}"),
+					"BILLING_URL2": intstr.FromString("${app:payments.billing.url}/v2"),
+					"ORDERS_URL":   intstr.FromString("${app:orders.apps.url}"),
+					"OTHER":        intstr.FromInt(1),
+				},
+			},
+		},
+	}
+	paymentsId := uuid.New()
+	ordersId := uuid.New()
+
+	deploymentRepository := &core.FakeDeploymentRepository{
+		GetByNameFn: func(name *core.NamespacedName, envName string) (*core.Deployment, error) {
+			assert.Equal(t, "myenv", envName)
+			if name.Name == "payments" {
+				assert.Equal(t, "billing", name.Namespace)
+				return &core.Deployment{DeploymentRecord: core.DeploymentRecord{Id: paymentsId}}, nil
+			}
+			assert.Equal(t, core.NewNamespacedName("orders", "apps"), name)
+			return &core.Deployment{DeploymentRecord: core.DeploymentRecord{Id: ordersId}}, nil
+		},
+		FindRevisionsFn: func(deploymentId uuid.UUID, limit int) ([]core.DeploymentRevision, error) {
+			assert.Equal(t, 1, limit)
+			if deploymentId == paymentsId {
+				return []core.DeploymentRevision{{Doc: core.DeploymentRevisionDoc{ExposeScope: model.AppExposeScope_Cluster}}}, nil
+			}
+			// A revision recorded before the expose scope was tracked
+			return []core.DeploymentRevision{{}}, nil
+		},
+	}
+
+	service := service{deployments: deploymentRepository}
+	result, err := service.resolveReferencedDeployments(deploymentConfig)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []core.ReferencedDeployment{
+		{Name: "payments", Namespace: "billing", ExposeScope: model.AppExposeScope_Cluster},
+		{Name: "orders", Namespace: "apps", ExposeScope: model.AppExposeScope_External},
+	}, result)
+	assert.Equal(t, 2, deploymentRepository.GetByNameCallCount)
+}
+
+func Test_resolveReferencedDeployments_NotFound(t *testing.T) {
+	deploymentConfig := &core.DeploymentConfig{
+		EnvironmentName: "myenv",
+		App: &model.AppConfig{
+			OverrideableAppConfig: model.OverrideableAppConfig{
+				Environment: map[string]intstr.IntOrString{"BILLING_URL": intstr.FromString("${app:payments.billing.url}")},
+			},
+		},
+	}
+
+	deploymentRepository := &core.FakeDeploymentRepository{
+		GetByNameFn: func(name *core.NamespacedName, envName string) (*core.Deployment, error) {
+			return nil, core.ErrNotFound
+		},
+	}
+
+	service := service{deployments: deploymentRepository}
+	result, err := service.resolveReferencedDeployments(deploymentConfig)
+
+	assert.Nil(t, result)
+	require.IsType(t, &core.ValidationError{}, err)
+	assert.Equal(t, `The env var "BILLING_URL" references the deployment "payments.billing" which does not exist in environment "myenv"`, err.Error())
+}
+
+func Test_resolveReferencedDeployments_Deleted(t *testing.T) {
+	deploymentConfig := &core.DeploymentConfig{
+		EnvironmentName: "myenv",
+		App: &model.AppConfig{
+			OverrideableAppConfig: model.OverrideableAppConfig{
+				Environment: map[string]intstr.IntOrString{"BILLING_URL": intstr.FromString("${app:payments.billing.url}")},
+			},
+		},
+	}
+	deletedAt := time.Now()
+
+	deploymentRepository := &core.FakeDeploymentRepository{
+		GetByNameFn: func(name *core.NamespacedName, envName string) (*core.Deployment, error) {
+			return &core.Deployment{DeploymentRecord: core.DeploymentRecord{DeletedAt: &deletedAt}}, nil
+		},
+	}
+
+	service := service{deployments: deploymentRepository}
+	_, err := service.resolveReferencedDeployments(deploymentConfig)
+
+	assert.IsType(t, &core.ValidationError{}, err)
+}
+
+func Test_resolveReferencedDeployments_NoService(t *testing.T) {
+	for _, kind := range []string{model.AppKind_Worker, model.AppKind_ScheduledJob} {
+		deploymentConfig := &core.DeploymentConfig{
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Environment: map[string]intstr.IntOrString{"BILLING_URL": intstr.FromString("${app:payments.billing.url}")},
+				},
+			},
+		}
+
+		deploymentRepository := &core.FakeDeploymentRepository{
+			GetByNameFn: func(name *core.NamespacedName, envName string) (*core.Deployment, error) {
+				return &core.Deployment{}, nil
+			},
+			FindRevisionsFn: func(uuid.UUID, int) ([]core.DeploymentRevision, error) {
+				return []core.DeploymentRevision{{Doc: core.DeploymentRevisionDoc{Kind: kind}}}, nil
+			},
+		}
+
+		service := service{deployments: deploymentRepository}
+		result, err := service.resolveReferencedDeployments(deploymentConfig)
+
+		assert.Nil(t, result, kind)
+		require.IsType(t, &core.ValidationError{}, err, kind)
+		assert.Equal(t, fmt.Sprintf(`The env var "BILLING_URL" references the deployment "payments.billing" which is a %s and does not expose a service`, kind),
+			err.Error(), kind)
+	}
+}
@@ -10,6 +10,9 @@ import (
 	"github.com/riser-platform/riser-server/pkg/environment"
 )
 
+// revisionHistoryLimit is the maximum number of revisions returned in a deployment's revision history
+const revisionHistoryLimit = 10
+
 // TODO: Consider better homes for these
 type Service interface {
 	GetByApp(appId uuid.UUID) (*core.AppStatus, error)
@@ -31,13 +34,26 @@ func (s *service) GetByApp(appId uuid.UUID) (*core.AppStatus, error) {
 	}
 
 	appStatus := &core.AppStatus{
-		Deployments:       deployments,
-		EnvironmentStatus: []core.EnvironmentStatus{},
+		Deployments:         deployments,
+		EnvironmentStatus:   []core.EnvironmentStatus{},
+		DeploymentRevisions: map[uuid.UUID][]core.DeploymentRevision{},
+	}
+
+	revisions, err := s.deployments.FindRevisionsByApp(appId, revisionHistoryLimit)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error retrieving deployment revisions")
+	}
+	for _, deploymentStatus := range deployments {
+		appStatus.DeploymentRevisions[deploymentStatus.DeploymentRecord.Id] = []core.DeploymentRevision{}
+	}
+	for _, revision := range revisions {
+		appStatus.DeploymentRevisions[revision.DeploymentId] = append(appStatus.DeploymentRevisions[revision.DeploymentId], revision)
 	}
 
 	environmentMap := map[string]core.EnvironmentStatus{}
 
 	for _, deploymentStatus := range deployments {
+
 		if _, ok := environmentMap[deploymentStatus.EnvironmentName]; !ok {
 			environmentStatus, err := s.envService.GetStatus(deploymentStatus.EnvironmentName)
 			if err != nil {
@@ -20,6 +20,7 @@ func Test_GetByApp(t *testing.T) {
 			AppId: uuid.New(),
 		},
 		DeploymentRecord: core.DeploymentRecord{
+			Id:              uuid.New(),
 			EnvironmentName: "myenv",
 			Doc: core.DeploymentDoc{
 				Status: &core.DeploymentStatus{
@@ -37,10 +38,39 @@ func Test_GetByApp(t *testing.T) {
 		},
 	}
 
+	otherStatus := core.Deployment{
+		DeploymentReservation: core.DeploymentReservation{
+			Name:  "myDeployment",
+			AppId: status.AppId,
+		},
+		DeploymentRecord: core.DeploymentRecord{
+			Id:              uuid.New(),
+			EnvironmentName: "myenv",
+		},
+	}
+
+	revisions := []core.DeploymentRevision{
+		{
+			DeploymentId:  status.DeploymentRecord.Id,
+			RiserRevision: 2,
+			Doc:           core.DeploymentRevisionDoc{Metadata: map[string]string{"git-sha": "4d1a7b0"}},
+		},
+		{
+			DeploymentId:  status.DeploymentRecord.Id,
+			RiserRevision: 1,
+			Doc:           core.DeploymentRevisionDoc{Metadata: map[string]string{"git-sha": "9c2e6c5"}},
+		},
+	}
+
 	deploymentRepository := &core.FakeDeploymentRepository{
 		FindByAppFn: func(appId uuid.UUID) ([]core.Deployment, error) {
 			assert.Equal(t, status.AppId, appId)
-			return []core.Deployment{status}, nil
+			return []core.Deployment{status, otherStatus}, nil
+		},
+		FindRevisionsByAppFn: func(appId uuid.UUID, limit int) ([]core.DeploymentRevision, error) {
+			assert.Equal(t, status.AppId, appId)
+			assert.Equal(t, 10, limit)
+			return revisions, nil
 		},
 	}
 
@@ -61,9 +91,14 @@ func Test_GetByApp(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, result.EnvironmentStatus, 1)
 	assert.Equal(t, "myenv", result.EnvironmentStatus[0].EnvironmentName)
-	assert.Len(t, result.Deployments, 1)
+	assert.Len(t, result.Deployments, 2)
 	assert.Equal(t, status, result.Deployments[0])
+	assert.Equal(t, revisions, result.DeploymentRevisions[status.DeploymentRecord.Id])
+	assert.Empty(t, result.DeploymentRevisions[otherStatus.DeploymentRecord.Id])
 	assert.Equal(t, 1, environmentService.GetStatusCallCount)
+	// The revisions of every deployment are retrieved in a single query
+	assert.Equal(t, 1, deploymentRepository.FindRevisionsByAppCallCount)
+	assert.Equal(t, 0, deploymentRepository.FindRevisionsCallCount)
 }
 
 func Test_GetByApp_StatusRepoErr_ReturnsErr(t *testing.T) {
@@ -92,6 +127,9 @@ func Test_GetByApp_EnvironmentStatusError_ReturnsError(t *testing.T) {
 		FindByAppFn: func(appId uuid.UUID) ([]core.Deployment, error) {
 			return []core.Deployment{deployment}, nil
 		},
+		FindRevisionsByAppFn: func(uuid.UUID, int) ([]core.DeploymentRevision, error) {
+			return []core.DeploymentRevision{}, nil
+		},
 	}
 
 	environmentService := &environment.FakeService{
@@ -107,3 +145,30 @@ func Test_GetByApp_EnvironmentStatusError_ReturnsError(t *testing.T) {
 	assert.Nil(t, result)
 	assert.Equal(t, "Error retrieving status for environment \"myenv\": test", err.Error())
 }
+
+func Test_GetByApp_FindRevisionsError_ReturnsError(t *testing.T) {
+	deployment := core.Deployment{
+		DeploymentReservation: core.DeploymentReservation{
+			Name: "myapp",
+		},
+		DeploymentRecord: core.DeploymentRecord{
+			EnvironmentName: "myenv",
+		},
+	}
+
+	deploymentRepository := &core.FakeDeploymentRepository{
+		FindByAppFn: func(appId uuid.UUID) ([]core.Deployment, error) {
+			return []core.Deployment{deployment}, nil
+		},
+		FindRevisionsByAppFn: func(uuid.UUID, int) ([]core.DeploymentRevision, error) {
+			return nil, errors.New("test")
+		},
+	}
+
+	service := service{deployments: deploymentRepository}
+
+	result, err := service.GetByApp(uuid.New())
+
+	assert.Nil(t, result)
+	assert.Equal(t, "Error retrieving deployment revisions: test", err.Error())
+}
@@ -0,0 +1,22 @@
+package domainreservation
+
+import (
+	"github.com/google/uuid"
+)
+
+type FakeService struct {
+	EnsureReservationsFn               func(appId uuid.UUID, domains []string, dryRun bool) error
+	EnsureReservationsCallCount        int
+	ReleaseUnusedReservationsFn        func(appId uuid.UUID, usedDomains []string) error
+	ReleaseUnusedReservationsCallCount int
+}
+
+func (f *FakeService) EnsureReservations(appId uuid.UUID, domains []string, dryRun bool) error {
+	f.EnsureReservationsCallCount++
+	return f.EnsureReservationsFn(appId, domains, dryRun)
+}
+
+func (f *FakeService) ReleaseUnusedReservations(appId uuid.UUID, usedDomains []string) error {
+	f.ReleaseUnusedReservationsCallCount++
+	return f.ReleaseUnusedReservationsFn(appId, usedDomains)
+}
@@ -0,0 +1,80 @@
+package domainreservation
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/riser-platform/riser-server/pkg/core"
+)
+
+type Service interface {
+	// EnsureReservations reserves each domain to the app. Domains are only checked and not reserved during a dry run.
+	EnsureReservations(appId uuid.UUID, domains []string, dryRun bool) error
+	// ReleaseUnusedReservations releases each of the app's reservations for a domain that is not in usedDomains
+	ReleaseUnusedReservations(appId uuid.UUID, usedDomains []string) error
+}
+
+type service struct {
+	reservations core.DomainReservationRepository
+}
+
+func NewService(reservations core.DomainReservationRepository) Service {
+	return &service{reservations}
+}
+
+func (s *service) EnsureReservations(appId uuid.UUID, domains []string, dryRun bool) error {
+	for _, domain := range domains {
+		reservation, err := s.reservations.GetByDomain(domain)
+		if err == core.ErrNotFound {
+			if dryRun {
+				continue
+			}
+			reservation = &core.DomainReservation{
+				Domain: domain,
+				AppId:  appId,
+			}
+			err = s.reservations.Create(reservation)
+			if err == core.ErrAlreadyExists {
+				// Another deployment reserved the domain since it was retrieved
+				reservation, err = s.reservations.GetByDomain(domain)
+				if err != nil {
+					return errors.Wrap(err, fmt.Sprintf("error retrieving reservation for domain %q", domain))
+				}
+			} else if err != nil {
+				return errors.Wrap(err, fmt.Sprintf("error creating reservation for domain %q", domain))
+			}
+		} else if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("error retrieving reservation for domain %q", domain))
+		}
+
+		if reservation.AppId != appId {
+			return core.NewValidationErrorMessage(fmt.Sprintf("the domain %q is already reserved by another application", domain))
+		}
+	}
+
+	return nil
+}
+
+func (s *service) ReleaseUnusedReservations(appId uuid.UUID, usedDomains []string) error {
+	reservations, err := s.reservations.FindByApp(appId)
+	if err != nil {
+		return errors.Wrap(err, "error retrieving domain reservations")
+	}
+
+	used := map[string]bool{}
+	for _, domain := range usedDomains {
+		used[domain] = true
+	}
+	for _, reservation := range reservations {
+		if used[reservation.Domain] {
+			continue
+		}
+		err = s.reservations.Delete(reservation.Domain)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("error releasing reservation for domain %q", reservation.Domain))
+		}
+	}
+
+	return nil
+}
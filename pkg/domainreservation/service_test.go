@@ -0,0 +1,177 @@
+package domainreservation
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_EnsureReservations_ExistingReservation(t *testing.T) {
+	appId := uuid.New()
+	reservations := &core.FakeDomainReservationRepository{
+		GetByDomainFn: func(domain string) (*core.DomainReservation, error) {
+			assert.Equal(t, "myapp.example.com", domain)
+			return &core.DomainReservation{Domain: domain, AppId: appId}, nil
+		},
+	}
+
+	svc := service{reservations}
+
+	err := svc.EnsureReservations(appId, []string{"myapp.example.com"}, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, reservations.GetByDomainCallCount)
+	assert.Equal(t, 0, reservations.CreateCallCount)
+}
+
+func Test_EnsureReservations_NewReservation(t *testing.T) {
+	appId := uuid.New()
+	reservations := &core.FakeDomainReservationRepository{
+		GetByDomainFn: func(domain string) (*core.DomainReservation, error) {
+			return nil, core.ErrNotFound
+		},
+		CreateFn: func(reservation *core.DomainReservation) error {
+			assert.Equal(t, appId, reservation.AppId)
+			return nil
+		},
+	}
+
+	svc := service{reservations}
+
+	err := svc.EnsureReservations(appId, []string{"a.example.com", "b.example.com"}, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, reservations.CreateCallCount)
+}
+
+func Test_EnsureReservations_DryRunDoesNotCreate(t *testing.T) {
+	reservations := &core.FakeDomainReservationRepository{
+		GetByDomainFn: func(domain string) (*core.DomainReservation, error) {
+			return nil, core.ErrNotFound
+		},
+	}
+
+	svc := service{reservations}
+
+	err := svc.EnsureReservations(uuid.New(), []string{"myapp.example.com"}, true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, reservations.GetByDomainCallCount)
+	assert.Equal(t, 0, reservations.CreateCallCount)
+}
+
+func Test_EnsureReservations_OwnedByAnotherApp(t *testing.T) {
+	reservations := &core.FakeDomainReservationRepository{
+		GetByDomainFn: func(domain string) (*core.DomainReservation, error) {
+			return &core.DomainReservation{Domain: domain, AppId: uuid.New()}, nil
+		},
+	}
+
+	svc := service{reservations}
+
+	err := svc.EnsureReservations(uuid.New(), []string{"myapp.example.com"}, true)
+
+	assert.IsType(t, &core.ValidationError{}, err)
+	assert.Equal(t, `the domain "myapp.example.com" is already reserved by another application`, err.Error())
+}
+
+func Test_EnsureReservations_GetErr(t *testing.T) {
+	reservations := &core.FakeDomainReservationRepository{
+		GetByDomainFn: func(domain string) (*core.DomainReservation, error) {
+			return nil, errors.New("test")
+		},
+	}
+
+	svc := service{reservations}
+
+	err := svc.EnsureReservations(uuid.New(), []string{"myapp.example.com"}, false)
+
+	assert.Equal(t, `error retrieving reservation for domain "myapp.example.com": test`, err.Error())
+}
+
+func Test_EnsureReservations_CreateErr(t *testing.T) {
+	reservations := &core.FakeDomainReservationRepository{
+		GetByDomainFn: func(domain string) (*core.DomainReservation, error) {
+			return nil, core.ErrNotFound
+		},
+		CreateFn: func(reservation *core.DomainReservation) error {
+			return errors.New("test")
+		},
+	}
+
+	svc := service{reservations}
+
+	err := svc.EnsureReservations(uuid.New(), []string{"myapp.example.com"}, false)
+
+	assert.Equal(t, `error creating reservation for domain "myapp.example.com": test`, err.Error())
+}
+
+func Test_EnsureReservations_ReservedByAnotherAppConcurrently(t *testing.T) {
+	otherAppId := uuid.New()
+	reservations := &core.FakeDomainReservationRepository{
+		CreateFn: func(reservation *core.DomainReservation) error {
+			return core.ErrAlreadyExists
+		},
+	}
+	// The other app reserves the domain after it is first retrieved
+	reservations.GetByDomainFn = func(domain string) (*core.DomainReservation, error) {
+		if reservations.CreateCallCount == 0 {
+			return nil, core.ErrNotFound
+		}
+		return &core.DomainReservation{Domain: domain, AppId: otherAppId}, nil
+	}
+
+	svc := service{reservations}
+
+	err := svc.EnsureReservations(uuid.New(), []string{"myapp.example.com"}, false)
+
+	assert.IsType(t, &core.ValidationError{}, err)
+	assert.Equal(t, `the domain "myapp.example.com" is already reserved by another application`, err.Error())
+	assert.Equal(t, 2, reservations.GetByDomainCallCount)
+}
+
+func Test_ReleaseUnusedReservations(t *testing.T) {
+	appId := uuid.New()
+	deleted := []string{}
+	reservations := &core.FakeDomainReservationRepository{
+		FindByAppFn: func(appIdArg uuid.UUID) ([]core.DomainReservation, error) {
+			assert.Equal(t, appId, appIdArg)
+			return []core.DomainReservation{
+				{Domain: "a.example.com", AppId: appId},
+				{Domain: "b.example.com", AppId: appId},
+				{Domain: "c.example.com", AppId: appId},
+			}, nil
+		},
+		DeleteFn: func(domain string) error {
+			deleted = append(deleted, domain)
+			return nil
+		},
+	}
+
+	svc := service{reservations}
+
+	err := svc.ReleaseUnusedReservations(appId, []string{"b.example.com"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.example.com", "c.example.com"}, deleted)
+}
+
+func Test_ReleaseUnusedReservations_DeleteErr(t *testing.T) {
+	reservations := &core.FakeDomainReservationRepository{
+		FindByAppFn: func(uuid.UUID) ([]core.DomainReservation, error) {
+			return []core.DomainReservation{{Domain: "myapp.example.com"}}, nil
+		},
+		DeleteFn: func(domain string) error {
+			return errors.New("test")
+		},
+	}
+
+	svc := service{reservations}
+
+	err := svc.ReleaseUnusedReservations(uuid.New(), nil)
+
+	assert.Equal(t, `error releasing reservation for domain "myapp.example.com": test`, err.Error())
+}
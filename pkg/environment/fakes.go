@@ -10,6 +10,8 @@ type FakeService struct {
 	GetStatusFn          func(envName string) (*core.EnvironmentStatus, error)
 	GetStatusCallCount   int
 	ValidateDeployableFn func(envName string) error
+	GetConfigFn          func(envName string) (*core.EnvironmentConfig, error)
+	GetConfigCallCount   int
 }
 
 func (fake *FakeService) Ping(envName string) error {
@@ -26,6 +28,11 @@ func (fake *FakeService) SetConfig(string, *core.EnvironmentConfig) error {
 	panic("NI")
 }
 
+func (fake *FakeService) GetConfig(envName string) (*core.EnvironmentConfig, error) {
+	fake.GetConfigCallCount++
+	return fake.GetConfigFn(envName)
+}
+
 func (fake *FakeService) ValidateDeployable(envName string) error {
 	return fake.ValidateDeployableFn(envName)
 }
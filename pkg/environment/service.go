@@ -21,6 +21,7 @@ type Service interface {
 	// If the environment has not yet been provisioned this will automatically create the environment (this may change in the future)
 	Ping(envName string) error
 	SetConfig(envName string, environment *core.EnvironmentConfig) error
+	GetConfig(envName string) (*core.EnvironmentConfig, error)
 	GetStatus(envName string) (*core.EnvironmentStatus, error)
 	ValidateDeployable(envName string) error
 }
@@ -33,7 +34,8 @@ func NewService(environments core.EnvironmentRepository) Service {
 	return &service{environments}
 }
 
-// SetConfig merges any non zero value with the existing environment configuration
+// SetConfig merges any non zero value with the existing environment configuration. Sections that control app policy are replaced as a
+// whole instead since a merge could never turn off, relax, or remove a value (see replacePolicySections).
 // DeleteConfig should be added if we ever need to clear a environment config value
 func (s *service) SetConfig(envName string, environmentConfig *core.EnvironmentConfig) error {
 	environment, err := s.environments.Get(envName)
@@ -45,6 +47,7 @@ func (s *service) SetConfig(envName string, environmentConfig *core.EnvironmentC
 	if err != nil {
 		return errors.Wrap(err, fmt.Sprintf("Error merging environment configuration for environment %q", envName))
 	}
+	replacePolicySections(&environment.Doc.Config, environmentConfig)
 
 	err = s.environments.Save(environment)
 	if err != nil {
@@ -54,6 +57,25 @@ func (s *service) SetConfig(envName string, environmentConfig *core.EnvironmentC
 	return nil
 }
 
+// replacePolicySections replaces the sections of the environment configuration that a merge cannot turn off or remove
+func replacePolicySections(dst, src *core.EnvironmentConfig) {
+	dst.DefaultDenyAccess = src.DefaultDenyAccess
+	dst.KNativeScheduling = src.KNativeScheduling
+	dst.AppDefaults = src.AppDefaults
+	dst.AppConstraints = src.AppConstraints
+	dst.AdmissionPolicies = src.AdmissionPolicies
+	dst.SecurityBaseline = src.SecurityBaseline
+}
+
+func (s *service) GetConfig(envName string) (*core.EnvironmentConfig, error) {
+	environment, err := s.environments.Get(envName)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Error retrieving environment %q", envName))
+	}
+
+	return &environment.Doc.Config, nil
+}
+
 func (s *service) GetStatus(envName string) (*core.EnvironmentStatus, error) {
 	environment, err := s.environments.Get(envName)
 	if err != nil {
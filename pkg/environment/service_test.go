@@ -5,8 +5,11 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/riser-platform/riser-server/api/v1/model"
 	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/riser-platform/riser-server/pkg/util"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func Test_Ping(t *testing.T) {
@@ -211,6 +214,131 @@ func Test_SetConfig_IgnoresEmptyValues(t *testing.T) {
 	assert.Equal(t, 1, environmentRepository.SaveCallCount)
 }
 
+func Test_SetConfig_TurnsOffDefaultDenyAccess(t *testing.T) {
+	environmentRepository := &core.FakeEnvironmentRepository{
+		GetFn: func(envName string) (*core.Environment, error) {
+			return &core.Environment{
+				Name: "myenv",
+				Doc: core.EnvironmentDoc{
+					Config: core.EnvironmentConfig{
+						SealedSecretCert:  []byte{0x2},
+						DefaultDenyAccess: true,
+					},
+				},
+			}, nil
+		},
+		SaveFn: func(environment *core.Environment) error {
+			assert.False(t, environment.Doc.Config.DefaultDenyAccess)
+			assert.Equal(t, []byte{0x2}, environment.Doc.Config.SealedSecretCert)
+			return nil
+		},
+	}
+
+	service := service{environmentRepository}
+
+	err := service.SetConfig("myenv", &core.EnvironmentConfig{DefaultDenyAccess: false})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, environmentRepository.SaveCallCount)
+}
+
+func Test_SetConfig_ReplacesAppDefaultsAndConstraints(t *testing.T) {
+	appDefaults := &model.OverrideableAppConfig{
+		Environment: map[string]intstr.IntOrString{"LOG_LEVEL": intstr.FromString("info")},
+	}
+	environmentRepository := &core.FakeEnvironmentRepository{
+		GetFn: func(envName string) (*core.Environment, error) {
+			return &core.Environment{
+				Name: "myenv",
+				Doc: core.EnvironmentDoc{
+					Config: core.EnvironmentConfig{
+						AppDefaults: &model.OverrideableAppConfig{
+							Environment:    map[string]intstr.IntOrString{"REGION": intstr.FromString("us-east-1")},
+							TimeoutSeconds: util.PtrInt64(30),
+						},
+						AppConstraints: &model.EnvironmentAppConstraints{
+							Autoscale: &model.EnvironmentAutoscaleConstraints{MinAtLeast: util.PtrInt(2)},
+						},
+					},
+				},
+			}, nil
+		},
+		SaveFn: func(environment *core.Environment) error {
+			assert.Equal(t, appDefaults, environment.Doc.Config.AppDefaults)
+			assert.Nil(t, environment.Doc.Config.AppConstraints)
+			return nil
+		},
+	}
+
+	service := service{environmentRepository}
+
+	err := service.SetConfig("myenv", &core.EnvironmentConfig{AppDefaults: appDefaults})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, environmentRepository.SaveCallCount)
+}
+
+func Test_SetConfig_ClearsAdmissionPolicies(t *testing.T) {
+	environmentRepository := &core.FakeEnvironmentRepository{
+		GetFn: func(envName string) (*core.Environment, error) {
+			return &core.Environment{
+				Name: "myenv",
+				Doc: core.EnvironmentDoc{
+					Config: core.EnvironmentConfig{
+						AdmissionPolicies: []model.EnvironmentAdmissionPolicy{
+							{Name: "registry", Expression: "image =~ '^registry.example.com/'"},
+						},
+					},
+				},
+			}, nil
+		},
+		SaveFn: func(environment *core.Environment) error {
+			assert.Empty(t, environment.Doc.Config.AdmissionPolicies)
+			return nil
+		},
+	}
+
+	service := service{environmentRepository}
+
+	err := service.SetConfig("myenv", &core.EnvironmentConfig{AdmissionPolicies: []model.EnvironmentAdmissionPolicy{}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, environmentRepository.SaveCallCount)
+}
+
+func Test_SetConfig_RelaxesSecurityBaseline(t *testing.T) {
+	securityBaseline := &model.EnvironmentSecurityBaseline{DenyPrivilegeEscalation: true}
+	environmentRepository := &core.FakeEnvironmentRepository{
+		GetFn: func(envName string) (*core.Environment, error) {
+			return &core.Environment{
+				Name: "myenv",
+				Doc: core.EnvironmentDoc{
+					Config: core.EnvironmentConfig{
+						SecurityBaseline: &model.EnvironmentSecurityBaseline{
+							RunAsNonRoot:            true,
+							ReadOnlyRootFilesystem:  true,
+							DenyPrivilegeEscalation: true,
+							DropCapabilities:        []string{"ALL"},
+							AllowedCapabilities:     []string{"NET_BIND_SERVICE"},
+						},
+					},
+				},
+			}, nil
+		},
+		SaveFn: func(environment *core.Environment) error {
+			assert.Equal(t, securityBaseline, environment.Doc.Config.SecurityBaseline)
+			return nil
+		},
+	}
+
+	service := service{environmentRepository}
+
+	err := service.SetConfig("myenv", &core.EnvironmentConfig{SecurityBaseline: securityBaseline})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, environmentRepository.SaveCallCount)
+}
+
 func Test_ValidateDeployable(t *testing.T) {
 	environmentRepository := &core.FakeEnvironmentRepository{
 		ListFn: func() ([]core.Environment, error) {
@@ -253,3 +381,41 @@ func Test_ValidateDeployable_ReturnsError(t *testing.T) {
 
 	assert.Equal(t, "Unable to validate environment: failed", err.Error())
 }
+
+func Test_GetConfig(t *testing.T) {
+	environmentRepository := &core.FakeEnvironmentRepository{
+		GetFn: func(envName string) (*core.Environment, error) {
+			assert.Equal(t, "myenv", envName)
+			return &core.Environment{
+				Name: "myenv",
+				Doc: core.EnvironmentDoc{
+					Config: core.EnvironmentConfig{
+						PublicGatewayHost: "myhost",
+					},
+				},
+			}, nil
+		},
+	}
+
+	service := service{environmentRepository}
+
+	result, err := service.GetConfig("myenv")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "myhost", result.PublicGatewayHost)
+}
+
+func Test_GetConfig_WhenGetFails(t *testing.T) {
+	environmentRepository := &core.FakeEnvironmentRepository{
+		GetFn: func(envName string) (*core.Environment, error) {
+			return nil, errors.New("test")
+		},
+	}
+
+	service := service{environmentRepository}
+
+	result, err := service.GetConfig("myenv")
+
+	assert.Nil(t, result)
+	assert.Equal(t, `Error retrieving environment "myenv": test`, err.Error())
+}
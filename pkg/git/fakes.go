@@ -13,6 +13,8 @@ type FakeRepo struct {
 	PushCallCount            int
 	ResetHardRemoteFn        func() error
 	ResetHardRemoteCallCount int
+	ReadFilesFn              func(path string) ([]core.ResourceFile, error)
+	ReadFilesCallCount       int
 	sync.Mutex
 }
 
@@ -30,3 +32,8 @@ func (fake *FakeRepo) ResetHardRemote() error {
 	fake.ResetHardRemoteCallCount++
 	return fake.ResetHardRemoteFn()
 }
+
+func (fake *FakeRepo) ReadFiles(path string) ([]core.ResourceFile, error) {
+	fake.ReadFilesCallCount++
+	return fake.ReadFilesFn(path)
+}
@@ -41,6 +41,9 @@ type Repo interface {
 	Commit(message string, files []core.ResourceFile) error
 	Push() error
 	ResetHardRemote() error
+	// ReadFiles returns the files at the path relative to the root of the repo. If the path is a directory all files in the
+	// directory are returned recursively. An empty slice is returned if the path does not exist.
+	ReadFiles(path string) ([]core.ResourceFile, error)
 	// Lock locks the repo. Be sure to call Unlock when your work is completed.
 	Lock()
 	// Unlock unlocks the repo.
@@ -99,6 +102,10 @@ func (repo *repo) Commit(message string, files []core.ResourceFile) error {
 	return err
 }
 
+func (repo *repo) ReadFiles(path string) ([]core.ResourceFile, error) {
+	return readFiles(repo.settings.LocalGitDir, path)
+}
+
 func (repo *repo) addAll() error {
 	return repo.execGitCmd("add", "--all")
 }
@@ -40,6 +40,37 @@ func processFiles(baseDir string, files []core.ResourceFile) error {
 	return nil
 }
 
+func readFiles(baseDir string, path string) ([]core.ResourceFile, error) {
+	files := []core.ResourceFile{}
+	err := filepath.Walk(filepath.Join(baseDir, path), func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		contents, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(baseDir, filePath)
+		if err != nil {
+			return err
+		}
+		files = append(files, core.ResourceFile{Name: relPath, Contents: contents})
+		return nil
+	})
+
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []core.ResourceFile{}, nil
+		}
+		return nil, errors.Wrap(err, "error reading files")
+	}
+
+	return files, nil
+}
+
 func isNoChangesErr(err error) bool {
 	return strings.Contains(err.Error(), "working tree clean")
 }
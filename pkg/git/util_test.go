@@ -57,6 +57,59 @@ func Test_processFiles_deletes(t *testing.T) {
 	assert.True(t, os.IsNotExist(err))
 }
 
+func Test_readFiles(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "riser-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = processFiles(dir, []core.ResourceFile{
+		{Name: "nested/test01", Contents: []byte("contents01")},
+		{Name: "nested/deep/test02", Contents: []byte("contents02")},
+	})
+	assert.NoError(t, err)
+
+	result, err := readFiles(dir, "nested")
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, "nested/deep/test02", result[0].Name)
+	assert.EqualValues(t, "contents02", result[0].Contents)
+	assert.Equal(t, "nested/test01", result[1].Name)
+	assert.EqualValues(t, "contents01", result[1].Contents)
+}
+
+func Test_readFiles_singleFile(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "riser-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = processFiles(dir, []core.ResourceFile{{Name: "nested/test01", Contents: []byte("contents")}})
+	assert.NoError(t, err)
+
+	result, err := readFiles(dir, "nested/test01")
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, "nested/test01", result[0].Name)
+}
+
+func Test_readFiles_notExists(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "riser-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	result, err := readFiles(dir, "nested")
+
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+}
+
 func Test_isNoChangesErr_cleanTreeErr(t *testing.T) {
 	result := isNoChangesErr(errors.New("Your branch is up to date with 'origin/main'.\n\nnothing to commit, working tree clean\n"))
 
@@ -3,7 +3,9 @@ package namespace
 import "github.com/riser-platform/riser-server/pkg/state"
 
 type FakeService struct {
-	ValidateDeployableFn func(string) error
+	ValidateDeployableFn                  func(string) error
+	EnsureNamespaceInEnvironmentFn        func(namespaceName string, envName string, committer state.Committer) error
+	EnsureNamespaceInEnvironmentCallCount int
 }
 
 func (fake *FakeService) ValidateDeployable(namespaceName string) error {
@@ -13,7 +15,8 @@ func (fake *FakeService) EnsureDefaultNamespace(committer state.Committer) error
 	panic("NI")
 }
 func (fake *FakeService) EnsureNamespaceInEnvironment(namespaceName string, envName string, committer state.Committer) error {
-	panic("NI")
+	fake.EnsureNamespaceInEnvironmentCallCount++
+	return fake.EnsureNamespaceInEnvironmentFn(namespaceName, envName, committer)
 }
 func (fake *FakeService) Create(namespaceName string, committer state.Committer) error {
 	panic("NI")
@@ -259,3 +259,81 @@ func (r *deploymentRepository) UpdateTraffic(name *core.NamespacedName, envName
 
 	return nil
 }
+
+func (r *deploymentRepository) SaveRevision(revision *core.DeploymentRevision) error {
+	_, err := r.db.Exec(`
+	INSERT INTO deployment_revision (deployment_id, riser_revision, doc)
+	VALUES ($1,$2,$3)
+	ON CONFLICT (deployment_id, riser_revision) DO UPDATE SET doc = EXCLUDED.doc
+	`, revision.DeploymentId, revision.RiserRevision, &revision.Doc)
+	return err
+}
+
+func (r *deploymentRepository) DeleteRevision(deploymentId uuid.UUID, riserRevision int64) error {
+	_, err := r.db.Exec(`
+	DELETE FROM deployment_revision
+	WHERE deployment_id = $1 AND riser_revision = $2
+	`, deploymentId, riserRevision)
+	return err
+}
+
+func (r *deploymentRepository) FindRevisions(deploymentId uuid.UUID, limit int) ([]core.DeploymentRevision, error) {
+	revisions := []core.DeploymentRevision{}
+	rows, err := r.db.Query(`
+	SELECT deployment_id, riser_revision, doc
+	FROM deployment_revision
+	WHERE deployment_id = $1
+	ORDER BY riser_revision DESC
+	LIMIT $2
+	`, deploymentId, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		revision := core.DeploymentRevision{}
+		err := rows.Scan(&revision.DeploymentId, &revision.RiserRevision, &revision.Doc)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, nil
+}
+
+func (r *deploymentRepository) FindRevisionsByApp(appId uuid.UUID, limit int) ([]core.DeploymentRevision, error) {
+	revisions := []core.DeploymentRevision{}
+	rows, err := r.db.Query(`
+	SELECT deployment_id, riser_revision, doc
+	FROM (
+		SELECT
+			deployment_revision.deployment_id,
+			deployment_revision.riser_revision,
+			deployment_revision.doc,
+			ROW_NUMBER() OVER (PARTITION BY deployment_revision.deployment_id ORDER BY deployment_revision.riser_revision DESC) AS revision_rank
+		FROM deployment_revision
+		INNER JOIN deployment ON deployment_revision.deployment_id = deployment.id
+		INNER JOIN deployment_reservation ON deployment.deployment_reservation_id = deployment_reservation.id
+		WHERE deployment_reservation.app_id = $1 AND deployment.deleted_at IS NULL
+	) ranked_revision
+	WHERE revision_rank <= $2
+	ORDER BY deployment_id, riser_revision DESC
+	`, appId, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		revision := core.DeploymentRevision{}
+		err := rows.Scan(&revision.DeploymentId, &revision.RiserRevision, &revision.Doc)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, nil
+}
@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/riser-platform/riser-server/pkg/core"
+)
+
+type domainReservationRepository struct {
+	db *sql.DB
+}
+
+func NewDomainReservationRepository(db *sql.DB) core.DomainReservationRepository {
+	return &domainReservationRepository{db: db}
+}
+
+func (r *domainReservationRepository) Create(reservation *core.DomainReservation) error {
+	result, err := r.db.Exec(`INSERT INTO domain_reservation (domain, app_id) VALUES ($1,$2) ON CONFLICT (domain) DO NOTHING`,
+		&reservation.Domain, &reservation.AppId)
+	if err != nil {
+		return err
+	}
+	if !resultHasRows(result) {
+		return core.ErrAlreadyExists
+	}
+	return nil
+}
+
+func (r *domainReservationRepository) Delete(domain string) error {
+	_, err := r.db.Exec(`DELETE FROM domain_reservation WHERE domain = $1`, domain)
+	return err
+}
+
+func (r *domainReservationRepository) GetByDomain(domain string) (*core.DomainReservation, error) {
+	reservation := &core.DomainReservation{}
+	err := r.db.QueryRow(`SELECT domain, app_id FROM domain_reservation WHERE domain = $1`, domain).
+		Scan(&reservation.Domain, &reservation.AppId)
+	return reservation, noRowsErrorHandler(err)
+}
+
+func (r *domainReservationRepository) FindByApp(appId uuid.UUID) ([]core.DomainReservation, error) {
+	reservations := []core.DomainReservation{}
+	rows, err := r.db.Query(`SELECT domain, app_id FROM domain_reservation WHERE app_id = $1 ORDER BY domain`, appId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		reservation := core.DomainReservation{}
+		err := rows.Scan(&reservation.Domain, &reservation.AppId)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	return reservations, nil
+}
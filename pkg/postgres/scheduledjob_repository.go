@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riser-platform/riser-server/pkg/core"
+)
+
+type scheduledJobRepository struct {
+	db *sql.DB
+}
+
+func NewScheduledJobRepository(db *sql.DB) core.ScheduledJobRepository {
+	return &scheduledJobRepository{db: db}
+}
+
+func (r *scheduledJobRepository) Create(job *core.ScheduledJob) error {
+	_, err := r.db.Exec(`
+	INSERT INTO scheduled_job (id, kind, run_at, status, doc)
+	VALUES ($1,$2,$3,$4,$5)`,
+		job.Id, job.Kind, job.RunAt, job.Status, &job.Doc)
+	return err
+}
+
+func (r *scheduledJobRepository) Get(id uuid.UUID) (*core.ScheduledJob, error) {
+	job := &core.ScheduledJob{}
+	err := r.db.QueryRow(`
+	SELECT id, kind, run_at, status, doc
+	FROM scheduled_job
+	WHERE id = $1
+	`, id).Scan(&job.Id, &job.Kind, &job.RunAt, &job.Status, &job.Doc)
+
+	return job, noRowsErrorHandler(err)
+}
+
+func (r *scheduledJobRepository) List(status string) ([]core.ScheduledJob, error) {
+	jobs := []core.ScheduledJob{}
+	rows, err := r.db.Query(`
+	SELECT id, kind, run_at, status, doc
+	FROM scheduled_job
+	WHERE $1 = '' OR status = $1
+	ORDER BY run_at DESC
+	`, status)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		job := core.ScheduledJob{}
+		err := rows.Scan(&job.Id, &job.Kind, &job.RunAt, &job.Status, &job.Doc)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+func (r *scheduledJobRepository) Cancel(id uuid.UUID) error {
+	result, err := r.db.Exec(`
+	UPDATE scheduled_job SET status = $2
+	WHERE id = $1 AND status = $3
+	`, id, core.ScheduledJobStatusCancelled, core.ScheduledJobStatusPending)
+
+	if err != nil {
+		return err
+	}
+
+	if !resultHasRows(result) {
+		return core.ErrConflictNewerVersion
+	}
+
+	return nil
+}
+
+// ClaimNext uses "SKIP LOCKED" so that multiple server instances never run the same job
+func (r *scheduledJobRepository) ClaimNext(runAt time.Time) (*core.ScheduledJob, error) {
+	job := &core.ScheduledJob{}
+	err := r.db.QueryRow(`
+	UPDATE scheduled_job SET status = $2
+	WHERE id = (
+		SELECT id FROM scheduled_job
+		WHERE status = $3 AND run_at <= $1
+		ORDER BY run_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING id, kind, run_at, status, doc
+	`, runAt, core.ScheduledJobStatusRunning, core.ScheduledJobStatusPending).Scan(&job.Id, &job.Kind, &job.RunAt, &job.Status, &job.Doc)
+
+	return job, noRowsErrorHandler(err)
+}
+
+func (r *scheduledJobRepository) Complete(id uuid.UUID, status string, result *core.ScheduledJobResult) error {
+	_, err := r.db.Exec(`
+	UPDATE scheduled_job
+	SET status = $2, doc = jsonb_set(doc, '{result}', $3)
+	WHERE id = $1
+	`, id, status, result)
+
+	return err
+}
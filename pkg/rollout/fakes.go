@@ -0,0 +1,30 @@
+package rollout
+
+import (
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/riser-platform/riser-server/pkg/state"
+)
+
+type FakeService struct {
+	UpdateTrafficFn                  func(name *core.NamespacedName, envName string, traffic core.TrafficConfig, committer state.Committer) error
+	UpdateTrafficCallCount           int
+	ApplyPreDeployTrafficFn          func(name *core.NamespacedName, envName string, riserRevision int64, committer state.Committer) error
+	ApplyPreDeployTrafficCallCount   int
+	DiscardPreDeployTrafficFn        func(name *core.NamespacedName, envName string, riserRevision int64) error
+	DiscardPreDeployTrafficCallCount int
+}
+
+func (fake *FakeService) UpdateTraffic(name *core.NamespacedName, envName string, traffic core.TrafficConfig, committer state.Committer) error {
+	fake.UpdateTrafficCallCount++
+	return fake.UpdateTrafficFn(name, envName, traffic, committer)
+}
+
+func (fake *FakeService) ApplyPreDeployTraffic(name *core.NamespacedName, envName string, riserRevision int64, committer state.Committer) error {
+	fake.ApplyPreDeployTrafficCallCount++
+	return fake.ApplyPreDeployTrafficFn(name, envName, riserRevision, committer)
+}
+
+func (fake *FakeService) DiscardPreDeployTraffic(name *core.NamespacedName, envName string, riserRevision int64) error {
+	fake.DiscardPreDeployTrafficCallCount++
+	return fake.DiscardPreDeployTrafficFn(name, envName, riserRevision)
+}
@@ -7,12 +7,18 @@ import (
 
 	"github.com/riser-platform/riser-server/api/v1/model"
 	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/riser-platform/riser-server/pkg/git"
 	"github.com/riser-platform/riser-server/pkg/state"
 	"github.com/riser-platform/riser-server/pkg/state/resources"
 )
 
 type Service interface {
 	UpdateTraffic(name *core.NamespacedName, envName string, rollout core.TrafficConfig, committer state.Committer) error
+	// ApplyPreDeployTraffic applies the traffic held for a revision until its pre-deploy job succeeded
+	ApplyPreDeployTraffic(name *core.NamespacedName, envName string, riserRevision int64, committer state.Committer) error
+	// DiscardPreDeployTraffic discards the traffic held for a revision whose pre-deploy job failed so that the revision never receives
+	// traffic. The traffic of the previous revisions is kept until the next deployment.
+	DiscardPreDeployTraffic(name *core.NamespacedName, envName string, riserRevision int64) error
 }
 
 type service struct {
@@ -43,6 +49,81 @@ func (s *service) UpdateTraffic(name *core.NamespacedName, envName string, traff
 		return err
 	}
 
+	return commitRoute(name, envName, app, deployment, traffic, committer)
+}
+
+func (s *service) ApplyPreDeployTraffic(name *core.NamespacedName, envName string, riserRevision int64, committer state.Committer) error {
+	deployment, revision, err := s.getPendingRevision(name, envName, riserRevision)
+	if err != nil || revision == nil {
+		return err
+	}
+
+	app, err := s.apps.Get(deployment.AppId)
+	if err != nil {
+		return errors.Wrap(err, "error getting app")
+	}
+
+	// The pending traffic is only cleared once the route is committed so that a failure is retried when the status is next reported
+	err = commitRoute(name, envName, app, deployment, revision.Doc.PendingTraffic, committer)
+	if err != nil && err != git.ErrNoChanges {
+		return err
+	}
+
+	err = s.deployments.UpdateTraffic(name, envName, riserRevision, revision.Doc.PendingTraffic)
+	if err != nil {
+		return errors.Wrap(err, "error updating traffic")
+	}
+
+	revision.Doc.PendingTraffic = nil
+	err = s.deployments.SaveRevision(revision)
+	if err != nil {
+		return errors.Wrap(err, "error saving revision")
+	}
+
+	return nil
+}
+
+func (s *service) DiscardPreDeployTraffic(name *core.NamespacedName, envName string, riserRevision int64) error {
+	_, revision, err := s.getPendingRevision(name, envName, riserRevision)
+	if err != nil || revision == nil {
+		return err
+	}
+
+	revision.Doc.PendingTraffic = nil
+	err = s.deployments.SaveRevision(revision)
+	if err != nil {
+		return errors.Wrap(err, "error saving revision")
+	}
+
+	return nil
+}
+
+// getPendingRevision returns the deployment and the revision if the revision is the deployment's latest revision and is holding traffic
+// until its pre-deploy job completes. The revision is nil otherwise (e.g. the held traffic was already applied or discarded).
+func (s *service) getPendingRevision(name *core.NamespacedName, envName string, riserRevision int64) (*core.Deployment, *core.DeploymentRevision, error) {
+	deployment, err := s.deployments.GetByName(name, envName)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error getting deployment")
+	}
+
+	// A newer revision holds its own traffic
+	if deployment.RiserRevision != riserRevision {
+		return deployment, nil, nil
+	}
+
+	revisions, err := s.deployments.FindRevisions(deployment.DeploymentRecord.Id, 1)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error finding revisions")
+	}
+
+	if len(revisions) == 0 || revisions[0].RiserRevision != riserRevision || len(revisions[0].Doc.PendingTraffic) == 0 {
+		return deployment, nil, nil
+	}
+
+	return deployment, &revisions[0], nil
+}
+
+func commitRoute(name *core.NamespacedName, envName string, app *core.App, deployment *core.Deployment, traffic core.TrafficConfig, committer state.Committer) error {
 	// TODO: Refactor underlying code to not require the entire deployment context. Currently this is hydrated only with fields that we know are needed
 	ctx := &core.DeploymentContext{
 		DeploymentConfig: &core.DeploymentConfig{
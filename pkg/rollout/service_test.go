@@ -7,7 +7,9 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/riser-platform/riser-server/pkg/state"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // See snapshot test for happy path
@@ -118,3 +120,191 @@ func Test_UpdateTraffic_ValidatesRevisionStatus_NoStatus(t *testing.T) {
 
 	assert.Equal(t, `revision "1" either does not exist or has not reported its status yet`, result.Error())
 }
+
+func Test_ApplyPreDeployTraffic(t *testing.T) {
+	appId := uuid.New()
+	deploymentId := uuid.New()
+	pendingTraffic := core.TrafficConfig{
+		core.TrafficConfigRule{
+			RiserRevision: 2,
+			RevisionName:  "myapp-2",
+			Percent:       100,
+		},
+	}
+
+	deployments := &core.FakeDeploymentRepository{
+		GetByNameFn: func(*core.NamespacedName, string) (*core.Deployment, error) {
+			return &core.Deployment{
+				DeploymentReservation: core.DeploymentReservation{AppId: appId},
+				DeploymentRecord:      core.DeploymentRecord{Id: deploymentId, RiserRevision: 2},
+			}, nil
+		},
+		FindRevisionsFn: func(deploymentIdArg uuid.UUID, limit int) ([]core.DeploymentRevision, error) {
+			assert.Equal(t, deploymentId, deploymentIdArg)
+			assert.Equal(t, 1, limit)
+			return []core.DeploymentRevision{
+				{DeploymentId: deploymentId, RiserRevision: 2, Doc: core.DeploymentRevisionDoc{DockerTag: "v2", PendingTraffic: pendingTraffic}},
+			}, nil
+		},
+		UpdateTrafficFn: func(name *core.NamespacedName, envName string, riserRevision int64, traffic core.TrafficConfig) error {
+			assert.Equal(t, core.NewNamespacedName("myapp", "myns"), name)
+			assert.Equal(t, "dev", envName)
+			assert.EqualValues(t, 2, riserRevision)
+			assert.Equal(t, pendingTraffic, traffic)
+			return nil
+		},
+		SaveRevisionFn: func(revision *core.DeploymentRevision) error {
+			assert.EqualValues(t, 2, revision.RiserRevision)
+			assert.Equal(t, "v2", revision.Doc.DockerTag)
+			assert.Empty(t, revision.Doc.PendingTraffic)
+			return nil
+		},
+	}
+
+	apps := &core.FakeAppRepository{
+		GetFn: func(id uuid.UUID) (*core.App, error) {
+			return &core.App{Id: id, Name: "myapp"}, nil
+		},
+	}
+
+	committer := state.NewDryRunCommitter()
+	svc := service{apps, deployments}
+
+	err := svc.ApplyPreDeployTraffic(core.NewNamespacedName("myapp", "myns"), "dev", 2, committer)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deployments.UpdateTrafficCallCount)
+	assert.Equal(t, 1, deployments.SaveRevisionCallCount)
+	require.Len(t, committer.Commits, 1)
+	require.Len(t, committer.Commits[0].Files, 1)
+	assert.Equal(t, "state/dev/riser-managed/myns/deployments/myapp/serving.knative.dev.route.myapp.yaml", committer.Commits[0].Files[0].Name)
+	assert.Contains(t, string(committer.Commits[0].Files[0].Contents), "revisionName: myapp-2")
+}
+
+func Test_ApplyPreDeployTraffic_WhenNewerRevision(t *testing.T) {
+	deployments := &core.FakeDeploymentRepository{
+		GetByNameFn: func(*core.NamespacedName, string) (*core.Deployment, error) {
+			return &core.Deployment{
+				DeploymentRecord: core.DeploymentRecord{RiserRevision: 3},
+			}, nil
+		},
+	}
+
+	svc := service{deployments: deployments}
+
+	err := svc.ApplyPreDeployTraffic(core.NewNamespacedName("myapp", "myns"), "dev", 2, nil)
+
+	assert.NoError(t, err)
+}
+
+func Test_ApplyPreDeployTraffic_WhenNoPendingTraffic(t *testing.T) {
+	deployments := &core.FakeDeploymentRepository{
+		GetByNameFn: func(*core.NamespacedName, string) (*core.Deployment, error) {
+			return &core.Deployment{
+				DeploymentRecord: core.DeploymentRecord{RiserRevision: 2},
+			}, nil
+		},
+		FindRevisionsFn: func(uuid.UUID, int) ([]core.DeploymentRevision, error) {
+			return []core.DeploymentRevision{{RiserRevision: 2}}, nil
+		},
+	}
+
+	svc := service{deployments: deployments}
+
+	err := svc.ApplyPreDeployTraffic(core.NewNamespacedName("myapp", "myns"), "dev", 2, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, deployments.UpdateTrafficCallCount)
+}
+
+func Test_ApplyPreDeployTraffic_ReturnsFindRevisionsError(t *testing.T) {
+	deployments := &core.FakeDeploymentRepository{
+		GetByNameFn: func(*core.NamespacedName, string) (*core.Deployment, error) {
+			return &core.Deployment{
+				DeploymentRecord: core.DeploymentRecord{RiserRevision: 2},
+			}, nil
+		},
+		FindRevisionsFn: func(uuid.UUID, int) ([]core.DeploymentRevision, error) {
+			return nil, errors.New("test")
+		},
+	}
+
+	svc := service{deployments: deployments}
+
+	err := svc.ApplyPreDeployTraffic(core.NewNamespacedName("myapp", "myns"), "dev", 2, nil)
+
+	assert.Equal(t, "error finding revisions: test", err.Error())
+}
+
+func Test_DiscardPreDeployTraffic(t *testing.T) {
+	deploymentId := uuid.New()
+	deployments := &core.FakeDeploymentRepository{
+		GetByNameFn: func(*core.NamespacedName, string) (*core.Deployment, error) {
+			return &core.Deployment{
+				DeploymentRecord: core.DeploymentRecord{Id: deploymentId, RiserRevision: 2},
+			}, nil
+		},
+		FindRevisionsFn: func(deploymentIdArg uuid.UUID, limit int) ([]core.DeploymentRevision, error) {
+			assert.Equal(t, deploymentId, deploymentIdArg)
+			assert.Equal(t, 1, limit)
+			return []core.DeploymentRevision{
+				{DeploymentId: deploymentId, RiserRevision: 2, Doc: core.DeploymentRevisionDoc{
+					DockerTag:      "v2",
+					PendingTraffic: core.TrafficConfig{{RiserRevision: 2, RevisionName: "myapp-2", Percent: 100}},
+				}},
+			}, nil
+		},
+		SaveRevisionFn: func(revision *core.DeploymentRevision) error {
+			assert.EqualValues(t, 2, revision.RiserRevision)
+			assert.Equal(t, "v2", revision.Doc.DockerTag)
+			assert.Empty(t, revision.Doc.PendingTraffic)
+			return nil
+		},
+	}
+
+	svc := service{deployments: deployments}
+
+	err := svc.DiscardPreDeployTraffic(core.NewNamespacedName("myapp", "myns"), "dev", 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deployments.SaveRevisionCallCount)
+	assert.Equal(t, 0, deployments.UpdateTrafficCallCount)
+}
+
+func Test_DiscardPreDeployTraffic_WhenNoPendingTraffic(t *testing.T) {
+	deployments := &core.FakeDeploymentRepository{
+		GetByNameFn: func(*core.NamespacedName, string) (*core.Deployment, error) {
+			return &core.Deployment{
+				DeploymentRecord: core.DeploymentRecord{RiserRevision: 2},
+			}, nil
+		},
+		FindRevisionsFn: func(uuid.UUID, int) ([]core.DeploymentRevision, error) {
+			return []core.DeploymentRevision{{RiserRevision: 2}}, nil
+		},
+	}
+
+	svc := service{deployments: deployments}
+
+	err := svc.DiscardPreDeployTraffic(core.NewNamespacedName("myapp", "myns"), "dev", 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, deployments.SaveRevisionCallCount)
+}
+
+func Test_DiscardPreDeployTraffic_WhenNewerRevision(t *testing.T) {
+	deployments := &core.FakeDeploymentRepository{
+		GetByNameFn: func(*core.NamespacedName, string) (*core.Deployment, error) {
+			return &core.Deployment{
+				DeploymentRecord: core.DeploymentRecord{RiserRevision: 3},
+			}, nil
+		},
+	}
+
+	svc := service{deployments: deployments}
+
+	err := svc.DiscardPreDeployTraffic(core.NewNamespacedName("myapp", "myns"), "dev", 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, deployments.FindRevisionsCallCount)
+	assert.Equal(t, 0, deployments.SaveRevisionCallCount)
+}
@@ -0,0 +1,40 @@
+package scheduledjob
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riser-platform/riser-server/api/v1/model"
+	"github.com/riser-platform/riser-server/pkg/core"
+)
+
+type FakeService struct {
+	ScheduleDeploymentFn        func(runAt time.Time, request *model.DeploymentRequest, deployments []*core.DeploymentConfig) (*core.ScheduledJob, error)
+	ScheduleDeploymentCallCount int
+	ScheduleRolloutFn           func(runAt time.Time, name *core.NamespacedName, envName string, traffic core.TrafficConfig) (*core.ScheduledJob, error)
+	ScheduleRolloutCallCount    int
+	CancelFn                    func(id uuid.UUID) error
+	CancelCallCount             int
+	RunNextFn                   func() (bool, error)
+	RunNextCallCount            int
+}
+
+func (fake *FakeService) ScheduleDeployment(runAt time.Time, request *model.DeploymentRequest, deployments []*core.DeploymentConfig) (*core.ScheduledJob, error) {
+	fake.ScheduleDeploymentCallCount++
+	return fake.ScheduleDeploymentFn(runAt, request, deployments)
+}
+
+func (fake *FakeService) ScheduleRollout(runAt time.Time, name *core.NamespacedName, envName string, traffic core.TrafficConfig) (*core.ScheduledJob, error) {
+	fake.ScheduleRolloutCallCount++
+	return fake.ScheduleRolloutFn(runAt, name, envName, traffic)
+}
+
+func (fake *FakeService) Cancel(id uuid.UUID) error {
+	fake.CancelCallCount++
+	return fake.CancelFn(id)
+}
+
+func (fake *FakeService) RunNext() (bool, error) {
+	fake.RunNextCallCount++
+	return fake.RunNextFn()
+}
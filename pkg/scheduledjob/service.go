@@ -0,0 +1,184 @@
+package scheduledjob
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/riser-platform/riser-server/api/v1/model"
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/riser-platform/riser-server/pkg/deployment"
+	"github.com/riser-platform/riser-server/pkg/environment"
+	"github.com/riser-platform/riser-server/pkg/git"
+	"github.com/riser-platform/riser-server/pkg/rollout"
+	"github.com/riser-platform/riser-server/pkg/state"
+)
+
+type Service interface {
+	// ScheduleDeployment plans the deployments as a dry run before the job is created so that a deployment that would be rejected is
+	// not scheduled. The environment config is applied to the request again when the job runs.
+	ScheduleDeployment(runAt time.Time, request *model.DeploymentRequest, deployments []*core.DeploymentConfig) (*core.ScheduledJob, error)
+	ScheduleRollout(runAt time.Time, name *core.NamespacedName, envName string, traffic core.TrafficConfig) (*core.ScheduledJob, error)
+	// Cancel cancels a pending job. Returns a ValidationError if the job is no longer pending.
+	Cancel(id uuid.UUID) error
+	// RunNext runs the next job that is due. Returns false if there were no jobs due.
+	// Errors from the job itself are recorded in the job's result and are not returned.
+	RunNext() (bool, error)
+}
+
+type service struct {
+	jobs               core.ScheduledJobRepository
+	deploymentService  deployment.Service
+	environmentService environment.Service
+	rolloutService     rollout.Service
+	committer          state.Committer
+}
+
+func NewService(jobs core.ScheduledJobRepository, deploymentService deployment.Service, environmentService environment.Service, rolloutService rollout.Service, committer state.Committer) Service {
+	return &service{jobs, deploymentService, environmentService, rolloutService, committer}
+}
+
+func (s *service) ScheduleDeployment(runAt time.Time, request *model.DeploymentRequest, deployments []*core.DeploymentConfig) (*core.ScheduledJob, error) {
+	err := s.deploymentService.Plan(deployments)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled := &core.ScheduledDeployment{Deployments: []core.DeploymentConfig{}, Request: request}
+	for _, deploymentConfig := range deployments {
+		scheduled.Deployments = append(scheduled.Deployments, *deploymentConfig)
+	}
+
+	return s.create(runAt, core.ScheduledJobKindDeployment, core.ScheduledJobDoc{Deployment: scheduled})
+}
+
+func (s *service) ScheduleRollout(runAt time.Time, name *core.NamespacedName, envName string, traffic core.TrafficConfig) (*core.ScheduledJob, error) {
+	return s.create(runAt, core.ScheduledJobKindRollout, core.ScheduledJobDoc{
+		Rollout: &core.ScheduledRollout{
+			Name:            *name,
+			EnvironmentName: envName,
+			Traffic:         traffic,
+		},
+	})
+}
+
+func (s *service) create(runAt time.Time, kind string, doc core.ScheduledJobDoc) (*core.ScheduledJob, error) {
+	doc.CreatedAt = time.Now().UTC()
+	job := &core.ScheduledJob{
+		Id:     uuid.New(),
+		Kind:   kind,
+		RunAt:  runAt.UTC(),
+		Status: core.ScheduledJobStatusPending,
+		Doc:    doc,
+	}
+
+	err := s.jobs.Create(job)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating scheduled job")
+	}
+
+	return job, nil
+}
+
+func (s *service) Cancel(id uuid.UUID) error {
+	err := s.jobs.Cancel(id)
+	if err == core.ErrConflictNewerVersion {
+		return core.NewValidationErrorMessage(fmt.Sprintf("The job %q does not exist or is no longer pending", id))
+	}
+	return err
+}
+
+func (s *service) RunNext() (bool, error) {
+	job, err := s.jobs.ClaimNext(time.Now().UTC())
+	if err != nil {
+		if err == core.ErrNotFound {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "Error claiming scheduled job")
+	}
+
+	result := &core.ScheduledJobResult{StartedAt: time.Now().UTC()}
+	status := core.ScheduledJobStatusSucceeded
+	err = s.run(job, result)
+	if err != nil {
+		if err == git.ErrNoChanges {
+			result.Message = "No changes to apply"
+		} else {
+			status = core.ScheduledJobStatusFailed
+			result.Message = err.Error()
+		}
+	}
+	result.CompletedAt = time.Now().UTC()
+
+	err = s.jobs.Complete(job.Id, status, result)
+	if err != nil {
+		return true, errors.Wrap(err, fmt.Sprintf("Error recording result for scheduled job %q", job.Id))
+	}
+
+	return true, nil
+}
+
+func (s *service) run(job *core.ScheduledJob, result *core.ScheduledJobResult) error {
+	switch job.Kind {
+	case core.ScheduledJobKindDeployment:
+		if job.Doc.Deployment == nil || len(job.Doc.Deployment.Deployments) == 0 {
+			return errors.New("The scheduled deployment is empty")
+		}
+		deployments, err := s.scheduledDeployments(job.Doc.Deployment)
+		if err != nil {
+			return err
+		}
+
+		if len(deployments) == 1 {
+			riserRevision, err := s.deploymentService.Update(deployments[0], s.committer, false)
+			if err != nil {
+				return err
+			}
+			result.RiserRevisions = map[string]int64{deployments[0].EnvironmentName: riserRevision}
+		} else {
+			riserRevisions, err := s.deploymentService.UpdateMany(deployments, s.committer, false)
+			if err != nil {
+				return err
+			}
+			result.RiserRevisions = riserRevisions
+		}
+		result.Message = "Deployment applied"
+	case core.ScheduledJobKindRollout:
+		if job.Doc.Rollout == nil {
+			return errors.New("The scheduled rollout is empty")
+		}
+		err := s.rolloutService.UpdateTraffic(&job.Doc.Rollout.Name, job.Doc.Rollout.EnvironmentName, job.Doc.Rollout.Traffic, s.committer)
+		if err != nil {
+			return err
+		}
+		result.Message = "Rollout applied"
+	default:
+		return fmt.Errorf("Unknown scheduled job kind %q", job.Kind)
+	}
+
+	return nil
+}
+
+// scheduledDeployments returns the deployments of a scheduled deployment with the current environment config applied to the request
+func (s *service) scheduledDeployments(scheduled *core.ScheduledDeployment) ([]*core.DeploymentConfig, error) {
+	deployments := []*core.DeploymentConfig{}
+	for idx := range scheduled.Deployments {
+		if scheduled.Request == nil {
+			deployments = append(deployments, &scheduled.Deployments[idx])
+			continue
+		}
+
+		envName := scheduled.Deployments[idx].EnvironmentName
+		environmentConfig, err := s.environmentService.GetConfig(envName)
+		if err != nil {
+			return nil, err
+		}
+		deploymentConfig, err := deployment.NewDeploymentConfig(scheduled.Request, envName, environmentConfig)
+		if err != nil {
+			return nil, err
+		}
+		deployments = append(deployments, deploymentConfig)
+	}
+	return deployments, nil
+}
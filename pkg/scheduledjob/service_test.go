@@ -0,0 +1,336 @@
+package scheduledjob
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/riser-platform/riser-server/api/v1/model"
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/riser-platform/riser-server/pkg/deployment"
+	"github.com/riser-platform/riser-server/pkg/environment"
+	"github.com/riser-platform/riser-server/pkg/git"
+	"github.com/riser-platform/riser-server/pkg/rollout"
+	"github.com/riser-platform/riser-server/pkg/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func Test_ScheduleDeployment(t *testing.T) {
+	runAt := time.Now().Add(time.Hour)
+	request := &model.DeploymentRequest{DeploymentMeta: model.DeploymentMeta{Name: "myapp"}}
+	deployments := []*core.DeploymentConfig{{Name: "myapp", EnvironmentName: "dev"}, {Name: "myapp", EnvironmentName: "prod"}}
+	jobs := &core.FakeScheduledJobRepository{
+		CreateFn: func(job *core.ScheduledJob) error {
+			assert.NotEqual(t, uuid.Nil, job.Id)
+			assert.Equal(t, core.ScheduledJobKindDeployment, job.Kind)
+			assert.Equal(t, core.ScheduledJobStatusPending, job.Status)
+			assert.True(t, runAt.Equal(job.RunAt))
+			assert.False(t, job.Doc.CreatedAt.IsZero())
+			require.NotNil(t, job.Doc.Deployment)
+			assert.Len(t, job.Doc.Deployment.Deployments, 2)
+			assert.Equal(t, "prod", job.Doc.Deployment.Deployments[1].EnvironmentName)
+			assert.Equal(t, request, job.Doc.Deployment.Request)
+			assert.Nil(t, job.Doc.Rollout)
+			return nil
+		},
+	}
+	deploymentService := &deployment.FakeService{
+		PlanFn: func(deploymentsArg []*core.DeploymentConfig) error {
+			assert.Equal(t, deployments, deploymentsArg)
+			return nil
+		},
+	}
+	s := service{jobs: jobs, deploymentService: deploymentService}
+
+	result, err := s.ScheduleDeployment(runAt, request, deployments)
+
+	assert.NoError(t, err)
+	assert.Equal(t, core.ScheduledJobKindDeployment, result.Kind)
+	assert.Equal(t, 1, deploymentService.PlanCallCount)
+	assert.Equal(t, 1, jobs.CreateCallCount)
+}
+
+func Test_ScheduleDeployment_WhenPlanFails(t *testing.T) {
+	jobs := &core.FakeScheduledJobRepository{}
+	deploymentService := &deployment.FakeService{
+		PlanFn: func([]*core.DeploymentConfig) error {
+			return &core.ValidationError{Message: "rejected"}
+		},
+	}
+	s := service{jobs: jobs, deploymentService: deploymentService}
+
+	result, err := s.ScheduleDeployment(time.Now(), &model.DeploymentRequest{}, []*core.DeploymentConfig{{Name: "myapp", EnvironmentName: "dev"}})
+
+	assert.Nil(t, result)
+	assert.Equal(t, "rejected", err.Error())
+	assert.Equal(t, 0, jobs.CreateCallCount)
+}
+
+func Test_ScheduleRollout(t *testing.T) {
+	runAt := time.Now().Add(time.Hour)
+	traffic := core.TrafficConfig{{RiserRevision: 1, RevisionName: "myapp-1", Percent: 100}}
+	jobs := &core.FakeScheduledJobRepository{
+		CreateFn: func(job *core.ScheduledJob) error {
+			assert.Equal(t, core.ScheduledJobKindRollout, job.Kind)
+			require.NotNil(t, job.Doc.Rollout)
+			assert.Equal(t, "myapp", job.Doc.Rollout.Name.Name)
+			assert.Equal(t, "dev", job.Doc.Rollout.EnvironmentName)
+			assert.Equal(t, traffic, job.Doc.Rollout.Traffic)
+			return nil
+		},
+	}
+	s := service{jobs: jobs}
+
+	_, err := s.ScheduleRollout(runAt, core.NewNamespacedName("myapp", "apps"), "dev", traffic)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, jobs.CreateCallCount)
+}
+
+func Test_Cancel_NotPending(t *testing.T) {
+	jobs := &core.FakeScheduledJobRepository{
+		CancelFn: func(uuid.UUID) error {
+			return core.ErrConflictNewerVersion
+		},
+	}
+	s := service{jobs: jobs}
+	id := uuid.MustParse("8B4B9C4A-8B35-4A5A-9F3B-0A6C6E7D2E51")
+
+	err := s.Cancel(id)
+
+	assert.IsType(t, &core.ValidationError{}, err)
+	assert.Equal(t, `The job "8b4b9c4a-8b35-4a5a-9f3b-0a6c6e7d2e51" does not exist or is no longer pending`, err.Error())
+}
+
+func Test_RunNext_NoJobs(t *testing.T) {
+	jobs := &core.FakeScheduledJobRepository{
+		ClaimNextFn: func(time.Time) (*core.ScheduledJob, error) {
+			return nil, core.ErrNotFound
+		},
+	}
+	s := service{jobs: jobs}
+
+	ran, err := s.RunNext()
+
+	assert.NoError(t, err)
+	assert.False(t, ran)
+	assert.Equal(t, 0, jobs.CompleteCallCount)
+}
+
+func Test_RunNext_Deployment(t *testing.T) {
+	committer := state.NewDryRunCommitter()
+	job := &core.ScheduledJob{
+		Id:   uuid.New(),
+		Kind: core.ScheduledJobKindDeployment,
+		Doc: core.ScheduledJobDoc{
+			Deployment: &core.ScheduledDeployment{
+				Deployments: []core.DeploymentConfig{{Name: "myapp", EnvironmentName: "dev"}},
+			},
+		},
+	}
+	jobs := &core.FakeScheduledJobRepository{
+		ClaimNextFn: func(time.Time) (*core.ScheduledJob, error) {
+			return job, nil
+		},
+		CompleteFn: func(id uuid.UUID, status string, result *core.ScheduledJobResult) error {
+			assert.Equal(t, job.Id, id)
+			assert.Equal(t, core.ScheduledJobStatusSucceeded, status)
+			assert.Equal(t, "Deployment applied", result.Message)
+			assert.Equal(t, map[string]int64{"dev": 4}, result.RiserRevisions)
+			assert.False(t, result.StartedAt.IsZero())
+			assert.False(t, result.CompletedAt.IsZero())
+			return nil
+		},
+	}
+	deploymentService := &deployment.FakeService{
+		UpdateFn: func(deploymentConfig *core.DeploymentConfig, committerArg state.Committer, dryRun bool) (int64, error) {
+			assert.Equal(t, "myapp", deploymentConfig.Name)
+			assert.Equal(t, committer, committerArg)
+			assert.False(t, dryRun)
+			return 4, nil
+		},
+	}
+	s := service{jobs: jobs, deploymentService: deploymentService, committer: committer}
+
+	ran, err := s.RunNext()
+
+	assert.NoError(t, err)
+	assert.True(t, ran)
+	assert.Equal(t, 1, deploymentService.UpdateCallCount)
+	assert.Equal(t, 1, jobs.CompleteCallCount)
+}
+
+func Test_RunNext_DeploymentAppliesEnvironmentConfig(t *testing.T) {
+	job := &core.ScheduledJob{
+		Id:   uuid.New(),
+		Kind: core.ScheduledJobKindDeployment,
+		Doc: core.ScheduledJobDoc{
+			Deployment: &core.ScheduledDeployment{
+				Deployments: []core.DeploymentConfig{{Name: "myapp", EnvironmentName: "dev"}},
+				Request: &model.DeploymentRequest{
+					DeploymentMeta: model.DeploymentMeta{Name: "myapp", Docker: model.DeploymentDocker{Tag: "0.0.1"}},
+					App: &model.AppConfigWithOverrides{
+						AppConfig: model.AppConfig{
+							Name:      "myapp",
+							Namespace: "apps",
+							Id:        uuid.New(),
+							Image:     "hashicorp/http-echo",
+							Expose:    &model.AppConfigExpose{ContainerPort: 8000},
+						},
+					},
+				},
+			},
+		},
+	}
+	jobs := &core.FakeScheduledJobRepository{
+		ClaimNextFn: func(time.Time) (*core.ScheduledJob, error) {
+			return job, nil
+		},
+		CompleteFn: func(id uuid.UUID, status string, result *core.ScheduledJobResult) error {
+			assert.Equal(t, core.ScheduledJobStatusSucceeded, status)
+			return nil
+		},
+	}
+	environmentService := &environment.FakeService{
+		GetConfigFn: func(envName string) (*core.EnvironmentConfig, error) {
+			assert.Equal(t, "dev", envName)
+			return &core.EnvironmentConfig{
+				AppDefaults: &model.OverrideableAppConfig{Environment: map[string]intstr.IntOrString{"LOG_LEVEL": intstr.FromString("info")}},
+			}, nil
+		},
+	}
+	deploymentService := &deployment.FakeService{
+		UpdateFn: func(deploymentConfig *core.DeploymentConfig, _ state.Committer, _ bool) (int64, error) {
+			assert.Equal(t, "myapp", deploymentConfig.Name)
+			assert.Equal(t, "dev", deploymentConfig.EnvironmentName)
+			assert.Equal(t, "0.0.1", deploymentConfig.Docker.Tag)
+			assert.Equal(t, intstr.FromString("info"), deploymentConfig.App.Environment["LOG_LEVEL"])
+			return 2, nil
+		},
+	}
+	s := service{jobs: jobs, deploymentService: deploymentService, environmentService: environmentService}
+
+	ran, err := s.RunNext()
+
+	assert.NoError(t, err)
+	assert.True(t, ran)
+	assert.Equal(t, 1, environmentService.GetConfigCallCount)
+	assert.Equal(t, 1, deploymentService.UpdateCallCount)
+}
+
+func Test_RunNext_DeploymentMultipleEnvironments(t *testing.T) {
+	job := &core.ScheduledJob{
+		Id:   uuid.New(),
+		Kind: core.ScheduledJobKindDeployment,
+		Doc: core.ScheduledJobDoc{
+			Deployment: &core.ScheduledDeployment{
+				Deployments: []core.DeploymentConfig{{Name: "myapp", EnvironmentName: "dev"}, {Name: "myapp", EnvironmentName: "prod"}},
+			},
+		},
+	}
+	jobs := &core.FakeScheduledJobRepository{
+		ClaimNextFn: func(time.Time) (*core.ScheduledJob, error) {
+			return job, nil
+		},
+		CompleteFn: func(id uuid.UUID, status string, result *core.ScheduledJobResult) error {
+			assert.Equal(t, core.ScheduledJobStatusSucceeded, status)
+			assert.Equal(t, map[string]int64{"dev": 4, "prod": 2}, result.RiserRevisions)
+			return nil
+		},
+	}
+	deploymentService := &deployment.FakeService{
+		UpdateManyFn: func(deployments []*core.DeploymentConfig, committer state.Committer, dryRun bool) (map[string]int64, error) {
+			assert.Len(t, deployments, 2)
+			return map[string]int64{"dev": 4, "prod": 2}, nil
+		},
+	}
+	s := service{jobs: jobs, deploymentService: deploymentService}
+
+	ran, err := s.RunNext()
+
+	assert.NoError(t, err)
+	assert.True(t, ran)
+	assert.Equal(t, 1, deploymentService.UpdateManyCallCount)
+}
+
+func Test_RunNext_RecordsFailure(t *testing.T) {
+	job := &core.ScheduledJob{
+		Id:   uuid.New(),
+		Kind: core.ScheduledJobKindRollout,
+		Doc: core.ScheduledJobDoc{
+			Rollout: &core.ScheduledRollout{Name: core.NamespacedName{Name: "myapp", Namespace: "apps"}, EnvironmentName: "dev"},
+		},
+	}
+	jobs := &core.FakeScheduledJobRepository{
+		ClaimNextFn: func(time.Time) (*core.ScheduledJob, error) {
+			return job, nil
+		},
+		CompleteFn: func(id uuid.UUID, status string, result *core.ScheduledJobResult) error {
+			assert.Equal(t, core.ScheduledJobStatusFailed, status)
+			assert.Equal(t, "test", result.Message)
+			return nil
+		},
+	}
+	rolloutService := &rollout.FakeService{
+		UpdateTrafficFn: func(name *core.NamespacedName, envName string, traffic core.TrafficConfig, committer state.Committer) error {
+			assert.Equal(t, "myapp.apps", name.String())
+			assert.Equal(t, "dev", envName)
+			return errors.New("test")
+		},
+	}
+	s := service{jobs: jobs, rolloutService: rolloutService}
+
+	ran, err := s.RunNext()
+
+	assert.NoError(t, err)
+	assert.True(t, ran)
+	assert.Equal(t, 1, jobs.CompleteCallCount)
+}
+
+func Test_RunNext_NoChanges(t *testing.T) {
+	job := &core.ScheduledJob{
+		Id:   uuid.New(),
+		Kind: core.ScheduledJobKindRollout,
+		Doc: core.ScheduledJobDoc{
+			Rollout: &core.ScheduledRollout{Name: core.NamespacedName{Name: "myapp", Namespace: "apps"}, EnvironmentName: "dev"},
+		},
+	}
+	jobs := &core.FakeScheduledJobRepository{
+		ClaimNextFn: func(time.Time) (*core.ScheduledJob, error) {
+			return job, nil
+		},
+		CompleteFn: func(id uuid.UUID, status string, result *core.ScheduledJobResult) error {
+			assert.Equal(t, core.ScheduledJobStatusSucceeded, status)
+			assert.Equal(t, "No changes to apply", result.Message)
+			return nil
+		},
+	}
+	rolloutService := &rollout.FakeService{
+		UpdateTrafficFn: func(*core.NamespacedName, string, core.TrafficConfig, state.Committer) error {
+			return git.ErrNoChanges
+		},
+	}
+	s := service{jobs: jobs, rolloutService: rolloutService}
+
+	ran, err := s.RunNext()
+
+	assert.NoError(t, err)
+	assert.True(t, ran)
+}
+
+func Test_RunNext_ClaimError(t *testing.T) {
+	jobs := &core.FakeScheduledJobRepository{
+		ClaimNextFn: func(time.Time) (*core.ScheduledJob, error) {
+			return nil, errors.New("test")
+		},
+	}
+	s := service{jobs: jobs}
+
+	ran, err := s.RunNext()
+
+	assert.False(t, ran)
+	assert.Equal(t, "Error claiming scheduled job: test", err.Error())
+}
@@ -0,0 +1,39 @@
+package scheduledjob
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultWorkerInterval is how often the worker checks for jobs that are due
+var DefaultWorkerInterval = time.Duration(15) * time.Second
+
+// RunWorker runs jobs as they become due until the context is cancelled.
+// TODO: Jobs that were running when the server stopped remain in the "running" status and are not retried.
+func RunWorker(ctx context.Context, s Service, interval time.Duration, logger *logrus.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		runDueJobs(s, logger)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func runDueJobs(s Service, logger *logrus.Logger) {
+	for {
+		ran, err := s.RunNext()
+		if err != nil {
+			logger.WithField("category", "scheduledjob").Errorf("Error running scheduled job: %s", err)
+			return
+		}
+		if !ran {
+			return
+		}
+	}
+}
@@ -26,13 +26,15 @@ type Client struct {
 	client  *http.Client
 
 	// Model clients
-	Apps         AppsClient
-	Deployments  DeploymentsClient
-	Namespaces   NamespacesClient
-	Rollouts     RolloutsClient
-	Secrets      SecretsClient
-	Environments EnvironmentsClient
-	Validate     ValidateClient
+	Apps          AppsClient
+	Deployments   DeploymentsClient
+	Namespaces    NamespacesClient
+	Rollouts      RolloutsClient
+	ScheduledJobs ScheduledJobsClient
+	Schemas       SchemasClient
+	Secrets       SecretsClient
+	Environments  EnvironmentsClient
+	Validate      ValidateClient
 }
 
 func NewClient(baseURI string, apikey string) (*Client, error) {
@@ -48,6 +50,8 @@ func NewClient(baseURI string, apikey string) (*Client, error) {
 	client.Deployments = &deploymentsClient{client}
 	client.Namespaces = &namespacesClient{client}
 	client.Rollouts = &rolloutsClient{client}
+	client.ScheduledJobs = &scheduledJobsClient{client}
+	client.Schemas = &schemasClient{client}
 	client.Secrets = &secretsClient{client}
 	client.Environments = &environmentsClient{client}
 	client.Validate = &validateClient{client}
@@ -102,7 +106,7 @@ func (c *Client) Do(req *http.Request, v interface{}) (*http.Response, error) {
 		return response, err
 	}
 
-	if v != nil {
+	if v != nil && response.StatusCode != http.StatusNoContent {
 		// TODO: Try to use same error handling logic from validateResponse and return a ClientError instead
 		responseBytes, err := ioutil.ReadAll(response.Body)
 		if err != nil {
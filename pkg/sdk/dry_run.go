@@ -0,0 +1,34 @@
+package sdk
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/riser-platform/riser-server/api/v1/model"
+)
+
+// WriteDryRunDiffs writes the file diffs from a dry run in a human readable format (e.g. for reviewing in CI)
+func WriteDryRunDiffs(writer io.Writer, commits []model.DryRunCommit) error {
+	for _, commit := range commits {
+		_, err := fmt.Fprintf(writer, "# %s\n", commit.Message)
+		if err != nil {
+			return err
+		}
+
+		if len(commit.Diffs) == 0 {
+			_, err = fmt.Fprintln(writer, "No changes")
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, diff := range commit.Diffs {
+			_, err = fmt.Fprintf(writer, "[%s] %s\n%s", diff.Change, diff.Name, diff.Diff)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package sdk
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/riser-platform/riser-server/api/v1/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const expectedDryRunDiffs = `# commit1
+[added] file1
+--- /dev/null
++++ b/file1
+@@ -0,0 +1 @@
++a: 1
+[removed] file2
+--- a/file2
++++ /dev/null
+@@ -1 +0,0 @@
+-b: 1
+# commit2
+No changes
+`
+
+func Test_WriteDryRunDiffs(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	commits := []model.DryRunCommit{
+		{
+			Message: "commit1",
+			Diffs: []model.DryRunFileDiff{
+				{
+					Name:   "file1",
+					Change: model.DryRunFileChange_Added,
+					Diff:   "--- /dev/null\n+++ b/file1\n@@ -0,0 +1 @@\n+a: 1\n",
+				},
+				{
+					Name:   "file2",
+					Change: model.DryRunFileChange_Removed,
+					Diff:   "--- a/file2\n+++ /dev/null\n@@ -1 +0,0 @@\n-b: 1\n",
+				},
+			},
+		},
+		{
+			Message: "commit2",
+		},
+	}
+
+	err := WriteDryRunDiffs(buffer, commits)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedDryRunDiffs, buffer.String())
+}
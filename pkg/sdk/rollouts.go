@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"regexp"
 	"strconv"
+	"time"
 
 	"github.com/riser-platform/riser-server/api/v1/model"
 )
@@ -14,6 +15,8 @@ var trafficRuleExp = regexp.MustCompile("r([0-9]+):([0-9]+)")
 
 type RolloutsClient interface {
 	Save(deploymentName, namespace, envName string, trafficRule ...string) error
+	// Schedule saves the rollout at the specified time
+	Schedule(deploymentName, namespace, envName string, runAt time.Time, trafficRule ...string) (*model.ScheduledJob, error)
 }
 
 type rolloutsClient struct {
@@ -21,10 +24,43 @@ type rolloutsClient struct {
 }
 
 func (c *rolloutsClient) Save(deploymentName, namespace, envName string, trafficRule ...string) error {
-	rolloutRequest := model.RolloutRequest{}
-	for _, rule := range trafficRule {
+	rolloutRequest, err := newRolloutRequest(trafficRule)
+	if err != nil {
+		return err
+	}
+	request, err := c.client.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/rollout/%s/%s/%s", envName, namespace, deploymentName), rolloutRequest)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.client.Do(request, nil)
+	return err
+}
+
+func (c *rolloutsClient) Schedule(deploymentName, namespace, envName string, runAt time.Time, trafficRule ...string) (*model.ScheduledJob, error) {
+	rolloutRequest, err := newRolloutRequest(trafficRule)
+	if err != nil {
+		return nil, err
+	}
+	rolloutRequest.Schedule = &runAt
+	request, err := c.client.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/rollout/%s/%s/%s", envName, namespace, deploymentName), rolloutRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &model.ScheduledJob{}
+	_, err = c.client.Do(request, job)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func newRolloutRequest(trafficRules []string) (*model.RolloutRequest, error) {
+	rolloutRequest := &model.RolloutRequest{}
+	for _, rule := range trafficRules {
 		if !trafficRuleExp.MatchString(rule) {
-			return errors.New("Rules must be in the format of \"r(rev):(percentage)\" e.g. \"r1:100\" routes 100% of traffic to rev 1")
+			return nil, errors.New("Rules must be in the format of \"r(rev):(percentage)\" e.g. \"r1:100\" routes 100% of traffic to rev 1")
 		}
 		ruleSplit := trafficRuleExp.FindStringSubmatch(rule)
 		rolloutRequest.Traffic = append(rolloutRequest.Traffic,
@@ -33,13 +69,7 @@ func (c *rolloutsClient) Save(deploymentName, namespace, envName string, traffic
 				Percent:       int(mustParseInt(ruleSplit[2])),
 			})
 	}
-	request, err := c.client.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/rollout/%s/%s/%s", envName, namespace, deploymentName), rolloutRequest)
-	if err != nil {
-		return err
-	}
-
-	_, err = c.client.Do(request, nil)
-	return err
+	return rolloutRequest, nil
 }
 
 // mustParseInt panics which should never happen - validate input before using!
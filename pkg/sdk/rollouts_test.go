@@ -1,8 +1,10 @@
 package sdk
 
 import (
+	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/riser-platform/riser-server/api/v1/model"
 	"github.com/stretchr/testify/assert"
@@ -29,6 +31,26 @@ func Test_Rollouts_Save(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func Test_Rollouts_Schedule(t *testing.T) {
+	setup()
+	defer teardown()
+
+	runAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	mux.HandleFunc("/api/v1/rollout/dev/myns/myapp", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		rollout := &model.RolloutRequest{}
+		mustUnmarshalR(r.Body, rollout)
+		assert.Len(t, rollout.Traffic, 1)
+		assert.True(t, runAt.Equal(*rollout.Schedule))
+		fmt.Fprint(w, `{"id": "5f1b7e2a-4c3d-4e8f-9a0b-1c2d3e4f5a6b", "kind": "rollout", "status": "pending"}`)
+	})
+
+	result, err := client.Rollouts.Schedule("myapp", "myns", "dev", runAt, "r1:100")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", result.Status)
+}
+
 func Test_Rollouts_Save_ReturnsError_WhenBadRule(t *testing.T) {
 	setup()
 	defer teardown()
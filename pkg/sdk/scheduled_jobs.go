@@ -0,0 +1,63 @@
+package sdk
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/riser-platform/riser-server/api/v1/model"
+)
+
+type ScheduledJobsClient interface {
+	// List returns scheduled jobs, optionally filtered by status. Pass an empty status to return jobs in any status.
+	List(status string) ([]model.ScheduledJob, error)
+	Get(id uuid.UUID) (*model.ScheduledJob, error)
+	Cancel(id uuid.UUID) error
+}
+
+type scheduledJobsClient struct {
+	client *Client
+}
+
+func (c *scheduledJobsClient) List(status string) ([]model.ScheduledJob, error) {
+	relativeUrl := "/api/v1/scheduledjobs"
+	if status != "" {
+		relativeUrl = fmt.Sprintf("%s?status=%s", relativeUrl, url.QueryEscape(status))
+	}
+	request, err := c.client.NewGetRequest(relativeUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := []model.ScheduledJob{}
+	_, err = c.client.Do(request, &jobs)
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (c *scheduledJobsClient) Get(id uuid.UUID) (*model.ScheduledJob, error) {
+	request, err := c.client.NewGetRequest(fmt.Sprintf("/api/v1/scheduledjobs/%s", id))
+	if err != nil {
+		return nil, err
+	}
+
+	job := &model.ScheduledJob{}
+	_, err = c.client.Do(request, job)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (c *scheduledJobsClient) Cancel(id uuid.UUID) error {
+	request, err := c.client.NewRequest(http.MethodDelete, fmt.Sprintf("/api/v1/scheduledjobs/%s", id), nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.client.Do(request, nil)
+	return err
+}
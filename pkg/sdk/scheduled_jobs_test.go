@@ -0,0 +1,63 @@
+package sdk
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+const testScheduledJobId = "5f1b7e2a-4c3d-4e8f-9a0b-1c2d3e4f5a6b"
+
+func Test_ScheduledJobs_List(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/scheduledjobs", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "pending", r.URL.Query().Get("status"))
+		fmt.Fprintf(w, `[{"id": %q, "kind": "rollout", "status": "pending", "environments": ["dev"]}]`, testScheduledJobId)
+	})
+
+	result, err := client.ScheduledJobs.List("pending")
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, testScheduledJobId, result[0].Id.String())
+	assert.Equal(t, "rollout", result[0].Kind)
+	assert.Equal(t, []string{"dev"}, result[0].Environments)
+}
+
+func Test_ScheduledJobs_Get(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/scheduledjobs/"+testScheduledJobId, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		fmt.Fprintf(w, `{"id": %q, "status": "failed", "result": {"message": "test"}}`, testScheduledJobId)
+	})
+
+	result, err := client.ScheduledJobs.Get(uuid.MustParse(testScheduledJobId))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "failed", result.Status)
+	assert.Equal(t, "test", result.Result.Message)
+}
+
+func Test_ScheduledJobs_Cancel(t *testing.T) {
+	setup()
+	defer teardown()
+
+	called := false
+	mux.HandleFunc("/api/v1/scheduledjobs/"+testScheduledJobId, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		called = true
+	})
+
+	err := client.ScheduledJobs.Cancel(uuid.MustParse(testScheduledJobId))
+
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
@@ -0,0 +1,29 @@
+package sdk
+
+import (
+	"github.com/riser-platform/riser-server/api/v1/model"
+)
+
+type SchemasClient interface {
+	// AppConfig returns the JSON Schema for the app config (e.g. for validating riser.yaml in an editor or CI)
+	AppConfig() (*model.JSONSchema, error)
+}
+
+type schemasClient struct {
+	client *Client
+}
+
+func (c *schemasClient) AppConfig() (*model.JSONSchema, error) {
+	request, err := c.client.NewGetRequest("/api/v1/schemas/appconfig")
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &model.JSONSchema{}
+	_, err = c.client.Do(request, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
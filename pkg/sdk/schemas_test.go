@@ -0,0 +1,27 @@
+package sdk
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Schemas_AppConfig(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/schemas/appconfig", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		fmt.Fprint(w, `{"$id":"https://riser.dev/schemas/appconfig.json","type":"object","properties":{"name":{"type":"string"}},"additionalProperties":false}`)
+	})
+
+	schema, err := client.Schemas.AppConfig()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://riser.dev/schemas/appconfig.json", schema.Id)
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, "string", schema.Properties["name"].Type)
+	assert.Equal(t, false, schema.AdditionalProperties)
+}
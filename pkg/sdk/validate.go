@@ -7,23 +7,25 @@ import (
 )
 
 type ValidateClient interface {
-	AppConfig(appConfig *model.AppConfigWithOverrides) error
+	// AppConfig validates the app config and returns any deprecated fields that were used
+	AppConfig(appConfig *model.AppConfigWithOverrides) (*model.ValidateAppConfigResponse, error)
 }
 
 type validateClient struct {
 	client *Client
 }
 
-func (c *validateClient) AppConfig(appConfig *model.AppConfigWithOverrides) error {
+func (c *validateClient) AppConfig(appConfig *model.AppConfigWithOverrides) (*model.ValidateAppConfigResponse, error) {
 	request, err := c.client.NewRequest(http.MethodPost, "/api/v1/validate/appconfig", appConfig)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = c.client.Do(request, nil)
+	response := &model.ValidateAppConfigResponse{}
+	_, err = c.client.Do(request, response)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return response, nil
 }
@@ -1,6 +1,7 @@
 package sdk
 
 import (
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -19,10 +20,28 @@ func Test_Validate_AppConfig(t *testing.T) {
 		actualModel := &model.AppConfigWithOverrides{}
 		mustUnmarshalR(r.Body, actualModel)
 		assert.Equal(t, requestModel, actualModel)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	result, err := client.Validate.AppConfig(requestModel)
+
+	assert.NoError(t, err)
+	assert.Empty(t, result.DeprecatedFields)
+}
+
+func Test_Validate_AppConfig_DeprecatedFields(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/validate/appconfig", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"deprecatedFields":[{"field":"resources.cpuCores","message":"use resources.limits.cpuCores instead"}]}`)
 	})
 
-	err := client.Validate.AppConfig(requestModel)
+	result, err := client.Validate.AppConfig(&model.AppConfigWithOverrides{})
 
 	assert.NoError(t, err)
+	assert.Len(t, result.DeprecatedFields, 1)
+	assert.Equal(t, "resources.cpuCores", result.DeprecatedFields[0].Field)
+	assert.Equal(t, "use resources.limits.cpuCores instead", result.DeprecatedFields[0].Message)
 }
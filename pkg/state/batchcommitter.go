@@ -0,0 +1,40 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/riser-platform/riser-server/pkg/core"
+)
+
+// BatchCommitter collects commits so that changes across multiple environments can be committed as a single atomic commit
+type BatchCommitter struct {
+	committer Committer
+	messages  []string
+	files     []core.ResourceFile
+}
+
+func NewBatchCommitter(committer Committer) *BatchCommitter {
+	return &BatchCommitter{
+		committer: committer,
+		messages:  []string{},
+		files:     []core.ResourceFile{},
+	}
+}
+
+// Commit collects the changes without committing them. Call Flush to commit all collected changes.
+func (committer *BatchCommitter) Commit(message string, files []core.ResourceFile) error {
+	committer.messages = append(committer.messages, message)
+	committer.files = append(committer.files, files...)
+	return nil
+}
+
+// Flush commits all collected changes using the underlying committer. The summary is used as the first line of the commit message
+// followed by the message of each collected commit.
+func (committer *BatchCommitter) Flush(summary string) error {
+	message := summary
+	if len(committer.messages) > 0 {
+		message = fmt.Sprintf("%s\n\n- %s", summary, strings.Join(committer.messages, "\n- "))
+	}
+	return committer.committer.Commit(message, committer.files)
+}
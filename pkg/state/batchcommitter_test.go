@@ -0,0 +1,30 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_BatchCommitter(t *testing.T) {
+	dryRunCommitter := NewDryRunCommitter()
+	committer := NewBatchCommitter(dryRunCommitter)
+
+	err := committer.Commit("commit1", []core.ResourceFile{{Name: "file1"}})
+	assert.NoError(t, err)
+	err = committer.Commit("commit2", []core.ResourceFile{{Name: "file2"}, {Name: "file3"}})
+	assert.NoError(t, err)
+	assert.Empty(t, dryRunCommitter.Commits)
+
+	err = committer.Flush("summary")
+
+	assert.NoError(t, err)
+	require.Len(t, dryRunCommitter.Commits, 1)
+	assert.Equal(t, "summary\n\n- commit1\n- commit2", dryRunCommitter.Commits[0].Message)
+	require.Len(t, dryRunCommitter.Commits[0].Files, 3)
+	assert.Equal(t, "file1", dryRunCommitter.Commits[0].Files[0].Name)
+	assert.Equal(t, "file2", dryRunCommitter.Commits[0].Files[1].Name)
+	assert.Equal(t, "file3", dryRunCommitter.Commits[0].Files[2].Name)
+}
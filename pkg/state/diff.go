@@ -0,0 +1,62 @@
+package state
+
+import (
+	"bytes"
+	"path/filepath"
+
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/riser-platform/riser-server/pkg/git"
+	"github.com/riser-platform/riser-server/pkg/util"
+)
+
+const (
+	FileChangeAdded   = "added"
+	FileChangeRemoved = "removed"
+	FileChangeChanged = "changed"
+)
+
+// FileDiff describes how a single file in the state repo would change
+type FileDiff struct {
+	Name   string
+	Change string
+	Diff   string
+}
+
+// DiffResourceFiles compares resource files with the current contents of the state repo. Files marked for deletion may refer to a
+// directory, in which case each file in that directory is reported as removed. Unchanged files are omitted.
+func DiffResourceFiles(repo git.Repo, files []core.ResourceFile) ([]FileDiff, error) {
+	diffs := []FileDiff{}
+	for _, file := range files {
+		existingFiles, err := repo.ReadFiles(file.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if file.Delete {
+			for _, existing := range existingFiles {
+				diffs = append(diffs, FileDiff{
+					Name:   existing.Name,
+					Change: FileChangeRemoved,
+					Diff:   util.UnifiedDiff(filepath.Join("a", existing.Name), util.DevNull, existing.Contents, nil),
+				})
+			}
+			continue
+		}
+
+		if len(existingFiles) == 0 {
+			diffs = append(diffs, FileDiff{
+				Name:   file.Name,
+				Change: FileChangeAdded,
+				Diff:   util.UnifiedDiff(util.DevNull, filepath.Join("b", file.Name), nil, file.Contents),
+			})
+		} else if !bytes.Equal(existingFiles[0].Contents, file.Contents) {
+			diffs = append(diffs, FileDiff{
+				Name:   file.Name,
+				Change: FileChangeChanged,
+				Diff:   util.UnifiedDiff(filepath.Join("a", file.Name), filepath.Join("b", file.Name), existingFiles[0].Contents, file.Contents),
+			})
+		}
+	}
+
+	return diffs, nil
+}
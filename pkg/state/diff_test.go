@@ -0,0 +1,70 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/riser-platform/riser-server/pkg/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DiffResourceFiles(t *testing.T) {
+	repo := &git.FakeRepo{
+		ReadFilesFn: func(path string) ([]core.ResourceFile, error) {
+			switch path {
+			case "changed.yaml":
+				return []core.ResourceFile{{Name: path, Contents: []byte("a: 1\n")}}, nil
+			case "unchanged.yaml":
+				return []core.ResourceFile{{Name: path, Contents: []byte("a: 1\n")}}, nil
+			}
+			return []core.ResourceFile{}, nil
+		},
+	}
+
+	files := []core.ResourceFile{
+		{Name: "added.yaml", Contents: []byte("a: 1\n")},
+		{Name: "changed.yaml", Contents: []byte("a: 2\n")},
+		{Name: "unchanged.yaml", Contents: []byte("a: 1\n")},
+	}
+
+	result, err := DiffResourceFiles(repo, files)
+
+	assert.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, FileDiff{
+		Name:   "added.yaml",
+		Change: FileChangeAdded,
+		Diff:   "--- /dev/null\n+++ b/added.yaml\n@@ -0,0 +1 @@\n+a: 1\n",
+	}, result[0])
+	assert.Equal(t, FileDiff{
+		Name:   "changed.yaml",
+		Change: FileChangeChanged,
+		Diff:   "--- a/changed.yaml\n+++ b/changed.yaml\n@@ -1 +1 @@\n-a: 1\n+a: 2\n",
+	}, result[1])
+}
+
+func Test_DiffResourceFiles_DeleteDeployment(t *testing.T) {
+	repo := &git.FakeRepo{
+		ReadFilesFn: func(path string) ([]core.ResourceFile, error) {
+			switch path {
+			case "state/dev/riser-managed/apps/deployments/mydep":
+				return []core.ResourceFile{
+					{Name: "state/dev/riser-managed/apps/deployments/mydep/route.yaml", Contents: []byte("kind: Route\n")},
+					{Name: "state/dev/riser-managed/apps/deployments/mydep/configuration.yaml", Contents: []byte("kind: Configuration\n")},
+				}, nil
+			}
+			return []core.ResourceFile{}, nil
+		},
+	}
+
+	result, err := DiffResourceFiles(repo, RenderDeleteDeployment("mydep", "apps", "dev"))
+
+	assert.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, "state/dev/riser-managed/apps/deployments/mydep/route.yaml", result[0].Name)
+	assert.Equal(t, FileChangeRemoved, result[0].Change)
+	assert.Equal(t, "--- a/state/dev/riser-managed/apps/deployments/mydep/route.yaml\n+++ /dev/null\n@@ -1 +0,0 @@\n-kind: Route\n", result[0].Diff)
+	assert.Equal(t, "state/dev/riser-managed/apps/deployments/mydep/configuration.yaml", result[1].Name)
+	assert.Equal(t, FileChangeRemoved, result[1].Change)
+}
@@ -1,14 +1,21 @@
 package state
 
-import "github.com/riser-platform/riser-server/pkg/core"
+import (
+	"github.com/pkg/errors"
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/riser-platform/riser-server/pkg/git"
+)
 
 type DryRunCommit struct {
 	Message string
 	Files   []core.ResourceFile
+	// Diffs is only populated when the committer has access to the state repo
+	Diffs []FileDiff
 }
 
 type DryRunComitter struct {
 	Commits []DryRunCommit
+	repo    git.Repo
 }
 
 func NewDryRunCommitter() *DryRunComitter {
@@ -17,7 +24,41 @@ func NewDryRunCommitter() *DryRunComitter {
 	}
 }
 
+// NewDryRunCommitterWithDiff creates a DryRunCommitter that also compares each commit with the current contents of the state repo
+func NewDryRunCommitterWithDiff(repo git.Repo) *DryRunComitter {
+	return &DryRunComitter{
+		Commits: []DryRunCommit{},
+		repo:    repo,
+	}
+}
+
 func (committer *DryRunComitter) Commit(message string, files []core.ResourceFile) error {
-	committer.Commits = append(committer.Commits, DryRunCommit{Message: message, Files: files})
+	commit := DryRunCommit{Message: message, Files: files}
+	if committer.repo != nil {
+		diffs, err := committer.diff(files)
+		if err != nil {
+			return err
+		}
+		commit.Diffs = diffs
+	}
+	committer.Commits = append(committer.Commits, commit)
 	return nil
 }
+
+func (committer *DryRunComitter) diff(files []core.ResourceFile) ([]FileDiff, error) {
+	committer.repo.Lock()
+	defer committer.repo.Unlock()
+
+	// Always reset so that we compare against the latest state
+	err := committer.repo.ResetHardRemote()
+	if err != nil {
+		return nil, errors.Wrap(err, "error resetting repo")
+	}
+
+	diffs, err := DiffResourceFiles(committer.repo, files)
+	if err != nil {
+		return nil, errors.Wrap(err, "error comparing changes")
+	}
+
+	return diffs, nil
+}
@@ -0,0 +1,60 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/riser-platform/riser-server/pkg/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DryRunCommitter_Commit(t *testing.T) {
+	committer := NewDryRunCommitter()
+	files := []core.ResourceFile{{Name: "test.yaml"}}
+
+	err := committer.Commit("test message", files)
+
+	assert.NoError(t, err)
+	require.Len(t, committer.Commits, 1)
+	assert.Equal(t, "test message", committer.Commits[0].Message)
+	assert.Equal(t, files, committer.Commits[0].Files)
+	assert.Nil(t, committer.Commits[0].Diffs)
+}
+
+func Test_DryRunCommitterWithDiff_Commit(t *testing.T) {
+	repo := &git.FakeRepo{
+		ResetHardRemoteFn: func() error {
+			return nil
+		},
+		ReadFilesFn: func(path string) ([]core.ResourceFile, error) {
+			return []core.ResourceFile{}, nil
+		},
+	}
+	committer := NewDryRunCommitterWithDiff(repo)
+
+	err := committer.Commit("test message", []core.ResourceFile{{Name: "test.yaml", Contents: []byte("test")}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, repo.ResetHardRemoteCallCount)
+	assert.Equal(t, 0, repo.CommitCallCount)
+	assert.Equal(t, 0, repo.PushCallCount)
+	require.Len(t, committer.Commits, 1)
+	require.Len(t, committer.Commits[0].Diffs, 1)
+	assert.Equal(t, FileChangeAdded, committer.Commits[0].Diffs[0].Change)
+}
+
+func Test_DryRunCommitterWithDiff_Commit_ResetErr(t *testing.T) {
+	repo := &git.FakeRepo{
+		ResetHardRemoteFn: func() error {
+			return errors.New("test")
+		},
+	}
+	committer := NewDryRunCommitterWithDiff(repo)
+
+	err := committer.Commit("test message", []core.ResourceFile{{Name: "test.yaml"}})
+
+	assert.Equal(t, "error resetting repo: test", err.Error())
+	assert.Empty(t, committer.Commits)
+}
@@ -41,6 +41,13 @@ func RenderSealedSecret(app, environmentName string, sealedSecret *resources.Sea
 	}, sealedSecret)
 }
 
+// RenderApp renders resources shared by all of an app's deployments in an environment (e.g. the app's service account)
+func RenderApp(app, namespace, environmentName string, appResources ...KubeResource) ([]core.ResourceFile, error) {
+	return renderKubeResources(func(resource KubeResource) string {
+		return getAppScmPath(app, namespace, environmentName, resource)
+	}, filterNilResources(appResources...)...)
+}
+
 // RenderDeployment renders resources that target a deployment's git folder
 func RenderDeployment(deployment *core.DeploymentConfig, deploymentResources ...KubeResource) ([]core.ResourceFile, error) {
 	files, err := renderKubeResources(func(resource KubeResource) string {
@@ -60,6 +67,18 @@ func RenderDeployment(deployment *core.DeploymentConfig, deploymentResources ...
 	return files, nil
 }
 
+// RenderDeleteDeploymentResources renders the removal of resources from a deployment's git folder
+func RenderDeleteDeploymentResources(deployment *core.DeploymentConfig, deploymentResources ...KubeResource) []core.ResourceFile {
+	files := []core.ResourceFile{}
+	for _, resource := range filterNilResources(deploymentResources...) {
+		files = append(files, core.ResourceFile{
+			Name:   getDeploymentScmPath(deployment.Name, deployment.Namespace, deployment.EnvironmentName, resource),
+			Delete: true,
+		})
+	}
+	return files
+}
+
 // RenderRoute renders just the route resource.
 func RenderRoute(deploymentName, namespace, environmentName string, resource KubeResource) ([]core.ResourceFile, error) {
 	files, err := renderKubeResources(func(resource KubeResource) string {
@@ -112,6 +131,15 @@ func getDeploymentScmPath(deploymentName, namespace, environmentName string, res
 		getFileNameFromResource(resource)))
 }
 
+func getAppScmPath(app, namespace, environmentName string, resource KubeResource) string {
+	return strings.ToLower(filepath.Join(
+		getRiserManagedStatePath(environmentName),
+		namespace,
+		"apps",
+		app,
+		getFileNameFromResource(resource)))
+}
+
 func getSecretScmPath(app string, environmentName string, sealedSecret KubeResource) string {
 	return strings.ToLower(filepath.Join(
 		getRiserManagedStatePath(environmentName),
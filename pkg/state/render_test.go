@@ -13,6 +13,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -64,6 +65,22 @@ func Test_getSecretScmPath(t *testing.T) {
 	assert.Equal(t, "state/dev/riser-managed/apps/secrets/myapp/sealedsecret.myapp-mysecret.yaml", result)
 }
 
+func Test_getAppScmPath(t *testing.T) {
+	serviceAccount := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "apps",
+			Name:      "myapp",
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind: "ServiceAccount",
+		},
+	}
+
+	result := getAppScmPath("myapp", "apps", "dev", serviceAccount)
+
+	assert.Equal(t, "state/dev/riser-managed/apps/apps/myapp/serviceaccount.myapp.yaml", result)
+}
+
 func Test_RenderDeployment(t *testing.T) {
 	deployment := &core.DeploymentConfig{
 		Name:            "mydeployment",
@@ -96,6 +113,33 @@ func Test_RenderDeployment(t *testing.T) {
 	assert.Contains(t, string(result[1].Contents), "name: myapp01")
 }
 
+func Test_RenderDeleteDeploymentResources(t *testing.T) {
+	deployment := &core.DeploymentConfig{
+		Name:            "mydeployment",
+		Namespace:       "apps",
+		EnvironmentName: "dev",
+	}
+	resource := &resources.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mydeployment",
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Route",
+			APIVersion: "serving.knative.dev/v1",
+		},
+	}
+
+	// Ignore nil resources
+	var nilResource *resources.Route
+
+	result := RenderDeleteDeploymentResources(deployment, nilResource, resource)
+
+	require.Len(t, result, 1)
+	assert.Equal(t, "state/dev/riser-managed/apps/deployments/mydeployment/serving.knative.dev.route.mydeployment.yaml", result[0].Name)
+	assert.True(t, result[0].Delete)
+	assert.Empty(t, result[0].Contents)
+}
+
 func Test_getFileNameFromResource(t *testing.T) {
 	objectMeta := metav1.ObjectMeta{
 		Name: "testname",
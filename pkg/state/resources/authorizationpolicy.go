@@ -3,6 +3,7 @@ package resources
 import (
 	"fmt"
 
+	"github.com/riser-platform/riser-server/api/v1/model"
 	"github.com/riser-platform/riser-server/pkg/core"
 	securityv1beta1 "istio.io/api/security/v1beta1"
 	typev1beta1 "istio.io/api/type/v1beta1"
@@ -10,14 +11,20 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// HealthcheckDenyPolicyName returns the name of the healthcheck DENY policy of a deployment
+func HealthcheckDenyPolicyName(deploymentName string) string {
+	return fmt.Sprintf("%s-healthcheck-deny", deploymentName)
+}
+
 func CreateHealthcheckDenyPolicy(dCtx *core.DeploymentContext) *v1beta1.AuthorizationPolicy {
-	if dCtx.DeploymentConfig.App.HealthCheck == nil {
+	paths := healthcheckPaths(dCtx.DeploymentConfig.App.HealthCheck, dCtx.DeploymentConfig.App.Expose)
+	if len(paths) == 0 {
 		return nil
 	}
 
 	return &v1beta1.AuthorizationPolicy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        fmt.Sprintf("%s-healthcheck-deny", dCtx.DeploymentConfig.Name),
+			Name:        HealthcheckDenyPolicyName(dCtx.DeploymentConfig.Name),
 			Namespace:   dCtx.DeploymentConfig.Namespace,
 			Labels:      deploymentLabels(dCtx),
 			Annotations: deploymentAnnotations(dCtx),
@@ -38,7 +45,7 @@ func CreateHealthcheckDenyPolicy(dCtx *core.DeploymentContext) *v1beta1.Authoriz
 					To: []*securityv1beta1.Rule_To{
 						{
 							Operation: &securityv1beta1.Operation{
-								Paths: []string{dCtx.DeploymentConfig.App.HealthCheck.Path},
+								Paths: paths,
 							},
 						},
 					},
@@ -47,3 +54,113 @@ func CreateHealthcheckDenyPolicy(dCtx *core.DeploymentContext) *v1beta1.Authoriz
 		},
 	}
 }
+
+// CreateAccessAllowPolicy allows calls from the apps and namespaces specified in the app's access section. Since Istio denies any request
+// that does not match an ALLOW policy, the policy is also created when the environment requires default deny so that only system callers
+// are allowed.
+func CreateAccessAllowPolicy(dCtx *core.DeploymentContext) *v1beta1.AuthorizationPolicy {
+	access := dCtx.DeploymentConfig.App.Access
+	defaultDeny := dCtx.EnvironmentConfig != nil && dCtx.EnvironmentConfig.DefaultDenyAccess
+	if (access == nil || len(access.Allow) == 0) && !defaultDeny {
+		return nil
+	}
+
+	rules := []*securityv1beta1.Rule{
+		{
+			From: []*securityv1beta1.Rule_From{
+				{
+					Source: &securityv1beta1.Source{
+						Namespaces: accessSystemNamespaces(dCtx.DeploymentConfig.App),
+					},
+				},
+			},
+		},
+	}
+	if access != nil {
+		for _, allow := range access.Allow {
+			rules = append(rules, accessRule(allow))
+		}
+	}
+
+	return &v1beta1.AuthorizationPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        AccessAllowPolicyName(dCtx.DeploymentConfig.Name),
+			Namespace:   dCtx.DeploymentConfig.Namespace,
+			Labels:      deploymentLabels(dCtx),
+			Annotations: deploymentAnnotations(dCtx),
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "AuthorizationPolicy",
+			APIVersion: "security.istio.io/v1beta1",
+		},
+		Spec: securityv1beta1.AuthorizationPolicy{
+			Action: securityv1beta1.AuthorizationPolicy_ALLOW,
+			Selector: &typev1beta1.WorkloadSelector{
+				MatchLabels: map[string]string{
+					riserLabel("deployment"): dCtx.DeploymentConfig.Name,
+				},
+			},
+			Rules: rules,
+		},
+	}
+}
+
+// AccessAllowPolicyName returns the name of the access ALLOW policy of a deployment
+func AccessAllowPolicyName(deploymentName string) string {
+	return fmt.Sprintf("%s-access-allow", deploymentName)
+}
+
+// accessSystemNamespaces returns the namespaces of system callers that are always allowed. KNative's activator and autoscaler call the
+// app from the knative-serving namespace. The activator proxies the requests of other callers when it is in the request path, so those
+// requests bypass the access rules (see AppConfig.Access). The ingress gateway in the istio-system namespace is allowed for externally
+// exposed apps.
+func accessSystemNamespaces(app *model.AppConfig) []string {
+	namespaces := []string{"knative-serving"}
+	if app.Expose != nil && app.Expose.Scope == model.AppExposeScope_External {
+		namespaces = append(namespaces, "istio-system")
+	}
+	return namespaces
+}
+
+// accessRule allows calls from an app or namespace. An app is identified by its service account (see CreateAppServiceAccount).
+func accessRule(allow model.AppConfigAccessRule) *securityv1beta1.Rule {
+	source := &securityv1beta1.Source{}
+	if allow.App == "" {
+		source.Namespaces = []string{allow.Namespace}
+	} else {
+		source.Principals = []string{fmt.Sprintf("cluster.local/ns/%s/sa/%s", allow.Namespace, allow.App)}
+	}
+
+	rule := &securityv1beta1.Rule{
+		From: []*securityv1beta1.Rule_From{{Source: source}},
+	}
+	if len(allow.Paths) > 0 || len(allow.Methods) > 0 {
+		rule.To = []*securityv1beta1.Rule_To{
+			{
+				Operation: &securityv1beta1.Operation{
+					Paths:   allow.Paths,
+					Methods: allow.Methods,
+				},
+			},
+		}
+	}
+	return rule
+}
+
+// healthcheckPaths returns the unique paths of all httpGet probes
+func healthcheckPaths(healthCheck *model.AppConfigHealthCheck, expose *model.AppConfigExpose) []string {
+	if healthCheck == nil {
+		return nil
+	}
+
+	paths := []string{}
+	seen := map[string]bool{}
+	for _, probe := range []*model.AppConfigProbe{&healthCheck.AppConfigProbe, healthCheck.Liveness, healthCheck.Startup} {
+		if probe == nil || probe.ModeOrDefault(expose) != model.AppHealthCheckMode_HTTPGet || seen[probe.Path] {
+			continue
+		}
+		seen[probe.Path] = true
+		paths = append(paths, probe.Path)
+	}
+	return paths
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/riser-platform/riser-server/api/v1/model"
 	"github.com/riser-platform/riser-server/pkg/core"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_createHealthcheckDenyPolicy(t *testing.T) {
@@ -15,8 +16,10 @@ func Test_createHealthcheckDenyPolicy(t *testing.T) {
 			EnvironmentName: "myenv",
 			App: &model.AppConfig{
 				Name: "myapp",
-				HealthCheck: &model.AppConfigHealthCheck{
-					Path: "/health",
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					HealthCheck: &model.AppConfigHealthCheck{
+						AppConfigProbe: model.AppConfigProbe{Path: "/health"},
+					},
 				},
 			},
 		},
@@ -49,3 +52,160 @@ func Test_createHealthcheckDenyPolicy_NoHealthcheckReturnsNil(t *testing.T) {
 
 	assert.Nil(t, result)
 }
+
+func Test_createHealthcheckDenyPolicy_UniqueHttpGetPaths(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp-dep",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myapp",
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					HealthCheck: &model.AppConfigHealthCheck{
+						AppConfigProbe: model.AppConfigProbe{Path: "/health"},
+						Liveness:       &model.AppConfigProbe{Path: "/health"},
+						Startup:        &model.AppConfigProbe{Path: "/startup"},
+					},
+				},
+			},
+		},
+	}
+
+	result := CreateHealthcheckDenyPolicy(ctx)
+
+	assert.Equal(t, []string{"/health", "/startup"}, result.Spec.Rules[0].To[0].Operation.Paths)
+}
+
+func Test_createHealthcheckDenyPolicy_NoHttpGetProbesReturnsNil(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp-dep",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myapp",
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					HealthCheck: &model.AppConfigHealthCheck{
+						AppConfigProbe: model.AppConfigProbe{Mode: model.AppHealthCheckMode_TCP},
+						Liveness:       &model.AppConfigProbe{Mode: model.AppHealthCheckMode_Exec, Command: []string{"true"}},
+					},
+				},
+			},
+		},
+	}
+
+	result := CreateHealthcheckDenyPolicy(ctx)
+
+	assert.Nil(t, result)
+}
+
+func Test_CreateAccessAllowPolicy(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp-dep",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name:   "myapp",
+				Expose: &model.AppConfigExpose{Scope: model.AppExposeScope_External},
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Access: &model.AppConfigAccess{
+						Allow: []model.AppConfigAccessRule{
+							{Namespace: "billing", App: "payments", Paths: []string{"/api/*"}, Methods: []string{"GET", "POST"}},
+							{Namespace: "other"},
+						},
+					},
+				},
+			},
+		},
+		EnvironmentConfig: &core.EnvironmentConfig{},
+	}
+
+	result := CreateAccessAllowPolicy(ctx)
+
+	assert.Equal(t, "myapp-dep-access-allow", result.Name)
+	assert.Equal(t, "apps", result.Namespace)
+	assert.Equal(t, deploymentLabels(ctx), result.Labels)
+	assert.Equal(t, deploymentAnnotations(ctx), result.Annotations)
+	assert.Equal(t, "AuthorizationPolicy", result.TypeMeta.Kind)
+	assert.Equal(t, "security.istio.io/v1beta1", result.TypeMeta.APIVersion)
+	assert.Equal(t, "myapp-dep", result.Spec.Selector.MatchLabels["riser.dev/deployment"])
+	assert.Equal(t, "ALLOW", result.Spec.Action.String())
+	require.Len(t, result.Spec.Rules, 3)
+	// System
+	assert.Equal(t, []string{"knative-serving", "istio-system"}, result.Spec.Rules[0].From[0].Source.Namespaces)
+	assert.Empty(t, result.Spec.Rules[0].To)
+	// App
+	assert.Equal(t, []string{"cluster.local/ns/billing/sa/payments"}, result.Spec.Rules[1].From[0].Source.Principals)
+	assert.Empty(t, result.Spec.Rules[1].From[0].Source.Namespaces)
+	assert.Equal(t, []string{"/api/*"}, result.Spec.Rules[1].To[0].Operation.Paths)
+	assert.Equal(t, []string{"GET", "POST"}, result.Spec.Rules[1].To[0].Operation.Methods)
+	// Namespace
+	assert.Equal(t, []string{"other"}, result.Spec.Rules[2].From[0].Source.Namespaces)
+	assert.Empty(t, result.Spec.Rules[2].From[0].Source.Principals)
+	assert.Empty(t, result.Spec.Rules[2].To)
+}
+
+func Test_CreateAccessAllowPolicy_ClusterScopeDoesNotAllowIngress(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp-dep",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name:   "myapp",
+				Expose: &model.AppConfigExpose{Scope: model.AppExposeScope_Cluster},
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Access: &model.AppConfigAccess{
+						Allow: []model.AppConfigAccessRule{{Namespace: "other"}},
+					},
+				},
+			},
+		},
+	}
+
+	result := CreateAccessAllowPolicy(ctx)
+
+	assert.Equal(t, []string{"knative-serving"}, result.Spec.Rules[0].From[0].Source.Namespaces)
+}
+
+func Test_CreateAccessAllowPolicy_DefaultDeny(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp-dep",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name:   "myapp",
+				Expose: &model.AppConfigExpose{Scope: model.AppExposeScope_Cluster},
+			},
+		},
+		EnvironmentConfig: &core.EnvironmentConfig{DefaultDenyAccess: true},
+	}
+
+	result := CreateAccessAllowPolicy(ctx)
+
+	require.Len(t, result.Spec.Rules, 1)
+	assert.Equal(t, []string{"knative-serving"}, result.Spec.Rules[0].From[0].Source.Namespaces)
+}
+
+func Test_CreateAccessAllowPolicy_NoAccessReturnsNil(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp-dep",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name:   "myapp",
+				Expose: &model.AppConfigExpose{Scope: model.AppExposeScope_External},
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Access: &model.AppConfigAccess{},
+				},
+			},
+		},
+		EnvironmentConfig: &core.EnvironmentConfig{},
+	}
+
+	result := CreateAccessAllowPolicy(ctx)
+
+	assert.Nil(t, result)
+}
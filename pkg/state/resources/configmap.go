@@ -0,0 +1,98 @@
+package resources
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/riser-platform/riser-server/pkg/core"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// appFile is a file from the app config's "files" section
+type appFile struct {
+	mountPath string
+	contents  string
+	// hash is a hash of the file's mount path and contents
+	hash string
+}
+
+// CreateFileConfigMaps creates a ConfigMap for each file in the app config. The name of each ConfigMap contains a hash of the file
+// so that changing a file results in a new ConfigMap instead of modifying the ConfigMap used by existing revisions.
+func CreateFileConfigMaps(ctx *core.DeploymentContext) []*corev1.ConfigMap {
+	configMaps := []*corev1.ConfigMap{}
+	for _, file := range appFiles(ctx) {
+		configMaps = append(configMaps, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        fileConfigMapName(ctx, file),
+				Namespace:   ctx.DeploymentConfig.Namespace,
+				Labels:      deploymentLabels(ctx),
+				Annotations: deploymentAnnotations(ctx),
+			},
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "ConfigMap",
+				APIVersion: "v1",
+			},
+			Data: map[string]string{
+				path.Base(file.mountPath): file.contents,
+			},
+		})
+	}
+	return configMaps
+}
+
+func fileVolumes(ctx *core.DeploymentContext) []corev1.Volume {
+	volumes := []corev1.Volume{}
+	for _, file := range appFiles(ctx) {
+		volumes = append(volumes, corev1.Volume{
+			Name: fileVolumeName(file),
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: fileConfigMapName(ctx, file),
+					},
+				},
+			},
+		})
+	}
+	return volumes
+}
+
+func fileVolumeMounts(ctx *core.DeploymentContext) []corev1.VolumeMount {
+	volumeMounts := []corev1.VolumeMount{}
+	for _, file := range appFiles(ctx) {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      fileVolumeName(file),
+			MountPath: file.mountPath,
+			SubPath:   path.Base(file.mountPath),
+			ReadOnly:  true,
+		})
+	}
+	return volumeMounts
+}
+
+// appFiles returns the app's files sorted by mount path
+func appFiles(ctx *core.DeploymentContext) []appFile {
+	files := []appFile{}
+	for mountPath, contents := range ctx.DeploymentConfig.App.Files {
+		files = append(files, appFile{
+			mountPath: mountPath,
+			contents:  contents,
+			hash:      fmt.Sprintf("%x", sha256.Sum256([]byte(mountPath+"\x00"+contents)))[:10],
+		})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].mountPath < files[j].mountPath
+	})
+	return files
+}
+
+func fileConfigMapName(ctx *core.DeploymentContext, file appFile) string {
+	return fmt.Sprintf("%s-files-%s", ctx.DeploymentConfig.Name, file.hash)
+}
+
+func fileVolumeName(file appFile) string {
+	return fmt.Sprintf("files-%s", file.hash)
+}
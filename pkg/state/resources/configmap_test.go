@@ -0,0 +1,117 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/riser-platform/riser-server/api/v1/model"
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CreateFileConfigMaps(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp-dep",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myapp",
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Files: map[string]string{
+						"/etc/myapp/b.conf": "b",
+						"/etc/myapp/a.conf": "a",
+					},
+				},
+			},
+		},
+		RiserRevision: 1,
+	}
+
+	result := CreateFileConfigMaps(ctx)
+
+	require.Len(t, result, 2)
+	assert.Regexp(t, "^myapp-dep-files-[0-9a-f]{10}$", result[0].Name)
+	assert.Equal(t, "apps", result[0].Namespace)
+	assert.Equal(t, deploymentLabels(ctx), result[0].Labels)
+	assert.Equal(t, deploymentAnnotations(ctx), result[0].Annotations)
+	assert.Equal(t, "ConfigMap", result[0].TypeMeta.Kind)
+	assert.Equal(t, "v1", result[0].TypeMeta.APIVersion)
+	assert.Equal(t, map[string]string{"a.conf": "a"}, result[0].Data)
+	assert.Equal(t, map[string]string{"b.conf": "b"}, result[1].Data)
+}
+
+func Test_CreateFileConfigMaps_NoFiles(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp-dep",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myapp",
+			},
+		},
+		RiserRevision: 1,
+	}
+
+	result := CreateFileConfigMaps(ctx)
+
+	assert.Empty(t, result)
+}
+
+func Test_CreateFileConfigMaps_NameChangesWithContents(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp-dep",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myapp",
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Files: map[string]string{"/etc/myapp.conf": "v1"},
+				},
+			},
+		},
+		RiserRevision: 1,
+	}
+
+	before := CreateFileConfigMaps(ctx)
+	ctx.DeploymentConfig.App.Files = map[string]string{"/etc/myapp.conf": "v2"}
+	after := CreateFileConfigMaps(ctx)
+	ctx.DeploymentConfig.App.Files = map[string]string{"/etc/myapp.conf": "v1"}
+	unchanged := CreateFileConfigMaps(ctx)
+
+	assert.NotEqual(t, before[0].Name, after[0].Name)
+	assert.Equal(t, before[0].Name, unchanged[0].Name)
+}
+
+func Test_fileVolumesAndMounts(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp-dep",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myapp",
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Files: map[string]string{
+						"/etc/myapp/config.yaml": "key: value",
+					},
+				},
+			},
+		},
+		RiserRevision: 1,
+	}
+	configMaps := CreateFileConfigMaps(ctx)
+
+	volumes := fileVolumes(ctx)
+	mounts := fileVolumeMounts(ctx)
+
+	require.Len(t, volumes, 1)
+	require.Len(t, mounts, 1)
+	assert.Equal(t, configMaps[0].Name, volumes[0].ConfigMap.Name)
+	assert.Equal(t, volumes[0].Name, mounts[0].Name)
+	assert.Equal(t, "/etc/myapp/config.yaml", mounts[0].MountPath)
+	assert.Equal(t, "config.yaml", mounts[0].SubPath)
+	assert.True(t, mounts[0].ReadOnly)
+}
@@ -31,7 +31,9 @@ func CreateKNativeConfiguration(ctx *core.DeploymentContext) *Configuration {
 			Template: RevisionTemplateSpec{
 				ObjectMeta: revisionMeta,
 				Spec: RevisionSpec{
-					PodSpec: podSpec,
+					PodSpec:              podSpec,
+					ContainerConcurrency: ctx.DeploymentConfig.App.Concurrency,
+					TimeoutSeconds:       ctx.DeploymentConfig.App.TimeoutSeconds,
 				},
 			},
 		},
@@ -44,12 +46,31 @@ func createRevisionMeta(ctx *core.DeploymentContext) metav1.ObjectMeta {
 		Labels:      deploymentLabels(ctx),
 		Annotations: deploymentAnnotations(ctx),
 	}
-	if ctx.DeploymentConfig.App.Autoscale != nil {
-		if ctx.DeploymentConfig.App.Autoscale.Min != nil {
-			revisionMeta.Annotations["autoscaling.knative.dev/minScale"] = fmt.Sprintf("%d", *ctx.DeploymentConfig.App.Autoscale.Min)
+	autoscale := ctx.DeploymentConfig.App.Autoscale
+	if autoscale != nil {
+		if autoscale.Min != nil {
+			revisionMeta.Annotations["autoscaling.knative.dev/minScale"] = fmt.Sprintf("%d", *autoscale.Min)
 		}
-		if ctx.DeploymentConfig.App.Autoscale.Max != nil {
-			revisionMeta.Annotations["autoscaling.knative.dev/maxScale"] = fmt.Sprintf("%d", *ctx.DeploymentConfig.App.Autoscale.Max)
+		if autoscale.Max != nil {
+			revisionMeta.Annotations["autoscaling.knative.dev/maxScale"] = fmt.Sprintf("%d", *autoscale.Max)
+		}
+		if autoscale.Class != "" {
+			revisionMeta.Annotations["autoscaling.knative.dev/class"] = fmt.Sprintf("%s.autoscaling.knative.dev", autoscale.Class)
+		}
+		if autoscale.Metric != "" {
+			revisionMeta.Annotations["autoscaling.knative.dev/metric"] = autoscale.Metric
+		}
+		if autoscale.Target != nil {
+			revisionMeta.Annotations["autoscaling.knative.dev/target"] = fmt.Sprintf("%d", *autoscale.Target)
+		}
+		if autoscale.TargetUtilizationPercentage != nil {
+			revisionMeta.Annotations["autoscaling.knative.dev/targetUtilizationPercentage"] = fmt.Sprintf("%d", *autoscale.TargetUtilizationPercentage)
+		}
+		if autoscale.ScaleDownDelay != "" {
+			revisionMeta.Annotations["autoscaling.knative.dev/scaleDownDelay"] = autoscale.ScaleDownDelay
+		}
+		if autoscale.StableWindow != "" {
+			revisionMeta.Annotations["autoscaling.knative.dev/window"] = autoscale.StableWindow
 		}
 	}
 
@@ -28,6 +28,30 @@ func Test_createRevisionMeta(t *testing.T) {
 	assert.Equal(t, deploymentAnnotations(ctx), result.Annotations)
 }
 
+func Test_createRevisionMeta_Metadata(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myapp",
+			},
+			Metadata: map[string]string{
+				"git-sha":   "9c2e6c5",
+				"build-url": "https://ci.example.com/builds/1",
+			},
+		},
+		RiserRevision: 1,
+	}
+
+	result := createRevisionMeta(ctx)
+
+	assert.Len(t, result.Annotations, 4)
+	assert.Equal(t, "9c2e6c5", result.Annotations["riser.dev/git-sha"])
+	assert.Equal(t, "https://ci.example.com/builds/1", result.Annotations["riser.dev/build-url"])
+	assert.Equal(t, "1", result.Annotations["riser.dev/revision"])
+}
+
 func Test_createRevisionMeta_Autoscale(t *testing.T) {
 	ctx := &core.DeploymentContext{
 		DeploymentConfig: &core.DeploymentConfig{
@@ -54,3 +78,105 @@ func Test_createRevisionMeta_Autoscale(t *testing.T) {
 	assert.Equal(t, "1", result.Annotations["riser.dev/revision"])
 	assert.Equal(t, util.VersionString, result.Annotations["riser.dev/server-version"])
 }
+
+func Test_createRevisionMeta_AutoscaleScaling(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myapp",
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Autoscale: &model.AppConfigAutoscale{
+						Class:                       model.AppAutoscaleClass_KPA,
+						Metric:                      model.AppAutoscaleMetric_RPS,
+						Target:                      util.PtrInt(150),
+						TargetUtilizationPercentage: util.PtrInt(80),
+						ScaleDownDelay:              "15m",
+						StableWindow:                "60s",
+					},
+				},
+			},
+		},
+		RiserRevision: 1,
+	}
+
+	result := createRevisionMeta(ctx)
+
+	assert.Len(t, result.Annotations, 8)
+	assert.Equal(t, "kpa.autoscaling.knative.dev", result.Annotations["autoscaling.knative.dev/class"])
+	assert.Equal(t, "rps", result.Annotations["autoscaling.knative.dev/metric"])
+	assert.Equal(t, "150", result.Annotations["autoscaling.knative.dev/target"])
+	assert.Equal(t, "80", result.Annotations["autoscaling.knative.dev/targetUtilizationPercentage"])
+	assert.Equal(t, "15m", result.Annotations["autoscaling.knative.dev/scaleDownDelay"])
+	assert.Equal(t, "60s", result.Annotations["autoscaling.knative.dev/window"])
+}
+
+func Test_createRevisionMeta_AutoscaleHPA(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myapp",
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Autoscale: &model.AppConfigAutoscale{
+						Class:  model.AppAutoscaleClass_HPA,
+						Metric: model.AppAutoscaleMetric_CPU,
+						Target: util.PtrInt(70),
+					},
+				},
+			},
+		},
+		RiserRevision: 1,
+	}
+
+	result := createRevisionMeta(ctx)
+
+	assert.Len(t, result.Annotations, 5)
+	assert.Equal(t, "hpa.autoscaling.knative.dev", result.Annotations["autoscaling.knative.dev/class"])
+	assert.Equal(t, "cpu", result.Annotations["autoscaling.knative.dev/metric"])
+	assert.Equal(t, "70", result.Annotations["autoscaling.knative.dev/target"])
+}
+
+func Test_CreateKNativeConfiguration_ConcurrencyAndTimeout(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name:   "myapp",
+				Expose: &model.AppConfigExpose{ContainerPort: 8080},
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Concurrency:    util.PtrInt64(10),
+					TimeoutSeconds: util.PtrInt64(60),
+				},
+			},
+		},
+		RiserRevision: 1,
+	}
+
+	result := CreateKNativeConfiguration(ctx)
+
+	assert.EqualValues(t, 10, *result.Spec.Template.Spec.ContainerConcurrency)
+	assert.EqualValues(t, 60, *result.Spec.Template.Spec.TimeoutSeconds)
+}
+
+func Test_CreateKNativeConfiguration_ConcurrencyAndTimeoutNotSpecified(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name:   "myapp",
+				Expose: &model.AppConfigExpose{ContainerPort: 8080},
+			},
+		},
+		RiserRevision: 1,
+	}
+
+	result := CreateKNativeConfiguration(ctx)
+
+	assert.Nil(t, result.Spec.Template.Spec.ContainerConcurrency)
+	assert.Nil(t, result.Spec.Template.Spec.TimeoutSeconds)
+}
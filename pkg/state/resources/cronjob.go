@@ -0,0 +1,58 @@
+package resources
+
+import (
+	"github.com/riser-platform/riser-server/api/v1/model"
+	"github.com/riser-platform/riser-server/pkg/core"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var cronJobConcurrencyPolicies = map[string]batchv1beta1.ConcurrencyPolicy{
+	model.AppJobConcurrencyPolicy_Allow:   batchv1beta1.AllowConcurrent,
+	model.AppJobConcurrencyPolicy_Forbid:  batchv1beta1.ForbidConcurrent,
+	model.AppJobConcurrencyPolicy_Replace: batchv1beta1.ReplaceConcurrent,
+}
+
+// CreateCronJob creates a CronJob for the scheduledJob kind. Values that are not specified in the job config use the Kubernetes defaults.
+func CreateCronJob(ctx *core.DeploymentContext) *batchv1beta1.CronJob {
+	job := ctx.DeploymentConfig.App.Job
+	podSpec := createPodSpec(ctx)
+	podSpec.RestartPolicy = corev1.RestartPolicyNever
+
+	return &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        ctx.DeploymentConfig.Name,
+			Namespace:   ctx.DeploymentConfig.Namespace,
+			Labels:      deploymentLabels(ctx),
+			Annotations: deploymentAnnotations(ctx),
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "CronJob",
+			APIVersion: "batch/v1beta1",
+		},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule:                   job.Schedule,
+			ConcurrencyPolicy:          cronJobConcurrencyPolicies[job.ConcurrencyPolicy],
+			SuccessfulJobsHistoryLimit: job.SuccessfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     job.FailedJobsHistoryLimit,
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      deploymentLabels(ctx),
+					Annotations: deploymentAnnotations(ctx),
+				},
+				Spec: batchv1.JobSpec{
+					BackoffLimit: job.BackoffLimit,
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels:      deploymentLabels(ctx),
+							Annotations: jobPodAnnotations(ctx),
+						},
+						Spec: podSpec,
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,83 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/riser-platform/riser-server/api/v1/model"
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/riser-platform/riser-server/pkg/util"
+	"github.com/stretchr/testify/assert"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_CreateCronJob(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myreport",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myreport",
+				Kind: model.AppKind_ScheduledJob,
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Job: &model.AppConfigJob{
+						Schedule:                   "0 3 * * *",
+						ConcurrencyPolicy:          model.AppJobConcurrencyPolicy_Forbid,
+						BackoffLimit:               util.PtrInt32(2),
+						SuccessfulJobsHistoryLimit: util.PtrInt32(5),
+						FailedJobsHistoryLimit:     util.PtrInt32(0),
+					},
+				},
+			},
+		},
+		RiserRevision: 2,
+	}
+
+	result := CreateCronJob(ctx)
+
+	assert.Equal(t, "myreport", result.Name)
+	assert.Equal(t, "apps", result.Namespace)
+	assert.Equal(t, deploymentLabels(ctx), result.Labels)
+	assert.Equal(t, deploymentAnnotations(ctx), result.Annotations)
+	assert.Equal(t, "CronJob", result.TypeMeta.Kind)
+	assert.Equal(t, "batch/v1beta1", result.TypeMeta.APIVersion)
+	assert.Equal(t, "0 3 * * *", result.Spec.Schedule)
+	assert.Equal(t, batchv1beta1.ForbidConcurrent, result.Spec.ConcurrencyPolicy)
+	assert.EqualValues(t, 5, *result.Spec.SuccessfulJobsHistoryLimit)
+	assert.EqualValues(t, 0, *result.Spec.FailedJobsHistoryLimit)
+	assert.Equal(t, deploymentLabels(ctx), result.Spec.JobTemplate.Labels)
+	assert.EqualValues(t, 2, *result.Spec.JobTemplate.Spec.BackoffLimit)
+	podTemplate := result.Spec.JobTemplate.Spec.Template
+	assert.Equal(t, deploymentLabels(ctx), podTemplate.Labels)
+	assert.Equal(t, "2", podTemplate.Annotations["riser.dev/revision"])
+	assert.Equal(t, "false", podTemplate.Annotations["sidecar.istio.io/inject"])
+	assert.Equal(t, corev1.RestartPolicyNever, podTemplate.Spec.RestartPolicy)
+	assert.Equal(t, "myreport", podTemplate.Spec.Containers[0].Name)
+	assert.Empty(t, podTemplate.Spec.Containers[0].Ports)
+}
+
+func Test_CreateCronJob_Defaults(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myreport",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myreport",
+				Kind: model.AppKind_ScheduledJob,
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Job: &model.AppConfigJob{Schedule: "0 3 * * *"},
+				},
+			},
+		},
+		RiserRevision: 2,
+	}
+
+	result := CreateCronJob(ctx)
+
+	assert.Empty(t, result.Spec.ConcurrencyPolicy)
+	assert.Nil(t, result.Spec.SuccessfulJobsHistoryLimit)
+	assert.Nil(t, result.Spec.FailedJobsHistoryLimit)
+	assert.Nil(t, result.Spec.JobTemplate.Spec.BackoffLimit)
+}
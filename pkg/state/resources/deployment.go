@@ -0,0 +1,51 @@
+package resources
+
+import (
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/riser-platform/riser-server/pkg/util"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateDeployment creates a Deployment for apps that KNative cannot serve (e.g. the tcp protocol or the worker kind). Unlike a KNative
+// Configuration, each riser revision replaces the previous one with a rolling update and the number of replicas is fixed at autoscale.min
+// (default 1). The replicas are left unset when managed by a HorizontalPodAutoscaler (see CreateHorizontalPodAutoscaler).
+func CreateDeployment(ctx *core.DeploymentContext) *appsv1.Deployment {
+	var replicas *int32
+	if !isHorizontalPodAutoscaled(ctx.DeploymentConfig.App) {
+		replicas = util.PtrInt32(1)
+		if ctx.DeploymentConfig.App.Autoscale != nil && ctx.DeploymentConfig.App.Autoscale.Min != nil {
+			replicas = util.PtrInt32(int32(*ctx.DeploymentConfig.App.Autoscale.Min))
+		}
+	}
+
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        ctx.DeploymentConfig.Name,
+			Namespace:   ctx.DeploymentConfig.Namespace,
+			Labels:      deploymentLabels(ctx),
+			Annotations: deploymentAnnotations(ctx),
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Deployment",
+			APIVersion: "apps/v1",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					riserLabel("deployment"): ctx.DeploymentConfig.Name,
+				},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: deploymentLabels(ctx),
+					// The revision annotation ensures that each riser revision rolls out new pods
+					Annotations: deploymentAnnotations(ctx),
+				},
+				Spec: createPodSpec(ctx),
+			},
+		},
+	}
+}
@@ -0,0 +1,112 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/riser-platform/riser-server/api/v1/model"
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/riser-platform/riser-server/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CreateDeployment(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "mydb",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "mydb",
+				Expose: &model.AppConfigExpose{
+					ContainerPort: 5432,
+					Protocol:      model.AppExposeProtocol_TCP,
+					Scope:         model.AppExposeScope_Cluster,
+				},
+			},
+		},
+		RiserRevision: 2,
+	}
+
+	result := CreateDeployment(ctx)
+
+	assert.Equal(t, "mydb", result.Name)
+	assert.Equal(t, "apps", result.Namespace)
+	assert.Equal(t, deploymentLabels(ctx), result.Labels)
+	assert.Equal(t, deploymentAnnotations(ctx), result.Annotations)
+	assert.Equal(t, "Deployment", result.TypeMeta.Kind)
+	assert.Equal(t, "apps/v1", result.TypeMeta.APIVersion)
+	assert.EqualValues(t, 1, *result.Spec.Replicas)
+	assert.Equal(t, map[string]string{"riser.dev/deployment": "mydb"}, result.Spec.Selector.MatchLabels)
+	assert.Equal(t, deploymentLabels(ctx), result.Spec.Template.Labels)
+	assert.Equal(t, "2", result.Spec.Template.Annotations["riser.dev/revision"])
+	assert.Equal(t, createPodSpec(ctx), result.Spec.Template.Spec)
+}
+
+func Test_CreateDeployment_AutoscaleMin(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "mydb",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "mydb",
+				Expose: &model.AppConfigExpose{
+					ContainerPort: 5432,
+					Protocol:      model.AppExposeProtocol_TCP,
+					Scope:         model.AppExposeScope_Cluster,
+				},
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Autoscale: &model.AppConfigAutoscale{Min: util.PtrInt(3)},
+				},
+			},
+		},
+		RiserRevision: 2,
+	}
+
+	result := CreateDeployment(ctx)
+
+	assert.EqualValues(t, 3, *result.Spec.Replicas)
+}
+
+func Test_CreateDeployment_Worker(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myworker",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myworker",
+				Kind: model.AppKind_Worker,
+			},
+		},
+		RiserRevision: 2,
+	}
+
+	result := CreateDeployment(ctx)
+
+	assert.Equal(t, "myworker", result.Name)
+	assert.EqualValues(t, 1, *result.Spec.Replicas)
+	assert.Empty(t, result.Spec.Template.Spec.Containers[0].Ports)
+}
+
+func Test_CreateDeployment_Worker_HorizontalPodAutoscaled(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myworker",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myworker",
+				Kind: model.AppKind_Worker,
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Autoscale: &model.AppConfigAutoscale{Min: util.PtrInt(2), Max: util.PtrInt(5)},
+				},
+			},
+		},
+		RiserRevision: 2,
+	}
+
+	result := CreateDeployment(ctx)
+
+	assert.Nil(t, result.Spec.Replicas)
+}
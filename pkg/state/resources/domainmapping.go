@@ -0,0 +1,123 @@
+package resources
+
+import (
+	"fmt"
+
+	"github.com/riser-platform/riser-server/pkg/core"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultCertificateIssuer is the cert-manager ClusterIssuer used when the environment does not specify one
+const DefaultCertificateIssuer = "letsencrypt"
+
+// DomainMapping is a subset of the KNative DomainMapping type (serving.knative.dev/v1alpha1)
+type DomainMapping struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DomainMappingSpec `json:"spec,omitempty"`
+}
+
+type DomainMappingSpec struct {
+	Ref DomainMappingRef  `json:"ref"`
+	TLS *DomainMappingTLS `json:"tls,omitempty"`
+}
+
+type DomainMappingRef struct {
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	APIVersion string `json:"apiVersion"`
+}
+
+type DomainMappingTLS struct {
+	SecretName string `json:"secretName"`
+}
+
+// Certificate is a subset of the cert-manager Certificate type (cert-manager.io/v1)
+type Certificate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec CertificateSpec `json:"spec,omitempty"`
+}
+
+type CertificateSpec struct {
+	SecretName string               `json:"secretName"`
+	DNSNames   []string             `json:"dnsNames"`
+	IssuerRef  CertificateIssuerRef `json:"issuerRef"`
+}
+
+type CertificateIssuerRef struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+// CreateDomainMappings creates a DomainMapping for each custom domain that routes to the deployment's KNative route
+func CreateDomainMappings(ctx *core.DeploymentContext) []*DomainMapping {
+	domainMappings := []*DomainMapping{}
+	for _, domain := range ctx.DeploymentConfig.App.Domains {
+		domainMappings = append(domainMappings, &DomainMapping{
+			ObjectMeta: metav1.ObjectMeta{
+				// KNative requires the name to be the domain
+				Name:        domain,
+				Namespace:   ctx.DeploymentConfig.Namespace,
+				Labels:      deploymentLabels(ctx),
+				Annotations: deploymentAnnotations(ctx),
+			},
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "DomainMapping",
+				APIVersion: "serving.knative.dev/v1alpha1",
+			},
+			Spec: DomainMappingSpec{
+				Ref: DomainMappingRef{
+					Kind:       "Route",
+					Namespace:  ctx.DeploymentConfig.Namespace,
+					Name:       ctx.DeploymentConfig.Name,
+					APIVersion: "serving.knative.dev/v1",
+				},
+				TLS: &DomainMappingTLS{
+					SecretName: domainCertificateSecretName(domain),
+				},
+			},
+		})
+	}
+	return domainMappings
+}
+
+// CreateDomainCertificates creates a cert-manager Certificate for each custom domain
+func CreateDomainCertificates(ctx *core.DeploymentContext) []*Certificate {
+	issuer := DefaultCertificateIssuer
+	if ctx.EnvironmentConfig != nil && ctx.EnvironmentConfig.CertificateIssuer != "" {
+		issuer = ctx.EnvironmentConfig.CertificateIssuer
+	}
+
+	certificates := []*Certificate{}
+	for _, domain := range ctx.DeploymentConfig.App.Domains {
+		certificates = append(certificates, &Certificate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        domain,
+				Namespace:   ctx.DeploymentConfig.Namespace,
+				Labels:      deploymentLabels(ctx),
+				Annotations: deploymentAnnotations(ctx),
+			},
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Certificate",
+				APIVersion: "cert-manager.io/v1",
+			},
+			Spec: CertificateSpec{
+				SecretName: domainCertificateSecretName(domain),
+				DNSNames:   []string{domain},
+				IssuerRef: CertificateIssuerRef{
+					Name: issuer,
+					Kind: "ClusterIssuer",
+				},
+			},
+		})
+	}
+	return certificates
+}
+
+func domainCertificateSecretName(domain string) string {
+	return fmt.Sprintf("%s-tls", domain)
+}
@@ -0,0 +1,118 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/riser-platform/riser-server/api/v1/model"
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CreateDomainMappings(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp-dep",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myapp",
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Domains: []string{"myapp.example.com", "www.example.com"},
+				},
+			},
+		},
+		EnvironmentConfig: &core.EnvironmentConfig{},
+		RiserRevision:     1,
+	}
+
+	result := CreateDomainMappings(ctx)
+
+	require.Len(t, result, 2)
+	assert.Equal(t, "myapp.example.com", result[0].Name)
+	assert.Equal(t, "apps", result[0].Namespace)
+	assert.Equal(t, deploymentLabels(ctx), result[0].Labels)
+	assert.Equal(t, deploymentAnnotations(ctx), result[0].Annotations)
+	assert.Equal(t, "DomainMapping", result[0].TypeMeta.Kind)
+	assert.Equal(t, "serving.knative.dev/v1alpha1", result[0].TypeMeta.APIVersion)
+	assert.Equal(t, DomainMappingRef{
+		Kind:       "Route",
+		Namespace:  "apps",
+		Name:       "myapp-dep",
+		APIVersion: "serving.knative.dev/v1",
+	}, result[0].Spec.Ref)
+	assert.Equal(t, "myapp.example.com-tls", result[0].Spec.TLS.SecretName)
+	assert.Equal(t, "www.example.com", result[1].Name)
+}
+
+func Test_CreateDomainMappings_NoDomains(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp-dep",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myapp",
+			},
+		},
+		EnvironmentConfig: &core.EnvironmentConfig{},
+		RiserRevision:     1,
+	}
+
+	result := CreateDomainMappings(ctx)
+
+	assert.Empty(t, result)
+}
+
+func Test_CreateDomainCertificates(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp-dep",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myapp",
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Domains: []string{"myapp.example.com"},
+				},
+			},
+		},
+		EnvironmentConfig: &core.EnvironmentConfig{},
+		RiserRevision:     1,
+	}
+
+	result := CreateDomainCertificates(ctx)
+
+	require.Len(t, result, 1)
+	assert.Equal(t, "myapp.example.com", result[0].Name)
+	assert.Equal(t, "apps", result[0].Namespace)
+	assert.Equal(t, deploymentLabels(ctx), result[0].Labels)
+	assert.Equal(t, deploymentAnnotations(ctx), result[0].Annotations)
+	assert.Equal(t, "Certificate", result[0].TypeMeta.Kind)
+	assert.Equal(t, "cert-manager.io/v1", result[0].TypeMeta.APIVersion)
+	assert.Equal(t, "myapp.example.com-tls", result[0].Spec.SecretName)
+	assert.Equal(t, []string{"myapp.example.com"}, result[0].Spec.DNSNames)
+	assert.Equal(t, CertificateIssuerRef{Name: DefaultCertificateIssuer, Kind: "ClusterIssuer"}, result[0].Spec.IssuerRef)
+}
+
+func Test_CreateDomainCertificates_EnvironmentIssuer(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp-dep",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myapp",
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Domains: []string{"myapp.example.com"},
+				},
+			},
+		},
+		EnvironmentConfig: &core.EnvironmentConfig{CertificateIssuer: "letsencrypt-staging"},
+		RiserRevision:     1,
+	}
+
+	result := CreateDomainCertificates(ctx)
+
+	assert.Equal(t, "letsencrypt-staging", result[0].Spec.IssuerRef.Name)
+}
@@ -2,27 +2,36 @@ package resources
 
 import (
 	"fmt"
+	"regexp"
 	"sort"
 	"strings"
 
+	"github.com/riser-platform/riser-server/api/v1/model"
 	"github.com/riser-platform/riser-server/pkg/util"
 
 	"github.com/riser-platform/riser-server/pkg/core"
 	corev1 "k8s.io/api/core/v1"
 )
 
+// Matches an escaped "$$" or a $(VAR_NAME) reference using the same syntax as Kubernetes
+var envVarReferencePattern = regexp.MustCompile(`\$\$|\$\(([A-Za-z_][A-Za-z0-9_]*)\)`)
+
 func k8sEnvVars(ctx *core.DeploymentContext) []corev1.EnvVar {
 	envVars := []corev1.EnvVar{}
 	// User defined  vars
 	for key, val := range ctx.DeploymentConfig.App.Environment {
 		envVars = append(envVars, corev1.EnvVar{
 			Name:  strings.ToUpper(key),
-			Value: val.String(),
+			Value: model.ReplaceAppReferences(val.String(), func(reference model.AppReference) string { return appReferenceValue(ctx, reference) }),
 		})
 	}
 
 	// Secret vars
 	for _, secret := range ctx.Secrets {
+		// Secrets mounted as files are not also exposed as env vars
+		if _, ok := ctx.DeploymentConfig.App.SecretFiles[secret.Name]; ok {
+			continue
+		}
 		secretEnv := corev1.EnvVar{
 			Name: strings.ToUpper(secret.Name),
 			ValueFrom: &corev1.EnvVarSource{
@@ -30,7 +39,7 @@ func k8sEnvVars(ctx *core.DeploymentContext) []corev1.EnvVar {
 					Key:      "data",
 					Optional: util.PtrBool(false),
 					LocalObjectReference: corev1.LocalObjectReference{
-						Name: fmt.Sprintf("%s-%s-%d", ctx.DeploymentConfig.App.Name, secret.Name, secret.Revision),
+						Name: secretResourceName(ctx, secret),
 					},
 				},
 			},
@@ -50,6 +59,22 @@ func k8sEnvVars(ctx *core.DeploymentContext) []corev1.EnvVar {
 	return envVars
 }
 
+// appReferenceValue resolves a reference to another app's deployment. The deployment's public URL is used when the deployment is exposed
+// externally, otherwise its in-cluster URL is used. Unresolved references are left as is since the deployment service validates that each
+// referenced deployment exists and exposes a service.
+func appReferenceValue(ctx *core.DeploymentContext, reference model.AppReference) string {
+	for _, referenced := range ctx.ReferencedDeployments {
+		if referenced.Name != reference.DeploymentName || referenced.Namespace != reference.Namespace {
+			continue
+		}
+		if referenced.ExposeScope == model.AppExposeScope_External && ctx.EnvironmentConfig != nil && ctx.EnvironmentConfig.PublicGatewayHost != "" {
+			return fmt.Sprintf("https://%s.%s.%s", referenced.Name, referenced.Namespace, ctx.EnvironmentConfig.PublicGatewayHost)
+		}
+		return fmt.Sprintf("http://%s.%s.svc.cluster.local", referenced.Name, referenced.Namespace)
+	}
+	return fmt.Sprintf("${app:%s.%s.%s}", reference.DeploymentName, reference.Namespace, reference.Property)
+}
+
 type envVarSorter struct {
 	items []corev1.EnvVar
 }
@@ -65,3 +90,30 @@ func (s *envVarSorter) Swap(i, j int) {
 func (s *envVarSorter) Less(i, j int) bool {
 	return strings.Compare(s.items[i].Name, s.items[j].Name) < 0
 }
+
+// expandEnvVarReferences expands $(VAR_NAME) references to env vars that have a literal value. Escaped references ($$(VAR_NAME)) and references
+// to other vars (e.g. secrets) are left as is for Kubernetes to expand when the container starts. Expanded values are escaped so that Kubernetes
+// does not expand them a second time.
+func expandEnvVarReferences(in []string, envVars []corev1.EnvVar) []string {
+	if in == nil {
+		return nil
+	}
+
+	values := map[string]string{}
+	for _, envVar := range envVars {
+		if envVar.ValueFrom == nil {
+			values[envVar.Name] = envVar.Value
+		}
+	}
+
+	out := []string{}
+	for _, str := range in {
+		out = append(out, envVarReferencePattern.ReplaceAllStringFunc(str, func(match string) string {
+			if value, ok := values[envVarReferencePattern.FindStringSubmatch(match)[1]]; ok {
+				return strings.ReplaceAll(value, "$", "$$")
+			}
+			return match
+		}))
+	}
+	return out
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/riser-platform/riser-server/api/v1/model"
 	"github.com/riser-platform/riser-server/pkg/core"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
@@ -59,3 +60,74 @@ func Test_k8sEnvVars(t *testing.T) {
 	assert.Equal(t, "SECRET2", result[8].Name)
 	assert.Equal(t, "myapp-secret2-1", result[8].ValueFrom.SecretKeyRef.LocalObjectReference.Name)
 }
+
+func Test_k8sEnvVars_AppReferences(t *testing.T) {
+	deploymentCtx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp",
+			Namespace:       "myns",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myapp",
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Environment: map[string]intstr.IntOrString{
+						"BILLING_URL": intstr.Parse("${app:payments.billing.url}/api"),
+						"ORDERS_URL":  intstr.Parse("${app:orders.apps.url}"),
+					},
+				},
+			},
+		},
+		EnvironmentConfig: &core.EnvironmentConfig{PublicGatewayHost: "dev.riser.org"},
+		ReferencedDeployments: []core.ReferencedDeployment{
+			{Name: "payments", Namespace: "billing", ExposeScope: model.AppExposeScope_Cluster},
+			{Name: "orders", Namespace: "apps", ExposeScope: model.AppExposeScope_External},
+		},
+	}
+
+	result := k8sEnvVars(deploymentCtx)
+
+	assert.Equal(t, "BILLING_URL", result[0].Name)
+	assert.Equal(t, "http://payments.billing.svc.cluster.local/api", result[0].Value)
+	assert.Equal(t, "ORDERS_URL", result[1].Name)
+	assert.Equal(t, "https://orders.apps.dev.riser.org", result[1].Value)
+}
+
+func Test_appReferenceValue_NotResolved(t *testing.T) {
+	deploymentCtx := &core.DeploymentContext{}
+
+	result := appReferenceValue(deploymentCtx, model.AppReference{DeploymentName: "payments", Namespace: "billing", Property: "url"})
+
+	assert.Equal(t, "${app:payments.billing.url}", result)
+}
+
+func Test_expandEnvVarReferences(t *testing.T) {
+	envVars := []corev1.EnvVar{
+		{Name: "PORT", Value: "8080"},
+		{Name: "PRICE", Value: "$5"},
+		{Name: "MYSECRET", ValueFrom: &corev1.EnvVarSource{}},
+	}
+
+	result := expandEnvVarReferences([]string{
+		"--port=$(PORT)",
+		"$(PRICE)",
+		"$(MYSECRET)",
+		"$(UNKNOWN)",
+		"$$(PORT)",
+		"$PORT",
+	}, envVars)
+
+	assert.Equal(t, []string{
+		"--port=8080",
+		// Expanded values are escaped so that Kubernetes does not expand them again
+		"$$5",
+		// Vars without a literal value are expanded by Kubernetes
+		"$(MYSECRET)",
+		"$(UNKNOWN)",
+		"$$(PORT)",
+		"$PORT",
+	}, result)
+}
+
+func Test_expandEnvVarReferences_Nil(t *testing.T) {
+	assert.Nil(t, expandEnvVarReferences(nil, []corev1.EnvVar{}))
+}
@@ -0,0 +1,71 @@
+package resources
+
+import (
+	"github.com/riser-platform/riser-server/api/v1/model"
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/riser-platform/riser-server/pkg/util"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Matches the default used by the Kubernetes HorizontalPodAutoscaler
+const defaultCPUTargetUtilization = 80
+
+// CreateHorizontalPodAutoscaler creates a HorizontalPodAutoscaler for a worker's Deployment. Returns nil unless autoscale.max is specified,
+// in which case the worker is scaled between autoscale.min (default 1) and autoscale.max replicas using autoscale.target as the average
+// CPU utilization percentage (default 80).
+func CreateHorizontalPodAutoscaler(ctx *core.DeploymentContext) *autoscalingv2beta2.HorizontalPodAutoscaler {
+	if !isHorizontalPodAutoscaled(ctx.DeploymentConfig.App) {
+		return nil
+	}
+
+	autoscale := ctx.DeploymentConfig.App.Autoscale
+	minReplicas := int32(1)
+	if autoscale.Min != nil {
+		minReplicas = int32(*autoscale.Min)
+	}
+	target := int32(defaultCPUTargetUtilization)
+	if autoscale.Target != nil {
+		target = int32(*autoscale.Target)
+	}
+
+	return &autoscalingv2beta2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        ctx.DeploymentConfig.Name,
+			Namespace:   ctx.DeploymentConfig.Namespace,
+			Labels:      deploymentLabels(ctx),
+			Annotations: deploymentAnnotations(ctx),
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "HorizontalPodAutoscaler",
+			APIVersion: "autoscaling/v2beta2",
+		},
+		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       ctx.DeploymentConfig.Name,
+			},
+			MinReplicas: util.PtrInt32(minReplicas),
+			MaxReplicas: int32(*autoscale.Max),
+			Metrics: []autoscalingv2beta2.MetricSpec{
+				{
+					Type: autoscalingv2beta2.ResourceMetricSourceType,
+					Resource: &autoscalingv2beta2.ResourceMetricSource{
+						Name: corev1.ResourceCPU,
+						Target: autoscalingv2beta2.MetricTarget{
+							Type:               autoscalingv2beta2.UtilizationMetricType,
+							AverageUtilization: util.PtrInt32(target),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// isHorizontalPodAutoscaled returns true if the number of replicas of the Deployment is managed by a HorizontalPodAutoscaler
+func isHorizontalPodAutoscaled(app *model.AppConfig) bool {
+	return app.KindOrDefault() == model.AppKind_Worker && app.Autoscale != nil && app.Autoscale.Max != nil
+}
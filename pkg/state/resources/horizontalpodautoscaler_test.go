@@ -0,0 +1,117 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/riser-platform/riser-server/api/v1/model"
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/riser-platform/riser-server/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_CreateHorizontalPodAutoscaler(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myworker",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myworker",
+				Kind: model.AppKind_Worker,
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Autoscale: &model.AppConfigAutoscale{Min: util.PtrInt(2), Max: util.PtrInt(5), Target: util.PtrInt(60)},
+				},
+			},
+		},
+		RiserRevision: 2,
+	}
+
+	result := CreateHorizontalPodAutoscaler(ctx)
+
+	require.NotNil(t, result)
+	assert.Equal(t, "myworker", result.Name)
+	assert.Equal(t, "apps", result.Namespace)
+	assert.Equal(t, deploymentLabels(ctx), result.Labels)
+	assert.Equal(t, deploymentAnnotations(ctx), result.Annotations)
+	assert.Equal(t, "HorizontalPodAutoscaler", result.TypeMeta.Kind)
+	assert.Equal(t, "autoscaling/v2beta2", result.TypeMeta.APIVersion)
+	assert.Equal(t, "apps/v1", result.Spec.ScaleTargetRef.APIVersion)
+	assert.Equal(t, "Deployment", result.Spec.ScaleTargetRef.Kind)
+	assert.Equal(t, "myworker", result.Spec.ScaleTargetRef.Name)
+	assert.EqualValues(t, 2, *result.Spec.MinReplicas)
+	assert.EqualValues(t, 5, result.Spec.MaxReplicas)
+	require.Len(t, result.Spec.Metrics, 1)
+	assert.Equal(t, corev1.ResourceCPU, result.Spec.Metrics[0].Resource.Name)
+	assert.EqualValues(t, 60, *result.Spec.Metrics[0].Resource.Target.AverageUtilization)
+}
+
+func Test_CreateHorizontalPodAutoscaler_Defaults(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myworker",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myworker",
+				Kind: model.AppKind_Worker,
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Autoscale: &model.AppConfigAutoscale{Max: util.PtrInt(3)},
+				},
+			},
+		},
+		RiserRevision: 2,
+	}
+
+	result := CreateHorizontalPodAutoscaler(ctx)
+
+	require.NotNil(t, result)
+	assert.EqualValues(t, 1, *result.Spec.MinReplicas)
+	assert.EqualValues(t, 3, result.Spec.MaxReplicas)
+	assert.EqualValues(t, 80, *result.Spec.Metrics[0].Resource.Target.AverageUtilization)
+}
+
+func Test_CreateHorizontalPodAutoscaler_NoMax(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myworker",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myworker",
+				Kind: model.AppKind_Worker,
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Autoscale: &model.AppConfigAutoscale{Min: util.PtrInt(2)},
+				},
+			},
+		},
+		RiserRevision: 2,
+	}
+
+	assert.Nil(t, CreateHorizontalPodAutoscaler(ctx))
+}
+
+func Test_CreateHorizontalPodAutoscaler_NotWorker(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "mydb",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "mydb",
+				Expose: &model.AppConfigExpose{
+					ContainerPort: 5432,
+					Protocol:      model.AppExposeProtocol_TCP,
+					Scope:         model.AppExposeScope_Cluster,
+				},
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Autoscale: &model.AppConfigAutoscale{Max: util.PtrInt(3)},
+				},
+			},
+		},
+		RiserRevision: 2,
+	}
+
+	assert.Nil(t, CreateHorizontalPodAutoscaler(ctx))
+}
@@ -0,0 +1,42 @@
+package resources
+
+import (
+	"github.com/riser-platform/riser-server/pkg/core"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Istio uses the port name prefix to select the protocol
+const tcpPortName = "tcp"
+
+// CreateKubeService creates a cluster IP Service for a Deployment (see CreateDeployment). The Service has the same name as the deployment so
+// that the app is available at the same cluster address (<deployment>.<namespace>.svc.cluster.local) as a KNative app.
+func CreateKubeService(ctx *core.DeploymentContext) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        ctx.DeploymentConfig.Name,
+			Namespace:   ctx.DeploymentConfig.Namespace,
+			Labels:      deploymentLabels(ctx),
+			Annotations: deploymentAnnotations(ctx),
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeClusterIP,
+			Selector: map[string]string{
+				riserLabel("deployment"): ctx.DeploymentConfig.Name,
+			},
+			Ports: []corev1.ServicePort{
+				{
+					Name:       tcpPortName,
+					Protocol:   corev1.ProtocolTCP,
+					Port:       ctx.DeploymentConfig.App.Expose.ContainerPort,
+					TargetPort: intstr.FromInt(int(ctx.DeploymentConfig.App.Expose.ContainerPort)),
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,46 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/riser-platform/riser-server/api/v1/model"
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_CreateKubeService(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "mydb",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "mydb",
+				Expose: &model.AppConfigExpose{
+					ContainerPort: 5432,
+					Protocol:      model.AppExposeProtocol_TCP,
+					Scope:         model.AppExposeScope_Cluster,
+				},
+			},
+		},
+		RiserRevision: 2,
+	}
+
+	result := CreateKubeService(ctx)
+
+	assert.Equal(t, "mydb", result.Name)
+	assert.Equal(t, "apps", result.Namespace)
+	assert.Equal(t, deploymentLabels(ctx), result.Labels)
+	assert.Equal(t, deploymentAnnotations(ctx), result.Annotations)
+	assert.Equal(t, "Service", result.TypeMeta.Kind)
+	assert.Equal(t, "v1", result.TypeMeta.APIVersion)
+	assert.Equal(t, corev1.ServiceTypeClusterIP, result.Spec.Type)
+	assert.Equal(t, map[string]string{"riser.dev/deployment": "mydb"}, result.Spec.Selector)
+	require.Len(t, result.Spec.Ports, 1)
+	assert.Equal(t, "tcp", result.Spec.Ports[0].Name)
+	assert.Equal(t, corev1.ProtocolTCP, result.Spec.Ports[0].Protocol)
+	assert.EqualValues(t, 5432, result.Spec.Ports[0].Port)
+	assert.EqualValues(t, 5432, result.Spec.Ports[0].TargetPort.IntValue())
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/riser-platform/riser-server/api/v1/model"
 	"github.com/riser-platform/riser-server/pkg/core"
@@ -13,26 +14,48 @@ import (
 )
 
 func createPodSpec(ctx *core.DeploymentContext) corev1.PodSpec {
+	envVars := k8sEnvVars(ctx)
 	return corev1.PodSpec{
-		EnableServiceLinks: util.PtrBool(false),
+		EnableServiceLinks:        util.PtrBool(false),
+		ServiceAccountName:        string(ctx.DeploymentConfig.App.Name),
+		Volumes:                   append(fileVolumes(ctx), secretFileVolumes(ctx)...),
+		NodeSelector:              nodeSelector(ctx.DeploymentConfig.App),
+		Tolerations:               tolerations(ctx.DeploymentConfig.App),
+		Affinity:                  affinity(ctx.DeploymentConfig.App),
+		TopologySpreadConstraints: topologySpreadConstraints(ctx),
+		SecurityContext:           podSecurityContext(ctx.DeploymentConfig.App),
 		Containers: []corev1.Container{
 			{
-				Name:           ctx.DeploymentConfig.Name,
-				Image:          fmt.Sprintf("%s:%s", ctx.DeploymentConfig.App.Image, ctx.DeploymentConfig.Docker.Tag),
-				Resources:      resources(ctx.DeploymentConfig.App),
-				ReadinessProbe: readinessProbe(ctx.DeploymentConfig.App),
-				Env:            k8sEnvVars(ctx),
-				Ports:          createPodPorts(ctx.DeploymentConfig.App.Expose),
+				Name:            ctx.DeploymentConfig.Name,
+				Image:           fmt.Sprintf("%s:%s", ctx.DeploymentConfig.App.Image, ctx.DeploymentConfig.Docker.Tag),
+				Resources:       resources(ctx.DeploymentConfig.App, ctx.EnvironmentConfig),
+				ReadinessProbe:  readinessProbe(ctx.DeploymentConfig.App),
+				LivenessProbe:   livenessProbe(ctx.DeploymentConfig.App),
+				StartupProbe:    startupProbe(ctx.DeploymentConfig.App),
+				Command:         expandEnvVarReferences(ctx.DeploymentConfig.App.Command, envVars),
+				Args:            expandEnvVarReferences(ctx.DeploymentConfig.App.Args, envVars),
+				Env:             envVars,
+				Ports:           createPodPorts(ctx.DeploymentConfig.App.Expose),
+				VolumeMounts:    append(fileVolumeMounts(ctx), secretFileVolumeMounts(ctx)...),
+				SecurityContext: containerSecurityContext(ctx.DeploymentConfig.App),
 			},
 		},
 	}
 }
 
 func createPodPorts(expose *model.AppConfigExpose) []corev1.ContainerPort {
+	// A worker does not expose a port
+	if expose == nil {
+		return nil
+	}
 	containerPortName := ""
+	switch expose.Protocol {
+	// gRPC is served over HTTP/2 without TLS.
 	// See https://github.com/knative/serving/blob/master/docs/runtime-contract.md#protocols-and-ports
-	if expose.Protocol == "http2" {
+	case model.AppExposeProtocol_HTTP2, model.AppExposeProtocol_GRPC:
 		containerPortName = "h2c"
+	case model.AppExposeProtocol_TCP:
+		containerPortName = tcpPortName
 	}
 	ports := []corev1.ContainerPort{
 		{
@@ -49,27 +72,133 @@ func readinessProbe(appConfig *model.AppConfig) *corev1.Probe {
 		return nil
 	}
 
-	probe := &corev1.Probe{
-		Handler: corev1.Handler{
-			HTTPGet: &corev1.HTTPGetAction{
-				Path: appConfig.HealthCheck.Path,
-			},
-		},
+	return createProbe(appConfig.Expose, &appConfig.HealthCheck.AppConfigProbe)
+}
+
+func livenessProbe(appConfig *model.AppConfig) *corev1.Probe {
+	if appConfig.HealthCheck == nil || appConfig.HealthCheck.Liveness == nil {
+		return nil
+	}
+
+	return createProbe(appConfig.Expose, appConfig.HealthCheck.Liveness)
+}
+
+func startupProbe(appConfig *model.AppConfig) *corev1.Probe {
+	if appConfig.HealthCheck == nil || appConfig.HealthCheck.Startup == nil {
+		return nil
+	}
+
+	return createProbe(appConfig.Expose, appConfig.HealthCheck.Startup)
+}
+
+// createProbe does not set the port for httpGet and tcp probes as KNative does not allow it. KNative uses the container port instead.
+// The port is set for the tcp protocol since it is not served by KNative.
+func createProbe(expose *model.AppConfigExpose, appProbe *model.AppConfigProbe) *corev1.Probe {
+	probePort := intstr.IntOrString{}
+	if expose != nil && expose.Protocol == model.AppExposeProtocol_TCP {
+		probePort = intstr.FromInt(int(expose.ContainerPort))
+	}
+
+	probe := &corev1.Probe{}
+	switch appProbe.ModeOrDefault(expose) {
+	case model.AppHealthCheckMode_TCP:
+		probe.TCPSocket = &corev1.TCPSocketAction{Port: probePort}
+	case model.AppHealthCheckMode_GRPC:
+		// Our version of k8s does not support gRPC probes so we rely on grpc_health_probe
+		command := []string{"grpc_health_probe", fmt.Sprintf("-addr=:%d", expose.ContainerPort)}
+		if appProbe.Service != "" {
+			command = append(command, fmt.Sprintf("-service=%s", appProbe.Service))
+		}
+		probe.Exec = &corev1.ExecAction{Command: command}
+	case model.AppHealthCheckMode_Exec:
+		probe.Exec = &corev1.ExecAction{Command: appProbe.Command}
+	default:
+		probe.HTTPGet = &corev1.HTTPGetAction{Path: appProbe.Path, Port: probePort}
+	}
+
+	if appProbe.InitialDelaySeconds != nil {
+		probe.InitialDelaySeconds = *appProbe.InitialDelaySeconds
+	}
+	if appProbe.PeriodSeconds != nil {
+		probe.PeriodSeconds = *appProbe.PeriodSeconds
+	}
+	if appProbe.TimeoutSeconds != nil {
+		probe.TimeoutSeconds = *appProbe.TimeoutSeconds
+	}
+	if appProbe.FailureThreshold != nil {
+		probe.FailureThreshold = *appProbe.FailureThreshold
 	}
 
 	return probe
 }
 
-func resources(appConfig *model.AppConfig) corev1.ResourceRequirements {
-	res := corev1.ResourceRequirements{}
+func resources(appConfig *model.AppConfig, environmentConfig *core.EnvironmentConfig) corev1.ResourceRequirements {
+	requests := model.AppConfigResourceList{}
+	limits := model.AppConfigResourceList{}
 	if appConfig.Resources != nil {
-		res.Limits = corev1.ResourceList{}
-		if appConfig.Resources.CpuCores != nil {
-			res.Limits[corev1.ResourceCPU] = *resource.NewScaledQuantity(int64(*appConfig.Resources.CpuCores*float32(1000)), resource.Milli)
+		if appConfig.Resources.Requests != nil {
+			requests = *appConfig.Resources.Requests
+		}
+		limits = appConfig.Resources.LimitsOrShorthand()
+	}
+
+	defaultRequests := model.AppConfigResourceList{}
+	defaultLimits := model.AppConfigResourceList{}
+	if environmentConfig != nil && environmentConfig.DefaultResources != nil {
+		if environmentConfig.DefaultResources.Requests != nil {
+			defaultRequests = *environmentConfig.DefaultResources.Requests
 		}
-		if appConfig.Resources.MemoryMB != nil {
-			res.Limits[corev1.ResourceMemory] = *resource.NewScaledQuantity(int64(*appConfig.Resources.MemoryMB), resource.Mega)
+		if environmentConfig.DefaultResources.Limits != nil {
+			defaultLimits = *environmentConfig.DefaultResources.Limits
 		}
 	}
+
+	res := corev1.ResourceRequirements{}
+	setResource(&res, corev1.ResourceCPU,
+		cpuQuantity(requests.CpuCores), cpuQuantity(limits.CpuCores), cpuQuantity(defaultRequests.CpuCores), cpuQuantity(defaultLimits.CpuCores))
+	setResource(&res, corev1.ResourceMemory,
+		memoryQuantity(requests.MemoryMB), memoryQuantity(limits.MemoryMB), memoryQuantity(defaultRequests.MemoryMB), memoryQuantity(defaultLimits.MemoryMB))
 	return res
 }
+
+// setResource sets the request and limit for a resource, falling back to the environment defaults when the app does not specify its own.
+// A default request is capped at the app's limit. A default limit is never raised, so an app that requests more than the default limit
+// must specify its own limit (see model.ValidateResourceRequests).
+func setResource(res *corev1.ResourceRequirements, name corev1.ResourceName, request, limit, defaultRequest, defaultLimit *resource.Quantity) {
+	if request == nil && defaultRequest != nil {
+		request = defaultRequest
+		if limit != nil && request.Cmp(*limit) > 0 {
+			request = limit
+		}
+	}
+	if limit == nil {
+		limit = defaultLimit
+	}
+
+	if request != nil {
+		if res.Requests == nil {
+			res.Requests = corev1.ResourceList{}
+		}
+		res.Requests[name] = *request
+	}
+	if limit != nil {
+		if res.Limits == nil {
+			res.Limits = corev1.ResourceList{}
+		}
+		res.Limits[name] = *limit
+	}
+}
+
+func cpuQuantity(cpuCores *float32) *resource.Quantity {
+	if cpuCores == nil {
+		return nil
+	}
+	return resource.NewScaledQuantity(int64(*cpuCores*float32(1000)), resource.Milli)
+}
+
+func memoryQuantity(memoryMB *int32) *resource.Quantity {
+	if memoryMB == nil {
+		return nil
+	}
+	return resource.NewScaledQuantity(int64(*memoryMB), resource.Mega)
+}
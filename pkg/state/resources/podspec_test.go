@@ -3,6 +3,7 @@ package resources
 import (
 	"testing"
 
+	"github.com/riser-platform/riser-server/pkg/core"
 	"github.com/riser-platform/riser-server/pkg/util"
 
 	"github.com/riser-platform/riser-server/api/v1/model"
@@ -32,8 +33,10 @@ func Test_readinessProbe_nilHealth(t *testing.T) {
 
 func Test_readinessProbe_httpGet(t *testing.T) {
 	app := &model.AppConfig{
-		HealthCheck: &model.AppConfigHealthCheck{
-			Path: "/health",
+		OverrideableAppConfig: model.OverrideableAppConfig{
+			HealthCheck: &model.AppConfigHealthCheck{
+				AppConfigProbe: model.AppConfigProbe{Path: "/health"},
+			},
 		},
 	}
 
@@ -42,6 +45,158 @@ func Test_readinessProbe_httpGet(t *testing.T) {
 	assert.Equal(t, "/health", result.HTTPGet.Path)
 	// KNative does not allow setting the port on a probe
 	assert.Empty(t, result.HTTPGet.Port)
+	assert.Nil(t, result.TCPSocket)
+	assert.Nil(t, result.Exec)
+}
+
+func Test_readinessProbe_tcp(t *testing.T) {
+	app := &model.AppConfig{
+		OverrideableAppConfig: model.OverrideableAppConfig{
+			HealthCheck: &model.AppConfigHealthCheck{
+				AppConfigProbe: model.AppConfigProbe{Mode: model.AppHealthCheckMode_TCP},
+			},
+		},
+	}
+
+	result := readinessProbe(app)
+
+	assert.NotNil(t, result.TCPSocket)
+	// KNative does not allow setting the port on a probe
+	assert.Empty(t, result.TCPSocket.Port)
+	assert.Nil(t, result.HTTPGet)
+}
+
+func Test_readinessProbe_grpc(t *testing.T) {
+	app := &model.AppConfig{
+		Expose: &model.AppConfigExpose{ContainerPort: 8080},
+		OverrideableAppConfig: model.OverrideableAppConfig{
+			HealthCheck: &model.AppConfigHealthCheck{
+				AppConfigProbe: model.AppConfigProbe{Mode: model.AppHealthCheckMode_GRPC, Service: "myservice"},
+			},
+		},
+	}
+
+	result := readinessProbe(app)
+
+	assert.Equal(t, []string{"grpc_health_probe", "-addr=:8080", "-service=myservice"}, result.Exec.Command)
+	assert.Nil(t, result.HTTPGet)
+}
+
+func Test_readinessProbe_grpcNoService(t *testing.T) {
+	app := &model.AppConfig{
+		Expose: &model.AppConfigExpose{ContainerPort: 8080},
+		OverrideableAppConfig: model.OverrideableAppConfig{
+			HealthCheck: &model.AppConfigHealthCheck{
+				AppConfigProbe: model.AppConfigProbe{Mode: model.AppHealthCheckMode_GRPC},
+			},
+		},
+	}
+
+	result := readinessProbe(app)
+
+	assert.Equal(t, []string{"grpc_health_probe", "-addr=:8080"}, result.Exec.Command)
+}
+
+func Test_readinessProbe_grpcProtocolDefault(t *testing.T) {
+	app := &model.AppConfig{
+		Expose: &model.AppConfigExpose{ContainerPort: 8080, Protocol: model.AppExposeProtocol_GRPC},
+		OverrideableAppConfig: model.OverrideableAppConfig{
+			HealthCheck: &model.AppConfigHealthCheck{},
+		},
+	}
+
+	result := readinessProbe(app)
+
+	assert.Equal(t, []string{"grpc_health_probe", "-addr=:8080"}, result.Exec.Command)
+	assert.Nil(t, result.HTTPGet)
+}
+
+func Test_readinessProbe_tcpProtocolSetsPort(t *testing.T) {
+	app := &model.AppConfig{
+		Expose: &model.AppConfigExpose{ContainerPort: 5432, Protocol: model.AppExposeProtocol_TCP},
+		OverrideableAppConfig: model.OverrideableAppConfig{
+			HealthCheck: &model.AppConfigHealthCheck{
+				Liveness: &model.AppConfigProbe{Mode: model.AppHealthCheckMode_HTTPGet, Path: "/health"},
+			},
+		},
+	}
+
+	readiness := readinessProbe(app)
+	liveness := livenessProbe(app)
+
+	// The tcp protocol is not served by KNative so the port must be set
+	assert.EqualValues(t, 5432, readiness.TCPSocket.Port.IntValue())
+	assert.EqualValues(t, 5432, liveness.HTTPGet.Port.IntValue())
+}
+
+func Test_readinessProbe_exec(t *testing.T) {
+	app := &model.AppConfig{
+		OverrideableAppConfig: model.OverrideableAppConfig{
+			HealthCheck: &model.AppConfigHealthCheck{
+				AppConfigProbe: model.AppConfigProbe{Mode: model.AppHealthCheckMode_Exec, Command: []string{"cat", "/tmp/healthy"}},
+			},
+		},
+	}
+
+	result := readinessProbe(app)
+
+	assert.Equal(t, []string{"cat", "/tmp/healthy"}, result.Exec.Command)
+	assert.Nil(t, result.HTTPGet)
+}
+
+func Test_readinessProbe_tuning(t *testing.T) {
+	app := &model.AppConfig{
+		OverrideableAppConfig: model.OverrideableAppConfig{
+			HealthCheck: &model.AppConfigHealthCheck{
+				AppConfigProbe: model.AppConfigProbe{
+					Path:                "/health",
+					InitialDelaySeconds: util.PtrInt32(1),
+					PeriodSeconds:       util.PtrInt32(2),
+					TimeoutSeconds:      util.PtrInt32(3),
+					FailureThreshold:    util.PtrInt32(4),
+				},
+			},
+		},
+	}
+
+	result := readinessProbe(app)
+
+	assert.EqualValues(t, 1, result.InitialDelaySeconds)
+	assert.EqualValues(t, 2, result.PeriodSeconds)
+	assert.EqualValues(t, 3, result.TimeoutSeconds)
+	assert.EqualValues(t, 4, result.FailureThreshold)
+}
+
+func Test_livenessProbe_startupProbe(t *testing.T) {
+	app := &model.AppConfig{
+		OverrideableAppConfig: model.OverrideableAppConfig{
+			HealthCheck: &model.AppConfigHealthCheck{
+				AppConfigProbe: model.AppConfigProbe{Path: "/ready"},
+				Liveness:       &model.AppConfigProbe{Path: "/live"},
+				Startup:        &model.AppConfigProbe{Mode: model.AppHealthCheckMode_TCP, FailureThreshold: util.PtrInt32(30)},
+			},
+		},
+	}
+
+	liveness := livenessProbe(app)
+	startup := startupProbe(app)
+
+	assert.Equal(t, "/live", liveness.HTTPGet.Path)
+	assert.NotNil(t, startup.TCPSocket)
+	assert.EqualValues(t, 30, startup.FailureThreshold)
+}
+
+func Test_livenessProbe_startupProbe_notSpecified(t *testing.T) {
+	app := &model.AppConfig{
+		OverrideableAppConfig: model.OverrideableAppConfig{
+			HealthCheck: &model.AppConfigHealthCheck{
+				AppConfigProbe: model.AppConfigProbe{Path: "/health"},
+			},
+		},
+	}
+
+	assert.Nil(t, livenessProbe(app))
+	assert.Nil(t, startupProbe(app))
 }
 
 func Test_resources(t *testing.T) {
@@ -54,10 +209,96 @@ func Test_resources(t *testing.T) {
 		},
 	}
 
-	result := resources(app)
+	result := resources(app, nil)
 
 	assert.EqualValues(t, 1500, result.Limits.Cpu().MilliValue(), "millicores")
 	assert.EqualValues(t, 4096000000, result.Limits.Memory().Value(), "bytes")
+	assert.Empty(t, result.Requests)
+}
+
+func Test_resources_RequestsAndLimits(t *testing.T) {
+	app := &model.AppConfig{
+		OverrideableAppConfig: model.OverrideableAppConfig{
+			Resources: &model.AppConfigResources{
+				Requests: &model.AppConfigResourceList{
+					CpuCores: util.PtrFloat32(0.25),
+					MemoryMB: util.PtrInt32(128),
+				},
+				Limits: &model.AppConfigResourceList{
+					CpuCores: util.PtrFloat32(1),
+					MemoryMB: util.PtrInt32(256),
+				},
+			},
+		},
+	}
+
+	result := resources(app, nil)
+
+	assert.EqualValues(t, 250, result.Requests.Cpu().MilliValue(), "millicores")
+	assert.EqualValues(t, 128000000, result.Requests.Memory().Value(), "bytes")
+	assert.EqualValues(t, 1000, result.Limits.Cpu().MilliValue(), "millicores")
+	assert.EqualValues(t, 256000000, result.Limits.Memory().Value(), "bytes")
+}
+
+func Test_resources_EnvironmentDefaults(t *testing.T) {
+	app := &model.AppConfig{}
+	environmentConfig := &core.EnvironmentConfig{
+		DefaultResources: &core.EnvironmentDefaultResources{
+			Requests: &model.AppConfigResourceList{
+				CpuCores: util.PtrFloat32(0.1),
+				MemoryMB: util.PtrInt32(64),
+			},
+			Limits: &model.AppConfigResourceList{
+				CpuCores: util.PtrFloat32(0.5),
+				MemoryMB: util.PtrInt32(512),
+			},
+		},
+	}
+
+	result := resources(app, environmentConfig)
+
+	assert.EqualValues(t, 100, result.Requests.Cpu().MilliValue(), "millicores")
+	assert.EqualValues(t, 64000000, result.Requests.Memory().Value(), "bytes")
+	assert.EqualValues(t, 500, result.Limits.Cpu().MilliValue(), "millicores")
+	assert.EqualValues(t, 512000000, result.Limits.Memory().Value(), "bytes")
+}
+
+func Test_resources_AppOverridesEnvironmentDefaults(t *testing.T) {
+	app := &model.AppConfig{
+		OverrideableAppConfig: model.OverrideableAppConfig{
+			Resources: &model.AppConfigResources{
+				// A limit below the default request caps the request
+				CpuCores: util.PtrFloat32(0.05),
+				// A request below the default limit keeps the default limit
+				Requests: &model.AppConfigResourceList{
+					MemoryMB: util.PtrInt32(256),
+				},
+			},
+		},
+	}
+	environmentConfig := &core.EnvironmentConfig{
+		DefaultResources: &core.EnvironmentDefaultResources{
+			Requests: &model.AppConfigResourceList{
+				CpuCores: util.PtrFloat32(0.1),
+				MemoryMB: util.PtrInt32(64),
+			},
+			Limits: &model.AppConfigResourceList{
+				CpuCores: util.PtrFloat32(0.5),
+				MemoryMB: util.PtrInt32(512),
+			},
+		},
+	}
+
+	result := resources(app, environmentConfig)
+
+	assert.EqualValues(t, 50, result.Requests.Cpu().MilliValue(), "millicores")
+	assert.EqualValues(t, 50, result.Limits.Cpu().MilliValue(), "millicores")
+	assert.EqualValues(t, 256000000, result.Requests.Memory().Value(), "bytes")
+	assert.EqualValues(t, 512000000, result.Limits.Memory().Value(), "bytes")
+}
+
+func Test_createPodPorts_noExpose(t *testing.T) {
+	assert.Nil(t, createPodPorts(nil))
 }
 
 func Test_createPodPorts_http(t *testing.T) {
@@ -87,3 +328,52 @@ func Test_createPodPorts_http2(t *testing.T) {
 	assert.Equal(t, corev1.ProtocolTCP, result[0].Protocol)
 	assert.Equal(t, "h2c", result[0].Name)
 }
+
+func Test_createPodPorts_grpc(t *testing.T) {
+	expose := &model.AppConfigExpose{
+		Protocol:      "grpc",
+		ContainerPort: 80,
+	}
+
+	result := createPodPorts(expose)
+
+	assert.Len(t, result, 1)
+	assert.Equal(t, "h2c", result[0].Name)
+}
+
+func Test_createPodPorts_tcp(t *testing.T) {
+	expose := &model.AppConfigExpose{
+		Protocol:      "tcp",
+		ContainerPort: 5432,
+	}
+
+	result := createPodPorts(expose)
+
+	assert.Len(t, result, 1)
+	assert.EqualValues(t, 5432, result[0].ContainerPort)
+	assert.Equal(t, corev1.ProtocolTCP, result[0].Protocol)
+	assert.Equal(t, "tcp", result[0].Name)
+}
+
+func Test_createPodSpec_CommandAndArgs(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp-admin",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name:   "myapp",
+				Expose: &model.AppConfigExpose{ContainerPort: 8080},
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Command: []string{"/bin/server"},
+					Args:    []string{"--mode=admin", "--env=$(RISER_ENVIRONMENT)"},
+				},
+			},
+		},
+		RiserRevision: 1,
+	}
+
+	result := createPodSpec(ctx)
+
+	assert.Equal(t, []string{"/bin/server"}, result.Containers[0].Command)
+	assert.Equal(t, []string{"--mode=admin", "--env=myenv"}, result.Containers[0].Args)
+}
@@ -0,0 +1,59 @@
+package resources
+
+import (
+	"fmt"
+
+	"github.com/riser-platform/riser-server/pkg/core"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PreDeployJobName returns the name of the pre-deploy job of a riser revision. The name includes the revision since a Job cannot be
+// updated once created.
+func PreDeployJobName(deploymentName string, riserRevision int64) string {
+	return fmt.Sprintf("%s-%d-predeploy", deploymentName, riserRevision)
+}
+
+// CreatePreDeployJob creates a Job that runs the app's pre-deploy command with the same image, env, and secrets as the revision. Returns
+// nil if the app does not specify a pre-deploy job. The riser controller reports the job's status so that the revision receives traffic
+// once the job succeeds.
+func CreatePreDeployJob(ctx *core.DeploymentContext) *batchv1.Job {
+	preDeploy := ctx.DeploymentConfig.App.PreDeploy
+	if preDeploy == nil {
+		return nil
+	}
+
+	podSpec := createPodSpec(ctx)
+	// Each retry runs in a new pod so that the logs of a failed attempt are kept
+	podSpec.RestartPolicy = corev1.RestartPolicyNever
+	container := &podSpec.Containers[0]
+	container.Command = expandEnvVarReferences(preDeploy.Command, container.Env)
+	container.Args = expandEnvVarReferences(preDeploy.Args, container.Env)
+	container.Ports = nil
+	container.ReadinessProbe = nil
+	container.LivenessProbe = nil
+	container.StartupProbe = nil
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        PreDeployJobName(ctx.DeploymentConfig.Name, ctx.RiserRevision),
+			Namespace:   ctx.DeploymentConfig.Namespace,
+			Labels:      deploymentLabels(ctx),
+			Annotations: deploymentAnnotations(ctx),
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Job",
+			APIVersion: "batch/v1",
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      deploymentLabels(ctx),
+					Annotations: jobPodAnnotations(ctx),
+				},
+				Spec: podSpec,
+			},
+		},
+	}
+}
@@ -0,0 +1,83 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/riser-platform/riser-server/api/v1/model"
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func Test_CreatePreDeployJob(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myapp",
+				Expose: &model.AppConfigExpose{
+					ContainerPort: 8000,
+				},
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					Command:     []string{"/bin/server"},
+					Environment: map[string]intstr.IntOrString{"DB_NAME": intstr.FromString("mydb")},
+					HealthCheck: &model.AppConfigHealthCheck{AppConfigProbe: model.AppConfigProbe{Path: "/health"}},
+					PreDeploy: &model.AppConfigPreDeploy{
+						Command: []string{"/bin/migrate"},
+						Args:    []string{"--database", "$(DB_NAME)"},
+					},
+				},
+			},
+		},
+		RiserRevision: 3,
+	}
+
+	result := CreatePreDeployJob(ctx)
+
+	require.NotNil(t, result)
+	assert.Equal(t, "myapp-3-predeploy", result.Name)
+	assert.Equal(t, "apps", result.Namespace)
+	assert.Equal(t, deploymentLabels(ctx), result.Labels)
+	assert.Equal(t, deploymentAnnotations(ctx), result.Annotations)
+	assert.Equal(t, "Job", result.TypeMeta.Kind)
+	assert.Equal(t, "batch/v1", result.TypeMeta.APIVersion)
+	assert.Nil(t, result.Spec.BackoffLimit)
+	assert.Equal(t, deploymentLabels(ctx), result.Spec.Template.Labels)
+	assert.Equal(t, "3", result.Spec.Template.Annotations["riser.dev/revision"])
+	assert.Equal(t, "false", result.Spec.Template.Annotations["sidecar.istio.io/inject"])
+	podSpec := result.Spec.Template.Spec
+	assert.Equal(t, corev1.RestartPolicyNever, podSpec.RestartPolicy)
+	require.Len(t, podSpec.Containers, 1)
+	container := podSpec.Containers[0]
+	assert.Equal(t, "myapp", container.Name)
+	assert.Equal(t, []string{"/bin/migrate"}, container.Command)
+	assert.Equal(t, []string{"--database", "mydb"}, container.Args)
+	assert.Equal(t, createPodSpec(ctx).Containers[0].Env, container.Env)
+	assert.Empty(t, container.Ports)
+	assert.Nil(t, container.ReadinessProbe)
+	assert.Nil(t, container.LivenessProbe)
+	assert.Nil(t, container.StartupProbe)
+}
+
+func Test_CreatePreDeployJob_NoPreDeploy(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myapp",
+				Expose: &model.AppConfigExpose{
+					ContainerPort: 8000,
+				},
+			},
+		},
+		RiserRevision: 3,
+	}
+
+	assert.Nil(t, CreatePreDeployJob(ctx))
+}
@@ -0,0 +1,94 @@
+package resources
+
+import (
+	"github.com/riser-platform/riser-server/api/v1/model"
+	"github.com/riser-platform/riser-server/pkg/core"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var tolerationOperators = map[string]corev1.TolerationOperator{
+	"":                                 corev1.TolerationOpEqual,
+	model.AppTolerationOperator_Equal:  corev1.TolerationOpEqual,
+	model.AppTolerationOperator_Exists: corev1.TolerationOpExists,
+}
+
+var taintEffects = map[string]corev1.TaintEffect{
+	model.AppTolerationEffect_NoSchedule:       corev1.TaintEffectNoSchedule,
+	model.AppTolerationEffect_PreferNoSchedule: corev1.TaintEffectPreferNoSchedule,
+	model.AppTolerationEffect_NoExecute:        corev1.TaintEffectNoExecute,
+}
+
+var zoneSpreadUnsatisfiableActions = map[string]corev1.UnsatisfiableConstraintAction{
+	model.AppZoneSpread_Preferred: corev1.ScheduleAnyway,
+	model.AppZoneSpread_Required:  corev1.DoNotSchedule,
+}
+
+func nodeSelector(appConfig *model.AppConfig) map[string]string {
+	if appConfig.Scheduling == nil {
+		return nil
+	}
+	return appConfig.Scheduling.NodeSelector
+}
+
+func tolerations(appConfig *model.AppConfig) []corev1.Toleration {
+	if appConfig.Scheduling == nil || len(appConfig.Scheduling.Tolerations) == 0 {
+		return nil
+	}
+
+	tolerations := []corev1.Toleration{}
+	for _, toleration := range appConfig.Scheduling.Tolerations {
+		tolerations = append(tolerations, corev1.Toleration{
+			Key:      toleration.Key,
+			Operator: tolerationOperators[toleration.Operator],
+			Value:    toleration.Value,
+			Effect:   taintEffects[toleration.Effect],
+		})
+	}
+	return tolerations
+}
+
+func affinity(appConfig *model.AppConfig) *corev1.Affinity {
+	if appConfig.Scheduling == nil || appConfig.Scheduling.Affinity == nil || len(appConfig.Scheduling.Affinity.Zones) == 0 {
+		return nil
+	}
+
+	return &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{
+						MatchExpressions: []corev1.NodeSelectorRequirement{
+							{
+								Key:      model.AppSchedulingZoneLabel,
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   appConfig.Scheduling.Affinity.Zones,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// topologySpreadConstraints spreads the deployment's pods across zones so that the number of pods in any two zones differs by at most one
+func topologySpreadConstraints(ctx *core.DeploymentContext) []corev1.TopologySpreadConstraint {
+	scheduling := ctx.DeploymentConfig.App.Scheduling
+	if scheduling == nil || scheduling.Affinity == nil || scheduling.Affinity.ZoneSpread == "" {
+		return nil
+	}
+
+	return []corev1.TopologySpreadConstraint{
+		{
+			MaxSkew:           1,
+			TopologyKey:       model.AppSchedulingZoneLabel,
+			WhenUnsatisfiable: zoneSpreadUnsatisfiableActions[scheduling.Affinity.ZoneSpread],
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					riserLabel("deployment"): ctx.DeploymentConfig.Name,
+				},
+			},
+		},
+	}
+}
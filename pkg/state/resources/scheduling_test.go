@@ -0,0 +1,107 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/riser-platform/riser-server/api/v1/model"
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_scheduling_notSpecified(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name: "myapp",
+			App:  &model.AppConfig{},
+		},
+	}
+
+	assert.Nil(t, nodeSelector(ctx.DeploymentConfig.App))
+	assert.Nil(t, tolerations(ctx.DeploymentConfig.App))
+	assert.Nil(t, affinity(ctx.DeploymentConfig.App))
+	assert.Nil(t, topologySpreadConstraints(ctx))
+}
+
+func Test_nodeSelector(t *testing.T) {
+	app := &model.AppConfig{
+		OverrideableAppConfig: model.OverrideableAppConfig{
+			Scheduling: &model.AppConfigScheduling{NodeSelector: map[string]string{"node-pool": "highmem"}},
+		},
+	}
+
+	result := nodeSelector(app)
+
+	assert.Equal(t, map[string]string{"node-pool": "highmem"}, result)
+}
+
+func Test_tolerations(t *testing.T) {
+	app := &model.AppConfig{
+		OverrideableAppConfig: model.OverrideableAppConfig{
+			Scheduling: &model.AppConfigScheduling{
+				Tolerations: []model.AppConfigToleration{
+					{Key: "dedicated", Value: "highmem", Effect: model.AppTolerationEffect_NoSchedule},
+					{Key: "example.com/maintenance", Operator: model.AppTolerationOperator_Exists},
+				},
+			},
+		},
+	}
+
+	result := tolerations(app)
+
+	require.Len(t, result, 2)
+	assert.Equal(t, corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "highmem", Effect: corev1.TaintEffectNoSchedule}, result[0])
+	assert.Equal(t, corev1.Toleration{Key: "example.com/maintenance", Operator: corev1.TolerationOpExists}, result[1])
+}
+
+func Test_affinity(t *testing.T) {
+	app := &model.AppConfig{
+		OverrideableAppConfig: model.OverrideableAppConfig{
+			Scheduling: &model.AppConfigScheduling{
+				Affinity: &model.AppConfigAffinity{Zones: []string{"us-east1-b", "us-east1-c"}},
+			},
+		},
+	}
+
+	result := affinity(app)
+
+	terms := result.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	require.Len(t, terms, 1)
+	assert.Equal(t, []corev1.NodeSelectorRequirement{
+		{Key: "topology.kubernetes.io/zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"us-east1-b", "us-east1-c"}},
+	}, terms[0].MatchExpressions)
+}
+
+func Test_topologySpreadConstraints(t *testing.T) {
+	tests := []struct {
+		zoneSpread        string
+		whenUnsatisfiable corev1.UnsatisfiableConstraintAction
+	}{
+		{model.AppZoneSpread_Preferred, corev1.ScheduleAnyway},
+		{model.AppZoneSpread_Required, corev1.DoNotSchedule},
+	}
+
+	for _, tt := range tests {
+		ctx := &core.DeploymentContext{
+			DeploymentConfig: &core.DeploymentConfig{
+				Name: "myapp",
+				App: &model.AppConfig{
+					OverrideableAppConfig: model.OverrideableAppConfig{
+						Scheduling: &model.AppConfigScheduling{
+							Affinity: &model.AppConfigAffinity{ZoneSpread: tt.zoneSpread},
+						},
+					},
+				},
+			},
+		}
+
+		result := topologySpreadConstraints(ctx)
+
+		require.Len(t, result, 1)
+		assert.EqualValues(t, 1, result[0].MaxSkew)
+		assert.Equal(t, "topology.kubernetes.io/zone", result[0].TopologyKey)
+		assert.Equal(t, tt.whenUnsatisfiable, result[0].WhenUnsatisfiable)
+		assert.Equal(t, map[string]string{"riser.dev/deployment": "myapp"}, result[0].LabelSelector.MatchLabels)
+	}
+}
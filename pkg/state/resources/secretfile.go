@@ -0,0 +1,82 @@
+package resources
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/riser-platform/riser-server/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// secretFile is a secret from the app config's "secretFiles" section
+type secretFile struct {
+	secret    core.SecretMeta
+	mountPath string
+	mode      *int32
+}
+
+func secretFileVolumes(ctx *core.DeploymentContext) []corev1.Volume {
+	volumes := []corev1.Volume{}
+	for _, file := range secretFiles(ctx) {
+		volumes = append(volumes, corev1.Volume{
+			Name: secretFileVolumeName(file),
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: secretResourceName(ctx, file.secret),
+					Items: []corev1.KeyToPath{
+						{Key: "data", Path: path.Base(file.mountPath)},
+					},
+					DefaultMode: file.mode,
+					Optional:    util.PtrBool(false),
+				},
+			},
+		})
+	}
+	return volumes
+}
+
+func secretFileVolumeMounts(ctx *core.DeploymentContext) []corev1.VolumeMount {
+	volumeMounts := []corev1.VolumeMount{}
+	for _, file := range secretFiles(ctx) {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      secretFileVolumeName(file),
+			MountPath: file.mountPath,
+			SubPath:   path.Base(file.mountPath),
+			// KNative requires all volume mounts to be read-only
+			ReadOnly: true,
+		})
+	}
+	return volumeMounts
+}
+
+// secretFiles returns the secrets that are mounted as files sorted by secret name. Secrets that do not exist in the environment are
+// omitted in the same way that they are omitted from env vars.
+func secretFiles(ctx *core.DeploymentContext) []secretFile {
+	files := []secretFile{}
+	for _, secret := range ctx.Secrets {
+		if file, ok := ctx.DeploymentConfig.App.SecretFiles[secret.Name]; ok {
+			files = append(files, secretFile{
+				secret:    secret,
+				mountPath: file.MountPath,
+				mode:      file.Mode,
+			})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].secret.Name < files[j].secret.Name
+	})
+	return files
+}
+
+// secretResourceName returns the name of the revisioned k8s secret
+func secretResourceName(ctx *core.DeploymentContext, secret core.SecretMeta) string {
+	return fmt.Sprintf("%s-%s-%d", ctx.DeploymentConfig.App.Name, secret.Name, secret.Revision)
+}
+
+// secretFileVolumeName uses a hash of the secret name since secret names are not always valid volume names
+func secretFileVolumeName(file secretFile) string {
+	return fmt.Sprintf("secret-%s", fmt.Sprintf("%x", sha256.Sum256([]byte(file.secret.Name)))[:10])
+}
@@ -0,0 +1,141 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/riser-platform/riser-server/api/v1/model"
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/riser-platform/riser-server/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_secretFileVolumes(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp-dep",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myapp",
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					SecretFiles: map[string]model.AppConfigSecretFile{
+						"tls_key": {MountPath: "/etc/myapp/tls.key", Mode: util.PtrInt32(0400)},
+						"ca_crt":  {MountPath: "/etc/myapp/ca.crt"},
+						// Does not exist in the environment
+						"missing": {MountPath: "/etc/myapp/missing"},
+					},
+				},
+			},
+		},
+		Secrets: []core.SecretMeta{
+			{Name: "tls_key", Revision: 3},
+			{Name: "envsecret", Revision: 1},
+			{Name: "ca_crt", Revision: 2},
+		},
+		RiserRevision: 1,
+	}
+
+	result := secretFileVolumes(ctx)
+
+	require.Len(t, result, 2)
+	assert.Regexp(t, "^secret-[0-9a-f]{10}$", result[0].Name)
+	assert.Equal(t, "myapp-ca_crt-2", result[0].Secret.SecretName)
+	assert.Equal(t, "data", result[0].Secret.Items[0].Key)
+	assert.Equal(t, "ca.crt", result[0].Secret.Items[0].Path)
+	assert.Nil(t, result[0].Secret.DefaultMode)
+	assert.False(t, *result[0].Secret.Optional)
+	assert.Equal(t, "myapp-tls_key-3", result[1].Secret.SecretName)
+	assert.Equal(t, "tls.key", result[1].Secret.Items[0].Path)
+	assert.Equal(t, int32(0400), *result[1].Secret.DefaultMode)
+	assert.NotEqual(t, result[0].Name, result[1].Name)
+}
+
+func Test_secretFileVolumeMounts(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp-dep",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myapp",
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					SecretFiles: map[string]model.AppConfigSecretFile{
+						"tls_key": {MountPath: "/etc/myapp/tls.key"},
+					},
+				},
+			},
+		},
+		Secrets: []core.SecretMeta{
+			{Name: "tls_key", Revision: 3},
+			{Name: "envsecret", Revision: 1},
+			{Name: "ca_crt", Revision: 2},
+		},
+		RiserRevision: 1,
+	}
+
+	result := secretFileVolumeMounts(ctx)
+
+	require.Len(t, result, 1)
+	assert.Equal(t, secretFileVolumes(ctx)[0].Name, result[0].Name)
+	assert.Equal(t, "/etc/myapp/tls.key", result[0].MountPath)
+	assert.Equal(t, "tls.key", result[0].SubPath)
+	assert.True(t, result[0].ReadOnly)
+}
+
+func Test_secretFileVolumes_NoSecretFiles(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp-dep",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myapp",
+			},
+		},
+		Secrets: []core.SecretMeta{
+			{Name: "tls_key", Revision: 3},
+			{Name: "envsecret", Revision: 1},
+			{Name: "ca_crt", Revision: 2},
+		},
+		RiserRevision: 1,
+	}
+
+	assert.Empty(t, secretFileVolumes(ctx))
+	assert.Empty(t, secretFileVolumeMounts(ctx))
+}
+
+func Test_k8sEnvVars_ExcludesSecretFiles(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:            "myapp-dep",
+			Namespace:       "apps",
+			EnvironmentName: "myenv",
+			App: &model.AppConfig{
+				Name: "myapp",
+				OverrideableAppConfig: model.OverrideableAppConfig{
+					SecretFiles: map[string]model.AppConfigSecretFile{
+						"tls_key": {MountPath: "/etc/myapp/tls.key"},
+						"ca_crt":  {MountPath: "/etc/myapp/ca.crt"},
+					},
+				},
+			},
+		},
+		Secrets: []core.SecretMeta{
+			{Name: "tls_key", Revision: 3},
+			{Name: "envsecret", Revision: 1},
+			{Name: "ca_crt", Revision: 2},
+		},
+		RiserRevision: 1,
+	}
+
+	result := k8sEnvVars(ctx)
+
+	secretEnvVars := []string{}
+	for _, envVar := range result {
+		if envVar.ValueFrom != nil {
+			secretEnvVars = append(secretEnvVars, envVar.Name)
+		}
+	}
+	assert.Equal(t, []string{"ENVSECRET"}, secretEnvVars)
+}
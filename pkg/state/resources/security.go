@@ -0,0 +1,51 @@
+package resources
+
+import (
+	"github.com/riser-platform/riser-server/api/v1/model"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func podSecurityContext(appConfig *model.AppConfig) *corev1.PodSecurityContext {
+	security := appConfig.Security
+	if security == nil || (security.RunAsNonRoot == nil && security.RunAsUser == nil && security.RunAsGroup == nil && security.FSGroup == nil) {
+		return nil
+	}
+
+	return &corev1.PodSecurityContext{
+		RunAsNonRoot: security.RunAsNonRoot,
+		RunAsUser:    security.RunAsUser,
+		RunAsGroup:   security.RunAsGroup,
+		FSGroup:      security.FSGroup,
+	}
+}
+
+func containerSecurityContext(appConfig *model.AppConfig) *corev1.SecurityContext {
+	security := appConfig.Security
+	if security == nil || (security.ReadOnlyRootFilesystem == nil && security.AllowPrivilegeEscalation == nil && security.Capabilities == nil) {
+		return nil
+	}
+
+	securityContext := &corev1.SecurityContext{
+		ReadOnlyRootFilesystem:   security.ReadOnlyRootFilesystem,
+		AllowPrivilegeEscalation: security.AllowPrivilegeEscalation,
+	}
+	if security.Capabilities != nil {
+		securityContext.Capabilities = &corev1.Capabilities{
+			Add:  k8sCapabilities(security.Capabilities.Add),
+			Drop: k8sCapabilities(security.Capabilities.Drop),
+		}
+	}
+	return securityContext
+}
+
+func k8sCapabilities(capabilities []string) []corev1.Capability {
+	if len(capabilities) == 0 {
+		return nil
+	}
+
+	out := []corev1.Capability{}
+	for _, capability := range capabilities {
+		out = append(out, corev1.Capability(capability))
+	}
+	return out
+}
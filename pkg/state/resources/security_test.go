@@ -0,0 +1,65 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/riser-platform/riser-server/api/v1/model"
+	"github.com/riser-platform/riser-server/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_securityContext_notSpecified(t *testing.T) {
+	app := &model.AppConfig{}
+
+	assert.Nil(t, podSecurityContext(app))
+	assert.Nil(t, containerSecurityContext(app))
+}
+
+func Test_podSecurityContext(t *testing.T) {
+	app := &model.AppConfig{
+		OverrideableAppConfig: model.OverrideableAppConfig{
+			Security: &model.AppConfigSecurity{
+				RunAsNonRoot: util.PtrBool(true),
+				RunAsUser:    util.PtrInt64(1000),
+				RunAsGroup:   util.PtrInt64(1001),
+				FSGroup:      util.PtrInt64(2000),
+			},
+		},
+	}
+
+	result := podSecurityContext(app)
+
+	assert.Equal(t, &corev1.PodSecurityContext{
+		RunAsNonRoot: util.PtrBool(true),
+		RunAsUser:    util.PtrInt64(1000),
+		RunAsGroup:   util.PtrInt64(1001),
+		FSGroup:      util.PtrInt64(2000),
+	}, result)
+	assert.Nil(t, containerSecurityContext(app))
+}
+
+func Test_containerSecurityContext(t *testing.T) {
+	app := &model.AppConfig{
+		OverrideableAppConfig: model.OverrideableAppConfig{
+			Security: &model.AppConfigSecurity{
+				ReadOnlyRootFilesystem:   util.PtrBool(true),
+				AllowPrivilegeEscalation: util.PtrBool(false),
+				Capabilities: &model.AppConfigCapabilities{
+					Add:  []string{"NET_BIND_SERVICE"},
+					Drop: []string{"ALL"},
+				},
+			},
+		},
+	}
+
+	result := containerSecurityContext(app)
+
+	require.NotNil(t, result)
+	assert.True(t, *result.ReadOnlyRootFilesystem)
+	assert.False(t, *result.AllowPrivilegeEscalation)
+	assert.Equal(t, []corev1.Capability{"NET_BIND_SERVICE"}, result.Capabilities.Add)
+	assert.Equal(t, []corev1.Capability{"ALL"}, result.Capabilities.Drop)
+	assert.Nil(t, podSecurityContext(app))
+}
@@ -0,0 +1,25 @@
+package resources
+
+import (
+	"github.com/riser-platform/riser-server/pkg/core"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateAppServiceAccount creates a service account shared by all of an app's deployments. The service account gives the app an identity
+// that other apps can allow access to (see CreateAccessAllowPolicy).
+func CreateAppServiceAccount(ctx *core.DeploymentContext) *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      string(ctx.DeploymentConfig.App.Name),
+			Namespace: ctx.DeploymentConfig.Namespace,
+			Labels: map[string]string{
+				riserLabel("app"): string(ctx.DeploymentConfig.App.Name),
+			},
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ServiceAccount",
+			APIVersion: "v1",
+		},
+	}
+}
@@ -0,0 +1,29 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/riser-platform/riser-server/api/v1/model"
+	"github.com/riser-platform/riser-server/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CreateAppServiceAccount(t *testing.T) {
+	ctx := &core.DeploymentContext{
+		DeploymentConfig: &core.DeploymentConfig{
+			Name:      "myapp-dep",
+			Namespace: "apps",
+			App: &model.AppConfig{
+				Name: "myapp",
+			},
+		},
+	}
+
+	result := CreateAppServiceAccount(ctx)
+
+	assert.Equal(t, "myapp", result.Name)
+	assert.Equal(t, "apps", result.Namespace)
+	assert.Equal(t, map[string]string{"riser.dev/app": "myapp"}, result.Labels)
+	assert.Equal(t, "ServiceAccount", result.TypeMeta.Kind)
+	assert.Equal(t, "v1", result.TypeMeta.APIVersion)
+}
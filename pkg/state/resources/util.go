@@ -20,10 +20,22 @@ func deploymentLabels(ctx *core.DeploymentContext) map[string]string {
 
 // deploymentAnnotations are annotations common to Riser deployment resources
 func deploymentAnnotations(ctx *core.DeploymentContext) map[string]string {
-	return map[string]string{
-		riserLabel("revision"):       strconv.FormatInt(ctx.RiserRevision, 10),
-		riserLabel("server-version"): util.VersionString,
+	annotations := map[string]string{}
+	// Metadata keys are validated to not collide with the riser annotations below
+	for key, value := range ctx.DeploymentConfig.Metadata {
+		annotations[riserLabel(key)] = value
 	}
+	annotations[riserLabel("revision")] = strconv.FormatInt(ctx.RiserRevision, 10)
+	annotations[riserLabel("server-version")] = util.VersionString
+	return annotations
+}
+
+// jobPodAnnotations are the annotations of a job's pods. The Istio sidecar is not injected since it never exits, which would prevent the
+// job from completing.
+func jobPodAnnotations(ctx *core.DeploymentContext) map[string]string {
+	annotations := deploymentAnnotations(ctx)
+	annotations["sidecar.istio.io/inject"] = "false"
+	return annotations
 }
 
 // riserLabel returns a fully qualified riser label or annotation (e.g. riser.dev/your-label)
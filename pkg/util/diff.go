@@ -0,0 +1,175 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DevNull is used as the file name in a unified diff header when a file was added or removed
+const DevNull = "/dev/null"
+
+const diffContextLines = 3
+
+type diffLine struct {
+	op   byte
+	text string
+}
+
+type diffHunk struct {
+	fromStart int
+	fromCount int
+	toStart   int
+	toCount   int
+	lines     []diffLine
+}
+
+// UnifiedDiff returns a unified diff (as with "diff -u") between two files. An empty string is returned when there are no differences.
+func UnifiedDiff(fromName, toName string, from, to []byte) string {
+	hunks := createDiffHunks(diffLines(splitLines(from), splitLines(to)))
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "--- %s\n+++ %s\n", fromName, toName)
+	for _, hunk := range hunks {
+		fmt.Fprintf(sb, "@@ -%s +%s @@\n", formatHunkRange(hunk.fromStart, hunk.fromCount), formatHunkRange(hunk.toStart, hunk.toCount))
+		for _, line := range hunk.lines {
+			sb.WriteByte(line.op)
+			sb.WriteString(line.text)
+			sb.WriteByte('\n')
+		}
+	}
+
+	return sb.String()
+}
+
+func splitLines(in []byte) []string {
+	if len(in) == 0 {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(string(in), "\n"), "\n")
+}
+
+// diffLines computes a line based edit script using the longest common subsequence. State files are small enough that the
+// quadratic cost is not a concern.
+func diffLines(from, to []string) []diffLine {
+	lcs := make([][]int, len(from)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	lines := []diffLine{}
+	i, j := 0, 0
+	for i < len(from) && j < len(to) {
+		if from[i] == to[j] {
+			lines = append(lines, diffLine{' ', from[i]})
+			i++
+			j++
+		} else if lcs[i+1][j] >= lcs[i][j+1] {
+			lines = append(lines, diffLine{'-', from[i]})
+			i++
+		} else {
+			lines = append(lines, diffLine{'+', to[j]})
+			j++
+		}
+	}
+	for ; i < len(from); i++ {
+		lines = append(lines, diffLine{'-', from[i]})
+	}
+	for ; j < len(to); j++ {
+		lines = append(lines, diffLine{'+', to[j]})
+	}
+
+	return lines
+}
+
+func createDiffHunks(lines []diffLine) []diffHunk {
+	hunks := []diffHunk{}
+	fromLine, toLine := 0, 0
+	idx := 0
+	for idx < len(lines) {
+		if lines[idx].op == ' ' {
+			fromLine++
+			toLine++
+			idx++
+			continue
+		}
+
+		// Walk back to include leading context
+		start := idx - diffContextLines
+		if start < 0 {
+			start = 0
+		}
+		hunk := diffHunk{
+			fromStart: fromLine - (idx - start) + 1,
+			toStart:   toLine - (idx - start) + 1,
+		}
+
+		// Extend the hunk until we find a run of unchanged lines long enough to separate it from the next change
+		end := idx
+		for end < len(lines) {
+			if lines[end].op != ' ' {
+				end++
+				continue
+			}
+			run := 0
+			for end+run < len(lines) && lines[end+run].op == ' ' {
+				run++
+			}
+			if end+run == len(lines) || run > diffContextLines*2 {
+				if run > diffContextLines {
+					run = diffContextLines
+				}
+				end += run
+				break
+			}
+			end += run
+		}
+
+		hunk.lines = lines[start:end]
+		for _, line := range hunk.lines {
+			if line.op != '+' {
+				hunk.fromCount++
+			}
+			if line.op != '-' {
+				hunk.toCount++
+			}
+		}
+		for _, line := range lines[idx:end] {
+			if line.op != '+' {
+				fromLine++
+			}
+			if line.op != '-' {
+				toLine++
+			}
+		}
+
+		hunks = append(hunks, hunk)
+		idx = end
+	}
+
+	return hunks
+}
+
+func formatHunkRange(start, count int) string {
+	// By convention an empty range starts at the line before the change
+	if count == 0 {
+		start--
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
@@ -0,0 +1,73 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_UnifiedDiff_NoChanges(t *testing.T) {
+	result := UnifiedDiff("a", "b", []byte("line1\nline2\n"), []byte("line1\nline2\n"))
+
+	assert.Empty(t, result)
+}
+
+func Test_UnifiedDiff_Added(t *testing.T) {
+	result := UnifiedDiff(DevNull, "b/file", nil, []byte("line1\nline2\n"))
+
+	assert.Equal(t, "--- /dev/null\n+++ b/file\n@@ -0,0 +1,2 @@\n+line1\n+line2\n", result)
+}
+
+func Test_UnifiedDiff_Removed(t *testing.T) {
+	result := UnifiedDiff("a/file", DevNull, []byte("line1\n"), nil)
+
+	assert.Equal(t, "--- a/file\n+++ /dev/null\n@@ -1 +0,0 @@\n-line1\n", result)
+}
+
+func Test_UnifiedDiff_Changed(t *testing.T) {
+	from := []byte("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n")
+	to := []byte("1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n")
+
+	result := UnifiedDiff("a/file", "b/file", from, to)
+
+	expected := `--- a/file
++++ b/file
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -13,3 +13,4 @@
+ 13
+ 14
+ 15
++16
+`
+	assert.Equal(t, expected, result)
+}
+
+func Test_UnifiedDiff_MergesNearbyChanges(t *testing.T) {
+	from := []byte("1\n2\n3\n4\n5\n6\n7\n8\n")
+	to := []byte("one\n2\n3\n4\n5\n6\n7\neight\n")
+
+	result := UnifiedDiff("a/file", "b/file", from, to)
+
+	expected := `--- a/file
++++ b/file
+@@ -1,8 +1,8 @@
+-1
++one
+ 2
+ 3
+ 4
+ 5
+ 6
+ 7
+-8
++eight
+`
+	assert.Equal(t, expected, result)
+}
@@ -18,6 +18,10 @@ func AssertSnapshot(t *testing.T, snapshotDir string, actualFiles []core.Resourc
 	snapshotFileMap := map[string][]byte{}
 
 	for _, file := range actualFiles {
+		// Deleted files cannot be represented in a snapshot
+		if file.Delete {
+			continue
+		}
 		actualFileMap[file.Name] = file.Contents
 	}
 